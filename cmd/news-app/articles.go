@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/jobrunner"
+)
+
+// topJobsLimit is how many jobs ArticleStats ranks by article count.
+const topJobsLimit = 5
+
+// ArticleStats summarises the saved (unarchived) articles of one user, or of
+// every user.
+type ArticleStats struct {
+	Total          int64
+	FirstRetrieved string // empty when there are no articles
+	LastRetrieved  string
+	TopJobs        []JobArticleCount
+	AvgPerRun      float64
+}
+
+// JobArticleCount is a job and how many saved articles it has.
+type JobArticleCount struct {
+	JobID    int64
+	Name     string
+	Articles int64
+}
+
+// ComputeArticleStats gathers ArticleStats for userID; a userID of 0 covers
+// every user.
+func ComputeArticleStats(ctx context.Context, db *sql.DB, userID int64) (ArticleStats, error) {
+	var stats ArticleStats
+	var first, last sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(retrieved_at), MAX(retrieved_at)
+		FROM articles
+		WHERE archived_at IS NULL AND (?1 = 0 OR user_id = ?1)
+	`, userID).Scan(&stats.Total, &first, &last)
+	if err != nil {
+		return stats, fmt.Errorf("count articles: %w", err)
+	}
+	stats.FirstRetrieved, stats.LastRetrieved = first.String, last.String
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT j.id, j.name, COUNT(*) AS articles
+		FROM articles a
+		JOIN jobs j ON a.job_id = j.id
+		WHERE a.archived_at IS NULL AND (?1 = 0 OR a.user_id = ?1)
+		GROUP BY j.id
+		ORDER BY articles DESC, j.name
+		LIMIT ?2
+	`, userID, topJobsLimit)
+	if err != nil {
+		return stats, fmt.Errorf("rank jobs: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var j JobArticleCount
+		if err := rows.Scan(&j.JobID, &j.Name, &j.Articles); err != nil {
+			return stats, fmt.Errorf("rank jobs: %w", err)
+		}
+		stats.TopJobs = append(stats.TopJobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("rank jobs: %w", err)
+	}
+
+	var avg sql.NullFloat64
+	err = db.QueryRowContext(ctx, `
+		SELECT AVG(COALESCE(jr.articles_saved, 0))
+		FROM job_runs jr
+		JOIN jobs j ON jr.job_id = j.id
+		WHERE ?1 = 0 OR j.user_id = ?1
+	`, userID).Scan(&avg)
+	if err != nil {
+		return stats, fmt.Errorf("average articles per run: %w", err)
+	}
+	stats.AvgPerRun = avg.Float64
+
+	return stats, nil
+}
+
+// ArticleRow is one line of `news-app articles list`.
+type ArticleRow struct {
+	ID          int64
+	Title       string
+	URL         string
+	RetrievedAt string
+}
+
+// ListRecentArticles returns up to limit of jobID's saved articles, newest
+// first.
+func ListRecentArticles(ctx context.Context, db *sql.DB, jobID int64, limit int) ([]ArticleRow, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, title, url, COALESCE(retrieved_at, '')
+		FROM articles
+		WHERE job_id = ? AND archived_at IS NULL
+		ORDER BY retrieved_at DESC, id DESC
+		LIMIT ?
+	`, jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []ArticleRow
+	for rows.Next() {
+		var a ArticleRow
+		if err := rows.Scan(&a.ID, &a.Title, &a.URL, &a.RetrievedAt); err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// PurgeResult counts the articles of a purge. Articles lists those found,
+// with the size of their content files.
+type PurgeResult struct {
+	Found       int
+	Deleted     int
+	FailedFiles int
+	FailedRows  int
+	Articles    []PurgeArticle
+}
+
+// PurgeArticle is an article found by PurgeArticles. Size is -1 when its
+// content file can't be read.
+type PurgeArticle struct {
+	ID          int64
+	Title       string
+	ContentPath string
+	Size        int64
+}
+
+// PurgeArticles permanently deletes the articles retrieved before before,
+// archived or not, with their content files. A userID or jobID of 0 covers
+// every user or job. Content files outside articlesDir are left alone and
+// count as failed, as do files that can't be removed; neither stops the
+// purge. A dry run only finds the articles.
+func PurgeArticles(ctx context.Context, db *sql.DB, userID int64, before time.Time, jobID int64, dryRun bool, articlesDir string) (PurgeResult, error) {
+	var res PurgeResult
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, title, content_path
+		FROM articles
+		WHERE retrieved_at < ?1 AND (?2 = 0 OR user_id = ?2) AND (?3 = 0 OR job_id = ?3)
+		ORDER BY retrieved_at, id
+	`, before.UTC().Format("2006-01-02 15:04:05"), userID, jobID)
+	if err != nil {
+		return res, fmt.Errorf("find articles: %w", err)
+	}
+	for rows.Next() {
+		a := PurgeArticle{Size: -1}
+		if err := rows.Scan(&a.ID, &a.Title, &a.ContentPath); err != nil {
+			rows.Close()
+			return res, fmt.Errorf("find articles: %w", err)
+		}
+		if info, err := os.Stat(a.ContentPath); err == nil {
+			a.Size = info.Size()
+		}
+		res.Articles = append(res.Articles, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, fmt.Errorf("find articles: %w", err)
+	}
+	res.Found = len(res.Articles)
+	if dryRun {
+		return res, nil
+	}
+
+	for _, a := range res.Articles {
+		if a.ContentPath != "" {
+			if !pathWithin(a.ContentPath, articlesDir) {
+				res.FailedFiles++
+			} else if err := os.Remove(a.ContentPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				res.FailedFiles++
+			}
+		}
+		if _, err := db.ExecContext(ctx, "DELETE FROM articles WHERE id = ?", a.ID); err != nil {
+			res.FailedRows++
+			continue
+		}
+		res.Deleted++
+	}
+	return res, nil
+}
+
+// pathWithin reports whether path is inside dir.
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func articlesCmd(args []string) error {
+	usage := "Usage: news-app articles stats [--user-id N]\n       news-app articles list --job N [--limit 20]\n       news-app articles purge --before YYYY-MM-DD [--user-id N] [--job-id N] [--dry-run]"
+	if len(args) < 1 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	switch args[0] {
+	case "stats":
+		fs := flag.NewFlagSet("articles stats", flag.ExitOnError)
+		userID := fs.Int64("user-id", 0, "only count this user's articles (default every user)")
+		fs.Parse(args[1:])
+
+		dbConn, err := openArticlesDBReadOnly()
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+
+		stats, err := ComputeArticleStats(context.Background(), dbConn, *userID)
+		if err != nil {
+			return err
+		}
+		return printArticleStats(os.Stdout, stats)
+	case "list":
+		fs := flag.NewFlagSet("articles list", flag.ExitOnError)
+		jobID := fs.Int64("job", 0, "job ID to list articles for")
+		limit := fs.Int("limit", 20, "maximum articles to list")
+		fs.Parse(args[1:])
+		if *jobID <= 0 {
+			return fmt.Errorf("--job is required\n%s", usage)
+		}
+		if *limit < 1 {
+			return fmt.Errorf("--limit must be at least 1")
+		}
+
+		dbConn, err := openArticlesDBReadOnly()
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+
+		articles, err := ListRecentArticles(context.Background(), dbConn, *jobID, *limit)
+		if err != nil {
+			return fmt.Errorf("list articles: %w", err)
+		}
+		return printArticleList(os.Stdout, articles)
+	case "purge":
+		fs := flag.NewFlagSet("articles purge", flag.ExitOnError)
+		beforeFlag := fs.String("before", "", "delete articles retrieved before this date (YYYY-MM-DD)")
+		userID := fs.Int64("user-id", 0, "only delete this user's articles (default every user)")
+		jobID := fs.Int64("job-id", 0, "only delete this job's articles (default every job)")
+		dryRun := fs.Bool("dry-run", false, "list the articles that would be deleted without deleting them")
+		fs.Parse(args[1:])
+		if *beforeFlag == "" {
+			return fmt.Errorf("--before is required\n%s", usage)
+		}
+		before, err := time.Parse("2006-01-02", *beforeFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --before date %q: want YYYY-MM-DD", *beforeFlag)
+		}
+
+		dbConn, err := openArticlesDB()
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+
+		res, err := PurgeArticles(context.Background(), dbConn, *userID, before, *jobID, *dryRun, jobrunner.DefaultConfig().ArticlesDir)
+		if err != nil {
+			return err
+		}
+		return printPurgeResult(os.Stdout, res, *dryRun)
+	default:
+		return fmt.Errorf("unknown articles command %q\n%s", args[0], usage)
+	}
+}
+
+// openArticlesDB opens the news-app database for commands that change it.
+func openArticlesDB() (*sql.DB, error) {
+	dbConn, err := db.Open(jobrunner.DefaultConfig().DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return dbConn, nil
+}
+
+// openArticlesDBReadOnly opens the news-app database read-only, for
+// commands that only report on it.
+func openArticlesDBReadOnly() (*sql.DB, error) {
+	dbConn, err := db.OpenReadOnly(jobrunner.DefaultConfig().DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return dbConn, nil
+}
+
+func printArticleStats(w io.Writer, stats ArticleStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Total articles:\t%d\n", stats.Total)
+	if stats.Total > 0 {
+		fmt.Fprintf(tw, "Date range:\t%s to %s\n", stats.FirstRetrieved, stats.LastRetrieved)
+	}
+	fmt.Fprintf(tw, "Avg articles per run:\t%.1f\n", stats.AvgPerRun)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(stats.TopJobs) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nTop jobs:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tJOB\tARTICLES")
+	for _, j := range stats.TopJobs {
+		fmt.Fprintf(tw, "%d\t%s\t%d\n", j.JobID, j.Name, j.Articles)
+	}
+	return tw.Flush()
+}
+
+func printArticleList(w io.Writer, articles []ArticleRow) error {
+	if len(articles) == 0 {
+		fmt.Fprintln(w, "No articles.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRETRIEVED\tTITLE\tURL")
+	for _, a := range articles {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", a.ID, a.RetrievedAt, a.Title, a.URL)
+	}
+	return tw.Flush()
+}
+
+func printPurgeResult(w io.Writer, res PurgeResult, dryRun bool) error {
+	if !dryRun {
+		fmt.Fprintf(w, "Found: %d, Deleted: %d, Failed files: %d, Failed rows: %d\n", res.Found, res.Deleted, res.FailedFiles, res.FailedRows)
+		return nil
+	}
+	if res.Found == 0 {
+		fmt.Fprintln(w, "No articles to purge.")
+		return nil
+	}
+
+	var total int64
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSIZE\tTITLE")
+	for _, a := range res.Articles {
+		size := "-"
+		if a.Size >= 0 {
+			size = fmt.Sprintf("%d", a.Size)
+			total += a.Size
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", a.ID, size, a.Title)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Would delete %d articles and %d bytes of content files.\n", res.Found, total)
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+func TestComputeArticleStats(t *testing.T) {
+	dbConn, err := db.Open(filepath.Join(t.TempDir(), "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer dbConn.Close()
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	alice, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "alice", Email: "alice@example.com"})
+	bob, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "bob", Email: "bob@example.com"})
+
+	// Alice has 3 articles from Space and 1 from Tech; Bob has 2 from Sport
+	articles := map[string]struct {
+		user  int64
+		count int
+	}{
+		"Space": {alice.ID, 3},
+		"Tech":  {alice.ID, 1},
+		"Sport": {bob.ID, 2},
+	}
+	for name, a := range articles {
+		job, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: a.user, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		run, err := queries.CreateJobRun(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+		if _, err := dbConn.Exec("UPDATE job_runs SET articles_saved = ? WHERE id = ?", a.count, run.ID); err != nil {
+			t.Fatal(err)
+		}
+		for i := range a.count {
+			_, err := queries.CreateArticle(ctx, dbgen.CreateArticleParams{
+				JobID: job.ID, UserID: a.user, Title: fmt.Sprintf("%s %d", name, i), Url: fmt.Sprintf("https://news.example/%s/%d", name, i),
+				RetrievedAt: time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("create article: %v", err)
+			}
+		}
+	}
+
+	stats, err := ComputeArticleStats(ctx, dbConn, alice.ID)
+	if err != nil {
+		t.Fatalf("ComputeArticleStats() error = %v", err)
+	}
+	if stats.Total != 4 || stats.AvgPerRun != 2 {
+		t.Errorf("total, avg per run = %d, %v, want 4, 2", stats.Total, stats.AvgPerRun)
+	}
+	if len(stats.TopJobs) != 2 || stats.TopJobs[0].Name != "Space" || stats.TopJobs[0].Articles != 3 {
+		t.Errorf("top jobs = %+v, want Space (3) first of 2", stats.TopJobs)
+	}
+	if stats.FirstRetrieved == "" || stats.LastRetrieved == "" {
+		t.Errorf("date range = %q to %q, want both ends set", stats.FirstRetrieved, stats.LastRetrieved)
+	}
+
+	// A user ID of 0 covers everyone
+	stats, err = ComputeArticleStats(ctx, dbConn, 0)
+	if err != nil || stats.Total != 6 || len(stats.TopJobs) != 3 {
+		t.Errorf("all users = %+v, %v, want 6 articles across 3 jobs", stats, err)
+	}
+
+	var buf bytes.Buffer
+	if err := printArticleStats(&buf, stats); err != nil || !strings.Contains(buf.String(), "Total articles:") {
+		t.Errorf("printArticleStats() = %q, %v", buf.String(), err)
+	}
+}
+
+func TestPurgeArticles(t *testing.T) {
+	tmp := t.TempDir()
+	dbConn, err := db.Open(filepath.Join(tmp, "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer dbConn.Close()
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	user, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "alice", Email: "alice@example.com"})
+	space, _ := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Space", Prompt: "p", Frequency: "daily"})
+	tech, _ := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Tech", Prompt: "p", Frequency: "daily"})
+
+	articlesDir := filepath.Join(tmp, "articles")
+	os.MkdirAll(articlesDir, 0755)
+	outside := filepath.Join(tmp, "outside.txt")
+	os.WriteFile(outside, []byte("keep"), 0644)
+
+	// Space has two old articles, one with a file outside the articles
+	// directory, and a new one; Tech has one old article
+	create := func(job dbgen.Job, title, retrieved, path string) {
+		t.Helper()
+		if path == "" {
+			path = filepath.Join(articlesDir, title+".txt")
+			os.WriteFile(path, []byte("content of "+title), 0644)
+		}
+		a, err := queries.CreateArticle(ctx, dbgen.CreateArticleParams{JobID: job.ID, UserID: user.ID, Title: title, Url: "https://news.example/" + title, ContentPath: path, RetrievedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("create article: %v", err)
+		}
+		dbConn.Exec("UPDATE articles SET retrieved_at = ? WHERE id = ?", retrieved, a.ID)
+	}
+	create(space, "old1", "2023-06-01 10:00:00", "")
+	create(space, "old2", "2023-12-31 23:59:59", outside)
+	create(space, "new", "2024-02-01 00:00:00", "")
+	create(tech, "tech-old", "2023-01-15 08:00:00", "")
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dry, err := PurgeArticles(ctx, dbConn, user.ID, before, space.ID, true, articlesDir)
+	if err != nil || dry.Found != 2 || dry.Deleted != 0 || len(dry.Articles) != 2 || dry.Articles[0].Size != int64(len("content of old1")) {
+		t.Fatalf("dry run = %+v, %v, want 2 found with sizes", dry, err)
+	}
+	var buf bytes.Buffer
+	printPurgeResult(&buf, dry, true)
+	if !strings.Contains(buf.String(), "old1") || !strings.Contains(buf.String(), "Would delete 2 articles") {
+		t.Errorf("printPurgeResult() = %q", buf.String())
+	}
+
+	res, err := PurgeArticles(ctx, dbConn, user.ID, before, space.ID, false, articlesDir)
+	if err != nil {
+		t.Fatalf("PurgeArticles() error = %v", err)
+	}
+	if res.Found != 2 || res.Deleted != 2 || res.FailedFiles != 1 || res.FailedRows != 0 {
+		t.Errorf("result = %+v, want 2 deleted and the outside file failed", res)
+	}
+	if _, err := os.Stat(filepath.Join(articlesDir, "old1.txt")); !os.IsNotExist(err) {
+		t.Errorf("old1 content file still exists: %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the articles directory was removed: %v", err)
+	}
+	if n, _ := queries.CountArticlesByUser(ctx, user.ID); n != 2 {
+		t.Errorf("remaining articles = %d, want the new one and Tech's", n)
+	}
+
+	// Without a job every job's old articles go
+	res, err = PurgeArticles(ctx, dbConn, 0, before, 0, false, articlesDir)
+	if err != nil || res.Deleted != 1 {
+		t.Errorf("purge of every job = %+v, %v, want Tech's article deleted", res, err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/jobrunner"
+)
+
+func dbStatsCmd(args []string) error {
+	fs := flag.NewFlagSet("db-stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	fs.Parse(args)
+
+	dbPath := jobrunner.DefaultConfig().DBPath
+	dbConn, err := db.OpenReadOnly(dbPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	stats, err := db.CollectDBStats(dbConn, dbPath)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	return printDBStats(os.Stdout, stats)
+}
+
+// printDBStats prints stats as a table of row counts followed by sizes.
+func printDBStats(w io.Writer, stats db.DBStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROWS")
+	tables := make([]string, 0, len(stats.TableCounts))
+	for table := range stats.TableCounts {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	for _, table := range tables {
+		fmt.Fprintf(tw, "%s\t%d\n", table, stats.TableCounts[table])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "Pages:\t%d of %d bytes\n", stats.PageCount, stats.PageSize)
+	fmt.Fprintf(tw, "Database size:\t%s\n", formatByteSize(stats.TotalSizeBytes))
+	fmt.Fprintf(tw, "Database file:\t%s\n", formatByteSize(stats.FileSizeBytes))
+	fmt.Fprintf(tw, "WAL file:\t%s\n", formatByteSize(stats.WALSizeBytes))
+	fmt.Fprintf(tw, "SHM file:\t%s\n", formatByteSize(stats.SHMSizeBytes))
+	return tw.Flush()
+}
+
+// formatByteSize formats n bytes in the largest binary unit that keeps the
+// value at least 1, e.g. "1.5 MiB".
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
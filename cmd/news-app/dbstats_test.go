@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/exedev/news-app/internal/db"
+)
+
+func TestPrintDBStats(t *testing.T) {
+	var out bytes.Buffer
+	stats := db.DBStats{
+		TableCounts:    map[string]int64{"articles": 1200, "users": 3},
+		PageCount:      384,
+		PageSize:       4096,
+		TotalSizeBytes: 384 * 4096,
+		FileSizeBytes:  384 * 4096,
+		WALSizeBytes:   512,
+	}
+	if err := printDBStats(&out, stats); err != nil {
+		t.Fatalf("printDBStats() error = %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"articles  1200", "users     3", "1.5 MiB", "512 B", "SHM file:", "0 B"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
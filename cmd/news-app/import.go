@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
+)
+
+// importBatchSize is how many CSV rows are read between ProcessArticles
+// calls and progress log lines.
+const importBatchSize = 100
+
+// importColumns are the CSV columns ImportArticlesCSV reads, in any order.
+// title, url and job_id are required.
+var importColumns = []string{"title", "url", "summary", "retrieved_at", "job_id"}
+
+// ImportOptions controls ImportArticlesCSV.
+type ImportOptions struct {
+	SkipDuplicates bool // count URLs already saved as skipped rather than failed
+	DryRun         bool // validate and count rows without saving anything
+	MaxParallel    int  // max concurrent article fetches; 0 uses the config's
+}
+
+// ImportResult counts the rows of an import. In a dry run Imported is the
+// number of rows that would be imported.
+type ImportResult struct {
+	Imported int
+	Skipped  int
+	Failed   int
+	Errors   []string
+}
+
+func (res *ImportResult) fail(format string, args ...any) {
+	res.Failed++
+	res.Errors = append(res.Errors, fmt.Sprintf(format, args...))
+}
+
+// importRow is a CSV row ready to be saved.
+type importRow struct {
+	job  dbgen.Job
+	info jobrunner.ArticleInfo
+}
+
+// ImportArticlesCSV saves the articles in a CSV file with a header row
+// naming the columns title, url, summary, retrieved_at and job_id. Articles
+// go through runner.ProcessArticles, so their content is fetched as for a
+// job run. retrieved_at may be RFC 3339 or YYYY-MM-DD; missing or invalid
+// dates are the time of import. Rows repeating a URL saved for the job's
+// user, or earlier in the file, are duplicates.
+func ImportArticlesCSV(ctx context.Context, dbConn *sql.DB, runner *jobrunner.Runner, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	var res ImportResult
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return res, fmt.Errorf("read header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"title", "url", "job_id"} {
+		if _, ok := cols[name]; !ok {
+			return res, fmt.Errorf("header has no %s column (want %s)", name, strings.Join(importColumns, ","))
+		}
+	}
+
+	queries := dbgen.New(dbConn)
+	jobs := make(map[int64]*dbgen.Job)
+	seen := make(map[string]bool) // user ID and URL of rows already accepted
+	var pending []importRow
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				res.fail("line %d: %v", line, parseErr.Err)
+				continue
+			}
+			return res, fmt.Errorf("read CSV: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		jobID, err := strconv.ParseInt(field("job_id"), 10, 64)
+		if err != nil {
+			res.fail("line %d: invalid job_id %q", line, field("job_id"))
+			continue
+		}
+		job, ok := jobs[jobID]
+		if !ok {
+			if j, err := queries.GetJobByID(ctx, jobID); err == nil {
+				job = &j
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return res, fmt.Errorf("get job %d: %w", jobID, err)
+			}
+			jobs[jobID] = job
+		}
+		if job == nil {
+			res.fail("line %d: job %d not found", line, jobID)
+			continue
+		}
+
+		// Sanitized here so duplicates are found by the URL that gets saved
+		info := jobrunner.ArticleInfo{Title: field("title"), URL: jobrunner.SanitizeArticleURL(field("url")), Summary: field("summary")}
+		if info.Title == "" {
+			res.fail("line %d: title is empty", line)
+			continue
+		}
+
+		if info.URL != "" {
+			key := fmt.Sprintf("%d %s", job.UserID, info.URL)
+			exists, err := queries.ArticleExistsByURL(ctx, dbgen.ArticleExistsByURLParams{UserID: job.UserID, Url: info.URL})
+			if err != nil {
+				return res, fmt.Errorf("check duplicate: %w", err)
+			}
+			if seen[key] || exists > 0 {
+				if opts.SkipDuplicates {
+					res.Skipped++
+				} else {
+					res.fail("line %d: duplicate URL %s", line, info.URL)
+				}
+				continue
+			}
+			seen[key] = true
+		}
+
+		retrievedAt := parseImportDate(field("retrieved_at"))
+		info.RetrievedAt = &retrievedAt
+		pending = append(pending, importRow{job: *job, info: info})
+		if (line-1)%importBatchSize == 0 {
+			if err := flushImport(ctx, runner, pending, opts, &res); err != nil {
+				return res, err
+			}
+			pending = pending[:0]
+			slog.Info("import progress", "rows", line-1, "imported", res.Imported, "skipped", res.Skipped, "failed", res.Failed)
+		}
+	}
+
+	if err := flushImport(ctx, runner, pending, opts, &res); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// parseImportDate parses an RFC 3339 or YYYY-MM-DD date, returning the
+// current time for anything else.
+func parseImportDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// flushImport saves rows, one ProcessArticles call per job. A dry run only
+// counts them.
+func flushImport(ctx context.Context, runner *jobrunner.Runner, rows []importRow, opts ImportOptions, res *ImportResult) error {
+	if opts.DryRun {
+		res.Imported += len(rows)
+		return nil
+	}
+
+	var jobIDs []int64
+	byJob := make(map[int64][]importRow)
+	for _, row := range rows {
+		if _, ok := byJob[row.job.ID]; !ok {
+			jobIDs = append(jobIDs, row.job.ID)
+		}
+		byJob[row.job.ID] = append(byJob[row.job.ID], row)
+	}
+
+	for _, jobID := range jobIDs {
+		jobRows := byJob[jobID]
+		articles := make([]jobrunner.ArticleInfo, len(jobRows))
+		for i, row := range jobRows {
+			articles[i] = row.info
+		}
+		saved, dups, err := runner.ProcessArticles(ctx, jobID, articles, jobrunner.ProcessArticlesOptions{MaxParallel: opts.MaxParallel})
+		if err != nil {
+			return fmt.Errorf("process articles for job %d: %w", jobID, err)
+		}
+		res.Imported += saved
+		res.Skipped += dups
+		// ProcessArticles drops articles with unusable URLs, logging why
+		if lost := len(articles) - saved - dups; lost > 0 {
+			res.Failed += lost
+			res.Errors = append(res.Errors, fmt.Sprintf("job %d: %d articles were not saved; see the log for why", jobID, lost))
+		}
+	}
+	return nil
+}
+
+func importArticlesCmd(args []string) error {
+	fs := flag.NewFlagSet("import-articles", flag.ExitOnError)
+	skipDuplicates := fs.Bool("skip-duplicates", true, "skip rows whose URL is already saved; otherwise count them as failed")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving anything")
+	parallel := fs.Int("parallel", 0, "max concurrent article fetches (default: NEWS_JOB_MAX_PARALLEL)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: news-app import-articles [--skip-duplicates=false] [--dry-run] [--parallel N] <articles.csv>")
+		fmt.Fprintln(os.Stderr, "\nImport articles from a CSV file with the columns "+strings.Join(importColumns, ",")+".")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("missing CSV file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	config := jobrunner.DefaultConfig()
+	dbConn, err := db.Open(config.DBPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	runner := jobrunner.NewRunner(dbConn, config)
+	res, err := ImportArticlesCSV(context.Background(), dbConn, runner, f, ImportOptions{
+		SkipDuplicates: *skipDuplicates,
+		DryRun:         *dryRun,
+		MaxParallel:    *parallel,
+	})
+	printImportResult(os.Stdout, res, *dryRun)
+	return err
+}
+
+func printImportResult(w io.Writer, res ImportResult, dryRun bool) {
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(w, "%s: %d, Skipped: %d, Failed: %d\n", verb, res.Imported, res.Skipped, res.Failed)
+	for _, e := range res.Errors {
+		fmt.Fprintf(w, "  - %s\n", e)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
+)
+
+func TestImportArticlesCSV(t *testing.T) {
+	tmp := t.TempDir()
+	dbConn, err := db.Open(filepath.Join(tmp, "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer dbConn.Close()
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	user, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "test-user", Email: "test@example.com"})
+	job, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Archive", Prompt: "p", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	config := jobrunner.DefaultConfig()
+	config.ArticlesDir = filepath.Join(tmp, "articles")
+	config.LogsDir = filepath.Join(tmp, "logs")
+	runner := jobrunner.NewRunner(dbConn, config)
+
+	// The last two rows repeat earlier URLs, one with a tracking parameter.
+	// The .invalid URLs fail to fetch immediately, which doesn't stop saving.
+	jobID := strconv.FormatInt(job.ID, 10)
+	csvData := "title,url,summary,retrieved_at,job_id\n" +
+		"One,https://news.invalid/1,First,2023-05-01," + jobID + "\n" +
+		"Two,https://news.invalid/2,Second,2023-05-02T10:00:00Z," + jobID + "\n" +
+		"Three,https://news.invalid/3,Third,not a date," + jobID + "\n" +
+		"One again,https://news.invalid/1,First,," + jobID + "\n" +
+		"Two again,https://news.invalid/2?utm_source=feed,Second,," + jobID + "\n"
+
+	dry, err := ImportArticlesCSV(ctx, dbConn, runner, strings.NewReader(csvData), ImportOptions{SkipDuplicates: true, DryRun: true})
+	if err != nil || dry.Imported != 3 || dry.Skipped != 2 {
+		t.Fatalf("dry run = %+v, %v, want 3 to import and 2 skipped", dry, err)
+	}
+	if n, _ := queries.CountArticlesByUser(ctx, user.ID); n != 0 {
+		t.Fatalf("dry run saved %d articles", n)
+	}
+
+	res, err := ImportArticlesCSV(ctx, dbConn, runner, strings.NewReader(csvData), ImportOptions{SkipDuplicates: true})
+	if err != nil {
+		t.Fatalf("ImportArticlesCSV() error = %v", err)
+	}
+	if res.Imported != 3 || res.Skipped != 2 || res.Failed != 0 {
+		t.Errorf("result = %+v, want 3 imported and 2 skipped", res)
+	}
+	if n, _ := queries.CountArticlesByUser(ctx, user.ID); n != 3 {
+		t.Errorf("saved articles = %d, want 3", n)
+	}
+	var retrieved string
+	if err := dbConn.QueryRow("SELECT retrieved_at FROM articles WHERE url = 'https://news.invalid/1'").Scan(&retrieved); err != nil || !strings.HasPrefix(retrieved, "2023-05-01") {
+		t.Errorf("retrieved_at = %q, %v, want the CSV's 2023-05-01", retrieved, err)
+	}
+
+	// Importing again finds only duplicates, which fail without
+	// --skip-duplicates; so do rows for unknown jobs
+	res, err = ImportArticlesCSV(ctx, dbConn, runner, strings.NewReader(csvData+"Four,https://news.invalid/4,,,999\n"), ImportOptions{})
+	if err != nil || res.Imported != 0 || res.Failed != 6 || len(res.Errors) != 6 {
+		t.Errorf("re-import = %+v, %v, want 6 failed rows", res, err)
+	}
+
+	var buf bytes.Buffer
+	printImportResult(&buf, res, false)
+	if !strings.Contains(buf.String(), "Failed: 6") || !strings.Contains(buf.String(), "job 999 not found") {
+		t.Errorf("printImportResult() = %q", buf.String())
+	}
+}
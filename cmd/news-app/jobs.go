@@ -0,0 +1,505 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
+	"github.com/exedev/news-app/internal/util"
+	"github.com/exedev/news-app/internal/web"
+)
+
+// JobsCommand implements `news-app jobs`, reading jobs straight from the
+// database so operators can check on them over SSH.
+type JobsCommand struct {
+	DB   *sql.DB
+	Out  io.Writer
+	JSON bool // print JSON instead of a table
+}
+
+// JobSummary is a row of `news-app jobs list`.
+type JobSummary struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	IsActive  bool       `json:"is_active"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	NextRunAt *time.Time `json:"next_run_at"`
+	Articles  int64      `json:"articles"`
+}
+
+// JobDetails is the output of `news-app jobs status`.
+type JobDetails struct {
+	JobSummary
+	Frequency     string     `json:"frequency"`
+	IsOneTime     bool       `json:"is_one_time"`
+	Prompt        string     `json:"prompt,omitempty"`
+	FeedURL       string     `json:"feed_url,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastRun       *RunDetail `json:"last_run"`
+}
+
+// RunDetail is the latest run shown by `news-app jobs status`.
+type RunDetail struct {
+	ID            int64      `json:"id"`
+	Status        string     `json:"status"`
+	StartedAt     time.Time  `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	ArticlesSaved *int64     `json:"articles_saved"`
+	ErrorMessage  string     `json:"error_message,omitempty"`
+}
+
+// ListJobs returns the jobs of userID, or of every user when it is 0, with
+// the given status, or any status when it is "all" or empty.
+func (c *JobsCommand) ListJobs(ctx context.Context, userID int64, status string) ([]JobSummary, error) {
+	if status == "all" {
+		status = ""
+	}
+	rows, err := c.DB.QueryContext(ctx, `
+		SELECT j.id, j.user_id, j.name, j.status, j.is_active, j.last_run_at, j.next_run_at,
+			(SELECT COUNT(*) FROM articles a WHERE a.job_id = j.id)
+		FROM jobs j
+		WHERE (?1 = 0 OR j.user_id = ?1) AND (?2 = '' OR j.status = ?2)
+		ORDER BY j.id
+	`, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []JobSummary
+	for rows.Next() {
+		var j JobSummary
+		if err := rows.Scan(&j.ID, &j.UserID, &j.Name, &j.Status, &j.IsActive, &j.LastRunAt, &j.NextRunAt, &j.Articles); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Details returns everything `news-app jobs status` shows for a job.
+func (c *JobsCommand) Details(ctx context.Context, jobID int64) (*JobDetails, error) {
+	var d JobDetails
+	err := c.DB.QueryRowContext(ctx, `
+		SELECT j.id, j.user_id, j.name, j.status, j.is_active, j.last_run_at, j.next_run_at,
+			(SELECT COUNT(*) FROM articles a WHERE a.job_id = j.id),
+			j.frequency, j.is_one_time, j.prompt, j.feed_url, j.created_at, j.last_success_at
+		FROM jobs j
+		WHERE j.id = ?
+	`, jobID).Scan(&d.ID, &d.UserID, &d.Name, &d.Status, &d.IsActive, &d.LastRunAt, &d.NextRunAt, &d.Articles,
+		&d.Frequency, &d.IsOneTime, &d.Prompt, &d.FeedURL, &d.CreatedAt, &d.LastSuccessAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("job %d not found", jobID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var run RunDetail
+	var errMsg sql.NullString
+	err = c.DB.QueryRowContext(ctx, `
+		SELECT id, status, started_at, completed_at, articles_saved, error_message
+		FROM job_runs
+		WHERE job_id = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, jobID).Scan(&run.ID, &run.Status, &run.StartedAt, &run.CompletedAt, &run.ArticlesSaved, &errMsg)
+	switch {
+	case err == nil:
+		run.ErrorMessage = errMsg.String
+		d.LastRun = &run
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("get last run: %w", err)
+	}
+	return &d, nil
+}
+
+// List prints the jobs of ListJobs.
+func (c *JobsCommand) List(ctx context.Context, userID int64, status string) error {
+	jobs, err := c.ListJobs(ctx, userID, status)
+	if err != nil {
+		return fmt.Errorf("list jobs: %w", err)
+	}
+	if c.JSON {
+		return c.printJSON(jobs)
+	}
+	if len(jobs) == 0 {
+		fmt.Fprintln(c.Out, "No jobs.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tLAST RUN\tNEXT RUN\tARTICLES")
+	for _, j := range jobs {
+		next := formatJobTime(j.NextRunAt)
+		if !j.IsActive {
+			next = "paused"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\n", j.ID, j.Name, j.Status, formatJobTime(j.LastRunAt), next, j.Articles)
+	}
+	return tw.Flush()
+}
+
+// Status prints the JobDetails of a job.
+func (c *JobsCommand) Status(ctx context.Context, jobID int64) error {
+	d, err := c.Details(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		return c.printJSON(d)
+	}
+
+	tw := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%d\n", d.ID)
+	fmt.Fprintf(tw, "Name:\t%s\n", d.Name)
+	fmt.Fprintf(tw, "User ID:\t%d\n", d.UserID)
+	fmt.Fprintf(tw, "Status:\t%s\n", d.Status)
+	fmt.Fprintf(tw, "Active:\t%t\n", d.IsActive)
+	frequency := d.Frequency
+	if d.IsOneTime {
+		frequency += " (one-time)"
+	}
+	fmt.Fprintf(tw, "Frequency:\t%s\n", frequency)
+	if d.FeedURL != "" {
+		fmt.Fprintf(tw, "Feed URL:\t%s\n", d.FeedURL)
+	} else {
+		fmt.Fprintf(tw, "Prompt:\t%s\n", d.Prompt)
+	}
+	fmt.Fprintf(tw, "Created:\t%s\n", d.CreatedAt.Local().Format(time.DateTime))
+	fmt.Fprintf(tw, "Last run:\t%s\n", formatJobTime(d.LastRunAt))
+	fmt.Fprintf(tw, "Last success:\t%s\n", formatJobTime(d.LastSuccessAt))
+	fmt.Fprintf(tw, "Next run:\t%s\n", formatJobTime(d.NextRunAt))
+	fmt.Fprintf(tw, "Articles:\t%d\n", d.Articles)
+	if run := d.LastRun; run != nil {
+		saved := "-"
+		if run.ArticlesSaved != nil {
+			saved = strconv.FormatInt(*run.ArticlesSaved, 10)
+		}
+		fmt.Fprintf(tw, "\nLast run ID:\t%d\n", run.ID)
+		fmt.Fprintf(tw, "Run status:\t%s\n", run.Status)
+		fmt.Fprintf(tw, "Started:\t%s\n", run.StartedAt.Local().Format(time.DateTime))
+		fmt.Fprintf(tw, "Completed:\t%s\n", formatJobTime(run.CompletedAt))
+		fmt.Fprintf(tw, "Articles saved:\t%s\n", saved)
+		if run.ErrorMessage != "" {
+			fmt.Fprintf(tw, "Error:\t%s\n", run.ErrorMessage)
+		}
+	}
+	return tw.Flush()
+}
+
+func (c *JobsCommand) printJSON(v any) error {
+	enc := json.NewEncoder(c.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formatJobTime formats an optional job time in local time, or "-".
+func formatJobTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// JobEditFlags are the job fields `news-app jobs edit` can change. Nil
+// fields are left as they are.
+type JobEditFlags struct {
+	Name      *string
+	Prompt    *string
+	Keywords  *string
+	Sources   *string
+	Region    *string
+	Frequency *string
+}
+
+func (f JobEditFlags) empty() bool {
+	return f.Name == nil && f.Prompt == nil && f.Keywords == nil && f.Sources == nil && f.Region == nil && f.Frequency == nil
+}
+
+// UpdateJobFromFlags changes the fields of a job set in flags, keeping the
+// rest. At least one field must be set.
+func UpdateJobFromFlags(ctx context.Context, dbConn *sql.DB, jobID int64, flags JobEditFlags) error {
+	if flags.empty() {
+		return errors.New("nothing to change: give at least one of --name, --prompt, --keywords, --sources, --region, --frequency or --interactive")
+	}
+	if flags.Frequency != nil {
+		switch *flags.Frequency {
+		case util.FreqHourly, util.Freq6Hours, util.FreqDaily, util.FreqWeekly:
+		default:
+			return fmt.Errorf("invalid frequency %q: want hourly, 6hours, daily or weekly", *flags.Frequency)
+		}
+	}
+	if flags.Name != nil && strings.TrimSpace(*flags.Name) == "" {
+		return errors.New("name can't be empty")
+	}
+
+	queries := dbgen.New(dbConn)
+	job, err := queries.GetJobByID(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("job %d not found", jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("get job: %w", err)
+	}
+
+	params := dbgen.UpdateJobParams{
+		Name:               job.Name,
+		Prompt:             job.Prompt,
+		Keywords:           job.Keywords,
+		Sources:            job.Sources,
+		Region:             job.Region,
+		Frequency:          job.Frequency,
+		IsActive:           job.IsActive,
+		FeedUrl:            job.FeedUrl,
+		FetchHeaders:       job.FetchHeaders,
+		FetchHeaderDomains: job.FetchHeaderDomains,
+		PromptTemplate:     job.PromptTemplate,
+		ScheduleJitterSecs: job.ScheduleJitterSecs,
+		ID:                 job.ID,
+		UserID:             job.UserID,
+	}
+	for _, f := range []struct {
+		value *string
+		field *string
+	}{
+		{flags.Name, &params.Name},
+		{flags.Prompt, &params.Prompt},
+		{flags.Keywords, &params.Keywords},
+		{flags.Sources, &params.Sources},
+		{flags.Region, &params.Region},
+		{flags.Frequency, &params.Frequency},
+	} {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+	if err := queries.UpdateJob(ctx, params); err != nil {
+		return fmt.Errorf("update job: %w", err)
+	}
+	return nil
+}
+
+// JobEditCommand implements `news-app jobs edit`, changing a job's
+// configuration from the command line.
+type JobEditCommand struct {
+	DB  *sql.DB
+	Out io.Writer
+	// EditPrompt lets the user edit a prompt for --interactive; nil opens
+	// it in $EDITOR
+	EditPrompt func(prompt string) (string, error)
+	// UpdateTimer rewrites a job's systemd timer after its frequency
+	// changes; nil uses web.UpdateJobTimer
+	UpdateTimer func(job dbgen.Job) error
+}
+
+// Run parses the arguments of `news-app jobs edit`, a job ID and the field
+// flags in either order, updates the job and prints it as JSON.
+func (c *JobEditCommand) Run(ctx context.Context, args []string) error {
+	usage := "Usage: news-app jobs edit <job_id> [--name NAME] [--prompt PROMPT] [--keywords KW1,KW2] [--sources S1,S2] [--region REGION] [--frequency hourly|6hours|daily|weekly] [--interactive]"
+	fs := flag.NewFlagSet("jobs edit", flag.ContinueOnError)
+	// Only flags given on the command line change their field
+	var flags JobEditFlags
+	for _, f := range []struct {
+		name, usage string
+		field       **string
+	}{
+		{"name", "new job name", &flags.Name},
+		{"prompt", "new prompt", &flags.Prompt},
+		{"keywords", "new comma-separated keywords", &flags.Keywords},
+		{"sources", "new comma-separated sources", &flags.Sources},
+		{"region", "new region", &flags.Region},
+		{"frequency", "new frequency: hourly, 6hours, daily or weekly", &flags.Frequency},
+	} {
+		fs.Func(f.name, f.usage, func(v string) error {
+			*f.field = &v
+			return nil
+		})
+	}
+	interactive := fs.Bool("interactive", false, "edit the current prompt in $EDITOR")
+
+	// Go's flag package stops at the first argument that isn't a flag, so
+	// accept the job ID before the flags too
+	var idArg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		idArg, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if idArg == "" && fs.NArg() > 0 {
+		idArg = fs.Arg(0)
+	}
+	if idArg == "" {
+		return fmt.Errorf("missing job ID\n%s", usage)
+	}
+	jobID, err := strconv.ParseInt(idArg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	queries := dbgen.New(c.DB)
+	before, err := queries.GetJobByID(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("job %d not found", jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("get job: %w", err)
+	}
+
+	if *interactive {
+		current := before.Prompt
+		if flags.Prompt != nil {
+			current = *flags.Prompt
+		}
+		editPrompt := c.EditPrompt
+		if editPrompt == nil {
+			editPrompt = editPromptInEditor
+		}
+		prompt, err := editPrompt(current)
+		if err != nil {
+			return err
+		}
+		if prompt == "" {
+			return errors.New("empty prompt, job not changed")
+		}
+		flags.Prompt = &prompt
+	}
+
+	if err := UpdateJobFromFlags(ctx, c.DB, jobID, flags); err != nil {
+		return err
+	}
+	job, err := queries.GetJobByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("get updated job: %w", err)
+	}
+
+	// Print the fetch header names without their credentials
+	printed := job
+	printed.FetchHeaders = jobrunner.RedactFetchHeaders(job.FetchHeaders)
+	enc := json.NewEncoder(c.Out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(printed); err != nil {
+		return err
+	}
+
+	if job.Frequency != before.Frequency && job.IsOneTime == 0 {
+		updateTimer := c.UpdateTimer
+		if updateTimer == nil {
+			updateTimer = web.UpdateJobTimer
+		}
+		if err := updateTimer(job); err != nil {
+			return fmt.Errorf("job updated, but its systemd timer wasn't: %w", err)
+		}
+	}
+	return nil
+}
+
+// editPromptInEditor opens prompt in $EDITOR (vi if unset) the way git
+// commit does, and returns the saved text without the comment lines.
+func editPromptInEditor(prompt string) (string, error) {
+	f, err := os.CreateTemp("", "news-app-prompt-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	header := "# Edit the job's prompt. Lines starting with '#' are ignored, and an\n# empty prompt leaves the job unchanged.\n"
+	if _, err := f.WriteString(prompt + "\n" + header); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may carry arguments, e.g. "code --wait", so let the shell split it
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run editor: %w", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return stripPromptComments(string(data)), nil
+}
+
+// stripPromptComments removes the '#' comment lines from an edited prompt
+// and trims the surrounding whitespace.
+func stripPromptComments(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func jobsCmd(args []string) error {
+	usage := "Usage: news-app jobs list [--user-id N] [--status running|failed|all] [--json]\n       news-app jobs status [--json] <job_id>\n       news-app jobs edit <job_id> [flags]"
+	if len(args) < 1 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	if args[0] == "edit" {
+		dbConn, err := openArticlesDB()
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+		return (&JobEditCommand{DB: dbConn, Out: os.Stdout}).Run(context.Background(), args[1:])
+	}
+
+	var run func(c *JobsCommand) error
+	fs := flag.NewFlagSet("jobs "+args[0], flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	switch args[0] {
+	case "list":
+		userID := fs.Int64("user-id", 0, "only list this user's jobs (default every user)")
+		status := fs.String("status", "all", "only list jobs with this status, e.g. running or failed")
+		fs.Parse(args[1:])
+		run = func(c *JobsCommand) error { return c.List(context.Background(), *userID, *status) }
+	case "status":
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("missing job ID\n%s", usage)
+		}
+		jobID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid job ID: %w", err)
+		}
+		run = func(c *JobsCommand) error { return c.Status(context.Background(), jobID) }
+	default:
+		return fmt.Errorf("unknown jobs command %q\n%s", args[0], usage)
+	}
+
+	dbConn, err := openArticlesDBReadOnly()
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+	return run(&JobsCommand{DB: dbConn, Out: os.Stdout, JSON: *asJSON})
+}
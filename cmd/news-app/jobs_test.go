@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+func TestJobsCommand(t *testing.T) {
+	dbConn, err := db.Open(filepath.Join(t.TempDir(), "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer dbConn.Close()
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	alice, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "alice", Email: "alice@example.com"})
+	bob, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "bob", Email: "bob@example.com"})
+
+	createJob := func(user int64, name, status string) dbgen.Job {
+		t.Helper()
+		job, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		if _, err := dbConn.Exec("UPDATE jobs SET status = ? WHERE id = ?", status, job.ID); err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+	space := createJob(alice.ID, "Space news", "failed")
+	createJob(alice.ID, "Tech news", "running")
+	createJob(bob.ID, "Sport news", "completed")
+
+	run, err := queries.CreateJobRun(ctx, space.ID)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	errMsg := "shelley: conversation timed out"
+	if err := queries.CompleteJobRun(ctx, dbgen.CompleteJobRunParams{Status: "failed", ErrorMessage: &errMsg, ID: run.ID}); err != nil {
+		t.Fatalf("complete run: %v", err)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &JobsCommand{DB: dbConn, Out: &out}
+		if err := c.List(ctx, alice.ID, "all"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		got := out.String()
+		for _, want := range []string{"NAME", "Space news", "failed", "Tech news", "running"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("List() output missing %q:\n%s", want, got)
+			}
+		}
+		if strings.Contains(got, "Sport news") {
+			t.Errorf("List() output has another user's job:\n%s", got)
+		}
+	})
+
+	t.Run("list by status", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &JobsCommand{DB: dbConn, Out: &out}
+		if err := c.List(ctx, 0, "failed"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if got := out.String(); !strings.Contains(got, "Space news") || strings.Contains(got, "Tech news") || strings.Contains(got, "Sport news") {
+			t.Errorf("List(failed) output:\n%s\nwant only the failed job", got)
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &JobsCommand{DB: dbConn, Out: &out}
+		if err := c.Status(ctx, space.ID); err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		got := out.String()
+		for _, want := range []string{"Space news", "failed", errMsg} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Status() output missing %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &JobsCommand{DB: dbConn, Out: &out, JSON: true}
+		if err := c.List(ctx, 0, "all"); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var jobs []JobSummary
+		if err := json.Unmarshal(out.Bytes(), &jobs); err != nil {
+			t.Fatalf("unmarshal output: %v\n%s", err, out.String())
+		}
+		if len(jobs) != 3 {
+			t.Errorf("List() JSON has %d jobs, want 3", len(jobs))
+		}
+	})
+
+	t.Run("missing job", func(t *testing.T) {
+		c := &JobsCommand{DB: dbConn, Out: &bytes.Buffer{}}
+		if err := c.Status(ctx, 999); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Status(999) error = %v, want not found", err)
+		}
+	})
+}
+
+func TestJobEditCommand(t *testing.T) {
+	dbConn, err := db.Open(filepath.Join(t.TempDir(), "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer dbConn.Close()
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	user, _ := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "alice", Email: "alice@example.com"})
+	job, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Space news", Prompt: "space", Keywords: "nasa", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	id := strconv.FormatInt(job.ID, 10)
+
+	t.Run("update from flags", func(t *testing.T) {
+		weekly := "weekly"
+		if err := UpdateJobFromFlags(ctx, dbConn, job.ID, JobEditFlags{Frequency: &weekly}); err != nil {
+			t.Fatalf("UpdateJobFromFlags() error = %v", err)
+		}
+		got, _ := queries.GetJobByID(ctx, job.ID)
+		if got.Frequency != "weekly" || got.Prompt != "space" || got.Keywords != "nasa" {
+			t.Errorf("job = %q %q %q, want weekly with the prompt and keywords unchanged", got.Frequency, got.Prompt, got.Keywords)
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		var out bytes.Buffer
+		var timers []dbgen.Job
+		if _, err := dbConn.ExecContext(ctx, "UPDATE jobs SET fetch_headers = ? WHERE id = ?", `{"Authorization": "Bearer secret"}`, job.ID); err != nil {
+			t.Fatalf("set fetch headers: %v", err)
+		}
+		c := &JobEditCommand{DB: dbConn, Out: &out, UpdateTimer: func(j dbgen.Job) error {
+			timers = append(timers, j)
+			return nil
+		}}
+		if err := c.Run(ctx, []string{id, "--frequency", "hourly", "--keywords", "kw1,kw2"}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		var printed dbgen.Job
+		if err := json.Unmarshal(out.Bytes(), &printed); err != nil {
+			t.Fatalf("unmarshal output: %v\n%s", err, out.String())
+		}
+		if printed.Frequency != "hourly" || printed.Keywords != "kw1,kw2" || printed.Prompt != "space" {
+			t.Errorf("printed job = %+v", printed)
+		}
+		if strings.Contains(out.String(), "Bearer secret") {
+			t.Errorf("output = %s, want the fetch header values redacted", out.String())
+		}
+		if len(timers) != 1 || timers[0].Frequency != "hourly" {
+			t.Errorf("timer updates = %v, want one for the hourly job", timers)
+		}
+
+		// A prompt change leaves the timer alone
+		timers = nil
+		if err := c.Run(ctx, []string{"--prompt", "rockets", id}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got, _ := queries.GetJobByID(ctx, job.ID); got.Prompt != "rockets" || len(timers) != 0 {
+			t.Errorf("prompt = %q with %d timer updates, want rockets and none", got.Prompt, len(timers))
+		}
+	})
+
+	t.Run("interactive", func(t *testing.T) {
+		c := &JobEditCommand{DB: dbConn, Out: &bytes.Buffer{}, EditPrompt: func(prompt string) (string, error) {
+			if prompt != "rockets" {
+				t.Errorf("editor got prompt %q, want the current one", prompt)
+			}
+			return "rockets and satellites", nil
+		}}
+		if err := c.Run(ctx, []string{id, "--interactive"}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got, _ := queries.GetJobByID(ctx, job.ID); got.Prompt != "rockets and satellites" {
+			t.Errorf("prompt = %q, want the edited one", got.Prompt)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c := &JobEditCommand{DB: dbConn, Out: &bytes.Buffer{}}
+		for _, args := range [][]string{
+			{id},
+			{id, "--frequency", "monthly"},
+			{"999", "--prompt", "x"},
+			{"--prompt", "x"},
+		} {
+			if err := c.Run(ctx, args); err == nil {
+				t.Errorf("Run(%q) succeeded, want an error", args)
+			}
+		}
+	})
+}
+
+func TestStripPromptComments(t *testing.T) {
+	got := stripPromptComments("Find news about rockets\n\nand satellites\n# Edit the job's prompt.\n# Ignored\n")
+	if want := "Find news about rockets\n\nand satellites"; got != want {
+		t.Errorf("stripPromptComments() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// logFollowInterval is how often tailFile checks a followed file for new
+// output.
+var logFollowInterval = 200 * time.Millisecond
+
+// RunLog is a job run and the path of its log file.
+type RunLog struct {
+	RunID int64
+	JobID int64
+	Path  string
+}
+
+// ListRunLogs returns the logs of the last runs with a log file, oldest
+// first. A non-zero jobID or runID limits them to that job or run.
+func ListRunLogs(ctx context.Context, db *sql.DB, jobID, runID int64, last int) ([]RunLog, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, job_id, log_path
+		FROM job_runs
+		WHERE log_path != '' AND (?1 = 0 OR job_id = ?1) AND (?2 = 0 OR id = ?2)
+		ORDER BY id DESC
+		LIMIT ?3
+	`, jobID, runID, last)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []RunLog
+	for rows.Next() {
+		var l RunLog
+		if err := rows.Scan(&l.RunID, &l.JobID, &l.Path); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	slices.Reverse(logs)
+	return logs, rows.Err()
+}
+
+// tailFile copies the file at path to w. With follow it then keeps copying
+// whatever is appended to the file, like tail -f, until ctx is done.
+func tailFile(ctx context.Context, path string, follow bool, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	if !follow {
+		return nil
+	}
+
+	// io.Copy left the offset at the end, where new output will appear
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := io.Copy(w, f); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// prefixWriter writes whole lines to w with a prefix, keeping any partial
+// line until it is completed or flushed. Writers sharing mu can write to
+// the same w without interleaving lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return len(b), err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// flush writes a trailing partial line, ending it with a newline.
+func (p *prefixWriter) flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
+
+// tailRunLogs tails the logs to w, prefixing lines with "[run_id] " when
+// there is more than one. Followed logs are tailed together. A log that
+// can't be read is reported to errw without stopping the others.
+func tailRunLogs(ctx context.Context, logs []RunLog, follow bool, w, errw io.Writer) {
+	var mu sync.Mutex
+	tail := func(l RunLog) {
+		out := io.Writer(w)
+		var pw *prefixWriter
+		if len(logs) > 1 {
+			pw = &prefixWriter{mu: &mu, w: w, prefix: fmt.Sprintf("[%d] ", l.RunID)}
+			out = pw
+		}
+		err := tailFile(ctx, l.Path, follow, out)
+		if pw != nil {
+			err = errors.Join(err, pw.flush())
+		}
+		if err != nil {
+			mu.Lock()
+			fmt.Fprintf(errw, "run %d: %v\n", l.RunID, err)
+			mu.Unlock()
+		}
+	}
+
+	if !follow {
+		for _, l := range logs {
+			tail(l)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for _, l := range logs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tail(l)
+		}()
+	}
+	wg.Wait()
+}
+
+func logsCmd(args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	jobID := fs.Int64("job-id", 0, "only show runs of this job")
+	runID := fs.Int64("run-id", 0, "show this run's log")
+	last := fs.Int("last", 1, "number of recent runs to show")
+	follow := fs.Bool("follow", false, "keep printing new output, like tail -f")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: news-app logs [--job-id N] [--run-id N] [--last 1] [--follow]")
+		fmt.Fprintln(os.Stderr, "\nPrint the log files of recent job runs.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *last < 1 {
+		return fmt.Errorf("--last must be at least 1")
+	}
+
+	dbConn, err := openArticlesDBReadOnly()
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logs, err := ListRunLogs(ctx, dbConn, *jobID, *runID, *last)
+	if err != nil {
+		return fmt.Errorf("list run logs: %w", err)
+	}
+	if len(logs) == 0 {
+		return fmt.Errorf("no run logs found")
+	}
+	tailRunLogs(ctx, logs, *follow, os.Stdout, os.Stderr)
+	return nil
+}
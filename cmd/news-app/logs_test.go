@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailFileFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	if err := os.WriteFile(path, []byte("started\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	interval := logFollowInterval
+	logFollowInterval = 10 * time.Millisecond
+	t.Cleanup(func() { logFollowInterval = interval })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- tailFile(ctx, path, true, &out) }()
+
+	go func() {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(f, "line %d\n", i)
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	want := "started\nline 1\nline 2\nline 3\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() != want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("tailFile() error = %v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("tailFile() wrote %q, want %q", got, want)
+	}
+}
+
+func TestTailRunLogsPrefixesRuns(t *testing.T) {
+	dir := t.TempDir()
+	logs := []RunLog{{RunID: 7, Path: filepath.Join(dir, "run_7.log")}, {RunID: 8, Path: filepath.Join(dir, "run_8.log")}}
+	os.WriteFile(logs[0].Path, []byte("a\nb"), 0644)
+	os.WriteFile(logs[1].Path, []byte("c\n"), 0644)
+	logs = append(logs, RunLog{RunID: 9, Path: filepath.Join(dir, "missing.log")})
+
+	var out, errOut bytes.Buffer
+	tailRunLogs(context.Background(), logs, false, &out, &errOut)
+	if want := "[7] a\n[7] b\n[8] c\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if !strings.HasPrefix(errOut.String(), "run 9: ") {
+		t.Errorf("errors = %q, want the missing run 9 log reported", errOut.String())
+	}
+}
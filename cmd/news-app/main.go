@@ -3,26 +3,54 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
 	"github.com/exedev/news-app/internal/jobrunner"
+	"github.com/exedev/news-app/internal/util"
 	"github.com/exedev/news-app/internal/web"
 )
 
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError is an error that makes the program exit with a particular
+// status instead of 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
 func run() error {
 	// Check for subcommand
 	if len(os.Args) >= 2 {
@@ -31,12 +59,34 @@ func run() error {
 			return runJobCmd(os.Args[2:])
 		case "resume-run":
 			return resumeRunCmd(os.Args[2:])
+		case "resume-orphans":
+			return resumeOrphansCmd(os.Args[2:])
 		case "cleanup":
 			return cleanupCmd(os.Args[2:])
 		case "troubleshoot":
 			return troubleshootCmd(os.Args[2:])
 		case "process-articles":
 			return processArticlesCmd(os.Args[2:])
+		case "db-wal-checkpoint":
+			return walCheckpointCmd(os.Args[2:])
+		case "db-stats":
+			return dbStatsCmd(os.Args[2:])
+		case "healthcheck":
+			return healthcheckCmd(os.Args[2:])
+		case "export-config":
+			return exportConfigCmd(os.Args[2:])
+		case "verify-systemd":
+			return verifySystemdCmd(os.Args[2:])
+		case "generate-config":
+			return generateConfigCmd(os.Args[2:])
+		case "articles":
+			return articlesCmd(os.Args[2:])
+		case "import-articles":
+			return importArticlesCmd(os.Args[2:])
+		case "logs":
+			return logsCmd(os.Args[2:])
+		case "jobs":
+			return jobsCmd(os.Args[2:])
 		case "help", "-h", "--help":
 			printUsage()
 			return nil
@@ -54,9 +104,20 @@ Commands:
   (default)              Start the web server
   run-job <id>           Execute a news job by ID
   resume-run <run_id>    Resume an interrupted job run
+  resume-orphans         Resume every run left in the running state
   cleanup                Clean up old Shelley conversations
   troubleshoot           Diagnose failed job runs
   process-articles       Process articles from JSON file
+  db-wal-checkpoint      Checkpoint the database's write-ahead log
+  db-stats               Print table row counts and database file sizes
+  healthcheck            Check a running server's /health, for liveness probes
+  export-config          Print the effective configuration for debugging
+  verify-systemd         Check job timer and service files against the database
+  generate-config        Write a reference TOML file of every setting and its default
+  articles               Print article statistics or list a job's recent articles
+  import-articles        Import articles from a CSV file
+  logs                   Print or follow the log files of recent job runs
+  jobs                   List, inspect or edit jobs
   help                   Show this help message
 
 Server flags:`)
@@ -65,31 +126,232 @@ Server flags:`)
 
 func runServer() error {
 	listenAddr := flag.String("listen", ":8000", "address to listen on")
+	unixSocket := flag.String("unix-socket", "", "listen on a Unix socket at this path instead of -listen, e.g. /run/news-app/news-app.sock")
+	hotReload := flag.Bool("hot-reload", false, "re-parse templates from disk on every request (development)")
+	maxBodySize := flag.Int64("max-body-size", 0, "maximum POST/PUT/PATCH request body in bytes (default NEWS_HTTP_MAX_BODY_SIZE_KB, or 1MB)")
+	autoRestart := flag.Bool("auto-restart", false, "restart in place when the binary is replaced or on SIGHUP, after in-flight requests finish")
+	autoResume := flag.Bool("auto-resume", true, "on startup, resume job runs left running by a previous server")
 	flag.Parse()
 
+	// The restarted process couldn't get the socket back from systemd, and
+	// systemd restarts a socket-activated service on demand anyway
+	if *autoRestart && systemdSocketPassed() {
+		return errors.New("-auto-restart can't be used with systemd socket activation")
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
 	}
 
-	server, err := web.New("db.sqlite3", hostname)
+	server, err := web.New("db.sqlite3", hostname,
+		web.WithTemplateHotReload(*hotReload),
+		web.WithMaxBodySize(*maxBodySize),
+		web.WithAutoResume(*autoResume),
+	)
 	if err != nil {
 		return fmt.Errorf("create server: %w", err)
 	}
 
-	return server.Serve(*listenAddr)
+	ln, err := getListener(*listenAddr, *unixSocket)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	if !*autoRestart {
+		return server.ServeListener(ln)
+	}
+
+	reloader, err := NewGracefulReloader(server.Drain)
+	if err != nil {
+		return fmt.Errorf("set up auto-restart: %w", err)
+	}
+
+	// Serve returns http.ErrServerClosed when the reloader drains it; the
+	// reloader then replaces this process, so only its errors matter.
+	errc := make(chan error, 2)
+	go func() {
+		if err := server.ServeListener(ln); !errors.Is(err, http.ErrServerClosed) {
+			errc <- err
+		}
+	}()
+	go func() {
+		errc <- reloader.Run(context.Background())
+	}()
+	return <-errc
+}
+
+// listenFDsStart is the first file descriptor systemd passes to a
+// socket-activated service.
+const listenFDsStart = 3
+
+// systemdSocketPassed reports whether systemd socket activation passed this
+// process a socket: LISTEN_FDS=1, and LISTEN_PID, when set, names the
+// process the socket is meant for.
+func systemdSocketPassed() bool {
+	if os.Getenv("LISTEN_FDS") != "1" {
+		return false
+	}
+	pid := os.Getenv("LISTEN_PID")
+	return pid == "" || pid == strconv.Itoa(os.Getpid())
+}
+
+// getListener returns the listener the server accepts connections on: the
+// socket passed by systemd socket activation when LISTEN_FDS=1, otherwise a
+// Unix socket at unixSocket if it is set, otherwise TCP on addr.
+func getListener(addr, unixSocket string) (net.Listener, error) {
+	if systemdSocketPassed() {
+		// Unset them so processes the server starts don't try to use the socket
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_PID")
+		f := os.NewFile(listenFDsStart, "systemd-socket")
+		defer f.Close()
+		ln, err := net.FileListener(f)
+		if err != nil {
+			return nil, fmt.Errorf("use systemd socket: %w", err)
+		}
+		return ln, nil
+	}
+
+	if unixSocket == "" {
+		return net.Listen("tcp", addr)
+	}
+	// A socket left behind by a previous server would make Listen fail
+	if err := os.Remove(unixSocket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("remove old socket: %w", err)
+	}
+	ln, err := net.Listen("unix", unixSocket)
+	if err != nil {
+		return nil, err
+	}
+	// Let a reverse proxy in the socket's group connect
+	if err := os.Chmod(unixSocket, 0660); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("set socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// Auto-restart defaults.
+const (
+	reloadPollInterval = 30 * time.Second
+	reloadDrainTimeout = 30 * time.Second
+)
+
+// GracefulReloader restarts the server in place with syscall.Exec when its
+// binary is replaced on disk or it receives SIGHUP. Before restarting it
+// drains the server so in-flight requests finish first. Job runs are
+// separate processes and carry on across the restart.
+type GracefulReloader struct {
+	Path         string        // binary to watch and re-exec
+	PollInterval time.Duration // how often to check Path's modification time
+	DrainTimeout time.Duration // how long to wait for HTTP requests before restarting anyway
+	Drain        func(ctx context.Context) error
+
+	modTime time.Time
+	exec    func(path string) error // replaces the process; stubbed in tests
+}
+
+// NewGracefulReloader returns a reloader for the running executable.
+func NewGracefulReloader(drain func(ctx context.Context) error) (*GracefulReloader, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("find executable: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat executable: %w", err)
+	}
+	return &GracefulReloader{
+		Path:         path,
+		PollInterval: reloadPollInterval,
+		DrainTimeout: reloadDrainTimeout,
+		Drain:        drain,
+		modTime:      info.ModTime(),
+		exec:         execSelf,
+	}, nil
+}
+
+// Run waits for the binary to change or for SIGHUP, then drains and restarts.
+// It only returns if ctx is done or the restart fails.
+func (g *GracefulReloader) Run(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(g.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-hup:
+			return g.reload("SIGHUP received")
+		case <-ticker.C:
+			if g.binaryChanged() {
+				return g.reload("binary updated")
+			}
+		}
+	}
+}
+
+// binaryChanged reports whether Path's modification time differs from when
+// the reloader started. A missing file, as during a non-atomic copy, counts
+// as unchanged until the next poll.
+func (g *GracefulReloader) binaryChanged() bool {
+	info, err := os.Stat(g.Path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(g.modTime)
+}
+
+func (g *GracefulReloader) reload(reason string) error {
+	slog.Info("restarting server", "reason", reason, "path", g.Path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.DrainTimeout)
+	defer cancel()
+	if err := g.Drain(ctx); err != nil {
+		// Only slow requests are cut short; job processes outlive the server
+		slog.Warn("drain incomplete, restarting anyway", "error", err)
+	}
+
+	if err := g.exec(g.Path); err != nil {
+		return fmt.Errorf("restart %s: %w", g.Path, err)
+	}
+	return nil
+}
+
+// execSelf replaces the current process with path, keeping the arguments and
+// environment.
+func execSelf(path string) error {
+	return syscall.Exec(path, os.Args, os.Environ())
 }
 
 func runJobCmd(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("usage: news-app run-job <job_id>")
+	fs := flag.NewFlagSet("run-job", flag.ExitOnError)
+	noDelay := fs.Bool("no-delay", false, "skip the random start delay")
+	timeout := fs.String("timeout", "", "override the job timeout for this run (e.g. 5m)")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("usage: news-app run-job [--no-delay] [--timeout duration] <job_id>")
 	}
 
-	jobID, err := strconv.ParseInt(args[0], 10, 64)
+	jobID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
 	if err != nil {
 		return fmt.Errorf("invalid job ID: %w", err)
 	}
 
+	opts := jobrunner.RunOptions{DisableStartDelay: *noDelay}
+	if *timeout != "" {
+		opts.OverrideTimeout, err = time.ParseDuration(*timeout)
+		if err != nil || opts.OverrideTimeout <= 0 {
+			return fmt.Errorf("invalid --timeout %q: must be a positive duration like 5m", *timeout)
+		}
+	}
+
 	// Open database
 	config := jobrunner.DefaultConfig()
 	dbConn, err := db.Open(config.DBPath)
@@ -109,7 +371,7 @@ func runJobCmd(args []string) error {
 	errChan := make(chan error, 1)
 	runner := jobrunner.NewRunner(dbConn, config)
 	go func() {
-		errChan <- runner.Run(ctx, jobID)
+		errChan <- runner.RunWithOptions(ctx, jobID, opts)
 	}()
 
 	// Wait for completion or signal
@@ -120,11 +382,12 @@ func runJobCmd(args []string) error {
 		fmt.Fprintf(os.Stderr, "\nReceived signal %v, shutting down gracefully...\n", sig)
 		cancel() // Cancel context to stop job gracefully
 		
-		// Wait up to 10 seconds for graceful shutdown
+		// Long-running overrides get proportionally more time to wind down
+		shutdownTimeout := max(10*time.Second, opts.OverrideTimeout/5)
 		select {
 		case err := <-errChan:
 			return err
-		case <-time.After(10 * time.Second):
+		case <-time.After(shutdownTimeout):
 			return fmt.Errorf("shutdown timeout")
 		}
 	}
@@ -180,15 +443,402 @@ func resumeRunCmd(args []string) error {
 	}
 }
 
+func resumeOrphansCmd(args []string) error {
+	config := jobrunner.DefaultConfig()
+	dbConn, err := db.Open(config.DBPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// Interrupted runs stay in the running state for the next resume
+	errChan := make(chan error, 1)
+	runner := jobrunner.NewRunner(dbConn, config)
+	go func() {
+		errChan <- runner.ResumeOrphanedRuns(ctx)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case sig := <-sigChan:
+		fmt.Fprintf(os.Stderr, "\nReceived signal %v, shutting down gracefully...\n", sig)
+		cancel()
+
+		select {
+		case err := <-errChan:
+			return err
+		case <-time.After(10 * time.Second):
+			return fmt.Errorf("shutdown timeout")
+		}
+	}
+}
+
+// HealthcheckResult mirrors the JSON body of the server's /health endpoint.
+type HealthcheckResult struct {
+	Status   string `json:"status"`
+	Database string `json:"database"`
+}
+
+// Degraded lists the checks in r that aren't "ok", as "name: value".
+func (r HealthcheckResult) Degraded() []string {
+	var fields []string
+	if r.Database != "ok" {
+		fields = append(fields, "database: "+r.Database)
+	}
+	return fields
+}
+
+// healthcheck exit codes, as returned by RunHealthcheck.
+const (
+	healthcheckHealthy  = 0
+	healthcheckDegraded = 1
+	healthcheckDown     = 2 // the server couldn't be reached
+)
+
+// healthcheckTimeout bounds the healthcheck command's request.
+const healthcheckTimeout = 5 * time.Second
+
+// RunHealthcheck fetches /health from the server at addr (host:port) and
+// returns its result with the exit code the healthcheck command uses: 0 if
+// the status is "ok", 1 if the server answered with anything else and 2 if
+// it couldn't be reached.
+func RunHealthcheck(addr string, timeout time.Duration) (HealthcheckResult, int, error) {
+	var result HealthcheckResult
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get("http://" + addr + "/health")
+	if err != nil {
+		return result, healthcheckDown, err
+	}
+	defer resp.Body.Close()
+
+	// The server answers 503 when degraded, with the same JSON body
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return result, healthcheckDegraded, fmt.Errorf("decode health response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "ok" {
+		return result, healthcheckDegraded, nil
+	}
+	return result, healthcheckHealthy, nil
+}
+
+func healthcheckCmd(args []string) error {
+	fs := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	port := fs.Int("port", 8000, "port the server listens on")
+	fs.Parse(args)
+
+	result, code, err := RunHealthcheck(net.JoinHostPort("localhost", strconv.Itoa(*port)), healthcheckTimeout)
+	switch {
+	case code == healthcheckHealthy:
+		return nil
+	case code == healthcheckDown:
+		return &exitCodeError{code, fmt.Errorf("server down: %w", err)}
+	case err != nil:
+		return &exitCodeError{code, err}
+	}
+	return &exitCodeError{code, fmt.Errorf("server %s: %s", result.Status, strings.Join(result.Degraded(), ", "))}
+}
+
+func walCheckpointCmd(args []string) error {
+	fs := flag.NewFlagSet("db-wal-checkpoint", flag.ExitOnError)
+	mode := fs.String("mode", "TRUNCATE", "checkpoint mode: PASSIVE, FULL, RESTART or TRUNCATE")
+	fs.Parse(args)
+
+	config := jobrunner.DefaultConfig()
+	dbConn, err := db.Open(config.DBPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	walked, checkpointed, err := db.WALCheckpoint(dbConn, *mode)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Checkpoint complete: %d of %d WAL pages checkpointed\n", checkpointed, walked)
+	return nil
+}
+
+func exportConfigCmd(args []string) error {
+	fs := flag.NewFlagSet("export-config", flag.ExitOnError)
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Parse(args)
+
+	return exportConfig(os.Stdout, *format)
+}
+
+func generateConfigCmd(args []string) error {
+	fs := flag.NewFlagSet("generate-config", flag.ExitOnError)
+	output := fs.String("output", "", "file to write (default stdout)")
+	uncomment := fs.Bool("uncomment", false, "write active assignments instead of commented-out ones")
+	fs.Parse(args)
+
+	if *output == "" {
+		return jobrunner.WriteConfigTOML(os.Stdout, jobrunner.DefaultConfig(), *uncomment)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("create config file: %w", err)
+	}
+	if err := jobrunner.WriteConfigTOML(f, jobrunner.DefaultConfig(), *uncomment); err != nil {
+		f.Close()
+		return fmt.Errorf("write config file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	return nil
+}
+
+// ConfigDump is the effective job runner configuration plus details of the
+// running binary, as printed by export-config. URLs that may carry
+// credentials are masked.
+type ConfigDump struct {
+	DBPath              string             `json:"db_path"`
+	ArticlesDir         string             `json:"articles_dir"`
+	LogsDir             string             `json:"logs_dir"`
+	ShelleyAPI          string             `json:"shelley_api"`
+	Model               string             `json:"model"`
+	ModelCosts          map[string]float64 `json:"model_costs_usd_per_million"`
+	JobTimeout          string             `json:"job_timeout"`
+	PollInterval        string             `json:"poll_interval"`
+	StatusCheckInterval string             `json:"status_check_interval"`
+	StartDelay          string             `json:"start_delay"`
+	MaxParallel         int                `json:"max_parallel"`
+	UserAgents          []string           `json:"user_agents"`
+	ProxyURL            string             `json:"proxy_url"`
+	ProxyURLs           []string           `json:"proxy_urls"`
+	CacheSize           int                `json:"cache_size"`
+	CacheTTL            string             `json:"cache_ttl"`
+	RespectRobots       bool               `json:"respect_robots_txt"`
+	ArticleDateDirs     bool               `json:"article_date_dirs"`
+	EnableFollowUp      bool               `json:"enable_follow_up"`
+	FollowUpMinArticles int                `json:"follow_up_min_articles"`
+	BatchNotifications  bool               `json:"batch_notifications"`
+	BatchWindow         string             `json:"batch_window"`
+	MaxConcurrentRuns   int                `json:"max_concurrent_runs"`
+
+	GoVersion  string `json:"go_version"`
+	OS         string `json:"os"`
+	Hostname   string `json:"hostname"`
+	BinaryPath string `json:"binary_path"`
+
+	ValidationErrors []string `json:"validation_errors"`
+}
+
+// newConfigDump describes config and the running process.
+func newConfigDump(config jobrunner.Config) ConfigDump {
+	d := ConfigDump{
+		DBPath:              config.DBPath,
+		ArticlesDir:         config.ArticlesDir,
+		LogsDir:             config.LogsDir,
+		ShelleyAPI:          urlHost(config.ShelleyAPI),
+		Model:               config.Model,
+		ModelCosts:          config.ModelCostMap,
+		JobTimeout:          config.JobTimeout.String(),
+		PollInterval:        config.PollInterval.String(),
+		StatusCheckInterval: config.StatusCheckInterval.String(),
+		StartDelay:          config.StartDelay.String(),
+		MaxParallel:         config.MaxParallel,
+		UserAgents:          config.UserAgents,
+		ProxyURL:            util.RedactURL(config.ProxyURL),
+		CacheSize:           config.CacheSize,
+		CacheTTL:            config.CacheTTL.String(),
+		RespectRobots:       config.RespectRobots,
+		ArticleDateDirs:     config.ArticleDateDirs,
+		EnableFollowUp:      config.EnableFollowUp,
+		FollowUpMinArticles: config.FollowUpMinArticles,
+		BatchNotifications:  config.BatchNotifications,
+		BatchWindow:         config.BatchWindow.String(),
+		MaxConcurrentRuns:   config.MaxConcurrentRuns,
+		GoVersion:           runtime.Version(),
+		OS:                  runtime.GOOS + "/" + runtime.GOARCH,
+		ValidationErrors:    []string{},
+	}
+	for _, p := range config.ProxyURLs {
+		d.ProxyURLs = append(d.ProxyURLs, util.RedactURL(p))
+	}
+	d.Hostname, _ = os.Hostname()
+	d.BinaryPath, _ = os.Executable()
+
+	if err := config.Validate(); err != nil {
+		d.ValidationErrors = strings.Split(err.Error(), "\n")
+	}
+	return d
+}
+
+// urlHost reduces a URL to its scheme and host, dropping any credentials,
+// path and query.
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// exportConfig writes the effective configuration to w as JSON or as a
+// table. Validation problems are included in the output rather than
+// returned, so scripts can always capture the dump.
+func exportConfig(w io.Writer, format string) error {
+	d := newConfigDump(jobrunner.DefaultConfig())
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	case "table":
+	default:
+		return fmt.Errorf("unknown format %q (want table or json)", format)
+	}
+
+	models := make([]string, 0, len(d.ModelCosts))
+	for model, cost := range d.ModelCosts {
+		models = append(models, fmt.Sprintf("%s=%g", model, cost))
+	}
+	sort.Strings(models)
+
+	userAgents := "built-in defaults"
+	if len(d.UserAgents) > 0 {
+		userAgents = fmt.Sprintf("%d configured", len(d.UserAgents))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"DB path", d.DBPath},
+		{"Articles dir", d.ArticlesDir},
+		{"Logs dir", d.LogsDir},
+		{"Shelley API", d.ShelleyAPI},
+		{"Model", d.Model},
+		{"Model costs (USD/M tokens)", strings.Join(models, ", ")},
+		{"Job timeout", d.JobTimeout},
+		{"Poll interval", d.PollInterval},
+		{"Status check interval", d.StatusCheckInterval},
+		{"Start delay", d.StartDelay},
+		{"Max parallel fetches", strconv.Itoa(d.MaxParallel)},
+		{"User agents", userAgents},
+		{"Proxy", d.ProxyURL},
+		{"Proxies", strings.Join(d.ProxyURLs, ", ")},
+		{"Cache size", strconv.Itoa(d.CacheSize)},
+		{"Cache TTL", d.CacheTTL},
+		{"Respect robots.txt", strconv.FormatBool(d.RespectRobots)},
+		{"Article date dirs", strconv.FormatBool(d.ArticleDateDirs)},
+		{"Follow-up rounds", strconv.FormatBool(d.EnableFollowUp)},
+		{"Follow-up min articles", strconv.Itoa(d.FollowUpMinArticles)},
+		{"Batch notifications", strconv.FormatBool(d.BatchNotifications)},
+		{"Batch window", d.BatchWindow},
+		{"Max concurrent runs", strconv.Itoa(d.MaxConcurrentRuns)},
+		{"Go version", d.GoVersion},
+		{"OS", d.OS},
+		{"Hostname", d.Hostname},
+		{"Binary", d.BinaryPath},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(d.ValidationErrors) > 0 {
+		fmt.Fprintln(w, "\nValidation warnings:")
+		for _, e := range d.ValidationErrors {
+			fmt.Fprintf(w, "  - %s\n", e)
+		}
+	}
+	return nil
+}
+
+func verifySystemdCmd(args []string) error {
+	fs := flag.NewFlagSet("verify-systemd", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "create missing unit files, enable disabled timers and remove orphaned files")
+	fs.Parse(args)
+
+	config := jobrunner.DefaultConfig()
+	dbConn, err := db.Open(config.DBPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	ctx := context.Background()
+	verifier := web.NewSystemdVerifier(dbgen.New(dbConn))
+	report, err := verifier.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	if report.OK() {
+		fmt.Println("Systemd units match the database.")
+		return nil
+	}
+	printSystemdReport(os.Stdout, report)
+
+	if !*fix {
+		return errors.New("systemd units are out of sync with the database (run with --fix to repair)")
+	}
+	if err := verifier.Fix(ctx, report); err != nil {
+		return fmt.Errorf("fix: %w", err)
+	}
+	fmt.Println("Fixed.")
+	return nil
+}
+
+// printSystemdReport lists each kind of inconsistency found by verify-systemd.
+func printSystemdReport(w io.Writer, report web.SystemdReport) {
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Missing service files (job IDs)", formatIDs(report.MissingServices)},
+		{"Missing timer files (job IDs)", formatIDs(report.MissingTimers)},
+		{"Disabled timers for active jobs (job IDs)", formatIDs(report.DisabledTimers)},
+		{"Orphaned unit files", report.OrphanedFiles},
+	}
+	for _, sec := range sections {
+		if len(sec.items) > 0 {
+			fmt.Fprintf(w, "%s: %s\n", sec.title, strings.Join(sec.items, ", "))
+		}
+	}
+}
+
+func formatIDs(ids []int64) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.FormatInt(id, 10)
+	}
+	return s
+}
+
 func cleanupCmd(args []string) error {
 	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
 	maxAge := fs.Int("max-age", 48, "max age in hours for conversations to keep")
 	dryRun := fs.Bool("dry-run", false, "show what would be deleted without deleting")
+	useAPI := fs.Bool("use-api", false, "find old conversations through the Shelley API instead of its database")
+	userID := fs.String("user-id", "", "only clean up conversations of this Shelley user, e.g. news-job-12")
+	jobID := fs.Int64("job-id", 0, "only clean up conversations of this job (its user news-job-<id>)")
+	telemetryDays := fs.Int("telemetry-days", 30, "days of article fetch telemetry to keep; 0 keeps all")
 	fs.Parse(args)
+	if *userID != "" && *jobID != 0 {
+		return fmt.Errorf("--user-id and --job-id can't be used together")
+	}
 
 	cfg := jobrunner.DefaultCleanupConfig()
 	cfg.MaxAgeHours = *maxAge
 	cfg.DryRun = *dryRun
+	cfg.UseAPI = *useAPI
+	cfg.UserID = *userID
+	if *jobID != 0 {
+		cfg.UserID = jobrunner.JobUserID(*jobID)
+	}
 
 	result, err := jobrunner.Cleanup(context.Background(), cfg)
 	if err != nil {
@@ -197,6 +847,20 @@ func cleanupCmd(args []string) error {
 
 	fmt.Printf("Cleanup complete: found %d, deleted %d, failed %d\n",
 		result.Found, result.Deleted, result.Failed)
+
+	if *telemetryDays <= 0 || cfg.DryRun {
+		return nil
+	}
+	dbConn, err := db.Open(jobrunner.DefaultConfig().DBPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer dbConn.Close()
+	pruned, err := jobrunner.PruneFetchTelemetry(context.Background(), dbConn, time.Duration(*telemetryDays)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pruned %d fetch telemetry rows older than %d days\n", pruned, *telemetryDays)
 	return nil
 }
 
@@ -204,17 +868,34 @@ func troubleshootCmd(args []string) error {
 	fs := flag.NewFlagSet("troubleshoot", flag.ExitOnError)
 	lookback := fs.Int("lookback", 24, "hours to look back for problems")
 	dryRun := fs.Bool("dry-run", false, "show problems without creating conversation")
+	fix := fs.Bool("fix", false, "re-run failed jobs instead of creating a conversation")
 	fs.Parse(args)
 
 	cfg := jobrunner.DefaultTroubleshootConfig()
 	cfg.Lookback = time.Duration(*lookback) * time.Hour
 	cfg.DryRun = *dryRun
+	cfg.Fix = *fix
+
+	if cfg.Fix && !cfg.DryRun {
+		config := jobrunner.DefaultConfig()
+		config.DBPath = cfg.DBPath
+		dbConn, err := db.Open(config.DBPath)
+		if err != nil {
+			return fmt.Errorf("open database: %w", err)
+		}
+		defer dbConn.Close()
+		cfg.Runner = jobrunner.NewRunner(dbConn, config)
+	}
 
 	result, err := jobrunner.Troubleshoot(context.Background(), cfg)
 	if err != nil {
 		return err
 	}
 
+	if result.Fix != nil {
+		return reportFixResult(result.Fix)
+	}
+
 	if result.ProblemsFound == 0 {
 		fmt.Println("No problems found.")
 	} else if result.ConversationID != "" {
@@ -225,15 +906,43 @@ func troubleshootCmd(args []string) error {
 	return nil
 }
 
+func reportFixResult(fix *jobrunner.FixResult) error {
+	if len(fix.JobIDs) == 0 {
+		fmt.Println("No failed jobs to fix.")
+		return nil
+	}
+
+	if fix.DryRun {
+		fmt.Printf("Would re-run %d failed jobs: %v\n", len(fix.JobIDs), fix.JobIDs)
+		return nil
+	}
+
+	for jobID, err := range fix.Failures {
+		fmt.Printf("Job %d: %v\n", jobID, err)
+	}
+	fmt.Printf("Re-ran %d jobs: %d succeeded, %d failed\n",
+		len(fix.JobIDs), len(fix.Succeeded), len(fix.Failures))
+
+	if !fix.OK() {
+		return fmt.Errorf("%d of %d fixes failed", len(fix.Failures), len(fix.JobIDs))
+	}
+	return nil
+}
+
 func processArticlesCmd(args []string) error {
 	fs := flag.NewFlagSet("process-articles", flag.ExitOnError)
+	parallel := fs.Int("parallel", 0, "max concurrent article fetches (default: NEWS_JOB_MAX_PARALLEL)")
+	dir := fs.String("dir", "", "process every articles_*.json file in this directory instead of a single file")
+	force := fs.Bool("force", false, "with --dir, reprocess files already listed in "+processedMarkerFile)
 	fs.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage: news-app process-articles <job_id> <articles.json>")
-		fmt.Fprintln(os.Stderr, "\nProcess articles from a JSON file and save them to the database.")
+		fmt.Fprintln(os.Stderr, "Usage: news-app process-articles [--parallel N] <job_id> <articles.json>")
+		fmt.Fprintln(os.Stderr, "       news-app process-articles [--parallel N] --dir path [--force] <job_id>")
+		fmt.Fprintln(os.Stderr, "\nProcess articles from JSON files and save them to the database.")
+		fs.PrintDefaults()
 	}
 	fs.Parse(args)
 
-	if fs.NArg() < 2 {
+	if fs.NArg() < 1 || (*dir == "" && fs.NArg() < 2) {
 		fs.Usage()
 		return fmt.Errorf("missing required arguments")
 	}
@@ -243,21 +952,15 @@ func processArticlesCmd(args []string) error {
 		return fmt.Errorf("invalid job ID: %w", err)
 	}
 
-	jsonPath := fs.Arg(1)
-
-	// Read and parse JSON
-	data, err := os.ReadFile(jsonPath)
-	if err != nil {
-		return fmt.Errorf("read file: %w", err)
-	}
-
 	var articles []jobrunner.ArticleInfo
-	if err := json.Unmarshal(data, &articles); err != nil {
-		return fmt.Errorf("parse JSON: %w", err)
+	if *dir == "" {
+		articles, err = readArticlesFile(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Found %d articles\n", len(articles))
 	}
 
-	fmt.Printf("Found %d articles\n", len(articles))
-
 	// Open database
 	config := jobrunner.DefaultConfig()
 	dbConn, err := db.Open(config.DBPath)
@@ -268,7 +971,13 @@ func processArticlesCmd(args []string) error {
 
 	// Process articles
 	runner := jobrunner.NewRunner(dbConn, config)
-	saved, dups, err := runner.ProcessArticles(context.Background(), jobID, articles)
+	opts := jobrunner.ProcessArticlesOptions{MaxParallel: *parallel}
+	var saved, dups int
+	if *dir != "" {
+		saved, dups, err = ProcessArticlesDirectory(context.Background(), runner, *dir, jobID, opts, *force)
+	} else {
+		saved, dups, err = runner.ProcessArticles(context.Background(), jobID, articles, opts)
+	}
 	if err != nil {
 		return fmt.Errorf("process articles: %w", err)
 	}
@@ -276,3 +985,112 @@ func processArticlesCmd(args []string) error {
 	fmt.Printf("Saved: %d, Duplicates: %d\n", saved, dups)
 	return nil
 }
+
+// processedMarkerFile lists, one per line, the files in a --dir directory
+// that have already been processed.
+const processedMarkerFile = "processed.txt"
+
+// ProcessArticlesDirectory processes every articles_*.json file in dir for a
+// job, in filename order, and returns the combined counts. Each file is
+// recorded in dir's processed.txt once done and skipped on later runs unless
+// force is set. It stops at the first file that fails, so a re-run resumes
+// from there.
+func ProcessArticlesDirectory(ctx context.Context, runner *jobrunner.Runner, dir string, jobID int64, opts jobrunner.ProcessArticlesOptions, force bool) (totalSaved, totalDups int, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "articles_*.json"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("list files: %w", err)
+	}
+
+	markerPath := filepath.Join(dir, processedMarkerFile)
+	processed, err := readProcessedMarker(markerPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if processed[name] && !force {
+			fmt.Printf("Skipping %s (already processed)\n", name)
+			continue
+		}
+
+		articles, err := readArticlesFile(path)
+		if err != nil {
+			return totalSaved, totalDups, err
+		}
+		saved, dups, err := runner.ProcessArticles(ctx, jobID, articles, opts)
+		if err != nil {
+			return totalSaved, totalDups, fmt.Errorf("%s: %w", name, err)
+		}
+		totalSaved += saved
+		totalDups += dups
+		fmt.Printf("%s: %d articles, saved %d, duplicates %d\n", name, len(articles), saved, dups)
+
+		if !processed[name] {
+			if err := appendLine(markerPath, name); err != nil {
+				return totalSaved, totalDups, fmt.Errorf("record %s as processed: %w", name, err)
+			}
+			processed[name] = true
+		}
+	}
+	return totalSaved, totalDups, nil
+}
+
+// articleFileEntry is an article in a process-articles JSON file. Unlike
+// agent output, it may say when the article was originally retrieved.
+type articleFileEntry struct {
+	jobrunner.ArticleInfo
+	RetrievedAt *time.Time `json:"retrieved_at,omitempty"`
+}
+
+// readArticlesFile reads a JSON array of articles.
+func readArticlesFile(path string) ([]jobrunner.ArticleInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var entries []articleFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse JSON %s: %w", path, err)
+	}
+	articles := make([]jobrunner.ArticleInfo, len(entries))
+	for i, e := range entries {
+		articles[i] = e.ArticleInfo
+		articles[i].RetrievedAt = e.RetrievedAt
+	}
+	return articles, nil
+}
+
+// readProcessedMarker returns the filenames listed in a processed.txt
+// marker file. A missing file means nothing has been processed.
+func readProcessedMarker(path string) (map[string]bool, error) {
+	processed := make(map[string]bool)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return processed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			processed[line] = true
+		}
+	}
+	return processed, nil
+}
+
+// appendLine appends line and a newline to the file at path, creating it if
+// needed.
+func appendLine(path, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -4,6 +4,7 @@ go 1.25.6
 
 require (
 	github.com/go-shiori/go-readability v0.0.0-20251205110129-5db1dc9836f0
+	go.uber.org/goleak v1.3.0
 	modernc.org/sqlite v1.39.0
 )
 
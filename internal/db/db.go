@@ -1,13 +1,19 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -20,25 +26,88 @@ var migrationFS embed.FS
 // migrationPattern matches files like "001-base.sql", "002-news-app.sql"
 var migrationPattern = regexp.MustCompile(`^(\d{3})-.*\.sql$`)
 
+// DBOpenOptions are the settings Open applies to a database.
+type DBOpenOptions struct {
+	InMemory  bool // open a private in-memory database instead of path, for tests
+	ReadOnly  bool // open with mode=ro, leaving the journal mode and auto-vacuum alone
+	CacheSize int  // page cache size in MB; 0 uses DefaultCacheSizeMB
+}
+
+// DBOpenOption configures Open.
+type DBOpenOption func(*DBOpenOptions)
+
+// DefaultCacheSizeMB is the page cache size of databases opened by Open.
+const DefaultCacheSizeMB = 10
+
+// WithInMemory makes Open ignore its path and open a new in-memory database,
+// shared by the connections of the returned *sql.DB but no other.
+func WithInMemory() DBOpenOption {
+	return func(o *DBOpenOptions) { o.InMemory = true }
+}
+
+// WithReadOnly makes Open open the database read-only.
+func WithReadOnly() DBOpenOption {
+	return func(o *DBOpenOptions) { o.ReadOnly = true }
+}
+
+// WithCacheSize sets the page cache size in MB.
+func WithCacheSize(mb int) DBOpenOption {
+	return func(o *DBOpenOptions) { o.CacheSize = mb }
+}
+
+// memoryDBCount numbers in-memory databases so each gets its own name.
+var memoryDBCount atomic.Int64
+
 // Open opens an sqlite database and prepares pragmas suitable for a small web app.
-func Open(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
+func Open(path string, opts ...DBOpenOption) (*sql.DB, error) {
+	o := DBOpenOptions{CacheSize: DefaultCacheSizeMB}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// _time_format=sqlite writes time.Time parameters as "2006-01-02
+	// 15:04:05-07:00", which SQLite's date functions understand and which
+	// sorts alongside CURRENT_TIMESTAMP values. Migration 031 rewrites
+	// values stored in the driver's old format.
+	//
+	// Per-connection pragmas go in the DSN as _pragma parameters, which the
+	// driver runs on every connection it opens; run once through db.Exec
+	// they would only reach whichever pooled connection took them.
+	params := fmt.Sprintf("_time_format=sqlite&_pragma=foreign_keys(1)&_pragma=busy_timeout(1000)&_pragma=cache_size(-%d)", o.CacheSize*1000)
+	dsn := path + "?" + params
+	switch {
+	case o.InMemory:
+		// A plain ":memory:" would give each pooled connection its own database
+		dsn = fmt.Sprintf("file:news-app-memdb-%d?mode=memory&cache=shared&%s", memoryDBCount.Add(1), params)
+	case o.ReadOnly:
+		dsn = "file:" + path + "?mode=ro&" + params
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
-	if err := configurePragmas(db); err != nil {
-		db.Close()
-		return nil, err
+	if !o.ReadOnly {
+		if err := configureDatabase(db); err != nil {
+			db.Close()
+			return nil, err
+		}
 	}
 	return db, nil
 }
 
-func configurePragmas(db *sql.DB) error {
-	pragmas := []string{
-		"PRAGMA foreign_keys=ON",
-		"PRAGMA journal_mode=wal",
-		"PRAGMA busy_timeout=1000",
-	}
+// OpenReadOnly opens the database at path read-only, for commands that only
+// inspect it.
+func OpenReadOnly(path string) (*sql.DB, error) {
+	return Open(path, WithReadOnly())
+}
+
+// configureDatabase sets the pragmas stored in the database file itself, so
+// setting them through any one connection is enough. auto_vacuum=INCREMENTAL
+// only takes effect on databases created with it, or after a VACUUM;
+// RunMigrations then reclaims free pages a little at a time.
+func configureDatabase(db *sql.DB) error {
+	// Must come before any table is created to apply to a new database
+	pragmas := []string{"PRAGMA auto_vacuum=INCREMENTAL", "PRAGMA journal_mode=wal"}
 	for _, p := range pragmas {
 		if _, err := db.Exec(p); err != nil {
 			return fmt.Errorf("%s: %w", p, err)
@@ -48,8 +117,22 @@ func configurePragmas(db *sql.DB) error {
 }
 
 // RunMigrations executes database migrations in numeric order (NNN-*.sql).
+// Each migration runs in its own transaction along with its migrations table
+// row, so a migration that fails part way leaves no trace. Afterwards up to
+// 100 free pages are reclaimed with an incremental vacuum.
 func RunMigrations(db *sql.DB) error {
-	migrations, err := listMigrationFiles()
+	if err := runMigrations(db, migrationFS); err != nil {
+		return err
+	}
+	if _, err := db.Exec("PRAGMA incremental_vacuum(100)"); err != nil {
+		slog.Warn("db: incremental vacuum", "error", err)
+	}
+	return nil
+}
+
+// runMigrations executes the migrations under fsys's migrations directory.
+func runMigrations(db *sql.DB, fsys fs.FS) error {
+	migrations, err := listMigrationFiles(fsys)
 	if err != nil {
 		return err
 	}
@@ -64,7 +147,7 @@ func RunMigrations(db *sql.DB) error {
 		if executed[num] {
 			continue
 		}
-		if err := executeMigration(db, m); err != nil {
+		if err := executeMigration(db, fsys, m); err != nil {
 			return fmt.Errorf("execute %s: %w", m, err)
 		}
 		slog.Info("db: applied migration", "file", m, "number", num)
@@ -72,9 +155,9 @@ func RunMigrations(db *sql.DB) error {
 	return nil
 }
 
-// listMigrationFiles returns sorted migration filenames from the embedded FS.
-func listMigrationFiles() ([]string, error) {
-	entries, err := migrationFS.ReadDir("migrations")
+// listMigrationFiles returns sorted migration filenames from fsys.
+func listMigrationFiles(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, "migrations")
 	if err != nil {
 		return nil, fmt.Errorf("read migrations dir: %w", err)
 	}
@@ -132,14 +215,279 @@ func parseMigrationNumber(filename string) int {
 	return n
 }
 
-// executeMigration reads and executes a single migration file.
-func executeMigration(db *sql.DB, filename string) error {
-	content, err := migrationFS.ReadFile("migrations/" + filename)
+// executeMigration reads and executes a single migration file in a
+// transaction. Migration files record themselves in the migrations table, so
+// a failed migration is rolled back without being marked as applied.
+func executeMigration(db *sql.DB, fsys fs.FS, filename string) error {
+	content, err := fs.ReadFile(fsys, "migrations/"+filename)
 	if err != nil {
 		return fmt.Errorf("read: %w", err)
 	}
-	if _, err := db.Exec(string(content)); err != nil {
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(content)); err != nil {
 		return fmt.Errorf("exec: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
 	return nil
 }
+
+// DefaultWALMaxBytes is the WAL file size above which MonitorDBIntegrity
+// checkpoints the database.
+const DefaultWALMaxBytes = 100 << 20
+
+// maxAlertDetails caps the quick_check output included in an alert, keeping
+// it within Discord's message size limit.
+const maxAlertDetails = 1500
+
+// NotificationSender delivers an alert message. It matches
+// jobrunner.NotificationSender, so a Discord notifier can be passed directly.
+type NotificationSender interface {
+	Send(ctx context.Context, message string) error
+}
+
+// MonitorResult is the outcome of one database health check.
+type MonitorResult struct {
+	IntegrityOK  bool
+	Details      string // quick_check output when IntegrityOK is false
+	WALSizeBytes int64
+	PageCount    int64
+}
+
+// CheckIntegrity runs PRAGMA quick_check. details holds the reported problems,
+// one per line, when ok is false.
+func CheckIntegrity(db *sql.DB) (ok bool, details string, err error) {
+	rows, err := db.Query("PRAGMA quick_check")
+	if err != nil {
+		return false, "", fmt.Errorf("quick_check: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return false, "", fmt.Errorf("scan quick_check: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return false, "", fmt.Errorf("quick_check: %w", err)
+	}
+
+	if len(lines) == 1 && lines[0] == "ok" {
+		return true, "", nil
+	}
+	return false, strings.Join(lines, "\n"), nil
+}
+
+// WALSize returns the size of the main database's WAL file, or 0 if there
+// is none (e.g. an in-memory database).
+func WALSize(db *sql.DB) (int64, error) {
+	rows, err := db.Query("PRAGMA database_list")
+	if err != nil {
+		return 0, fmt.Errorf("database_list: %w", err)
+	}
+	defer rows.Close()
+
+	var path string
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return 0, fmt.Errorf("scan database_list: %w", err)
+		}
+		if name == "main" {
+			path = file
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if path == "" {
+		return 0, nil
+	}
+
+	info, err := os.Stat(path + "-wal")
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// statsTables are the tables whose rows CollectDBStats counts.
+var statsTables = []string{"articles", "jobs", "job_runs", "preferences", "users"}
+
+// DBStats describes how large a database has grown.
+type DBStats struct {
+	TableCounts    map[string]int64 `json:"table_counts"` // rows per user-facing table
+	PageCount      int64            `json:"page_count"`
+	PageSize       int              `json:"page_size"`
+	TotalSizeBytes int64            `json:"total_size_bytes"` // PageCount * PageSize
+	FileSizeBytes  int64            `json:"file_size_bytes"`  // size of the database file on disk
+	WALSizeBytes   int64            `json:"wal_size_bytes"`
+	SHMSizeBytes   int64            `json:"shm_size_bytes"`
+}
+
+// CollectDBStats counts the rows of the user-facing tables and measures
+// the database at dbPath, along with its WAL and shared-memory files.
+// Files that don't exist, such as the WAL after a TRUNCATE checkpoint,
+// count as 0 bytes.
+func CollectDBStats(db *sql.DB, dbPath string) (DBStats, error) {
+	stats := DBStats{TableCounts: make(map[string]int64, len(statsTables))}
+	for _, table := range statsTables {
+		var n int64
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			return DBStats{}, fmt.Errorf("count %s: %w", table, err)
+		}
+		stats.TableCounts[table] = n
+	}
+
+	if err := db.QueryRow("PRAGMA page_count").Scan(&stats.PageCount); err != nil {
+		return DBStats{}, fmt.Errorf("page_count: %w", err)
+	}
+	if err := db.QueryRow("PRAGMA page_size").Scan(&stats.PageSize); err != nil {
+		return DBStats{}, fmt.Errorf("page_size: %w", err)
+	}
+	stats.TotalSizeBytes = stats.PageCount * int64(stats.PageSize)
+
+	for _, f := range []struct {
+		path string
+		size *int64
+	}{
+		{dbPath, &stats.FileSizeBytes},
+		{dbPath + "-wal", &stats.WALSizeBytes},
+		{dbPath + "-shm", &stats.SHMSizeBytes},
+	} {
+		info, err := os.Stat(f.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return DBStats{}, err
+		}
+		*f.size = info.Size()
+	}
+	return stats, nil
+}
+
+// walCheckpointModes are the modes accepted by WALCheckpoint.
+var walCheckpointModes = map[string]bool{"PASSIVE": true, "FULL": true, "RESTART": true, "TRUNCATE": true}
+
+// WALCheckpoint copies the WAL's contents into the database file with
+// PRAGMA wal_checkpoint. mode is PASSIVE, FULL, RESTART or TRUNCATE (any
+// case); TRUNCATE also empties the WAL file. pagesWalked is the number of
+// frames that were in the WAL and pagesCheckpointed how many were copied.
+// A checkpoint blocked by other connections is reported as an error.
+func WALCheckpoint(db *sql.DB, mode string) (pagesWalked, pagesCheckpointed int, err error) {
+	mode = strings.ToUpper(mode)
+	if !walCheckpointModes[mode] {
+		return 0, 0, fmt.Errorf("invalid checkpoint mode %q", mode)
+	}
+
+	// TRUNCATE reports the emptied WAL's counts (zero), so count the frames
+	// with a passive checkpoint first. A TRUNCATE that isn't blocked has
+	// copied every one of them.
+	if mode == "TRUNCATE" {
+		if pagesWalked, _, err = walCheckpoint(db, "PASSIVE"); err != nil {
+			return 0, 0, err
+		}
+		if _, _, err = walCheckpoint(db, mode); err != nil {
+			return pagesWalked, 0, err
+		}
+		return pagesWalked, pagesWalked, nil
+	}
+	return walCheckpoint(db, mode)
+}
+
+// walCheckpoint runs PRAGMA wal_checkpoint(mode) for a validated mode.
+func walCheckpoint(db *sql.DB, mode string) (pagesWalked, pagesCheckpointed int, err error) {
+	var busy int
+	err = db.QueryRow("PRAGMA wal_checkpoint("+mode+")").Scan(&busy, &pagesWalked, &pagesCheckpointed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("wal_checkpoint(%s): %w", mode, err)
+	}
+	if busy != 0 {
+		return pagesWalked, pagesCheckpointed, fmt.Errorf("wal_checkpoint(%s): database busy", mode)
+	}
+	return pagesWalked, pagesCheckpointed, nil
+}
+
+// CheckHealth checks the database's integrity and WAL size. If the WAL has
+// grown past walMaxBytes it is logged and truncated with a checkpoint.
+func CheckHealth(db *sql.DB, walMaxBytes int64) (MonitorResult, error) {
+	var result MonitorResult
+	ok, details, err := CheckIntegrity(db)
+	if err != nil {
+		return result, err
+	}
+	result.IntegrityOK, result.Details = ok, details
+
+	if err := db.QueryRow("PRAGMA page_count").Scan(&result.PageCount); err != nil {
+		return result, fmt.Errorf("page_count: %w", err)
+	}
+
+	if result.WALSizeBytes, err = WALSize(db); err != nil {
+		return result, fmt.Errorf("wal size: %w", err)
+	}
+	if walMaxBytes > 0 && result.WALSizeBytes > walMaxBytes {
+		slog.Warn("db: WAL file is large, checkpointing", "wal_size_bytes", result.WALSizeBytes, "max_bytes", walMaxBytes)
+		if _, _, err := WALCheckpoint(db, "TRUNCATE"); err != nil {
+			slog.Warn("db: WAL checkpoint failed", "error", err)
+		}
+	}
+	return result, nil
+}
+
+// MonitorDBIntegrity checks the database every interval until ctx is
+// cancelled, sending an alert through notifier (if non-nil) whenever the
+// integrity check fails. WAL files larger than walMaxBytes are checkpointed.
+func MonitorDBIntegrity(ctx context.Context, db *sql.DB, notifier NotificationSender, interval time.Duration, walMaxBytes int64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		monitorOnce(ctx, db, notifier, walMaxBytes)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// monitorOnce runs a single health check and reports failures.
+func monitorOnce(ctx context.Context, db *sql.DB, notifier NotificationSender, walMaxBytes int64) {
+	result, err := CheckHealth(db, walMaxBytes)
+	if err != nil {
+		slog.Warn("db: health check failed", "error", err)
+		return
+	}
+	if result.IntegrityOK {
+		slog.Debug("db: integrity ok", "page_count", result.PageCount, "wal_size_bytes", result.WALSizeBytes)
+		return
+	}
+
+	slog.Error("db: integrity check failed", "details", result.Details)
+	if notifier == nil {
+		return
+	}
+	details := result.Details
+	if len(details) > maxAlertDetails {
+		details = details[:maxAlertDetails] + "\n..."
+	}
+	msg := "⚠️ **Database integrity check failed**\n```\n" + details + "\n```"
+	if err := notifier.Send(ctx, msg); err != nil {
+		slog.Warn("db: send integrity alert", "error", err)
+	}
+}
@@ -0,0 +1,404 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeDriver answers the pragmas used by the health checks with canned rows,
+// simulating a database whose quick_check reports corruption.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, fmt.Errorf("not supported") }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return 0 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.ResultNoRows, nil }
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	switch s.query {
+	case "PRAGMA quick_check":
+		return &fakeRows{cols: []string{"quick_check"}, rows: [][]driver.Value{
+			{"*** in database main ***"},
+			{"corruption found"},
+		}}, nil
+	case "PRAGMA page_count":
+		return &fakeRows{cols: []string{"page_count"}, rows: [][]driver.Value{{int64(10)}}}, nil
+	case "PRAGMA database_list":
+		return &fakeRows{cols: []string{"seq", "name", "file"}, rows: [][]driver.Value{{int64(0), "main", ""}}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", s.query)
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("fake-corrupt", fakeDriver{})
+}
+
+// recordingNotifier records the messages it is asked to send.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (n *recordingNotifier) Send(_ context.Context, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.messages...)
+}
+
+func TestCheckIntegrityCorrupt(t *testing.T) {
+	db, err := sql.Open("fake-corrupt", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	ok, details, err := CheckIntegrity(db)
+	if err != nil {
+		t.Fatalf("CheckIntegrity() error = %v", err)
+	}
+	if ok || !strings.Contains(details, "corruption found") {
+		t.Errorf("CheckIntegrity() = (%v, %q), want failure with details", ok, details)
+	}
+
+	notifier := &recordingNotifier{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		MonitorDBIntegrity(ctx, db, notifier, time.Hour, DefaultWALMaxBytes)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(notifier.sent()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	sent := notifier.sent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "corruption found") {
+		t.Errorf("notifier messages = %q, want one alert with the quick_check output", sent)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "health.sqlite3"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+
+	result, err := CheckHealth(db, 1)
+	if err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+	if !result.IntegrityOK || result.Details != "" {
+		t.Errorf("integrity = (%v, %q), want ok", result.IntegrityOK, result.Details)
+	}
+	if result.PageCount == 0 || result.WALSizeBytes == 0 {
+		t.Errorf("page count = %d, WAL size = %d, want both non-zero", result.PageCount, result.WALSizeBytes)
+	}
+
+	// Exceeding the 1-byte limit truncated the WAL
+	if size, err := WALSize(db); err != nil || size != 0 {
+		t.Errorf("WAL size after checkpoint = %d (err %v), want 0", size, err)
+	}
+}
+
+func TestWALCheckpoint(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "wal.sqlite3"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	// Stop SQLite checkpointing on its own so the WAL keeps growing
+	if _, err := db.Exec("PRAGMA wal_autocheckpoint=0"); err != nil {
+		t.Fatalf("disable autocheckpoint: %v", err)
+	}
+	if _, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, body TEXT)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		if _, err := db.Exec("INSERT INTO items (body) VALUES (?)", strings.Repeat("x", 1000)); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if size, err := WALSize(db); err != nil || size == 0 {
+		t.Fatalf("WAL size before checkpoint = %d (err %v), want non-zero", size, err)
+	}
+
+	walked, checkpointed, err := WALCheckpoint(db, "TRUNCATE")
+	if err != nil {
+		t.Fatalf("WALCheckpoint() error = %v", err)
+	}
+	if checkpointed <= 0 || walked < checkpointed {
+		t.Errorf("walked %d, checkpointed %d pages, want checkpointed > 0", walked, checkpointed)
+	}
+	if size, err := WALSize(db); err != nil || size != 0 {
+		t.Errorf("WAL size after checkpoint = %d (err %v), want 0", size, err)
+	}
+
+	if _, _, err := WALCheckpoint(db, "EVERYTHING"); err == nil {
+		t.Error("WALCheckpoint() accepted an invalid mode")
+	}
+}
+
+func TestRunMigrationsRollsBackFailedMigration(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "migrate.sqlite3"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"migrations/001-base.sql": {Data: []byte(`
+CREATE TABLE migrations (migration_number INTEGER PRIMARY KEY, migration_name TEXT NOT NULL);
+INSERT INTO migrations (migration_number, migration_name) VALUES (1, '001-base');
+`)},
+		"migrations/002-broken.sql": {Data: []byte(`
+CREATE TABLE widgets (id INTEGER PRIMARY KEY);
+INSERT INTO no_such_table VALUES (1);
+INSERT INTO migrations (migration_number, migration_name) VALUES (2, '002-broken');
+`)},
+	}
+	if err := runMigrations(db, fsys); err == nil || !strings.Contains(err.Error(), "002-broken.sql") {
+		t.Fatalf("runMigrations() error = %v, want 002-broken.sql to fail", err)
+	}
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'widgets'").Scan(&n); err != nil || n != 0 {
+		t.Errorf("widgets tables = %d (err %v), want the failed migration's table rolled back", n, err)
+	}
+	executed, err := getExecutedMigrations(db)
+	if err != nil {
+		t.Fatalf("getExecutedMigrations() error = %v", err)
+	}
+	if !executed[1] || executed[2] {
+		t.Errorf("executed migrations = %v, want only 1", executed)
+	}
+}
+
+func TestNormalizeTimestampsMigration(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "timestamps.sqlite3"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+
+	// Values as the driver stored them before _time_format=sqlite, plus one
+	// already in CURRENT_TIMESTAMP's layout
+	values := map[string]string{
+		"a": "2026-01-02 03:04:05.123456789 +0000 UTC m=+0.001765064",
+		"b": "2026-01-02 23:30:00 -0400 EDT",
+		"c": "2026-01-02 03:04:05",
+	}
+	for id, v := range values {
+		if _, err := db.Exec("INSERT INTO visitors (id, view_count, created_at, last_seen) VALUES (?, 0, ?, ?)", id, v, v); err != nil {
+			t.Fatalf("insert visitor: %v", err)
+		}
+	}
+	migration, err := migrationFS.ReadFile("migrations/031-normalize-timestamps.sql")
+	if err != nil {
+		t.Fatalf("read migration: %v", err)
+	}
+	if _, err := db.Exec(string(migration)); err != nil {
+		t.Fatalf("rerun migration 031: %v", err)
+	}
+
+	want := map[string]string{
+		"a": "2026-01-02 03:04:05",
+		"b": "2026-01-03 03:30:00",
+		"c": "2026-01-02 03:04:05",
+	}
+	for id, w := range want {
+		var created, lastSeen string
+		if err := db.QueryRow("SELECT created_at || '', last_seen || '' FROM visitors WHERE id = ?", id).Scan(&created, &lastSeen); err != nil {
+			t.Fatalf("select visitor %s: %v", id, err)
+		}
+		if created != w || lastSeen != w {
+			t.Errorf("visitor %s = %q, %q, want %q", id, created, lastSeen, w)
+		}
+	}
+}
+
+func TestOpenInMemory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	open := func() *sql.DB {
+		t.Helper()
+		db, err := Open("db.sqlite3", WithInMemory())
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	db := open()
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	executed, err := getExecutedMigrations(db)
+	if err != nil {
+		t.Fatalf("getExecutedMigrations() error = %v", err)
+	}
+	files, err := listMigrationFiles(migrationFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, _ := strconv.Atoi(files[len(files)-1][:3])
+	if !executed[last] {
+		t.Errorf("migration %d was not recorded", last)
+	}
+	var jobs int
+	if err := db.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&jobs); err != nil {
+		t.Errorf("query jobs table: %v", err)
+	}
+
+	// Each in-memory database is separate
+	var n int
+	if err := open().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'migrations'").Scan(&n); err != nil || n != 0 {
+		t.Errorf("second database has %d migrations tables (err %v), want none", n, err)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("in-memory database wrote files: %v", entries)
+	}
+}
+
+func TestOpenPragmas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pragmas.sqlite3")
+	db, err := Open(path, WithCacheSize(4))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	var autoVacuum, cacheSize int
+	db.QueryRow("PRAGMA auto_vacuum").Scan(&autoVacuum)
+	db.QueryRow("PRAGMA cache_size").Scan(&cacheSize)
+	if autoVacuum != 2 || cacheSize != -4000 {
+		t.Errorf("auto_vacuum = %d, cache_size = %d; want 2 (incremental) and -4000", autoVacuum, cacheSize)
+	}
+
+	// Per-connection pragmas apply to every pooled connection, not just the
+	// first
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Conn() error = %v", err)
+		}
+		defer conn.Close()
+		var foreignKeys, busyTimeout int
+		conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+		conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout)
+		if foreignKeys != 1 || busyTimeout != 1000 {
+			t.Errorf("connection %d: foreign_keys = %d, busy_timeout = %d; want 1 and 1000", i, foreignKeys, busyTimeout)
+		}
+	}
+	if _, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer ro.Close()
+	if _, err := ro.Exec("INSERT INTO items DEFAULT VALUES"); err == nil {
+		t.Error("read-only database accepted an insert")
+	}
+}
+
+func TestCollectDBStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.sqlite3")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+
+	if _, err := db.Exec("INSERT INTO users (exe_user_id, email) VALUES ('u1', 'u1@example.com')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO jobs (user_id, name, prompt, frequency) VALUES (1, 'Job', 'p', 'daily')"); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if _, err := db.Exec("INSERT INTO articles (job_id, user_id, title, url, content_path) VALUES (1, 1, 'Article', ?, '')", fmt.Sprintf("https://example.com/%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := CollectDBStats(db, path)
+	if err != nil {
+		t.Fatalf("CollectDBStats() error = %v", err)
+	}
+	if got := stats.TableCounts["articles"]; got != 3 {
+		t.Errorf("articles count = %d, want 3", got)
+	}
+	if got := stats.TableCounts["users"]; got != 1 {
+		t.Errorf("users count = %d, want 1", got)
+	}
+	if stats.PageCount == 0 || stats.TotalSizeBytes != stats.PageCount*int64(stats.PageSize) {
+		t.Errorf("page_count = %d, page_size = %d, total = %d", stats.PageCount, stats.PageSize, stats.TotalSizeBytes)
+	}
+	if stats.FileSizeBytes == 0 || stats.WALSizeBytes == 0 {
+		t.Errorf("file size = %d, WAL size = %d; want both nonzero", stats.FileSizeBytes, stats.WALSizeBytes)
+	}
+}
@@ -26,8 +26,19 @@ func (q *Queries) ArticleExistsByURL(ctx context.Context, arg ArticleExistsByURL
 	return count, err
 }
 
+const countArchivedArticles = `-- name: CountArchivedArticles :one
+SELECT COUNT(*) FROM articles WHERE user_id = ? AND archived_at IS NOT NULL
+`
+
+func (q *Queries) CountArchivedArticles(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countArchivedArticles, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countArticlesByJob = `-- name: CountArticlesByJob :one
-SELECT COUNT(*) FROM articles WHERE job_id = ? AND user_id = ?
+SELECT COUNT(*) FROM articles WHERE job_id = ? AND user_id = ? AND archived_at IS NULL
 `
 
 type CountArticlesByJobParams struct {
@@ -42,8 +53,24 @@ func (q *Queries) CountArticlesByJob(ctx context.Context, arg CountArticlesByJob
 	return count, err
 }
 
+const countArticlesBySourceName = `-- name: CountArticlesBySourceName :one
+SELECT COUNT(*) FROM articles WHERE user_id = ? AND source_name = ? AND archived_at IS NULL
+`
+
+type CountArticlesBySourceNameParams struct {
+	UserID     int64  `json:"user_id"`
+	SourceName string `json:"source_name"`
+}
+
+func (q *Queries) CountArticlesBySourceName(ctx context.Context, arg CountArticlesBySourceNameParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countArticlesBySourceName, arg.UserID, arg.SourceName)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countArticlesByUser = `-- name: CountArticlesByUser :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ?
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL
 `
 
 func (q *Queries) CountArticlesByUser(ctx context.Context, userID int64) (int64, error) {
@@ -54,7 +81,7 @@ func (q *Queries) CountArticlesByUser(ctx context.Context, userID int64) (int64,
 }
 
 const countArticlesByUserDateRange = `-- name: CountArticlesByUserDateRange :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND retrieved_at >= ? AND retrieved_at <= ?
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? AND retrieved_at <= ?
 `
 
 type CountArticlesByUserDateRangeParams struct {
@@ -70,8 +97,52 @@ func (q *Queries) CountArticlesByUserDateRange(ctx context.Context, arg CountArt
 	return count, err
 }
 
+const countArticlesByUserPerDay = `-- name: CountArticlesByUserPerDay :many
+SELECT CAST(strftime('%Y-%m-%d', retrieved_at) AS TEXT) AS date, COUNT(*) AS count
+FROM articles
+WHERE user_id = ?1 AND archived_at IS NULL AND retrieved_at >= ?2
+    AND (CAST(?3 AS INTEGER) = 0 OR job_id = ?3)
+GROUP BY date
+ORDER BY date
+`
+
+type CountArticlesByUserPerDayParams struct {
+	UserID int64     `json:"user_id"`
+	Since  time.Time `json:"since"`
+	JobID  int64     `json:"job_id"`
+}
+
+type CountArticlesByUserPerDayRow struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// Articles retrieved per UTC day since @since; a job_id of 0 counts every job.
+func (q *Queries) CountArticlesByUserPerDay(ctx context.Context, arg CountArticlesByUserPerDayParams) ([]CountArticlesByUserPerDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, countArticlesByUserPerDay, arg.UserID, arg.Since, arg.JobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountArticlesByUserPerDayRow{}
+	for rows.Next() {
+		var i CountArticlesByUserPerDayRow
+		if err := rows.Scan(&i.Date, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countArticlesByUserSince = `-- name: CountArticlesByUserSince :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND retrieved_at >= ?
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ?
 `
 
 type CountArticlesByUserSinceParams struct {
@@ -86,9 +157,29 @@ func (q *Queries) CountArticlesByUserSince(ctx context.Context, arg CountArticle
 	return count, err
 }
 
+const countSearchArticles = `-- name: CountSearchArticles :one
+SELECT COUNT(*) FROM articles_fts
+JOIN articles ON articles.id = articles_fts.rowid
+WHERE articles_fts MATCH ?1 AND articles.user_id = ?2 AND articles.archived_at IS NULL
+    AND (CAST(?3 AS INTEGER) = 0 OR articles.job_id = ?3)
+`
+
+type CountSearchArticlesParams struct {
+	Query  string `json:"query"`
+	UserID int64  `json:"user_id"`
+	JobID  int64  `json:"job_id"`
+}
+
+func (q *Queries) CountSearchArticles(ctx context.Context, arg CountSearchArticlesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchArticles, arg.Query, arg.UserID, arg.JobID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countSearchArticlesByUser = `-- name: CountSearchArticlesByUser :one
 SELECT COUNT(*) FROM articles 
-WHERE user_id = ? AND (title LIKE ? OR summary LIKE ?)
+WHERE user_id = ? AND archived_at IS NULL AND (title LIKE ? OR summary LIKE ?)
 `
 
 type CountSearchArticlesByUserParams struct {
@@ -105,18 +196,24 @@ func (q *Queries) CountSearchArticlesByUser(ctx context.Context, arg CountSearch
 }
 
 const createArticle = `-- name: CreateArticle :one
-INSERT INTO articles (job_id, user_id, title, url, summary, content_path, retrieved_at)
-VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-RETURNING id, job_id, user_id, title, url, summary, content_path, retrieved_at
+INSERT INTO articles (job_id, user_id, title, url, summary, content_path, author, published_at, source, image_url, source_name, retrieved_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name
 `
 
 type CreateArticleParams struct {
-	JobID       int64  `json:"job_id"`
-	UserID      int64  `json:"user_id"`
-	Title       string `json:"title"`
-	Url         string `json:"url"`
-	Summary     string `json:"summary"`
-	ContentPath string `json:"content_path"`
+	JobID       int64     `json:"job_id"`
+	UserID      int64     `json:"user_id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	Summary     string    `json:"summary"`
+	ContentPath string    `json:"content_path"`
+	Author      string    `json:"author"`
+	PublishedAt string    `json:"published_at"`
+	Source      string    `json:"source"`
+	ImageUrl    string    `json:"image_url"`
+	SourceName  string    `json:"source_name"`
+	RetrievedAt time.Time `json:"retrieved_at"`
 }
 
 func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error) {
@@ -127,6 +224,12 @@ func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (A
 		arg.Url,
 		arg.Summary,
 		arg.ContentPath,
+		arg.Author,
+		arg.PublishedAt,
+		arg.Source,
+		arg.ImageUrl,
+		arg.SourceName,
+		arg.RetrievedAt,
 	)
 	var i Article
 	err := row.Scan(
@@ -138,6 +241,12 @@ func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (A
 		&i.Summary,
 		&i.ContentPath,
 		&i.RetrievedAt,
+		&i.ArchivedAt,
+		&i.Author,
+		&i.PublishedAt,
+		&i.Source,
+		&i.ImageUrl,
+		&i.SourceName,
 	)
 	return i, err
 }
@@ -157,7 +266,7 @@ func (q *Queries) DeleteArticle(ctx context.Context, arg DeleteArticleParams) er
 }
 
 const getArticle = `-- name: GetArticle :one
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE id = ? AND user_id = ?
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE id = ? AND user_id = ?
 `
 
 type GetArticleParams struct {
@@ -177,12 +286,100 @@ func (q *Queries) GetArticle(ctx context.Context, arg GetArticleParams) (Article
 		&i.Summary,
 		&i.ContentPath,
 		&i.RetrievedAt,
+		&i.ArchivedAt,
+		&i.Author,
+		&i.PublishedAt,
+		&i.Source,
+		&i.ImageUrl,
+		&i.SourceName,
 	)
 	return i, err
 }
 
+const listArchivedArticles = `-- name: ListArchivedArticles :many
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ? AND archived_at IS NOT NULL ORDER BY archived_at DESC LIMIT ? OFFSET ?
+`
+
+type ListArchivedArticlesParams struct {
+	UserID int64 `json:"user_id"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+func (q *Queries) ListArchivedArticles(ctx context.Context, arg ListArchivedArticlesParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArchivedArticles, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticleSources = `-- name: ListArticleSources :many
+SELECT url, source_name FROM articles WHERE user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC
+`
+
+type ListArticleSourcesRow struct {
+	Url        string `json:"url"`
+	SourceName string `json:"source_name"`
+}
+
+// URL and source name of each of a user's articles, newest first, for
+// grouping by domain.
+func (q *Queries) ListArticleSources(ctx context.Context, userID int64) ([]ListArticleSourcesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listArticleSources, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListArticleSourcesRow{}
+	for rows.Next() {
+		var i ListArticleSourcesRow
+		if err := rows.Scan(&i.Url, &i.SourceName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listArticlesByJob = `-- name: ListArticlesByJob :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE job_id = ? ORDER BY retrieved_at DESC
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE job_id = ? ORDER BY retrieved_at DESC
 `
 
 func (q *Queries) ListArticlesByJob(ctx context.Context, jobID int64) ([]Article, error) {
@@ -203,6 +400,12 @@ func (q *Queries) ListArticlesByJob(ctx context.Context, jobID int64) ([]Article
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
@@ -218,7 +421,7 @@ func (q *Queries) ListArticlesByJob(ctx context.Context, jobID int64) ([]Article
 }
 
 const listArticlesByJobPaginated = `-- name: ListArticlesByJobPaginated :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE job_id = ? AND user_id = ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE job_id = ? AND user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
 `
 
 type ListArticlesByJobPaginatedParams struct {
@@ -251,6 +454,66 @@ func (q *Queries) ListArticlesByJobPaginated(ctx context.Context, arg ListArticl
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesBySourceName = `-- name: ListArticlesBySourceName :many
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ? AND source_name = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
+`
+
+type ListArticlesBySourceNameParams struct {
+	UserID     int64  `json:"user_id"`
+	SourceName string `json:"source_name"`
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset"`
+}
+
+func (q *Queries) ListArticlesBySourceName(ctx context.Context, arg ListArticlesBySourceNameParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesBySourceName,
+		arg.UserID,
+		arg.SourceName,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
@@ -266,7 +529,7 @@ func (q *Queries) ListArticlesByJobPaginated(ctx context.Context, arg ListArticl
 }
 
 const listArticlesByUser = `-- name: ListArticlesByUser :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE user_id = ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
 `
 
 type ListArticlesByUserParams struct {
@@ -293,6 +556,12 @@ func (q *Queries) ListArticlesByUser(ctx context.Context, arg ListArticlesByUser
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
@@ -308,7 +577,7 @@ func (q *Queries) ListArticlesByUser(ctx context.Context, arg ListArticlesByUser
 }
 
 const listArticlesByUserDateRange = `-- name: ListArticlesByUserDateRange :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE user_id = ? AND retrieved_at >= ? AND retrieved_at <= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? AND retrieved_at <= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
 `
 
 type ListArticlesByUserDateRangeParams struct {
@@ -343,6 +612,12 @@ func (q *Queries) ListArticlesByUserDateRange(ctx context.Context, arg ListArtic
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
@@ -358,7 +633,7 @@ func (q *Queries) ListArticlesByUserDateRange(ctx context.Context, arg ListArtic
 }
 
 const listArticlesByUserSince = `-- name: ListArticlesByUserSince :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles WHERE user_id = ? AND retrieved_at >= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
 `
 
 type ListArticlesByUserSinceParams struct {
@@ -391,6 +666,295 @@ func (q *Queries) ListArticlesByUserSince(ctx context.Context, arg ListArticlesB
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesByUserSortedByJob = `-- name: ListArticlesByUserSortedByJob :many
+SELECT articles.id, articles.job_id, articles.user_id, articles.title, articles.url, articles.summary, articles.content_path, articles.retrieved_at, articles.archived_at, articles.author, articles.published_at, articles.source, articles.image_url, articles.source_name FROM articles
+JOIN jobs ON jobs.id = articles.job_id
+WHERE articles.user_id = ?1 AND articles.archived_at IS NULL
+ORDER BY CASE WHEN ?2 THEN lower(jobs.name) END DESC, lower(jobs.name), articles.retrieved_at DESC, articles.id
+LIMIT ?3 OFFSET ?4
+`
+
+type ListArticlesByUserSortedByJobParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+// Sorted by the name of the job that found them.
+func (q *Queries) ListArticlesByUserSortedByJob(ctx context.Context, arg ListArticlesByUserSortedByJobParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesByUserSortedByJob,
+		arg.UserID,
+		arg.Desc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesByUserSortedBySource = `-- name: ListArticlesByUserSortedBySource :many
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ?1 AND archived_at IS NULL
+ORDER BY source_name = '', CASE WHEN ?2 THEN lower(source_name) END DESC, lower(source_name), retrieved_at DESC, id
+LIMIT ?3 OFFSET ?4
+`
+
+type ListArticlesByUserSortedBySourceParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+// Articles without a source name come last in either order.
+func (q *Queries) ListArticlesByUserSortedBySource(ctx context.Context, arg ListArticlesByUserSortedBySourceParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesByUserSortedBySource,
+		arg.UserID,
+		arg.Desc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesByUserSortedByTitle = `-- name: ListArticlesByUserSortedByTitle :many
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles WHERE user_id = ?1 AND archived_at IS NULL
+ORDER BY CASE WHEN ?2 THEN lower(title) END DESC, lower(title), retrieved_at DESC, id
+LIMIT ?3 OFFSET ?4
+`
+
+type ListArticlesByUserSortedByTitleParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+func (q *Queries) ListArticlesByUserSortedByTitle(ctx context.Context, arg ListArticlesByUserSortedByTitleParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, listArticlesByUserSortedByTitle,
+		arg.UserID,
+		arg.Desc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableArticleIDs = `-- name: ListPurgeableArticleIDs :many
+SELECT id FROM articles WHERE user_id = ? AND archived_at IS NOT NULL AND archived_at < ?
+`
+
+type ListPurgeableArticleIDsParams struct {
+	UserID     int64      `json:"user_id"`
+	ArchivedAt *time.Time `json:"archived_at"`
+}
+
+func (q *Queries) ListPurgeableArticleIDs(ctx context.Context, arg ListPurgeableArticleIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableArticleIDs, arg.UserID, arg.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreArticle = `-- name: RestoreArticle :execrows
+UPDATE articles SET archived_at = NULL WHERE id = ? AND user_id = ? AND archived_at IS NOT NULL
+`
+
+type RestoreArticleParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) RestoreArticle(ctx context.Context, arg RestoreArticleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreArticle, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchArticles = `-- name: SearchArticles :many
+SELECT articles.id, articles.job_id, articles.user_id, articles.title, articles.url, articles.summary, articles.content_path, articles.retrieved_at, articles.archived_at, articles.author, articles.published_at, articles.source, articles.image_url, articles.source_name FROM articles_fts
+JOIN articles ON articles.id = articles_fts.rowid
+WHERE articles_fts MATCH ?1 AND articles.user_id = ?2 AND articles.archived_at IS NULL
+    AND (CAST(?3 AS INTEGER) = 0 OR articles.job_id = ?3)
+ORDER BY articles_fts.rank, articles.retrieved_at DESC
+LIMIT ?4 OFFSET ?5
+`
+
+type SearchArticlesParams struct {
+	Query  string `json:"query"`
+	UserID int64  `json:"user_id"`
+	JobID  int64  `json:"job_id"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
+// Full-text search; a job_id of 0 searches every job.
+func (q *Queries) SearchArticles(ctx context.Context, arg SearchArticlesParams) ([]Article, error) {
+	rows, err := q.db.QueryContext(ctx, searchArticles,
+		arg.Query,
+		arg.UserID,
+		arg.JobID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Article{}
+	for rows.Next() {
+		var i Article
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Title,
+			&i.Url,
+			&i.Summary,
+			&i.ContentPath,
+			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
@@ -406,8 +970,8 @@ func (q *Queries) ListArticlesByUserSince(ctx context.Context, arg ListArticlesB
 }
 
 const searchArticlesByUser = `-- name: SearchArticlesByUser :many
-SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at FROM articles 
-WHERE user_id = ? AND (title LIKE ? OR summary LIKE ?)
+SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, archived_at, author, published_at, source, image_url, source_name FROM articles 
+WHERE user_id = ? AND archived_at IS NULL AND (title LIKE ? OR summary LIKE ?)
 ORDER BY retrieved_at DESC LIMIT ? OFFSET ?
 `
 
@@ -443,6 +1007,12 @@ func (q *Queries) SearchArticlesByUser(ctx context.Context, arg SearchArticlesBy
 			&i.Summary,
 			&i.ContentPath,
 			&i.RetrievedAt,
+			&i.ArchivedAt,
+			&i.Author,
+			&i.PublishedAt,
+			&i.Source,
+			&i.ImageUrl,
+			&i.SourceName,
 		); err != nil {
 			return nil, err
 		}
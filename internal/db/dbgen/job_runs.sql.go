@@ -57,10 +57,94 @@ func (q *Queries) CompleteJobRun(ctx context.Context, arg CompleteJobRunParams)
 	return err
 }
 
+const costSummary = `-- name: CostSummary :many
+SELECT jr.job_id, CAST(SUM(jr.estimated_cost_usd) AS REAL) AS cost
+FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE j.user_id = ? AND jr.started_at >= ?
+GROUP BY jr.job_id
+ORDER BY cost DESC, jr.job_id
+`
+
+type CostSummaryParams struct {
+	UserID    int64     `json:"user_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+type CostSummaryRow struct {
+	JobID int64   `json:"job_id"`
+	Cost  float64 `json:"cost"`
+}
+
+func (q *Queries) CostSummary(ctx context.Context, arg CostSummaryParams) ([]CostSummaryRow, error) {
+	rows, err := q.db.QueryContext(ctx, costSummary, arg.UserID, arg.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CostSummaryRow{}
+	for rows.Next() {
+		var i CostSummaryRow
+		if err := rows.Scan(&i.JobID, &i.Cost); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countActiveRuns = `-- name: CountActiveRuns :one
+SELECT COUNT(*) FROM job_runs WHERE status = 'running' AND started_at > ?1
+`
+
+// Runs executing in any process. Runs started before @since are taken to be
+// abandoned, e.g. by a crashed process, and aren't counted.
+func (q *Queries) CountActiveRuns(ctx context.Context, since time.Time) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveRuns, since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countJobRunsByJob = `-- name: CountJobRunsByJob :one
+SELECT COUNT(*) FROM job_runs WHERE job_id = ?
+`
+
+func (q *Queries) CountJobRunsByJob(ctx context.Context, jobID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countJobRunsByJob, jobID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserConversationsSince = `-- name: CountUserConversationsSince :one
+SELECT COUNT(*) FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE j.user_id = ? AND jr.started_at >= ? AND jr.conversation_id != ''
+`
+
+type CountUserConversationsSinceParams struct {
+	UserID    int64     `json:"user_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (q *Queries) CountUserConversationsSince(ctx context.Context, arg CountUserConversationsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserConversationsSince, arg.UserID, arg.StartedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createJobRun = `-- name: CreateJobRun :one
 INSERT INTO job_runs (job_id, status, started_at)
 VALUES (?, 'running', CURRENT_TIMESTAMP)
-RETURNING id, job_id, status, error_message, started_at, completed_at, articles_saved, duplicates_skipped, log_path
+RETURNING id, job_id, status, error_message, started_at, completed_at, articles_saved, duplicates_skipped, log_path, conversation_messages, estimated_tokens, conversation_id, conversation_snapshot, estimated_cost_usd, idempotency_key
 `
 
 func (q *Queries) CreateJobRun(ctx context.Context, jobID int64) (JobRun, error) {
@@ -76,12 +160,18 @@ func (q *Queries) CreateJobRun(ctx context.Context, jobID int64) (JobRun, error)
 		&i.ArticlesSaved,
 		&i.DuplicatesSkipped,
 		&i.LogPath,
+		&i.ConversationMessages,
+		&i.EstimatedTokens,
+		&i.ConversationID,
+		&i.ConversationSnapshot,
+		&i.EstimatedCostUsd,
+		&i.IdempotencyKey,
 	)
 	return i, err
 }
 
 const getJobRun = `-- name: GetJobRun :one
-SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, j.name as job_name, j.user_id as job_user_id
+SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, jr.conversation_messages, jr.estimated_tokens, jr.conversation_id, jr.conversation_snapshot, jr.estimated_cost_usd, jr.idempotency_key, j.name as job_name, j.user_id as job_user_id
 FROM job_runs jr
 JOIN jobs j ON jr.job_id = j.id
 WHERE jr.id = ? AND j.user_id = ?
@@ -93,17 +183,23 @@ type GetJobRunParams struct {
 }
 
 type GetJobRunRow struct {
-	ID                int64      `json:"id"`
-	JobID             int64      `json:"job_id"`
-	Status            string     `json:"status"`
-	ErrorMessage      *string    `json:"error_message"`
-	StartedAt         time.Time  `json:"started_at"`
-	CompletedAt       *time.Time `json:"completed_at"`
-	ArticlesSaved     *int64     `json:"articles_saved"`
-	DuplicatesSkipped *int64     `json:"duplicates_skipped"`
-	LogPath           string     `json:"log_path"`
-	JobName           string     `json:"job_name"`
-	JobUserID         int64      `json:"job_user_id"`
+	ID                   int64      `json:"id"`
+	JobID                int64      `json:"job_id"`
+	Status               string     `json:"status"`
+	ErrorMessage         *string    `json:"error_message"`
+	StartedAt            time.Time  `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+	ArticlesSaved        *int64     `json:"articles_saved"`
+	DuplicatesSkipped    *int64     `json:"duplicates_skipped"`
+	LogPath              string     `json:"log_path"`
+	ConversationMessages int64      `json:"conversation_messages"`
+	EstimatedTokens      int64      `json:"estimated_tokens"`
+	ConversationID       string     `json:"conversation_id"`
+	ConversationSnapshot string     `json:"conversation_snapshot"`
+	EstimatedCostUsd     float64    `json:"estimated_cost_usd"`
+	IdempotencyKey       string     `json:"idempotency_key"`
+	JobName              string     `json:"job_name"`
+	JobUserID            int64      `json:"job_user_id"`
 }
 
 func (q *Queries) GetJobRun(ctx context.Context, arg GetJobRunParams) (GetJobRunRow, error) {
@@ -119,6 +215,12 @@ func (q *Queries) GetJobRun(ctx context.Context, arg GetJobRunParams) (GetJobRun
 		&i.ArticlesSaved,
 		&i.DuplicatesSkipped,
 		&i.LogPath,
+		&i.ConversationMessages,
+		&i.EstimatedTokens,
+		&i.ConversationID,
+		&i.ConversationSnapshot,
+		&i.EstimatedCostUsd,
+		&i.IdempotencyKey,
 		&i.JobName,
 		&i.JobUserID,
 	)
@@ -143,8 +245,73 @@ func (q *Queries) GetJobRunLogPath(ctx context.Context, arg GetJobRunLogPathPara
 	return log_path, err
 }
 
+const getJobRunStats = `-- name: GetJobRunStats :one
+SELECT
+    COUNT(*) AS total_runs,
+    CAST(COALESCE(SUM(conversation_messages), 0) AS INTEGER) AS total_messages,
+    CAST(COALESCE(SUM(estimated_tokens), 0) AS INTEGER) AS total_tokens,
+    CAST(COALESCE(AVG(estimated_tokens), 0) AS REAL) AS avg_tokens_per_run
+FROM job_runs
+WHERE job_id = ? AND completed_at IS NOT NULL
+`
+
+type GetJobRunStatsRow struct {
+	TotalRuns       int64   `json:"total_runs"`
+	TotalMessages   int64   `json:"total_messages"`
+	TotalTokens     int64   `json:"total_tokens"`
+	AvgTokensPerRun float64 `json:"avg_tokens_per_run"`
+}
+
+func (q *Queries) GetJobRunStats(ctx context.Context, jobID int64) (GetJobRunStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getJobRunStats, jobID)
+	var i GetJobRunStatsRow
+	err := row.Scan(
+		&i.TotalRuns,
+		&i.TotalMessages,
+		&i.TotalTokens,
+		&i.AvgTokensPerRun,
+	)
+	return i, err
+}
+
+const getLatestJobRun = `-- name: GetLatestJobRun :one
+SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, jr.conversation_messages, jr.estimated_tokens, jr.conversation_id, jr.conversation_snapshot, jr.estimated_cost_usd, jr.idempotency_key FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE jr.job_id = ? AND j.user_id = ?
+ORDER BY jr.started_at DESC, jr.id DESC
+LIMIT 1
+`
+
+type GetLatestJobRunParams struct {
+	JobID  int64 `json:"job_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetLatestJobRun(ctx context.Context, arg GetLatestJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, getLatestJobRun, arg.JobID, arg.UserID)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.ArticlesSaved,
+		&i.DuplicatesSkipped,
+		&i.LogPath,
+		&i.ConversationMessages,
+		&i.EstimatedTokens,
+		&i.ConversationID,
+		&i.ConversationSnapshot,
+		&i.EstimatedCostUsd,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
 const listJobRunsByJob = `-- name: ListJobRunsByJob :many
-SELECT id, job_id, status, error_message, started_at, completed_at, articles_saved, duplicates_skipped, log_path FROM job_runs WHERE job_id = ? ORDER BY started_at DESC LIMIT 10
+SELECT id, job_id, status, error_message, started_at, completed_at, articles_saved, duplicates_skipped, log_path, conversation_messages, estimated_tokens, conversation_id, conversation_snapshot, estimated_cost_usd, idempotency_key FROM job_runs WHERE job_id = ? ORDER BY started_at DESC LIMIT 10
 `
 
 func (q *Queries) ListJobRunsByJob(ctx context.Context, jobID int64) ([]JobRun, error) {
@@ -166,6 +333,61 @@ func (q *Queries) ListJobRunsByJob(ctx context.Context, jobID int64) ([]JobRun,
 			&i.ArticlesSaved,
 			&i.DuplicatesSkipped,
 			&i.LogPath,
+			&i.ConversationMessages,
+			&i.EstimatedTokens,
+			&i.ConversationID,
+			&i.ConversationSnapshot,
+			&i.EstimatedCostUsd,
+			&i.IdempotencyKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobRunsByJobPage = `-- name: ListJobRunsByJobPage :many
+SELECT id, job_id, status, error_message, started_at, completed_at, articles_saved, duplicates_skipped, log_path, conversation_messages, estimated_tokens, conversation_id, conversation_snapshot, estimated_cost_usd, idempotency_key FROM job_runs WHERE job_id = ? ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?
+`
+
+type ListJobRunsByJobPageParams struct {
+	JobID  int64 `json:"job_id"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+func (q *Queries) ListJobRunsByJobPage(ctx context.Context, arg ListJobRunsByJobPageParams) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listJobRunsByJobPage, arg.JobID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []JobRun{}
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.ArticlesSaved,
+			&i.DuplicatesSkipped,
+			&i.LogPath,
+			&i.ConversationMessages,
+			&i.EstimatedTokens,
+			&i.ConversationID,
+			&i.ConversationSnapshot,
+			&i.EstimatedCostUsd,
+			&i.IdempotencyKey,
 		); err != nil {
 			return nil, err
 		}
@@ -181,7 +403,7 @@ func (q *Queries) ListJobRunsByJob(ctx context.Context, jobID int64) ([]JobRun,
 }
 
 const listRecentJobRuns = `-- name: ListRecentJobRuns :many
-SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, j.name as job_name, j.user_id as job_user_id
+SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, jr.conversation_messages, jr.estimated_tokens, jr.conversation_id, jr.conversation_snapshot, jr.estimated_cost_usd, jr.idempotency_key, j.name as job_name, j.user_id as job_user_id
 FROM job_runs jr
 JOIN jobs j ON jr.job_id = j.id
 WHERE j.user_id = ?
@@ -195,17 +417,23 @@ type ListRecentJobRunsParams struct {
 }
 
 type ListRecentJobRunsRow struct {
-	ID                int64      `json:"id"`
-	JobID             int64      `json:"job_id"`
-	Status            string     `json:"status"`
-	ErrorMessage      *string    `json:"error_message"`
-	StartedAt         time.Time  `json:"started_at"`
-	CompletedAt       *time.Time `json:"completed_at"`
-	ArticlesSaved     *int64     `json:"articles_saved"`
-	DuplicatesSkipped *int64     `json:"duplicates_skipped"`
-	LogPath           string     `json:"log_path"`
-	JobName           string     `json:"job_name"`
-	JobUserID         int64      `json:"job_user_id"`
+	ID                   int64      `json:"id"`
+	JobID                int64      `json:"job_id"`
+	Status               string     `json:"status"`
+	ErrorMessage         *string    `json:"error_message"`
+	StartedAt            time.Time  `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+	ArticlesSaved        *int64     `json:"articles_saved"`
+	DuplicatesSkipped    *int64     `json:"duplicates_skipped"`
+	LogPath              string     `json:"log_path"`
+	ConversationMessages int64      `json:"conversation_messages"`
+	EstimatedTokens      int64      `json:"estimated_tokens"`
+	ConversationID       string     `json:"conversation_id"`
+	ConversationSnapshot string     `json:"conversation_snapshot"`
+	EstimatedCostUsd     float64    `json:"estimated_cost_usd"`
+	IdempotencyKey       string     `json:"idempotency_key"`
+	JobName              string     `json:"job_name"`
+	JobUserID            int64      `json:"job_user_id"`
 }
 
 func (q *Queries) ListRecentJobRuns(ctx context.Context, arg ListRecentJobRunsParams) ([]ListRecentJobRunsRow, error) {
@@ -227,6 +455,12 @@ func (q *Queries) ListRecentJobRuns(ctx context.Context, arg ListRecentJobRunsPa
 			&i.ArticlesSaved,
 			&i.DuplicatesSkipped,
 			&i.LogPath,
+			&i.ConversationMessages,
+			&i.EstimatedTokens,
+			&i.ConversationID,
+			&i.ConversationSnapshot,
+			&i.EstimatedCostUsd,
+			&i.IdempotencyKey,
 			&i.JobName,
 			&i.JobUserID,
 		); err != nil {
@@ -244,7 +478,7 @@ func (q *Queries) ListRecentJobRuns(ctx context.Context, arg ListRecentJobRunsPa
 }
 
 const listRunningJobRuns = `-- name: ListRunningJobRuns :many
-SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, j.name as job_name, j.user_id as job_user_id
+SELECT jr.id, jr.job_id, jr.status, jr.error_message, jr.started_at, jr.completed_at, jr.articles_saved, jr.duplicates_skipped, jr.log_path, jr.conversation_messages, jr.estimated_tokens, jr.conversation_id, jr.conversation_snapshot, jr.estimated_cost_usd, jr.idempotency_key, j.name as job_name, j.user_id as job_user_id
 FROM job_runs jr
 JOIN jobs j ON jr.job_id = j.id
 WHERE jr.status = 'running' AND j.user_id = ?
@@ -252,17 +486,23 @@ ORDER BY jr.started_at DESC
 `
 
 type ListRunningJobRunsRow struct {
-	ID                int64      `json:"id"`
-	JobID             int64      `json:"job_id"`
-	Status            string     `json:"status"`
-	ErrorMessage      *string    `json:"error_message"`
-	StartedAt         time.Time  `json:"started_at"`
-	CompletedAt       *time.Time `json:"completed_at"`
-	ArticlesSaved     *int64     `json:"articles_saved"`
-	DuplicatesSkipped *int64     `json:"duplicates_skipped"`
-	LogPath           string     `json:"log_path"`
-	JobName           string     `json:"job_name"`
-	JobUserID         int64      `json:"job_user_id"`
+	ID                   int64      `json:"id"`
+	JobID                int64      `json:"job_id"`
+	Status               string     `json:"status"`
+	ErrorMessage         *string    `json:"error_message"`
+	StartedAt            time.Time  `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+	ArticlesSaved        *int64     `json:"articles_saved"`
+	DuplicatesSkipped    *int64     `json:"duplicates_skipped"`
+	LogPath              string     `json:"log_path"`
+	ConversationMessages int64      `json:"conversation_messages"`
+	EstimatedTokens      int64      `json:"estimated_tokens"`
+	ConversationID       string     `json:"conversation_id"`
+	ConversationSnapshot string     `json:"conversation_snapshot"`
+	EstimatedCostUsd     float64    `json:"estimated_cost_usd"`
+	IdempotencyKey       string     `json:"idempotency_key"`
+	JobName              string     `json:"job_name"`
+	JobUserID            int64      `json:"job_user_id"`
 }
 
 func (q *Queries) ListRunningJobRuns(ctx context.Context, userID int64) ([]ListRunningJobRunsRow, error) {
@@ -284,6 +524,12 @@ func (q *Queries) ListRunningJobRuns(ctx context.Context, userID int64) ([]ListR
 			&i.ArticlesSaved,
 			&i.DuplicatesSkipped,
 			&i.LogPath,
+			&i.ConversationMessages,
+			&i.EstimatedTokens,
+			&i.ConversationID,
+			&i.ConversationSnapshot,
+			&i.EstimatedCostUsd,
+			&i.IdempotencyKey,
 			&i.JobName,
 			&i.JobUserID,
 		); err != nil {
@@ -302,16 +548,22 @@ func (q *Queries) ListRunningJobRuns(ctx context.Context, userID int64) ([]ListR
 
 const updateJobRunComplete = `-- name: UpdateJobRunComplete :exec
 UPDATE job_runs
-SET status = ?, error_message = ?, articles_saved = ?, duplicates_skipped = ?, completed_at = CURRENT_TIMESTAMP
+SET status = ?, error_message = ?, articles_saved = ?, duplicates_skipped = ?,
+    conversation_messages = ?, estimated_tokens = ?, estimated_cost_usd = ?, conversation_id = ?,
+    completed_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
 
 type UpdateJobRunCompleteParams struct {
-	Status            string  `json:"status"`
-	ErrorMessage      *string `json:"error_message"`
-	ArticlesSaved     *int64  `json:"articles_saved"`
-	DuplicatesSkipped *int64  `json:"duplicates_skipped"`
-	ID                int64   `json:"id"`
+	Status               string  `json:"status"`
+	ErrorMessage         *string `json:"error_message"`
+	ArticlesSaved        *int64  `json:"articles_saved"`
+	DuplicatesSkipped    *int64  `json:"duplicates_skipped"`
+	ConversationMessages int64   `json:"conversation_messages"`
+	EstimatedTokens      int64   `json:"estimated_tokens"`
+	EstimatedCostUsd     float64 `json:"estimated_cost_usd"`
+	ConversationID       string  `json:"conversation_id"`
+	ID                   int64   `json:"id"`
 }
 
 func (q *Queries) UpdateJobRunComplete(ctx context.Context, arg UpdateJobRunCompleteParams) error {
@@ -320,21 +572,71 @@ func (q *Queries) UpdateJobRunComplete(ctx context.Context, arg UpdateJobRunComp
 		arg.ErrorMessage,
 		arg.ArticlesSaved,
 		arg.DuplicatesSkipped,
+		arg.ConversationMessages,
+		arg.EstimatedTokens,
+		arg.EstimatedCostUsd,
+		arg.ConversationID,
 		arg.ID,
 	)
 	return err
 }
 
+const updateJobRunConversation = `-- name: UpdateJobRunConversation :exec
+UPDATE job_runs SET conversation_id = ? WHERE id = ?
+`
+
+type UpdateJobRunConversationParams struct {
+	ConversationID string `json:"conversation_id"`
+	ID             int64  `json:"id"`
+}
+
+func (q *Queries) UpdateJobRunConversation(ctx context.Context, arg UpdateJobRunConversationParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobRunConversation, arg.ConversationID, arg.ID)
+	return err
+}
+
+const updateJobRunIdempotencyKey = `-- name: UpdateJobRunIdempotencyKey :exec
+UPDATE job_runs SET idempotency_key = ? WHERE id = ?
+`
+
+type UpdateJobRunIdempotencyKeyParams struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	ID             int64  `json:"id"`
+}
+
+func (q *Queries) UpdateJobRunIdempotencyKey(ctx context.Context, arg UpdateJobRunIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobRunIdempotencyKey, arg.IdempotencyKey, arg.ID)
+	return err
+}
+
 const updateJobRunLogPath = `-- name: UpdateJobRunLogPath :exec
 UPDATE job_runs SET log_path = ? WHERE id = ?
 `
 
 type UpdateJobRunLogPathParams struct {
-	LogPath string `json:"log_path"`
-	ID      int64  `json:"id"`
+	LogPath              string `json:"log_path"`
+	ConversationMessages int64  `json:"conversation_messages"`
+	EstimatedTokens      int64  `json:"estimated_tokens"`
+	ConversationID       string `json:"conversation_id"`
+	ConversationSnapshot string `json:"conversation_snapshot"`
+	ID                   int64  `json:"id"`
 }
 
 func (q *Queries) UpdateJobRunLogPath(ctx context.Context, arg UpdateJobRunLogPathParams) error {
 	_, err := q.db.ExecContext(ctx, updateJobRunLogPath, arg.LogPath, arg.ID)
 	return err
 }
+
+const updateJobRunSnapshot = `-- name: UpdateJobRunSnapshot :exec
+UPDATE job_runs SET conversation_snapshot = ? WHERE id = ?
+`
+
+type UpdateJobRunSnapshotParams struct {
+	ConversationSnapshot string `json:"conversation_snapshot"`
+	ID                   int64  `json:"id"`
+}
+
+func (q *Queries) UpdateJobRunSnapshot(ctx context.Context, arg UpdateJobRunSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobRunSnapshot, arg.ConversationSnapshot, arg.ID)
+	return err
+}
@@ -11,21 +11,26 @@ import (
 )
 
 const createJob = `-- name: CreateJob :one
-INSERT INTO jobs (user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, status, next_run_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1, 'pending', ?)
-RETURNING id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id
+INSERT INTO jobs (user_id, name, prompt, keywords, sources, region, frequency, is_one_time, feed_url, fetch_headers, fetch_header_domains, prompt_template, schedule_jitter_secs, is_active, status, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, 'pending', ?)
+RETURNING id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs
 `
 
 type CreateJobParams struct {
-	UserID    int64      `json:"user_id"`
-	Name      string     `json:"name"`
-	Prompt    string     `json:"prompt"`
-	Keywords  string     `json:"keywords"`
-	Sources   string     `json:"sources"`
-	Region    string     `json:"region"`
-	Frequency string     `json:"frequency"`
-	IsOneTime int64      `json:"is_one_time"`
-	NextRunAt *time.Time `json:"next_run_at"`
+	UserID             int64      `json:"user_id"`
+	Name               string     `json:"name"`
+	Prompt             string     `json:"prompt"`
+	Keywords           string     `json:"keywords"`
+	Sources            string     `json:"sources"`
+	Region             string     `json:"region"`
+	Frequency          string     `json:"frequency"`
+	IsOneTime          int64      `json:"is_one_time"`
+	FeedUrl            string     `json:"feed_url"`
+	FetchHeaders       string     `json:"fetch_headers"`
+	FetchHeaderDomains string     `json:"fetch_header_domains"`
+	PromptTemplate     string     `json:"prompt_template"`
+	ScheduleJitterSecs *int64     `json:"schedule_jitter_secs"`
+	NextRunAt          *time.Time `json:"next_run_at"`
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -38,6 +43,11 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.Region,
 		arg.Frequency,
 		arg.IsOneTime,
+		arg.FeedUrl,
+		arg.FetchHeaders,
+		arg.FetchHeaderDomains,
+		arg.PromptTemplate,
+		arg.ScheduleJitterSecs,
 		arg.NextRunAt,
 	)
 	var i Job
@@ -58,6 +68,12 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CurrentConversationID,
+		&i.FeedUrl,
+		&i.FetchHeaders,
+		&i.FetchHeaderDomains,
+		&i.PromptTemplate,
+		&i.LastSuccessAt,
+		&i.ScheduleJitterSecs,
 	)
 	return i, err
 }
@@ -86,7 +102,7 @@ func (q *Queries) DeleteJob(ctx context.Context, arg DeleteJobParams) error {
 }
 
 const getJob = `-- name: GetJob :one
-SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id FROM jobs WHERE id = ? AND user_id = ?
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE id = ? AND user_id = ?
 `
 
 type GetJobParams struct {
@@ -114,12 +130,18 @@ func (q *Queries) GetJob(ctx context.Context, arg GetJobParams) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CurrentConversationID,
+		&i.FeedUrl,
+		&i.FetchHeaders,
+		&i.FetchHeaderDomains,
+		&i.PromptTemplate,
+		&i.LastSuccessAt,
+		&i.ScheduleJitterSecs,
 	)
 	return i, err
 }
 
 const getJobByID = `-- name: GetJobByID :one
-SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id FROM jobs WHERE id = ?
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE id = ?
 `
 
 func (q *Queries) GetJobByID(ctx context.Context, id int64) (Job, error) {
@@ -142,12 +164,18 @@ func (q *Queries) GetJobByID(ctx context.Context, id int64) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CurrentConversationID,
+		&i.FeedUrl,
+		&i.FetchHeaders,
+		&i.FetchHeaderDomains,
+		&i.PromptTemplate,
+		&i.LastSuccessAt,
+		&i.ScheduleJitterSecs,
 	)
 	return i, err
 }
 
 const listActiveJobs = `-- name: ListActiveJobs :many
-SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id FROM jobs WHERE is_active = 1 AND (is_one_time = 0 OR status = 'pending')
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE is_active = 1 AND (is_one_time = 0 OR status = 'pending')
 `
 
 func (q *Queries) ListActiveJobs(ctx context.Context) ([]Job, error) {
@@ -176,6 +204,12 @@ func (q *Queries) ListActiveJobs(ctx context.Context) ([]Job, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
 		); err != nil {
 			return nil, err
 		}
@@ -190,8 +224,51 @@ func (q *Queries) ListActiveJobs(ctx context.Context) ([]Job, error) {
 	return items, nil
 }
 
+const listJobArticleCountsSince = `-- name: ListJobArticleCountsSince :many
+SELECT j.id, j.name, COUNT(a.id) AS article_count
+FROM jobs j
+LEFT JOIN articles a ON a.job_id = j.id AND a.retrieved_at >= ?
+WHERE j.user_id = ?
+GROUP BY j.id
+ORDER BY article_count DESC, j.name
+`
+
+type ListJobArticleCountsSinceParams struct {
+	RetrievedAt time.Time `json:"retrieved_at"`
+	UserID      int64     `json:"user_id"`
+}
+
+type ListJobArticleCountsSinceRow struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"article_count"`
+}
+
+func (q *Queries) ListJobArticleCountsSince(ctx context.Context, arg ListJobArticleCountsSinceParams) ([]ListJobArticleCountsSinceRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJobArticleCountsSince, arg.RetrievedAt, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListJobArticleCountsSinceRow{}
+	for rows.Next() {
+		var i ListJobArticleCountsSinceRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.ArticleCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listJobsByUser = `-- name: ListJobsByUser :many
-SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id FROM jobs WHERE user_id = ? ORDER BY created_at DESC
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE user_id = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) ListJobsByUser(ctx context.Context, userID int64) ([]Job, error) {
@@ -220,6 +297,294 @@ func (q *Queries) ListJobsByUser(ctx context.Context, userID int64) ([]Job, erro
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsByUserSortedByLastRun = `-- name: ListJobsByUserSortedByLastRun :many
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE user_id = ?1
+ORDER BY last_run_at IS NULL, CASE WHEN ?2 THEN last_run_at END DESC, last_run_at, id
+`
+
+type ListJobsByUserSortedByLastRunParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+}
+
+func (q *Queries) ListJobsByUserSortedByLastRun(ctx context.Context, arg ListJobsByUserSortedByLastRunParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsByUserSortedByLastRun, arg.UserID, arg.Desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prompt,
+			&i.Keywords,
+			&i.Sources,
+			&i.Region,
+			&i.Frequency,
+			&i.IsOneTime,
+			&i.IsActive,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsByUserSortedByName = `-- name: ListJobsByUserSortedByName :many
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE user_id = ?1
+ORDER BY CASE WHEN ?2 THEN lower(name) END DESC, lower(name), id
+`
+
+type ListJobsByUserSortedByNameParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+}
+
+func (q *Queries) ListJobsByUserSortedByName(ctx context.Context, arg ListJobsByUserSortedByNameParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsByUserSortedByName, arg.UserID, arg.Desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prompt,
+			&i.Keywords,
+			&i.Sources,
+			&i.Region,
+			&i.Frequency,
+			&i.IsOneTime,
+			&i.IsActive,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsByUserSortedByNextRun = `-- name: ListJobsByUserSortedByNextRun :many
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE user_id = ?1
+ORDER BY next_run_at IS NULL, CASE WHEN ?2 THEN next_run_at END DESC, next_run_at, id
+`
+
+type ListJobsByUserSortedByNextRunParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+}
+
+func (q *Queries) ListJobsByUserSortedByNextRun(ctx context.Context, arg ListJobsByUserSortedByNextRunParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsByUserSortedByNextRun, arg.UserID, arg.Desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prompt,
+			&i.Keywords,
+			&i.Sources,
+			&i.Region,
+			&i.Frequency,
+			&i.IsOneTime,
+			&i.IsActive,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsByUserSortedByStatus = `-- name: ListJobsByUserSortedByStatus :many
+SELECT id, user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, last_run_at, next_run_at, status, created_at, updated_at, current_conversation_id, feed_url, fetch_headers, fetch_header_domains, prompt_template, last_success_at, schedule_jitter_secs FROM jobs WHERE user_id = ?1
+ORDER BY CASE WHEN ?2 THEN status END DESC, status, lower(name), id
+`
+
+type ListJobsByUserSortedByStatusParams struct {
+	UserID int64 `json:"user_id"`
+	Desc   bool  `json:"desc"`
+}
+
+func (q *Queries) ListJobsByUserSortedByStatus(ctx context.Context, arg ListJobsByUserSortedByStatusParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsByUserSortedByStatus, arg.UserID, arg.Desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prompt,
+			&i.Keywords,
+			&i.Sources,
+			&i.Region,
+			&i.Frequency,
+			&i.IsOneTime,
+			&i.IsActive,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CurrentConversationID,
+			&i.FeedUrl,
+			&i.FetchHeaders,
+			&i.FetchHeaderDomains,
+			&i.PromptTemplate,
+			&i.LastSuccessAt,
+			&i.ScheduleJitterSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobsWithArticleCounts = `-- name: ListJobsWithArticleCounts :many
+SELECT j.id, j.name, j.frequency, j.is_one_time, j.is_active, j.status, j.last_run_at, j.next_run_at, j.last_success_at, j.created_at, COUNT(a.id) AS article_count
+FROM jobs j
+LEFT JOIN articles a ON a.job_id = j.id
+WHERE j.user_id = ?
+GROUP BY j.id
+ORDER BY j.created_at DESC
+`
+
+type ListJobsWithArticleCountsRow struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Frequency     string     `json:"frequency"`
+	IsOneTime     int64      `json:"is_one_time"`
+	IsActive      int64      `json:"is_active"`
+	Status        string     `json:"status"`
+	LastRunAt     *time.Time `json:"last_run_at"`
+	NextRunAt     *time.Time `json:"next_run_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ArticleCount  int64      `json:"article_count"`
+}
+
+func (q *Queries) ListJobsWithArticleCounts(ctx context.Context, userID int64) ([]ListJobsWithArticleCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJobsWithArticleCounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListJobsWithArticleCountsRow{}
+	for rows.Next() {
+		var i ListJobsWithArticleCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Frequency,
+			&i.IsOneTime,
+			&i.IsActive,
+			&i.Status,
+			&i.LastRunAt,
+			&i.NextRunAt,
+			&i.LastSuccessAt,
+			&i.CreatedAt,
+			&i.ArticleCount,
 		); err != nil {
 			return nil, err
 		}
@@ -236,20 +601,25 @@ func (q *Queries) ListJobsByUser(ctx context.Context, userID int64) ([]Job, erro
 
 const updateJob = `-- name: UpdateJob :exec
 UPDATE jobs
-SET name = ?, prompt = ?, keywords = ?, sources = ?, region = ?, frequency = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+SET name = ?, prompt = ?, keywords = ?, sources = ?, region = ?, frequency = ?, is_active = ?, feed_url = ?, fetch_headers = ?, fetch_header_domains = ?, prompt_template = ?, schedule_jitter_secs = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ? AND user_id = ?
 `
 
 type UpdateJobParams struct {
-	Name      string `json:"name"`
-	Prompt    string `json:"prompt"`
-	Keywords  string `json:"keywords"`
-	Sources   string `json:"sources"`
-	Region    string `json:"region"`
-	Frequency string `json:"frequency"`
-	IsActive  int64  `json:"is_active"`
-	ID        int64  `json:"id"`
-	UserID    int64  `json:"user_id"`
+	Name               string `json:"name"`
+	Prompt             string `json:"prompt"`
+	Keywords           string `json:"keywords"`
+	Sources            string `json:"sources"`
+	Region             string `json:"region"`
+	Frequency          string `json:"frequency"`
+	IsActive           int64  `json:"is_active"`
+	FeedUrl            string `json:"feed_url"`
+	FetchHeaders       string `json:"fetch_headers"`
+	FetchHeaderDomains string `json:"fetch_header_domains"`
+	PromptTemplate     string `json:"prompt_template"`
+	ScheduleJitterSecs *int64 `json:"schedule_jitter_secs"`
+	ID                 int64  `json:"id"`
+	UserID             int64  `json:"user_id"`
 }
 
 func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) error {
@@ -261,6 +631,11 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) error {
 		arg.Region,
 		arg.Frequency,
 		arg.IsActive,
+		arg.FeedUrl,
+		arg.FetchHeaders,
+		arg.FetchHeaderDomains,
+		arg.PromptTemplate,
+		arg.ScheduleJitterSecs,
 		arg.ID,
 		arg.UserID,
 	)
@@ -281,6 +656,20 @@ func (q *Queries) UpdateJobConversation(ctx context.Context, arg UpdateJobConver
 	return err
 }
 
+const updateJobLastSuccess = `-- name: UpdateJobLastSuccess :exec
+UPDATE jobs SET last_success_at = ? WHERE id = ?
+`
+
+type UpdateJobLastSuccessParams struct {
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	ID            int64      `json:"id"`
+}
+
+func (q *Queries) UpdateJobLastSuccess(ctx context.Context, arg UpdateJobLastSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobLastSuccess, arg.LastSuccessAt, arg.ID)
+	return err
+}
+
 const updateJobStatus = `-- name: UpdateJobStatus :exec
 UPDATE jobs
 SET status = ?, last_run_at = ?, next_run_at = ?, updated_at = CURRENT_TIMESTAMP
@@ -9,14 +9,46 @@ import (
 )
 
 type Article struct {
-	ID          int64     `json:"id"`
-	JobID       int64     `json:"job_id"`
-	UserID      int64     `json:"user_id"`
-	Title       string    `json:"title"`
-	Url         string    `json:"url"`
-	Summary     string    `json:"summary"`
-	ContentPath string    `json:"content_path"`
-	RetrievedAt time.Time `json:"retrieved_at"`
+	ID          int64      `json:"id"`
+	JobID       int64      `json:"job_id"`
+	UserID      int64      `json:"user_id"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Summary     string     `json:"summary"`
+	ContentPath string     `json:"content_path"`
+	RetrievedAt time.Time  `json:"retrieved_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	Author      string     `json:"author"`
+	PublishedAt string     `json:"published_at"`
+	Source      string     `json:"source"`
+	ImageUrl    string     `json:"image_url"`
+	SourceName  string     `json:"source_name"`
+}
+
+type Collection struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	ParentID  *int64    `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CollectionArticle struct {
+	CollectionID int64     `json:"collection_id"`
+	ArticleID    int64     `json:"article_id"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+type FetchTelemetry struct {
+	ID            int64     `json:"id"`
+	JobID         int64     `json:"job_id"`
+	RunID         *int64    `json:"run_id"`
+	Url           string    `json:"url"`
+	DurationMs    int64     `json:"duration_ms"`
+	StatusCode    int64     `json:"status_code"`
+	Error         string    `json:"error"`
+	ContentLength int64     `json:"content_length"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Job struct {
@@ -36,18 +68,45 @@ type Job struct {
 	CreatedAt             time.Time  `json:"created_at"`
 	UpdatedAt             time.Time  `json:"updated_at"`
 	CurrentConversationID *string    `json:"current_conversation_id"`
+	FeedUrl               string     `json:"feed_url"`
+	FetchHeaders          string     `json:"fetch_headers"`
+	FetchHeaderDomains    string     `json:"fetch_header_domains"`
+	PromptTemplate        string     `json:"prompt_template"`
+	LastSuccessAt         *time.Time `json:"last_success_at"`
+	ScheduleJitterSecs    *int64     `json:"schedule_jitter_secs"`
+}
+
+type JobEvent struct {
+	ID        int64     `json:"id"`
+	JobID     int64     `json:"job_id"`
+	RunID     *int64    `json:"run_id"`
+	EventType string    `json:"event_type"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type JobRun struct {
-	ID                int64      `json:"id"`
-	JobID             int64      `json:"job_id"`
-	Status            string     `json:"status"`
-	ErrorMessage      *string    `json:"error_message"`
-	StartedAt         time.Time  `json:"started_at"`
-	CompletedAt       *time.Time `json:"completed_at"`
-	ArticlesSaved     *int64     `json:"articles_saved"`
-	DuplicatesSkipped *int64     `json:"duplicates_skipped"`
-	LogPath           string     `json:"log_path"`
+	ID                   int64      `json:"id"`
+	JobID                int64      `json:"job_id"`
+	Status               string     `json:"status"`
+	ErrorMessage         *string    `json:"error_message"`
+	StartedAt            time.Time  `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+	ArticlesSaved        *int64     `json:"articles_saved"`
+	DuplicatesSkipped    *int64     `json:"duplicates_skipped"`
+	LogPath              string     `json:"log_path"`
+	ConversationMessages int64      `json:"conversation_messages"`
+	EstimatedTokens      int64      `json:"estimated_tokens"`
+	ConversationID       string     `json:"conversation_id"`
+	ConversationSnapshot string     `json:"conversation_snapshot"`
+	EstimatedCostUsd     float64    `json:"estimated_cost_usd"`
+	IdempotencyKey       string     `json:"idempotency_key"`
+}
+
+type JobTag struct {
+	JobID   int64  `json:"job_id"`
+	UserID  int64  `json:"user_id"`
+	TagName string `json:"tag_name"`
 }
 
 type Migration struct {
@@ -56,15 +115,35 @@ type Migration struct {
 	ExecutedAt      time.Time `json:"executed_at"`
 }
 
+type PendingNotification struct {
+	ID         int64     `json:"id"`
+	WebhookUrl string    `json:"webhook_url"`
+	Message    string    `json:"message"`
+	CreatedAt  time.Time `json:"created_at"`
+	Attempts   int64     `json:"attempts"`
+}
+
 type Preference struct {
-	ID             int64     `json:"id"`
-	UserID         int64     `json:"user_id"`
-	SystemPrompt   string    `json:"system_prompt"`
-	DiscordWebhook string    `json:"discord_webhook"`
-	NotifySuccess  int64     `json:"notify_success"`
-	NotifyFailure  int64     `json:"notify_failure"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                 int64      `json:"id"`
+	UserID             int64      `json:"user_id"`
+	SystemPrompt       string     `json:"system_prompt"`
+	DiscordWebhook     string     `json:"discord_webhook"`
+	NotifySuccess      int64      `json:"notify_success"`
+	NotifyFailure      int64      `json:"notify_failure"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	NotifyWeeklyDigest int64      `json:"notify_weekly_digest"`
+	DigestDay          string     `json:"digest_day"`
+	LastDigestAt       *time.Time `json:"last_digest_at"`
+	UseDiscordEmbeds   int64      `json:"use_discord_embeds"`
+	ShelleyQuotaDaily  int64      `json:"shelley_quota_daily"`
+}
+
+type ReadingList struct {
+	UserID    int64     `json:"user_id"`
+	ArticleID int64     `json:"article_id"`
+	Position  int64     `json:"position"`
+	AddedAt   time.Time `json:"added_at"`
 }
 
 type User struct {
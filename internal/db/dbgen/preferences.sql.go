@@ -7,12 +7,13 @@ package dbgen
 
 import (
 	"context"
+	"time"
 )
 
 const createPreferences = `-- name: CreatePreferences :one
 INSERT INTO preferences (user_id, system_prompt, discord_webhook, notify_success, notify_failure)
 VALUES (?, '', '', 0, 0)
-RETURNING id, user_id, system_prompt, discord_webhook, notify_success, notify_failure, created_at, updated_at
+RETURNING id, user_id, system_prompt, discord_webhook, notify_success, notify_failure, created_at, updated_at, notify_weekly_digest, digest_day, last_digest_at, use_discord_embeds, shelley_quota_daily
 `
 
 func (q *Queries) CreatePreferences(ctx context.Context, userID int64) (Preference, error) {
@@ -27,12 +28,17 @@ func (q *Queries) CreatePreferences(ctx context.Context, userID int64) (Preferen
 		&i.NotifyFailure,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotifyWeeklyDigest,
+		&i.DigestDay,
+		&i.LastDigestAt,
+		&i.UseDiscordEmbeds,
+		&i.ShelleyQuotaDaily,
 	)
 	return i, err
 }
 
 const getPreferences = `-- name: GetPreferences :one
-SELECT id, user_id, system_prompt, discord_webhook, notify_success, notify_failure, created_at, updated_at FROM preferences WHERE user_id = ?
+SELECT id, user_id, system_prompt, discord_webhook, notify_success, notify_failure, created_at, updated_at, notify_weekly_digest, digest_day, last_digest_at, use_discord_embeds, shelley_quota_daily FROM preferences WHERE user_id = ?
 `
 
 func (q *Queries) GetPreferences(ctx context.Context, userID int64) (Preference, error) {
@@ -47,22 +53,87 @@ func (q *Queries) GetPreferences(ctx context.Context, userID int64) (Preference,
 		&i.NotifyFailure,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NotifyWeeklyDigest,
+		&i.DigestDay,
+		&i.LastDigestAt,
+		&i.UseDiscordEmbeds,
+		&i.ShelleyQuotaDaily,
 	)
 	return i, err
 }
 
+const listDigestPreferences = `-- name: ListDigestPreferences :many
+SELECT id, user_id, system_prompt, discord_webhook, notify_success, notify_failure, created_at, updated_at, notify_weekly_digest, digest_day, last_digest_at, use_discord_embeds, shelley_quota_daily FROM preferences
+WHERE notify_weekly_digest = 1 AND discord_webhook != ''
+ORDER BY user_id
+`
+
+func (q *Queries) ListDigestPreferences(ctx context.Context) ([]Preference, error) {
+	rows, err := q.db.QueryContext(ctx, listDigestPreferences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Preference{}
+	for rows.Next() {
+		var i Preference
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.SystemPrompt,
+			&i.DiscordWebhook,
+			&i.NotifySuccess,
+			&i.NotifyFailure,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NotifyWeeklyDigest,
+			&i.DigestDay,
+			&i.LastDigestAt,
+			&i.UseDiscordEmbeds,
+			&i.ShelleyQuotaDaily,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLastDigestAt = `-- name: UpdateLastDigestAt :exec
+UPDATE preferences SET last_digest_at = ? WHERE user_id = ?
+`
+
+type UpdateLastDigestAtParams struct {
+	LastDigestAt *time.Time `json:"last_digest_at"`
+	UserID       int64      `json:"user_id"`
+}
+
+func (q *Queries) UpdateLastDigestAt(ctx context.Context, arg UpdateLastDigestAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateLastDigestAt, arg.LastDigestAt, arg.UserID)
+	return err
+}
+
 const updatePreferences = `-- name: UpdatePreferences :exec
 UPDATE preferences 
-SET system_prompt = ?, discord_webhook = ?, notify_success = ?, notify_failure = ?, updated_at = CURRENT_TIMESTAMP
+SET system_prompt = ?, discord_webhook = ?, notify_success = ?, notify_failure = ?, notify_weekly_digest = ?, digest_day = ?, use_discord_embeds = ?, updated_at = CURRENT_TIMESTAMP
 WHERE user_id = ?
 `
 
 type UpdatePreferencesParams struct {
-	SystemPrompt   string `json:"system_prompt"`
-	DiscordWebhook string `json:"discord_webhook"`
-	NotifySuccess  int64  `json:"notify_success"`
-	NotifyFailure  int64  `json:"notify_failure"`
-	UserID         int64  `json:"user_id"`
+	SystemPrompt       string `json:"system_prompt"`
+	DiscordWebhook     string `json:"discord_webhook"`
+	NotifySuccess      int64  `json:"notify_success"`
+	NotifyFailure      int64  `json:"notify_failure"`
+	NotifyWeeklyDigest int64  `json:"notify_weekly_digest"`
+	DigestDay          string `json:"digest_day"`
+	UseDiscordEmbeds   int64  `json:"use_discord_embeds"`
+	UserID             int64  `json:"user_id"`
 }
 
 func (q *Queries) UpdatePreferences(ctx context.Context, arg UpdatePreferencesParams) error {
@@ -71,7 +142,31 @@ func (q *Queries) UpdatePreferences(ctx context.Context, arg UpdatePreferencesPa
 		arg.DiscordWebhook,
 		arg.NotifySuccess,
 		arg.NotifyFailure,
+		arg.NotifyWeeklyDigest,
+		arg.DigestDay,
+		arg.UseDiscordEmbeds,
 		arg.UserID,
 	)
 	return err
 }
+
+const updateShelleyQuota = `-- name: UpdateShelleyQuota :execrows
+INSERT INTO preferences (user_id, shelley_quota_daily)
+SELECT id, ?1 FROM users WHERE id = ?2
+ON CONFLICT (user_id) DO UPDATE SET shelley_quota_daily = excluded.shelley_quota_daily
+`
+
+type UpdateShelleyQuotaParams struct {
+	ShelleyQuotaDaily int64 `json:"shelley_quota_daily"`
+	UserID            int64 `json:"user_id"`
+}
+
+// Creates the user's preferences if they have none yet. Affects no rows if
+// the user doesn't exist.
+func (q *Queries) UpdateShelleyQuota(ctx context.Context, arg UpdateShelleyQuotaParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateShelleyQuota, arg.ShelleyQuotaDaily, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,15 @@
+-- Reading list: articles queued by the user to read in order
+
+CREATE TABLE IF NOT EXISTS reading_list (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, article_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_list_user_position ON reading_list(user_id, position);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (009, '009-reading-list');
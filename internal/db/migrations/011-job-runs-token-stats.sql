@@ -0,0 +1,8 @@
+-- Track Shelley conversation size per run for cost monitoring
+
+ALTER TABLE job_runs ADD COLUMN conversation_messages INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE job_runs ADD COLUMN estimated_tokens INTEGER NOT NULL DEFAULT 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (011, '011-job-runs-token-stats');
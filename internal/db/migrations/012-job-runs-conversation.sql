@@ -0,0 +1,9 @@
+-- Keep each run's Shelley conversation so it can be reviewed later. The
+-- snapshot is used when the live conversation has been archived.
+
+ALTER TABLE job_runs ADD COLUMN conversation_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE job_runs ADD COLUMN conversation_snapshot TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (012, '012-job-runs-conversation');
@@ -0,0 +1,8 @@
+-- Jobs with a feed_url ingest that RSS/Atom feed directly instead of asking
+-- Shelley to search for articles
+
+ALTER TABLE jobs ADD COLUMN feed_url TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (013, '013-job-feed-url');
@@ -0,0 +1,9 @@
+-- Weekly digest notification settings
+
+ALTER TABLE preferences ADD COLUMN notify_weekly_digest INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE preferences ADD COLUMN digest_day TEXT NOT NULL DEFAULT 'MON';
+ALTER TABLE preferences ADD COLUMN last_digest_at TIMESTAMP;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (015, '015-weekly-digest');
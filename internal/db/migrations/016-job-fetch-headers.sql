@@ -0,0 +1,11 @@
+-- Custom HTTP headers (a JSON object) sent when fetching a job's articles,
+-- e.g. for sources that need an API key. fetch_header_domains is a
+-- comma-separated list of domains the headers may be sent to; empty means
+-- every domain.
+
+ALTER TABLE jobs ADD COLUMN fetch_headers TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN fetch_header_domains TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (016, '016-job-fetch-headers');
@@ -0,0 +1,10 @@
+-- Deleting an article from the UI archives it instead: archived_at is set
+-- and the article drops out of listings until it is restored or purged.
+
+ALTER TABLE articles ADD COLUMN archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_articles_user_archived ON articles(user_id, archived_at);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (017, '017-article-archive');
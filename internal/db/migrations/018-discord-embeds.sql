@@ -0,0 +1,7 @@
+-- Send job notifications as Discord embeds instead of plain text
+
+ALTER TABLE preferences ADD COLUMN use_discord_embeds INTEGER NOT NULL DEFAULT 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (018, '018-discord-embeds');
@@ -0,0 +1,19 @@
+-- Per-URL article fetch timings, kept for diagnosing slow or failing sites
+
+CREATE TABLE IF NOT EXISTS fetch_telemetry (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    run_id INTEGER REFERENCES job_runs(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    content_length INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fetch_telemetry_run_id ON fetch_telemetry(run_id);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (019, '019-fetch-telemetry');
@@ -0,0 +1,7 @@
+-- Estimated Shelley cost per run, from its token count and the model's price
+
+ALTER TABLE job_runs ADD COLUMN estimated_cost_usd REAL NOT NULL DEFAULT 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (020, '020-job-runs-estimated-cost');
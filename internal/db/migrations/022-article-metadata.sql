@@ -0,0 +1,10 @@
+-- Author, publication date and publication name reported by the agent.
+-- published_at is kept as the ISO 8601 text the agent returned.
+
+ALTER TABLE articles ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN published_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN source TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (022, '022-article-metadata');
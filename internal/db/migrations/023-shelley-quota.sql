@@ -0,0 +1,7 @@
+-- Per-user cap on Shelley conversations started per day (0 = unlimited)
+
+ALTER TABLE preferences ADD COLUMN shelley_quota_daily INTEGER NOT NULL DEFAULT 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (023, '023-shelley-quota');
@@ -0,0 +1,8 @@
+-- A text/template replacing the built-in prompt structure for a job; empty
+-- uses the default prompt.
+
+ALTER TABLE jobs ADD COLUMN prompt_template TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (024, '024-job-prompt-template');
@@ -0,0 +1,14 @@
+-- Job tags: user-defined labels for filtering and grouping jobs
+
+CREATE TABLE IF NOT EXISTS job_tags (
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tag_name TEXT NOT NULL,
+    UNIQUE (job_id, tag_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_tags_user_tag ON job_tags(user_id, tag_name);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (025, '025-job-tags');
@@ -0,0 +1,7 @@
+-- Find the run a Shelley conversation belongs to without scanning job_runs
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_conversation_id ON job_runs(conversation_id);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (026, '026-job-runs-conversation-index');
@@ -0,0 +1,7 @@
+-- Lead image of an article, as extracted from its page (e.g. og:image).
+
+ALTER TABLE articles ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (027, '027-article-image-url');
@@ -0,0 +1,14 @@
+-- When a job last had a successful run, so a job whose runs keep failing
+-- can be told apart from one that ran recently and worked.
+
+ALTER TABLE jobs ADD COLUMN last_success_at TIMESTAMP;
+
+-- Backfill from existing runs
+UPDATE jobs SET last_success_at = (
+    SELECT MAX(completed_at) FROM job_runs
+    WHERE job_runs.job_id = jobs.id AND job_runs.status IN ('completed', 'completed_no_new')
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (028, '028-job-last-success');
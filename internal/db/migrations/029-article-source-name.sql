@@ -0,0 +1,10 @@
+-- Publication name of an article, from its page's og:site_name or, failing
+-- that, the URL's hostname. Unlike source, it doesn't depend on the agent.
+
+ALTER TABLE articles ADD COLUMN source_name TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_articles_user_source_name ON articles(user_id, source_name);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (029, '029-article-source-name');
@@ -0,0 +1,9 @@
+-- Per-job start delay: a run waits a random 0..schedule_jitter_secs seconds
+-- before starting. NULL uses the global NEWS_JOB_START_DELAY_SECS, 0 starts at
+-- once.
+
+ALTER TABLE jobs ADD COLUMN schedule_jitter_secs INTEGER;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (030, '030-job-schedule-jitter');
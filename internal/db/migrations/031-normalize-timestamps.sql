@@ -0,0 +1,52 @@
+-- Before _time_format=sqlite, the driver stored time.Time parameters as Go's
+-- time.String() text, e.g. "2026-01-02 03:04:05.123 -0400 EDT m=+0.01", which
+-- SQLite's date functions can't read and which doesn't sort with
+-- CURRENT_TIMESTAMP values. Rewrite those to CURRENT_TIMESTAMP's UTC
+-- "YYYY-MM-DD HH:MM:SS" layout, dropping fractional seconds.
+--
+-- instr(substr(v, 12), ' ') + 11 is the space after the time of day; the
+-- "-0400" offset after it becomes "-04:00" so datetime() converts to UTC.
+
+UPDATE visitors SET created_at = datetime(
+    substr(created_at, 1, instr(substr(created_at, 12), ' ') + 10) || substr(created_at, instr(substr(created_at, 12), ' ') + 12, 3) || ':' || substr(created_at, instr(substr(created_at, 12), ' ') + 15, 2)
+) WHERE created_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE visitors SET last_seen = datetime(
+    substr(last_seen, 1, instr(substr(last_seen, 12), ' ') + 10) || substr(last_seen, instr(substr(last_seen, 12), ' ') + 12, 3) || ':' || substr(last_seen, instr(substr(last_seen, 12), ' ') + 15, 2)
+) WHERE last_seen GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE jobs SET last_run_at = datetime(
+    substr(last_run_at, 1, instr(substr(last_run_at, 12), ' ') + 10) || substr(last_run_at, instr(substr(last_run_at, 12), ' ') + 12, 3) || ':' || substr(last_run_at, instr(substr(last_run_at, 12), ' ') + 15, 2)
+) WHERE last_run_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE jobs SET next_run_at = datetime(
+    substr(next_run_at, 1, instr(substr(next_run_at, 12), ' ') + 10) || substr(next_run_at, instr(substr(next_run_at, 12), ' ') + 12, 3) || ':' || substr(next_run_at, instr(substr(next_run_at, 12), ' ') + 15, 2)
+) WHERE next_run_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE jobs SET last_success_at = datetime(
+    substr(last_success_at, 1, instr(substr(last_success_at, 12), ' ') + 10) || substr(last_success_at, instr(substr(last_success_at, 12), ' ') + 12, 3) || ':' || substr(last_success_at, instr(substr(last_success_at, 12), ' ') + 15, 2)
+) WHERE last_success_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE articles SET retrieved_at = datetime(
+    substr(retrieved_at, 1, instr(substr(retrieved_at, 12), ' ') + 10) || substr(retrieved_at, instr(substr(retrieved_at, 12), ' ') + 12, 3) || ':' || substr(retrieved_at, instr(substr(retrieved_at, 12), ' ') + 15, 2)
+) WHERE retrieved_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE articles SET archived_at = datetime(
+    substr(archived_at, 1, instr(substr(archived_at, 12), ' ') + 10) || substr(archived_at, instr(substr(archived_at, 12), ' ') + 12, 3) || ':' || substr(archived_at, instr(substr(archived_at, 12), ' ') + 15, 2)
+) WHERE archived_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE job_runs SET started_at = datetime(
+    substr(started_at, 1, instr(substr(started_at, 12), ' ') + 10) || substr(started_at, instr(substr(started_at, 12), ' ') + 12, 3) || ':' || substr(started_at, instr(substr(started_at, 12), ' ') + 15, 2)
+) WHERE started_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE job_runs SET completed_at = datetime(
+    substr(completed_at, 1, instr(substr(completed_at, 12), ' ') + 10) || substr(completed_at, instr(substr(completed_at, 12), ' ') + 12, 3) || ':' || substr(completed_at, instr(substr(completed_at, 12), ' ') + 15, 2)
+) WHERE completed_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE preferences SET last_digest_at = datetime(
+    substr(last_digest_at, 1, instr(substr(last_digest_at, 12), ' ') + 10) || substr(last_digest_at, instr(substr(last_digest_at, 12), ' ') + 12, 3) || ':' || substr(last_digest_at, instr(substr(last_digest_at, 12), ' ') + 15, 2)
+) WHERE last_digest_at GLOB '* [+-][0-9][0-9][0-9][0-9] *';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (031, '031-normalize-timestamps');
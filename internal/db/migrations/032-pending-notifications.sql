@@ -0,0 +1,14 @@
+-- Discord notifications waiting to be sent in a batch. Each job run is its
+-- own process, so the queue lives here rather than in memory; the web server
+-- sends it once per batch window.
+
+CREATE TABLE IF NOT EXISTS pending_notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_url TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (032, '032-pending-notifications');
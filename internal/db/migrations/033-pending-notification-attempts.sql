@@ -0,0 +1,8 @@
+-- How many times a queued notification failed to send, so one that keeps
+-- failing, e.g. to a deleted webhook, is eventually dropped
+
+ALTER TABLE pending_notifications ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (033, '033-pending-notification-attempts');
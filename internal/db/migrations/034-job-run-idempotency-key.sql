@@ -0,0 +1,9 @@
+-- The X-Idempotency-Key a run creates its conversation with, so every
+-- attempt to create it, including retries after a lost response, sends the
+-- same key
+
+ALTER TABLE job_runs ADD COLUMN idempotency_key TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (034, '034-job-run-idempotency-key');
@@ -0,0 +1,24 @@
+-- Admin queries span all users and must only be exposed behind admin auth.
+
+-- name: ListAllUsersWithCounts :many
+SELECT u.*,
+    (SELECT COUNT(*) FROM jobs j WHERE j.user_id = u.id) AS job_count,
+    (SELECT COUNT(*) FROM articles a WHERE a.user_id = u.id) AS article_count
+FROM users u
+ORDER BY u.id;
+
+-- name: ListAllActiveJobs :many
+SELECT * FROM jobs WHERE is_active = 1 ORDER BY user_id, id;
+
+-- name: ListAllRunningRuns :many
+SELECT jr.*, j.name as job_name, j.user_id as job_user_id
+FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE jr.status = 'running'
+ORDER BY jr.started_at DESC;
+
+-- name: ListArticleIDsByUser :many
+SELECT id FROM articles WHERE user_id = ?;
+
+-- name: DeleteUserCascade :execrows
+DELETE FROM users WHERE id = ?;
@@ -2,49 +2,115 @@
 SELECT * FROM articles WHERE id = ? AND user_id = ?;
 
 -- name: ListArticlesByUser :many
-SELECT * FROM articles WHERE user_id = ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM articles WHERE user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+
+-- name: ListArticlesByUserSortedByTitle :many
+SELECT * FROM articles WHERE user_id = ?1 AND archived_at IS NULL
+ORDER BY CASE WHEN ?2 THEN lower(title) END DESC, lower(title), retrieved_at DESC, id
+LIMIT ?3 OFFSET ?4;
+
+-- name: ListArticlesByUserSortedBySource :many
+-- Articles without a source name come last in either order.
+SELECT * FROM articles WHERE user_id = ?1 AND archived_at IS NULL
+ORDER BY source_name = '', CASE WHEN ?2 THEN lower(source_name) END DESC, lower(source_name), retrieved_at DESC, id
+LIMIT ?3 OFFSET ?4;
+
+-- name: ListArticlesByUserSortedByJob :many
+-- Sorted by the name of the job that found them.
+SELECT articles.* FROM articles
+JOIN jobs ON jobs.id = articles.job_id
+WHERE articles.user_id = ?1 AND articles.archived_at IS NULL
+ORDER BY CASE WHEN ?2 THEN lower(jobs.name) END DESC, lower(jobs.name), articles.retrieved_at DESC, articles.id
+LIMIT ?3 OFFSET ?4;
 
 -- name: ListArticlesByJob :many
 SELECT * FROM articles WHERE job_id = ? ORDER BY retrieved_at DESC;
 
 -- name: CreateArticle :one
-INSERT INTO articles (job_id, user_id, title, url, summary, content_path, retrieved_at)
-VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+INSERT INTO articles (job_id, user_id, title, url, summary, content_path, author, published_at, source, image_url, source_name, retrieved_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: DeleteArticle :exec
 DELETE FROM articles WHERE id = ? AND user_id = ?;
 
 -- name: CountArticlesByUser :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ?;
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL;
 
 -- name: ListArticlesByUserSince :many
-SELECT * FROM articles WHERE user_id = ? AND retrieved_at >= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
 
 -- name: CountArticlesByUserSince :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND retrieved_at >= ?;
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ?;
+
+-- name: CountArticlesByUserPerDay :many
+-- Articles retrieved per UTC day since @since; a job_id of 0 counts every job.
+SELECT CAST(strftime('%Y-%m-%d', retrieved_at) AS TEXT) AS date, COUNT(*) AS count
+FROM articles
+WHERE user_id = @user_id AND archived_at IS NULL AND retrieved_at >= @since
+    AND (CAST(@job_id AS INTEGER) = 0 OR job_id = @job_id)
+GROUP BY date
+ORDER BY date;
 
 -- name: ListArticlesByUserDateRange :many
-SELECT * FROM articles WHERE user_id = ? AND retrieved_at >= ? AND retrieved_at <= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? AND retrieved_at <= ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
 
 -- name: CountArticlesByUserDateRange :one
-SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND retrieved_at >= ? AND retrieved_at <= ?;
+SELECT COUNT(*) as count FROM articles WHERE user_id = ? AND archived_at IS NULL AND retrieved_at >= ? AND retrieved_at <= ?;
 
 -- name: SearchArticlesByUser :many
 SELECT * FROM articles 
-WHERE user_id = ? AND (title LIKE ? OR summary LIKE ?)
+WHERE user_id = ? AND archived_at IS NULL AND (title LIKE ? OR summary LIKE ?)
 ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
 
 -- name: CountSearchArticlesByUser :one
 SELECT COUNT(*) FROM articles 
-WHERE user_id = ? AND (title LIKE ? OR summary LIKE ?);
+WHERE user_id = ? AND archived_at IS NULL AND (title LIKE ? OR summary LIKE ?);
+
+-- name: SearchArticles :many
+-- Full-text search; a job_id of 0 searches every job.
+SELECT articles.* FROM articles_fts
+JOIN articles ON articles.id = articles_fts.rowid
+WHERE articles_fts MATCH @query AND articles.user_id = @user_id AND articles.archived_at IS NULL
+    AND (CAST(@job_id AS INTEGER) = 0 OR articles.job_id = @job_id)
+ORDER BY articles_fts.rank, articles.retrieved_at DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: CountSearchArticles :one
+SELECT COUNT(*) FROM articles_fts
+JOIN articles ON articles.id = articles_fts.rowid
+WHERE articles_fts MATCH @query AND articles.user_id = @user_id AND articles.archived_at IS NULL
+    AND (CAST(@job_id AS INTEGER) = 0 OR articles.job_id = @job_id);
 
 
 -- name: ListArticlesByJobPaginated :many
-SELECT * FROM articles WHERE job_id = ? AND user_id = ? ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM articles WHERE job_id = ? AND user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
 
 -- name: CountArticlesByJob :one
-SELECT COUNT(*) FROM articles WHERE job_id = ? AND user_id = ?;
+SELECT COUNT(*) FROM articles WHERE job_id = ? AND user_id = ? AND archived_at IS NULL;
+
+-- name: ListArticlesBySourceName :many
+SELECT * FROM articles WHERE user_id = ? AND source_name = ? AND archived_at IS NULL ORDER BY retrieved_at DESC LIMIT ? OFFSET ?;
+
+-- name: CountArticlesBySourceName :one
+SELECT COUNT(*) FROM articles WHERE user_id = ? AND source_name = ? AND archived_at IS NULL;
+
+-- name: ListArticleSources :many
+-- URL and source name of each of a user's articles, newest first, for
+-- grouping by domain.
+SELECT url, source_name FROM articles WHERE user_id = ? AND archived_at IS NULL ORDER BY retrieved_at DESC;
 
 -- name: ArticleExistsByURL :one
 SELECT COUNT(*) FROM articles WHERE user_id = ? AND url = ?;
+
+-- name: ListArchivedArticles :many
+SELECT * FROM articles WHERE user_id = ? AND archived_at IS NOT NULL ORDER BY archived_at DESC LIMIT ? OFFSET ?;
+
+-- name: CountArchivedArticles :one
+SELECT COUNT(*) FROM articles WHERE user_id = ? AND archived_at IS NOT NULL;
+
+-- name: RestoreArticle :execrows
+UPDATE articles SET archived_at = NULL WHERE id = ? AND user_id = ? AND archived_at IS NOT NULL;
+
+-- name: ListPurgeableArticleIDs :many
+SELECT id FROM articles WHERE user_id = ? AND archived_at IS NOT NULL AND archived_at < ?;
@@ -0,0 +1,41 @@
+-- name: CreateCollection :one
+INSERT INTO collections (user_id, name, parent_id)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetCollection :one
+SELECT * FROM collections WHERE id = ? AND user_id = ?;
+
+-- name: ListCollections :many
+SELECT * FROM collections
+WHERE user_id = ?
+ORDER BY COALESCE(parent_id, id), parent_id IS NOT NULL, name COLLATE NOCASE;
+
+-- name: DeleteCollection :execrows
+DELETE FROM collections WHERE id = ? AND user_id = ?;
+
+-- name: AddArticleToCollection :execrows
+INSERT INTO collection_articles (collection_id, article_id)
+VALUES (?, ?)
+ON CONFLICT (collection_id, article_id) DO NOTHING;
+
+-- name: RemoveArticleFromCollection :execrows
+DELETE FROM collection_articles WHERE collection_id = ? AND article_id = ?;
+
+-- name: MoveCollectionArticles :exec
+INSERT INTO collection_articles (collection_id, article_id, added_at)
+SELECT ?, article_id, added_at FROM collection_articles
+WHERE collection_articles.collection_id = ?
+ON CONFLICT (collection_id, article_id) DO NOTHING;
+
+-- name: ListArticlesByCollection :many
+SELECT a.* FROM collection_articles ca
+JOIN articles a ON a.id = ca.article_id
+WHERE a.user_id = ? AND ca.collection_id = ? AND a.archived_at IS NULL
+ORDER BY a.retrieved_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountArticlesByCollection :one
+SELECT COUNT(*) FROM collection_articles ca
+JOIN articles a ON a.id = ca.article_id
+WHERE a.user_id = ? AND ca.collection_id = ? AND a.archived_at IS NULL;
@@ -0,0 +1,6 @@
+-- name: InsertFetchTelemetry :exec
+INSERT INTO fetch_telemetry (job_id, run_id, url, duration_ms, status_code, error, content_length)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: DeleteFetchTelemetryBefore :execrows
+DELETE FROM fetch_telemetry WHERE created_at < @before;
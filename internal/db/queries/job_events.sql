@@ -0,0 +1,14 @@
+-- name: InsertJobEvent :exec
+INSERT INTO job_events (job_id, run_id, event_type, message)
+VALUES (?, ?, ?, ?);
+
+-- name: ListJobEvents :many
+SELECT * FROM job_events
+WHERE job_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: ListRunEvents :many
+SELECT * FROM job_events
+WHERE run_id = ?
+ORDER BY id;
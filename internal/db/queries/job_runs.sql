@@ -11,6 +11,15 @@ WHERE id = ?;
 -- name: UpdateJobRunLogPath :exec
 UPDATE job_runs SET log_path = ? WHERE id = ?;
 
+-- name: UpdateJobRunConversation :exec
+UPDATE job_runs SET conversation_id = ? WHERE id = ?;
+
+-- name: UpdateJobRunSnapshot :exec
+UPDATE job_runs SET conversation_snapshot = ? WHERE id = ?;
+
+-- name: UpdateJobRunIdempotencyKey :exec
+UPDATE job_runs SET idempotency_key = ? WHERE id = ?;
+
 -- name: GetJobRunLogPath :one
 SELECT log_path FROM job_runs jr
 JOIN jobs j ON jr.job_id = j.id
@@ -19,6 +28,17 @@ WHERE jr.id = ? AND j.user_id = ?;
 -- name: ListJobRunsByJob :many
 SELECT * FROM job_runs WHERE job_id = ? ORDER BY started_at DESC LIMIT 10;
 
+-- name: ListJobRunsByJobPage :many
+SELECT * FROM job_runs WHERE job_id = ? ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?;
+
+-- name: CountJobRunsByJob :one
+SELECT COUNT(*) FROM job_runs WHERE job_id = ?;
+
+-- name: CountActiveRuns :one
+-- Runs executing in any process. Runs started before @since are taken to be
+-- abandoned, e.g. by a crashed process, and aren't counted.
+SELECT COUNT(*) FROM job_runs WHERE status = 'running' AND started_at > @since;
+
 -- name: ListRunningJobRuns :many
 SELECT jr.*, j.name as job_name, j.user_id as job_user_id
 FROM job_runs jr
@@ -40,6 +60,13 @@ FROM job_runs jr
 JOIN jobs j ON jr.job_id = j.id
 WHERE jr.id = ? AND j.user_id = ?;
 
+-- name: GetLatestJobRun :one
+SELECT jr.* FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE jr.job_id = ? AND j.user_id = ?
+ORDER BY jr.started_at DESC, jr.id DESC
+LIMIT 1;
+
 -- name: CancelJobRun :exec
 UPDATE job_runs
 SET status = 'cancelled', error_message = 'Cancelled by user', completed_at = CURRENT_TIMESTAMP
@@ -52,5 +79,29 @@ WHERE job_id = ? AND status = 'running';
 
 -- name: UpdateJobRunComplete :exec
 UPDATE job_runs
-SET status = ?, error_message = ?, articles_saved = ?, duplicates_skipped = ?, completed_at = CURRENT_TIMESTAMP
+SET status = ?, error_message = ?, articles_saved = ?, duplicates_skipped = ?,
+    conversation_messages = ?, estimated_tokens = ?, estimated_cost_usd = ?, conversation_id = ?,
+    completed_at = CURRENT_TIMESTAMP
 WHERE id = ?;
+
+-- name: GetJobRunStats :one
+SELECT
+    COUNT(*) AS total_runs,
+    CAST(COALESCE(SUM(conversation_messages), 0) AS INTEGER) AS total_messages,
+    CAST(COALESCE(SUM(estimated_tokens), 0) AS INTEGER) AS total_tokens,
+    CAST(COALESCE(AVG(estimated_tokens), 0) AS REAL) AS avg_tokens_per_run
+FROM job_runs
+WHERE job_id = ? AND completed_at IS NOT NULL;
+
+-- name: CostSummary :many
+SELECT jr.job_id, CAST(SUM(jr.estimated_cost_usd) AS REAL) AS cost
+FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE j.user_id = ? AND jr.started_at >= ?
+GROUP BY jr.job_id
+ORDER BY cost DESC, jr.job_id;
+
+-- name: CountUserConversationsSince :one
+SELECT COUNT(*) FROM job_runs jr
+JOIN jobs j ON jr.job_id = j.id
+WHERE j.user_id = ? AND jr.started_at >= ? AND jr.conversation_id != '';
@@ -0,0 +1,25 @@
+-- name: AddJobTag :exec
+INSERT INTO job_tags (job_id, user_id, tag_name)
+VALUES (?, ?, ?)
+ON CONFLICT (job_id, tag_name) DO NOTHING;
+
+-- name: RemoveJobTag :exec
+DELETE FROM job_tags WHERE job_id = ? AND tag_name = ?;
+
+-- name: ClearJobTags :exec
+DELETE FROM job_tags WHERE job_id = ?;
+
+-- name: ListJobTags :many
+SELECT tag_name FROM job_tags WHERE job_id = ? ORDER BY tag_name;
+
+-- name: ListJobTagsByUser :many
+SELECT job_id, tag_name FROM job_tags WHERE user_id = ? ORDER BY job_id, tag_name;
+
+-- name: ListUserTags :many
+SELECT DISTINCT tag_name FROM job_tags WHERE user_id = ? ORDER BY tag_name;
+
+-- name: ListJobsByTag :many
+SELECT j.* FROM jobs j
+JOIN job_tags jt ON jt.job_id = j.id
+WHERE jt.user_id = ? AND jt.tag_name = ?
+ORDER BY j.created_at DESC;
@@ -4,17 +4,33 @@ SELECT * FROM jobs WHERE id = ? AND user_id = ?;
 -- name: ListJobsByUser :many
 SELECT * FROM jobs WHERE user_id = ? ORDER BY created_at DESC;
 
+-- name: ListJobsByUserSortedByLastRun :many
+SELECT * FROM jobs WHERE user_id = ?1
+ORDER BY last_run_at IS NULL, CASE WHEN ?2 THEN last_run_at END DESC, last_run_at, id;
+
+-- name: ListJobsByUserSortedByName :many
+SELECT * FROM jobs WHERE user_id = ?1
+ORDER BY CASE WHEN ?2 THEN lower(name) END DESC, lower(name), id;
+
+-- name: ListJobsByUserSortedByNextRun :many
+SELECT * FROM jobs WHERE user_id = ?1
+ORDER BY next_run_at IS NULL, CASE WHEN ?2 THEN next_run_at END DESC, next_run_at, id;
+
+-- name: ListJobsByUserSortedByStatus :many
+SELECT * FROM jobs WHERE user_id = ?1
+ORDER BY CASE WHEN ?2 THEN status END DESC, status, lower(name), id;
+
 -- name: ListActiveJobs :many
 SELECT * FROM jobs WHERE is_active = 1 AND (is_one_time = 0 OR status = 'pending');
 
 -- name: CreateJob :one
-INSERT INTO jobs (user_id, name, prompt, keywords, sources, region, frequency, is_one_time, is_active, status, next_run_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1, 'pending', ?)
+INSERT INTO jobs (user_id, name, prompt, keywords, sources, region, frequency, is_one_time, feed_url, fetch_headers, fetch_header_domains, prompt_template, schedule_jitter_secs, is_active, status, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, 'pending', ?)
 RETURNING *;
 
 -- name: UpdateJob :exec
 UPDATE jobs
-SET name = ?, prompt = ?, keywords = ?, sources = ?, region = ?, frequency = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+SET name = ?, prompt = ?, keywords = ?, sources = ?, region = ?, frequency = ?, is_active = ?, feed_url = ?, fetch_headers = ?, fetch_header_domains = ?, prompt_template = ?, schedule_jitter_secs = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ? AND user_id = ?;
 
 -- name: UpdateJobStatus :exec
@@ -22,6 +38,9 @@ UPDATE jobs
 SET status = ?, last_run_at = ?, next_run_at = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
+-- name: UpdateJobLastSuccess :exec
+UPDATE jobs SET last_success_at = ? WHERE id = ?;
+
 -- name: DeleteJob :exec
 DELETE FROM jobs WHERE id = ? AND user_id = ?;
 
@@ -33,3 +52,19 @@ UPDATE jobs SET current_conversation_id = ? WHERE id = ?;
 
 -- name: DeactivateJob :exec
 UPDATE jobs SET is_active = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: ListJobArticleCountsSince :many
+SELECT j.id, j.name, COUNT(a.id) AS article_count
+FROM jobs j
+LEFT JOIN articles a ON a.job_id = j.id AND a.retrieved_at >= ?
+WHERE j.user_id = ?
+GROUP BY j.id
+ORDER BY article_count DESC, j.name;
+
+-- name: ListJobsWithArticleCounts :many
+SELECT j.id, j.name, j.frequency, j.is_one_time, j.is_active, j.status, j.last_run_at, j.next_run_at, j.last_success_at, j.created_at, COUNT(a.id) AS article_count
+FROM jobs j
+LEFT JOIN articles a ON a.job_id = j.id
+WHERE j.user_id = ?
+GROUP BY j.id
+ORDER BY j.created_at DESC;
@@ -0,0 +1,12 @@
+-- name: CreatePendingNotification :exec
+INSERT INTO pending_notifications (webhook_url, message)
+VALUES (?, ?);
+
+-- name: RequeuePendingNotification :exec
+-- Puts back a notification that failed to send, counting the attempt.
+INSERT INTO pending_notifications (webhook_url, message, attempts, created_at)
+VALUES (?, ?, ?, ?);
+
+-- name: TakePendingNotifications :many
+DELETE FROM pending_notifications
+RETURNING *;
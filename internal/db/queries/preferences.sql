@@ -8,5 +8,20 @@ RETURNING *;
 
 -- name: UpdatePreferences :exec
 UPDATE preferences 
-SET system_prompt = ?, discord_webhook = ?, notify_success = ?, notify_failure = ?, updated_at = CURRENT_TIMESTAMP
+SET system_prompt = ?, discord_webhook = ?, notify_success = ?, notify_failure = ?, notify_weekly_digest = ?, digest_day = ?, use_discord_embeds = ?, updated_at = CURRENT_TIMESTAMP
 WHERE user_id = ?;
+
+-- name: ListDigestPreferences :many
+SELECT * FROM preferences
+WHERE notify_weekly_digest = 1 AND discord_webhook != ''
+ORDER BY user_id;
+
+-- name: UpdateLastDigestAt :exec
+UPDATE preferences SET last_digest_at = ? WHERE user_id = ?;
+
+-- name: UpdateShelleyQuota :execrows
+-- Creates the user's preferences if they have none yet. Affects no rows if
+-- the user doesn't exist.
+INSERT INTO preferences (user_id, shelley_quota_daily)
+SELECT id, @shelley_quota_daily FROM users WHERE id = @user_id
+ON CONFLICT (user_id) DO UPDATE SET shelley_quota_daily = excluded.shelley_quota_daily;
@@ -0,0 +1,17 @@
+-- name: AddToReadingList :execrows
+INSERT INTO reading_list (user_id, article_id, position, added_at)
+SELECT ?, ?, COALESCE(MAX(position), 0) + 1, CURRENT_TIMESTAMP
+FROM reading_list WHERE user_id = ?
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+-- name: RemoveFromReadingList :execrows
+DELETE FROM reading_list WHERE user_id = ? AND article_id = ?;
+
+-- name: UpdateReadingListPosition :exec
+UPDATE reading_list SET position = ? WHERE user_id = ? AND article_id = ?;
+
+-- name: GetReadingList :many
+SELECT a.* FROM reading_list rl
+JOIN articles a ON a.id = rl.article_id
+WHERE rl.user_id = ? AND a.archived_at IS NULL
+ORDER BY rl.position ASC, rl.added_at ASC;
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
 )
 
 // CleanupConfig holds configuration for conversation cleanup.
@@ -16,8 +18,14 @@ type CleanupConfig struct {
 	ShelleyAPI    string
 	MaxAgeHours   int
 	DryRun        bool
+	UseAPI        bool   // list conversations through the Shelley API rather than its database; implied when ShelleyDBPath is empty
+	UserID        string // only clean up conversations created as this user, e.g. JobUserID(id); empty cleans up every user's
 }
 
+// cleanupPageSize is how many conversations API-based cleanup lists per
+// request.
+const cleanupPageSize = 100
+
 // DefaultCleanupConfig returns default cleanup configuration.
 func DefaultCleanupConfig() CleanupConfig {
 	return CleanupConfig{
@@ -35,28 +43,35 @@ type CleanupResult struct {
 	Failed  int
 }
 
-// Cleanup removes old conversations from the Shelley API.
+// Cleanup removes old conversations from the Shelley API. Old
+// conversations are found in Shelley's database, or through its API when
+// cfg.UseAPI is set or there is no database path.
 func Cleanup(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
+	if cfg.UseAPI || cfg.ShelleyDBPath == "" {
+		return cleanupViaAPI(ctx, cfg)
+	}
+
 	logger := slog.Default()
 	result := &CleanupResult{}
 
 	// Open Shelley database (read-only)
-	db, err := sql.Open("sqlite", cfg.ShelleyDBPath+"?mode=ro")
+	shelleyDB, err := db.OpenReadOnly(cfg.ShelleyDBPath)
 	if err != nil {
 		return nil, fmt.Errorf("open shelley db: %w", err)
 	}
-	defer db.Close()
+	defer shelleyDB.Close()
 
 	// Find old parent conversations (cwd IS NULL = API-created, not interactive)
 	cutoff := time.Now().Add(-time.Duration(cfg.MaxAgeHours) * time.Hour)
-	rows, err := db.QueryContext(ctx, `
+	rows, err := shelleyDB.QueryContext(ctx, `
 		SELECT conversation_id 
 		FROM conversations 
 		WHERE cwd IS NULL 
 		AND parent_conversation_id IS NULL
-		AND created_at < ?
+		AND created_at < ?1
+		AND (?2 = '' OR user_id LIKE ?2)
 		ORDER BY created_at ASC
-	`, cutoff.UTC().Format("2006-01-02 15:04:05"))
+	`, cutoff.UTC().Format("2006-01-02 15:04:05"), cfg.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("query old conversations: %w", err)
 	}
@@ -75,7 +90,7 @@ func Cleanup(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
 	}
 
 	result.Found = len(parentIDs)
-	logger.Info("found old conversations", "count", result.Found, "max_age_hours", cfg.MaxAgeHours)
+	logger.Info("found old conversations", "count", result.Found, "max_age_hours", cfg.MaxAgeHours, "user_id", cfg.UserID)
 
 	if cfg.DryRun {
 		logger.Info("dry run - not deleting")
@@ -86,8 +101,11 @@ func Cleanup(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
 	client := NewShelleyClient(cfg.ShelleyAPI)
 
 	// Delete each parent and its children
+	children := func(convID string) ([]string, error) {
+		return dbChildConversations(ctx, shelleyDB, convID)
+	}
 	for _, parentID := range parentIDs {
-		deleted, failed := deleteConversationTree(ctx, db, client, parentID, logger)
+		deleted, failed := deleteConversationTree(ctx, client, children, parentID, logger)
 		result.Deleted += deleted
 		result.Failed += failed
 	}
@@ -100,37 +118,119 @@ func Cleanup(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
 	return result, nil
 }
 
-// deleteConversationTree deletes a conversation and all its children.
-func deleteConversationTree(ctx context.Context, db *sql.DB, client *ShelleyClient, convID string, logger *slog.Logger) (deleted, failed int) {
-	// Find children first
+// cleanupViaAPI is Cleanup for when Shelley's database isn't available. It
+// lists every conversation before deleting any, so deletions don't shift
+// the pages still to be read.
+func cleanupViaAPI(ctx context.Context, cfg CleanupConfig) (*CleanupResult, error) {
+	logger := slog.Default()
+	result := &CleanupResult{}
+	client := NewShelleyClient(cfg.ShelleyAPI)
+
+	cutoff := time.Now().Add(-time.Duration(cfg.MaxAgeHours) * time.Hour)
+	var parentIDs []string
+	childIDs := make(map[string][]string)
+	for page := 1; ; page++ {
+		convs, err := client.ListConversations(ctx, "cleanup", cfg.UserID, page, cleanupPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("list conversations (page %d): %w", page, err)
+		}
+		for _, conv := range convs {
+			switch {
+			case cfg.UserID != "" && conv.UserID != "" && conv.UserID != cfg.UserID:
+				// Shelley versions that don't filter by user_id still report it
+			case conv.ParentConversationID != "":
+				childIDs[conv.ParentConversationID] = append(childIDs[conv.ParentConversationID], conv.ConversationID)
+			case conv.Cwd == "" && conv.CreatedAt.Before(cutoff):
+				// API-created, not interactive
+				parentIDs = append(parentIDs, conv.ConversationID)
+			}
+		}
+		if len(convs) < cleanupPageSize {
+			break
+		}
+	}
+
+	result.Found = len(parentIDs)
+	logger.Info("found old conversations", "count", result.Found, "max_age_hours", cfg.MaxAgeHours, "user_id", cfg.UserID, "source", "api")
+
+	if cfg.DryRun {
+		logger.Info("dry run - not deleting")
+		return result, nil
+	}
+
+	children := func(convID string) ([]string, error) {
+		return childIDs[convID], nil
+	}
+	for _, parentID := range parentIDs {
+		deleted, failed := deleteConversationTree(ctx, client, children, parentID, logger)
+		result.Deleted += deleted
+		result.Failed += failed
+	}
+
+	logger.Info("cleanup complete",
+		"found", result.Found,
+		"deleted", result.Deleted,
+		"failed", result.Failed)
+
+	return result, nil
+}
+
+// PruneFetchTelemetry deletes the article fetch telemetry recorded more than
+// maxAge ago from the news-app database and returns how many rows went.
+func PruneFetchTelemetry(ctx context.Context, dbConn *sql.DB, maxAge time.Duration) (int64, error) {
+	pruned, err := dbgen.New(dbConn).DeleteFetchTelemetryBefore(ctx, time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return 0, fmt.Errorf("prune fetch telemetry: %w", err)
+	}
+	return pruned, nil
+}
+
+// dbChildConversations returns the IDs of a conversation's children in
+// Shelley's database.
+func dbChildConversations(ctx context.Context, db *sql.DB, convID string) ([]string, error) {
 	rows, err := db.QueryContext(ctx, `
 		SELECT conversation_id FROM conversations 
 		WHERE parent_conversation_id = ?
 	`, convID)
 	if err != nil {
-		logger.Warn("query children", "conversation_id", convID, "error", err)
-	} else {
-		var childIDs []string
-		for rows.Next() {
-			var id string
-			if err := rows.Scan(&id); err == nil {
-				childIDs = append(childIDs, id)
-			}
-		}
-		rows.Close()
-
-		// Recursively delete children
-		for _, childID := range childIDs {
-			logger.Debug("deleting child conversation", "child_id", childID, "parent_id", convID)
-			d, f := deleteConversationTree(ctx, db, client, childID, logger)
-			deleted += d
-			failed += f
+		return nil, err
+	}
+	defer rows.Close()
+
+	var childIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			childIDs = append(childIDs, id)
 		}
 	}
+	return childIDs, rows.Err()
+}
+
+// deleteConversationTree deletes a conversation and all its children, as
+// reported by children.
+func deleteConversationTree(ctx context.Context, client *ShelleyClient, children func(convID string) ([]string, error), convID string, logger *slog.Logger) (deleted, failed int) {
+	// Find children first
+	childIDs, err := children(convID)
+	if err != nil {
+		logger.Warn("query children", "conversation_id", convID, "error", err)
+	}
+
+	// Recursively delete children
+	for _, childID := range childIDs {
+		logger.Debug("deleting child conversation", "child_id", childID, "parent_id", convID)
+		d, f := deleteConversationTree(ctx, client, children, childID, logger)
+		deleted += d
+		failed += f
+	}
 
 	// Delete this conversation
 	logger.Info("deleting conversation", "conversation_id", convID)
-	if err := client.DeleteConversationAsCleanup(ctx, convID); err != nil {
+	err = client.DeleteConversationAsCleanup(ctx, convID)
+	if isShelleyStatus(err, http.StatusNotFound) {
+		err = nil // already gone
+	}
+	if err != nil {
 		logger.Warn("delete conversation", "conversation_id", convID, "error", err)
 		failed++
 	} else {
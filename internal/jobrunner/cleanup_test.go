@@ -0,0 +1,136 @@
+package jobrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanupViaAPI(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	var convs []ConversationSummary
+	for i := range 120 {
+		convs = append(convs, ConversationSummary{ConversationID: fmt.Sprintf("old-%d", i), CreatedAt: old})
+	}
+	convs = append(convs,
+		ConversationSummary{ConversationID: "child", CreatedAt: time.Now(), ParentConversationID: "old-0"},
+		ConversationSummary{ConversationID: "recent", CreatedAt: time.Now()},
+		ConversationSummary{ConversationID: "interactive", CreatedAt: old, Cwd: "/home/exedev"},
+	)
+
+	var mu sync.Mutex
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		start := min((page-1)*limit, len(convs))
+		json.NewEncoder(w).Encode(convs[start:min(start+limit, len(convs))])
+	})
+	mux.HandleFunc("DELETE /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deleted = append(deleted, r.PathValue("id"))
+		mu.Unlock()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := CleanupConfig{ShelleyAPI: srv.URL, MaxAgeHours: 48, DryRun: true}
+	result, err := Cleanup(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("dry run Cleanup() error = %v", err)
+	}
+	if result.Found != 120 || len(deleted) != 0 {
+		t.Fatalf("dry run found %d and deleted %d, want 120 found and none deleted", result.Found, len(deleted))
+	}
+
+	cfg.DryRun = false
+	result, err = Cleanup(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if result.Found != 120 || result.Deleted != 121 || result.Failed != 0 {
+		t.Errorf("result = %+v, want 120 found and 121 deleted", result)
+	}
+	if deleted[0] != "child" || deleted[1] != "old-0" {
+		t.Errorf("first deletions = %q, want the child before its parent", deleted[:2])
+	}
+	for _, kept := range []string{"recent", "interactive"} {
+		if slices.Contains(deleted, kept) {
+			t.Errorf("conversation %q was deleted", kept)
+		}
+	}
+}
+
+func TestCleanupViaAPIByUser(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	convs := []ConversationSummary{
+		{ConversationID: "job-1-a", UserID: JobUserID(1), CreatedAt: old},
+		{ConversationID: "job-1-b", UserID: JobUserID(1), CreatedAt: old},
+		{ConversationID: "job-2-a", UserID: JobUserID(2), CreatedAt: old},
+	}
+
+	var mu sync.Mutex
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		var matching []ConversationSummary
+		for _, c := range convs {
+			if user := r.URL.Query().Get("user_id"); user == "" || c.UserID == user {
+				matching = append(matching, c)
+			}
+		}
+		json.NewEncoder(w).Encode(matching)
+	})
+	mux.HandleFunc("DELETE /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deleted = append(deleted, r.PathValue("id"))
+		mu.Unlock()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := CleanupConfig{ShelleyAPI: srv.URL, MaxAgeHours: 48, UserID: JobUserID(1)}
+	result, err := Cleanup(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if result.Found != 2 || result.Deleted != 2 {
+		t.Errorf("result = %+v, want 2 found and deleted", result)
+	}
+	slices.Sort(deleted)
+	if want := []string{"job-1-a", "job-1-b"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted = %q, want %q", deleted, want)
+	}
+}
+
+func TestPruneFetchTelemetry(t *testing.T) {
+	_, dbConn, job := newTestRunner(t, "http://localhost:0")
+	ctx := context.Background()
+
+	for _, age := range []time.Duration{40 * 24 * time.Hour, time.Hour} {
+		_, err := dbConn.ExecContext(ctx,
+			"INSERT INTO fetch_telemetry (job_id, url, duration_ms, created_at) VALUES (?, 'https://example.com', 100, ?)",
+			job.ID, time.Now().UTC().Add(-age).Format("2006-01-02 15:04:05"))
+		if err != nil {
+			t.Fatalf("insert telemetry: %v", err)
+		}
+	}
+
+	pruned, err := PruneFetchTelemetry(ctx, dbConn, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("PruneFetchTelemetry() error = %v", err)
+	}
+	var remaining int
+	dbConn.QueryRowContext(ctx, "SELECT COUNT(*) FROM fetch_telemetry").Scan(&remaining)
+	if pruned != 1 || remaining != 1 {
+		t.Errorf("pruned %d rows, %d left; want 1 and 1", pruned, remaining)
+	}
+}
@@ -0,0 +1,184 @@
+package jobrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/exedev/news-app/internal/util"
+)
+
+// configFieldMeta describes a Config field for generated config files.
+type configFieldMeta struct {
+	Description string
+	Env         string
+}
+
+// configMeta holds the description and environment variable of every Config
+// field, keyed by field name. WriteConfigTOML fails for fields missing here.
+var configMeta = map[string]configFieldMeta{
+	"DBPath":              {"SQLite database path", "NEWS_APP_DB_PATH"},
+	"ArticlesDir":         {"Directory article files are saved under", "NEWS_APP_ARTICLES_DIR"},
+	"LogsDir":             {"Directory per-run log files are written to", "NEWS_APP_LOGS_DIR"},
+	"ShelleyAPI":          {"Shelley API base URL", "NEWS_APP_SHELLEY_API"},
+	"Model":               {"Shelley model used for job conversations", "NEWS_SHELLEY_MODEL"},
+	"ModelCostMap":        {"Estimated USD per million tokens, by model", "NEWS_MODEL_COSTS"},
+	"JobTimeout":          {"Maximum time to wait for a job's conversation (env value in seconds)", "NEWS_JOB_TIMEOUT_SECS"},
+	"PollInterval":        {"Interval between Shelley API polls (env value in seconds)", "NEWS_JOB_POLL_INTERVAL_SECS"},
+	"StatusCheckInterval": {"Interval between checks of a running conversation's status; 0 uses PollInterval (env value in seconds)", "NEWS_JOB_STATUS_CHECK_INTERVAL_SECS"},
+	"StartDelay":          {"Maximum random delay before a job starts (env value in seconds)", "NEWS_JOB_START_DELAY_SECS"},
+	"MaxParallel":         {"Maximum concurrent article fetches per run", "NEWS_JOB_MAX_PARALLEL"},
+	"UserAgents":          {"User agents rotated across article fetches; empty uses common browser UAs", "NEWS_FETCH_USER_AGENTS"},
+	"ProxyURL":            {"Proxy for article and feed fetches", "NEWS_FETCH_PROXY"},
+	"ProxyURLs":           {"Proxies rotated across article fetches", "NEWS_FETCH_PROXIES"},
+	"CacheSize":           {"Maximum cached article contents; negative disables the cache", "NEWS_FETCH_CACHE_SIZE"},
+	"CacheTTL":            {"How long cached article content is reused", "NEWS_FETCH_CACHE_TTL"},
+	"RespectRobots":       {"Skip articles whose site's robots.txt disallows them", "NEWS_FETCH_RESPECT_ROBOTS"},
+	"ArticleDateDirs":     {"Save article files in YYYY/MM subdirectories of each job's directory", "NEWS_APP_ARTICLES_DATE_DIRS"},
+	"EnableFollowUp":      {"Ask for more articles in the same conversation when the first answer is short", "NEWS_FOLLOWUP_ENABLE"},
+	"FollowUpPrompt":      {"Template of the follow-up message (fields: Prompt, ArticleCount, URLs)", "NEWS_FOLLOWUP_PROMPT"},
+	"FollowUpMinArticles": {"Send a follow-up when the first answer has fewer articles than this", "NEWS_FOLLOWUP_MIN_ARTICLES"},
+	"BatchNotifications":  {"Combine Discord notifications sent within the batch window", "NEWS_NOTIFY_BATCH"},
+	"BatchWindow":         {"How often the web server sends queued notifications", "NEWS_NOTIFY_BATCH_WINDOW"},
+	"MaxConcurrentRuns":   {"Maximum job runs executing at once, across all processes and users", "NEWS_MAX_CONCURRENT_RUNS"},
+}
+
+// WriteDefaultConfig writes DefaultConfig as a commented-out TOML reference
+// listing every setting, its environment variable and its default.
+func WriteDefaultConfig(w io.Writer) error {
+	return WriteConfigTOML(w, DefaultConfig(), false)
+}
+
+// builtinConfig returns DefaultConfig as it is with none of the environment
+// variables in configMeta set. It unsets them while it runs, so it is only
+// for commands like generate-config that nothing else runs alongside.
+func builtinConfig() Config {
+	for _, meta := range configMeta {
+		if v, ok := os.LookupEnv(meta.Env); ok {
+			os.Unsetenv(meta.Env)
+			defer os.Setenv(meta.Env, v)
+		}
+	}
+	return DefaultConfig()
+}
+
+// WriteConfigTOML writes config as TOML, one key per Config field (including
+// the fields of embedded structs), each preceded by its description,
+// environment variable and built-in default as comments. Unless uncomment is
+// set the assignments are commented out too. Proxy passwords are masked, as
+// in export-config.
+func WriteConfigTOML(w io.Writer, config Config, uncomment bool) error {
+	var b strings.Builder
+	b.WriteString("# news-app job runner configuration\n")
+	b.WriteString("# Settings are applied through the environment variables named below.\n\n")
+
+	config.ProxyURL = util.RedactURL(config.ProxyURL)
+	proxies := make([]string, len(config.ProxyURLs))
+	for i, p := range config.ProxyURLs {
+		proxies[i] = util.RedactURL(p)
+	}
+	config.ProxyURLs = proxies
+
+	v := reflect.ValueOf(config)
+	defaults := reflect.ValueOf(builtinConfig())
+	// Embedded structs such as MultiRoundConfig are flattened into their fields
+	for _, field := range reflect.VisibleFields(v.Type()) {
+		if field.Anonymous {
+			continue
+		}
+		meta, ok := configMeta[field.Name]
+		if !ok {
+			return fmt.Errorf("config field %s has no description", field.Name)
+		}
+		value, err := tomlValue(v.FieldByIndex(field.Index))
+		if err != nil {
+			return fmt.Errorf("config field %s: %w", field.Name, err)
+		}
+		def, err := tomlValue(defaults.FieldByIndex(field.Index))
+		if err != nil {
+			return fmt.Errorf("config field %s: %w", field.Name, err)
+		}
+
+		assignment := fmt.Sprintf("%s = %s", tomlKey(field.Name), value)
+		if !uncomment {
+			assignment = "# " + assignment
+		}
+		fmt.Fprintf(&b, "# %s (env: %s)\n# default: %s\n%s\n\n", meta.Description, meta.Env, def, assignment)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tomlKey converts a Go field name to a snake_case TOML key, keeping
+// acronyms together (DBPath becomes db_path, ProxyURLs proxy_urls).
+func tomlKey(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && runes[i+1] != 's'
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// tomlValue formats a Config field value as a TOML value. Durations are
+// written as Go duration strings.
+func tomlValue(v reflect.Value) (string, error) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return strconv.Quote(d.String()), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			item, err := tomlValue(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		var entries []string
+		for _, key := range v.MapKeys() {
+			value, err := tomlValue(v.MapIndex(key))
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, fmt.Sprintf("%s = %s", strconv.Quote(key.String()), value))
+		}
+		if len(entries) == 0 {
+			return "{}", nil
+		}
+		slices.Sort(entries)
+		return "{ " + strings.Join(entries, ", ") + " }", nil
+	case reflect.Float64:
+		// TOML reads a number without a fraction as an integer
+		f := strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		if !strings.Contains(f, ".") {
+			f += ".0"
+		}
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
@@ -0,0 +1,537 @@
+package jobrunner
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseFeedContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		data        string
+		contentType string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "rss first item",
+			fixture:     "feed_rss.xml",
+			contentType: "application/rss+xml; charset=utf-8",
+			want:        "Researchers & engineers announced a breakthrough.",
+		},
+		{
+			name:        "atom first entry",
+			fixture:     "feed_atom.xml",
+			contentType: "application/atom+xml",
+			want:        "The city council approved the new transit plan.",
+		},
+		{
+			name:        "rss without items describes feed",
+			data:        `<rss version="2.0"><channel><title>Empty</title><description>Nothing yet</description></channel></rss>`,
+			contentType: "text/xml",
+			want:        "[Feed: Empty]\nNothing yet",
+		},
+		{
+			name:        "not a feed",
+			data:        `<html><body>hello</body></html>`,
+			contentType: "text/xml",
+			wantErr:     true,
+		},
+		{
+			name:        "html content type",
+			fixture:     "feed_rss.xml",
+			contentType: "text/html",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+			if tt.fixture != "" {
+				var err error
+				data, err = os.ReadFile(filepath.Join("testdata", tt.fixture))
+				if err != nil {
+					t.Fatalf("read fixture: %v", err)
+				}
+			}
+
+			got, err := parseFeedContent(data, tt.contentType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFeedContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseFeedContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleFetcherRotatesUserAgents(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]int)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Header.Get("User-Agent")]++
+		mu.Unlock()
+		fmt.Fprint(w, "<html><body><article><p>Article body text.</p></article></body></html>")
+	}))
+	defer srv.Close()
+
+	agents := []string{"agent-a", "agent-b", "agent-c", "agent-d", "agent-e"}
+	fetcher := NewArticleFetcher(FetchConfig{UserAgents: agents})
+
+	for i := 0; i < 20; i++ {
+		// Distinct URLs so the content cache doesn't answer repeat fetches
+		if _, err := fetcher.FetchArticleContent(context.Background(), fmt.Sprintf("%s/%d", srv.URL, i), nil); err != nil {
+			t.Fatalf("FetchArticleContent() error = %v", err)
+		}
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("saw %d distinct user agents across 20 fetches, want at least 2: %v", len(seen), seen)
+	}
+	for ua := range seen {
+		found := false
+		for _, a := range agents {
+			found = found || ua == a
+		}
+		if !found {
+			t.Errorf("unexpected user agent %q", ua)
+		}
+	}
+}
+
+func TestNewArticleFetcherDefaults(t *testing.T) {
+	fetcher := NewArticleFetcher(FetchConfig{})
+	if len(fetcher.config.UserAgents) != len(defaultUserAgents) {
+		t.Errorf("got %d user agents, want the %d defaults", len(fetcher.config.UserAgents), len(defaultUserAgents))
+	}
+	if fetcher.client.Timeout <= 0 {
+		t.Errorf("client timeout = %v, want a positive default", fetcher.client.Timeout)
+	}
+}
+
+// newTunnelProxy serves a proxy that only supports CONNECT, tunnelling each
+// request to its target. It counts tunnels and records Proxy-Authorization.
+func newTunnelProxy(t *testing.T) (srv *httptest.Server, tunnels *atomic.Int64, auth *atomic.Value) {
+	t.Helper()
+	tunnels, auth = new(atomic.Int64), new(atomic.Value)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		tunnels.Add(1)
+		auth.Store(r.Header.Get("Proxy-Authorization"))
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		fmt.Fprint(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, tunnels, auth
+}
+
+// trustTarget makes every client in f trust the target's TLS certificate.
+func trustTarget(f *ArticleFetcher, target *httptest.Server) {
+	tlsConfig := target.Client().Transport.(*http.Transport).TLSClientConfig
+	f.client.Transport = target.Client().Transport
+	for _, p := range f.proxies {
+		p.client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	}
+}
+
+func newArticleTarget(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	hits := new(atomic.Int64)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Connection", "close") // one tunnel per request
+		fmt.Fprint(w, "<html><body><article><p>Article body text.</p></article></body></html>")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hits
+}
+
+func TestFetchArticleContentViaProxy(t *testing.T) {
+	target, _ := newArticleTarget(t)
+	proxy, tunnels, auth := newTunnelProxy(t)
+
+	proxyURL := strings.Replace(proxy.URL, "http://", "http://user:pass@", 1)
+	fetcher := NewArticleFetcher(FetchConfig{ProxyURL: proxyURL})
+	trustTarget(fetcher, target)
+
+	content, err := fetcher.FetchArticleContent(context.Background(), target.URL, nil)
+	if err != nil {
+		t.Fatalf("FetchArticleContent() error = %v", err)
+	}
+	if !strings.Contains(content.Text, "Article body text.") {
+		t.Errorf("content = %q, want the article body", content.Text)
+	}
+	if tunnels.Load() != 1 {
+		t.Errorf("proxy tunnels = %d, want 1", tunnels.Load())
+	}
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	if got, _ := auth.Load().(string); got != wantAuth {
+		t.Errorf("Proxy-Authorization = %q, want %q", got, wantAuth)
+	}
+}
+
+func TestFetchArticleContentProxyRotation(t *testing.T) {
+	target, _ := newArticleTarget(t)
+	proxyA, tunnelsA, _ := newTunnelProxy(t)
+	proxyB, tunnelsB, _ := newTunnelProxy(t)
+
+	fetcher := NewArticleFetcher(FetchConfig{ProxyURLs: []string{proxyA.URL, proxyB.URL}})
+	trustTarget(fetcher, target)
+
+	for i := 0; i < 4; i++ {
+		if _, err := fetcher.FetchArticleContent(context.Background(), fmt.Sprintf("%s/%d", target.URL, i), nil); err != nil {
+			t.Fatalf("FetchArticleContent() error = %v", err)
+		}
+	}
+	if tunnelsA.Load() != 2 || tunnelsB.Load() != 2 {
+		t.Errorf("tunnels = %d and %d, want 2 through each proxy", tunnelsA.Load(), tunnelsB.Load())
+	}
+}
+
+func TestFetchArticleContentProxyFallback(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	t.Run("next proxy", func(t *testing.T) {
+		target, _ := newArticleTarget(t)
+		proxy, tunnels, _ := newTunnelProxy(t)
+		fetcher := NewArticleFetcher(FetchConfig{ProxyURLs: []string{deadURL, proxy.URL}})
+		trustTarget(fetcher, target)
+
+		if _, err := fetcher.FetchArticleContent(context.Background(), target.URL, nil); err != nil {
+			t.Fatalf("FetchArticleContent() error = %v", err)
+		}
+		if tunnels.Load() != 1 {
+			t.Errorf("proxy tunnels = %d, want 1", tunnels.Load())
+		}
+	})
+
+	t.Run("direct", func(t *testing.T) {
+		target, hits := newArticleTarget(t)
+		fetcher := NewArticleFetcher(FetchConfig{ProxyURL: deadURL})
+		trustTarget(fetcher, target)
+
+		if _, err := fetcher.FetchArticleContent(context.Background(), target.URL, nil); err != nil {
+			t.Fatalf("FetchArticleContent() error = %v", err)
+		}
+		if hits.Load() != 1 {
+			t.Errorf("target hits = %d, want 1 direct fetch", hits.Load())
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		fetcher := NewArticleFetcher(FetchConfig{ProxyURL: deadURL})
+		_, err := fetcher.FetchArticleContent(context.Background(), deadURL, nil)
+
+		var perr *ProxyError
+		if !errors.As(err, &perr) {
+			t.Fatalf("error = %v, want a *ProxyError", err)
+		}
+		if perr.Proxy != deadURL {
+			t.Errorf("ProxyError.Proxy = %q, want %q", perr.Proxy, deadURL)
+		}
+	})
+}
+
+func TestFetchArticleContentCache(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			http.Error(w, "gone", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "<html><body><article><p>Article body text.</p></article></body></html>")
+	}))
+	defer srv.Close()
+
+	fetcher := NewArticleFetcher(FetchConfig{})
+	first, err := fetcher.FetchArticleContent(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("first FetchArticleContent() error = %v", err)
+	}
+	second, err := fetcher.FetchArticleContent(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("second FetchArticleContent() error = %v, want the cached content", err)
+	}
+	if second != first {
+		t.Errorf("second fetch = %q, want cached %q", second, first)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server calls = %d, want 1", calls.Load())
+	}
+	if stats := fetcher.CacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("CacheStats() = %+v, want 1 hit, 1 miss, 1 entry", stats)
+	}
+
+	// Failed fetches are not cached
+	if _, err := fetcher.FetchArticleContent(context.Background(), srv.URL+"/other", nil); err == nil {
+		t.Fatal("FetchArticleContent() of failing URL succeeded")
+	}
+	if stats := fetcher.CacheStats(); stats.Entries != 1 {
+		t.Errorf("cache entries = %d after a failed fetch, want 1", stats.Entries)
+	}
+}
+
+func TestFetchArticleContentCustomHeaders(t *testing.T) {
+	var gotAuth, gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotUA = r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+		fmt.Fprint(w, "<html><body><p>Members-only article body.</p></body></html>")
+	}))
+	defer srv.Close()
+
+	headers, err := parseJobFetchHeaders(`{"Authorization": "Bearer secret"}`)
+	if err != nil {
+		t.Fatalf("parseJobFetchHeaders() error = %v", err)
+	}
+	fetcher := NewArticleFetcher(FetchConfig{UserAgents: []string{"test-agent"}})
+
+	tests := []struct {
+		name     string
+		domains  []string
+		wantAuth string
+	}{
+		{"no domains", nil, "Bearer secret"},
+		{"matching domain", []string{"127.0.0.1"}, "Bearer secret"},
+		{"other domain", []string{"example.com"}, ""},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAuth, gotUA = "", ""
+			url := fmt.Sprintf("%s/%d", srv.URL, i)
+			h := fetchHeaders{headers: headers, domains: tt.domains}
+			if _, err := fetcher.FetchArticleContent(context.Background(), url, h.forURL(url)); err != nil {
+				t.Fatalf("FetchArticleContent() error = %v", err)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+			if gotUA != "test-agent" {
+				t.Errorf("User-Agent = %q, want the default kept", gotUA)
+			}
+		})
+	}
+}
+
+func TestFetchArticleContentRedirectDropsHeaders(t *testing.T) {
+	// The target is reached as localhost, a different host from the
+	// allowed 127.0.0.1
+	var gotKey string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-API-Key")
+		fmt.Fprint(w, "<html><body><p>Redirected article body.</p></body></html>")
+	}))
+	defer target.Close()
+	_, port, _ := net.SplitHostPort(target.Listener.Addr().String())
+	elsewhere := "http://localhost:" + port
+	allowed := target.URL
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dest := allowed
+		if r.URL.Path == "/elsewhere" {
+			dest = elsewhere
+		}
+		http.Redirect(w, r, dest+"/article", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	h := fetchHeaders{headers: map[string]string{"X-API-Key": "secret"}, domains: []string{"127.0.0.1"}}
+	fetcher := NewArticleFetcher(FetchConfig{CacheSize: -1})
+	tests := []struct {
+		path, wantKey string
+	}{
+		{"/same", "secret"},
+		{"/elsewhere", ""},
+	}
+	for _, tt := range tests {
+		gotKey = ""
+		ctx := withFetchHeaderDomains(context.Background(), h.domains)
+		url := origin.URL + tt.path
+		if _, err := fetcher.FetchArticleContent(ctx, url, h.forURL(url)); err != nil {
+			t.Fatalf("FetchArticleContent(%s) error = %v", tt.path, err)
+		}
+		if gotKey != tt.wantKey {
+			t.Errorf("redirect from %s: X-API-Key = %q, want %q", tt.path, gotKey, tt.wantKey)
+		}
+	}
+}
+
+func TestFetchArticleContentTypes(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte("BT /F1 12 Tf (Compressed PDF text) Tj ET"))
+	zw.Close()
+
+	bodies := map[string]struct{ contentType, body string }{
+		"/image":      {"image/jpeg", "\xff\xd8\xff\xe0 not really a jpeg"},
+		"/download":   {"application/octet-stream", "\x00\x01\x02"},
+		"/text":       {"text/plain; charset=utf-8", "Plain   text\n\n\n\nreport."},
+		"/pdf":        {"application/pdf", "%PDF-1.4\nstream\nBT (Hello \\(PDF\\)) Tj ET\nendstream\n"},
+		"/pdf-flate":  {"application/pdf", "%PDF-1.4\n<< /Filter /FlateDecode >>\nstream\n" + compressed.String() + "\nendstream\n"},
+		"/pdf-images": {"application/pdf", "%PDF-1.4\nstream\nq 100 0 0 100 0 0 cm /Im1 Do Q\nendstream\n"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := bodies[r.URL.Path]
+		w.Header().Set("Content-Type", b.contentType)
+		fmt.Fprint(w, b.body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/image", "[Binary file: image/jpeg]"},
+		{"/download", "[Binary file: application/octet-stream]"},
+		{"/text", "Plain text\n\nreport."},
+		{"/pdf", "Hello (PDF)"},
+		{"/pdf-flate", "Compressed PDF text"},
+		{"/pdf-images", "[PDF file: use manual download]"},
+	}
+	fetcher := NewArticleFetcher(FetchConfig{CacheSize: -1})
+	for _, tt := range tests {
+		got, err := fetcher.FetchArticleContent(context.Background(), srv.URL+tt.path, nil)
+		if err != nil {
+			t.Errorf("FetchArticleContent(%s) error = %v", tt.path, err)
+			continue
+		}
+		if got.Text != tt.want {
+			t.Errorf("FetchArticleContent(%s) = %q, want %q", tt.path, got.Text, tt.want)
+		}
+	}
+}
+
+func TestArticleContentCacheEviction(t *testing.T) {
+	cache := NewArticleContentCache(2, time.Hour)
+	cache.Put("a", ArticleContent{Text: "A"})
+	cache.Put("b", ArticleContent{Text: "B"})
+	cache.Get("a") // a is now more recently used than b
+	cache.Put("c", ArticleContent{Text: "C"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	for _, url := range []string{"a", "c"} {
+		if _, ok := cache.Get(url); !ok {
+			t.Errorf("entry %s was evicted", url)
+		}
+	}
+
+	expired := NewArticleContentCache(2, time.Nanosecond)
+	expired.Put("a", ArticleContent{Text: "A"})
+	time.Sleep(time.Millisecond)
+	if _, ok := expired.Get("a"); ok {
+		t.Error("expired entry was returned")
+	}
+}
+
+func TestParseFeedArticles(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []ArticleInfo
+	}{
+		{
+			fixture: "feed_rss.xml",
+			want: []ArticleInfo{
+				{Title: "First story", URL: "https://example.com/first", Summary: "Researchers & engineers announced a breakthrough."},
+				{Title: "Second story", URL: "https://example.com/second", Summary: "Not the first item."},
+			},
+		},
+		{
+			fixture: "feed_atom.xml",
+			want: []ArticleInfo{
+				{Title: "Atom entry", URL: "", Summary: "The city council approved the new transit plan."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+
+			got, err := parseFeedArticles(data)
+			if err != nil {
+				t.Fatalf("parseFeedArticles() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d articles, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("article %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := parseFeedArticles([]byte("<html></html>")); err == nil {
+		t.Error("parseFeedArticles() on HTML returned no error")
+	}
+}
+
+func TestFilterArticlesByKeywords(t *testing.T) {
+	articles := []ArticleInfo{
+		{Title: "Go 1.24 released", Summary: "Generic type aliases"},
+		{Title: "Rust news", Summary: "Borrow checker improvements"},
+		{Title: "Weather", Summary: "Sunny"},
+	}
+
+	tests := []struct {
+		keywords string
+		want     int
+	}{
+		{"", 3},
+		{"go 1.24", 1},
+		{"GENERIC, borrow", 2},
+		{" , ", 3},
+		{"python", 0},
+	}
+	for _, tt := range tests {
+		if got := filterArticlesByKeywords(articles, tt.keywords); len(got) != tt.want {
+			t.Errorf("filterArticlesByKeywords(%q) kept %d, want %d", tt.keywords, len(got), tt.want)
+		}
+	}
+}
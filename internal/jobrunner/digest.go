@@ -0,0 +1,203 @@
+package jobrunner
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+// NotificationSender delivers a message over a notification transport such as
+// a Discord webhook, so digests can be built independently of delivery.
+type NotificationSender interface {
+	Send(ctx context.Context, message string) error
+}
+
+// DiscordNotifier sends notifications to a Discord webhook.
+type DiscordNotifier struct {
+	WebhookURL string
+}
+
+// Send posts message to the webhook, retrying on failure.
+func (d DiscordNotifier) Send(ctx context.Context, message string) error {
+	return SendDiscordNotification(d.WebhookURL, message)
+}
+
+const (
+	digestPeriod        = 7 * 24 * time.Hour
+	digestCheckInterval = time.Hour
+
+	// DefaultDigestDay is the weekday digests are sent on unless configured.
+	DefaultDigestDay = "MON"
+)
+
+var digestWeekdays = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// DigestWeekday parses a digest day (MON-SUN, case-insensitive).
+func DigestWeekday(day string) (time.Weekday, bool) {
+	wd, ok := digestWeekdays[strings.ToUpper(day)]
+	return wd, ok
+}
+
+// WeeklyDigest summarizes how many articles each of a user's jobs saved over
+// the past week.
+type WeeklyDigest struct {
+	Since    time.Time
+	Jobs     []dbgen.ListJobArticleCountsSinceRow // most productive first
+	Total    int64
+	IdleJobs []string // jobs that saved nothing, which may need attention
+}
+
+// TopJob returns the job that saved the most articles, if any saved any.
+func (d WeeklyDigest) TopJob() (dbgen.ListJobArticleCountsSinceRow, bool) {
+	if len(d.Jobs) == 0 || d.Jobs[0].ArticleCount == 0 {
+		return dbgen.ListJobArticleCountsSinceRow{}, false
+	}
+	return d.Jobs[0], true
+}
+
+// Markdown formats the digest as a Markdown message with a per-job table.
+func (d WeeklyDigest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📰 **Weekly news digest** (since %s)\n\n", d.Since.Format("Jan 2"))
+	b.WriteString("| Job | Articles |\n|-----|---------:|\n")
+	for _, j := range d.Jobs {
+		fmt.Fprintf(&b, "| %s | %d |\n", strings.ReplaceAll(j.Name, "|", `\|`), j.ArticleCount)
+	}
+	fmt.Fprintf(&b, "\n**Total:** %d articles\n", d.Total)
+	if top, ok := d.TopJob(); ok {
+		fmt.Fprintf(&b, "**Most productive:** %s (%d)\n", top.Name, top.ArticleCount)
+	}
+	if len(d.IdleJobs) > 0 {
+		fmt.Fprintf(&b, "⚠️ **No articles this week:** %s\n", strings.Join(d.IdleJobs, ", "))
+	}
+	return b.String()
+}
+
+// buildWeeklyDigest collects the per-job article counts for the week before now.
+func buildWeeklyDigest(ctx context.Context, queries *dbgen.Queries, userID int64, now time.Time) (WeeklyDigest, error) {
+	since := now.Add(-digestPeriod).UTC()
+	jobs, err := queries.ListJobArticleCountsSince(ctx, dbgen.ListJobArticleCountsSinceParams{
+		RetrievedAt: since,
+		UserID:      userID,
+	})
+	if err != nil {
+		return WeeklyDigest{}, fmt.Errorf("count articles: %w", err)
+	}
+
+	digest := WeeklyDigest{Since: since, Jobs: jobs}
+	for _, j := range jobs {
+		digest.Total += j.ArticleCount
+		if j.ArticleCount == 0 {
+			digest.IdleJobs = append(digest.IdleJobs, j.Name)
+		}
+	}
+	return digest, nil
+}
+
+// SendWeeklyDigest sends a user a summary of the articles their jobs saved over
+// the past week. Users without jobs are skipped.
+func SendWeeklyDigest(ctx context.Context, userID int64, db *sql.DB, notifier NotificationSender) error {
+	digest, err := buildWeeklyDigest(ctx, dbgen.New(db), userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(digest.Jobs) == 0 {
+		return nil
+	}
+	return notifier.Send(ctx, digest.Markdown())
+}
+
+// DigestScheduler periodically sends weekly digests to users who enabled them,
+// on their chosen day.
+type DigestScheduler struct {
+	db       *sql.DB
+	queries  *dbgen.Queries
+	interval time.Duration
+	logger   *slog.Logger
+
+	// newNotifier returns the transport for a user's digest.
+	newNotifier func(prefs dbgen.Preference) NotificationSender
+}
+
+// NewDigestScheduler creates a scheduler that delivers digests over Discord.
+func NewDigestScheduler(db *sql.DB) *DigestScheduler {
+	return &DigestScheduler{
+		db:       db,
+		queries:  dbgen.New(db),
+		interval: digestCheckInterval,
+		logger:   slog.Default(),
+		newNotifier: func(prefs dbgen.Preference) NotificationSender {
+			return DiscordNotifier{WebhookURL: prefs.DiscordWebhook}
+		},
+	}
+}
+
+// Start checks for due digests in the background until ctx is cancelled.
+func (s *DigestScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.sendDue(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sendDue sends digests to every user whose digest day is today and who has
+// not had one in the past six days. It returns the number sent.
+func (s *DigestScheduler) sendDue(ctx context.Context, now time.Time) int {
+	prefs, err := s.queries.ListDigestPreferences(ctx)
+	if err != nil {
+		s.logger.Warn("list digest preferences", "error", err)
+		return 0
+	}
+
+	sent := 0
+	for _, p := range prefs {
+		if !digestDue(p, now) {
+			continue
+		}
+		if err := SendWeeklyDigest(ctx, p.UserID, s.db, s.newNotifier(p)); err != nil {
+			s.logger.Warn("send weekly digest", "user_id", p.UserID, "error", err)
+			continue
+		}
+		sentAt := now.UTC()
+		if err := s.queries.UpdateLastDigestAt(ctx, dbgen.UpdateLastDigestAtParams{LastDigestAt: &sentAt, UserID: p.UserID}); err != nil {
+			s.logger.Warn("record digest sent", "user_id", p.UserID, "error", err)
+		}
+		sent++
+	}
+	return sent
+}
+
+// digestDue reports whether a user's digest should go out at now.
+func digestDue(p dbgen.Preference, now time.Time) bool {
+	day, ok := DigestWeekday(p.DigestDay)
+	if !ok {
+		day = time.Monday
+	}
+	if now.UTC().Weekday() != day {
+		return false
+	}
+	// Allow some slack so a digest sent late last week doesn't block this one
+	return p.LastDigestAt == nil || now.Sub(*p.LastDigestAt) >= digestPeriod-24*time.Hour
+}
@@ -0,0 +1,115 @@
+package jobrunner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+// recordingNotifier captures sent messages instead of delivering them.
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestSendWeeklyDigest(t *testing.T) {
+	_, dbConn, job := newTestRunner(t, "")
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+
+	newJob := func(name string) dbgen.Job {
+		j, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: job.UserID, Name: name, Prompt: "test", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		return j
+	}
+	busy, idle := newJob("Busy Job"), newJob("Idle Job")
+
+	addArticles := func(j dbgen.Job, n int) []dbgen.Article {
+		var articles []dbgen.Article
+		for i := 0; i < n; i++ {
+			a, err := queries.CreateArticle(ctx, dbgen.CreateArticleParams{JobID: j.ID, UserID: j.UserID, Title: j.Name, RetrievedAt: time.Now()})
+			if err != nil {
+				t.Fatalf("create article: %v", err)
+			}
+			articles = append(articles, a)
+		}
+		return articles
+	}
+	addArticles(busy, 3)
+	addArticles(job, 1)
+
+	// Articles from before the past week don't count
+	for _, a := range append(addArticles(job, 1), addArticles(idle, 1)...) {
+		if _, err := dbConn.Exec(`UPDATE articles SET retrieved_at = datetime('now', '-10 days') WHERE id = ?`, a.ID); err != nil {
+			t.Fatalf("age article: %v", err)
+		}
+	}
+
+	notifier := &recordingNotifier{}
+	if err := SendWeeklyDigest(ctx, job.UserID, dbConn, notifier); err != nil {
+		t.Fatalf("SendWeeklyDigest() error = %v", err)
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(notifier.messages))
+	}
+
+	msg := notifier.messages[0]
+	for _, want := range []string{
+		"| Busy Job | 3 |",
+		"| Test Job | 1 |",
+		"| Idle Job | 0 |",
+		"**Total:** 4 articles",
+		"**Most productive:** Busy Job (3)",
+		"**No articles this week:** Idle Job",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("digest missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestDigestSchedulerSendsOncePerWeek(t *testing.T) {
+	_, dbConn, job := newTestRunner(t, "")
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+
+	now := time.Now().UTC()
+	if _, err := queries.CreatePreferences(ctx, job.UserID); err != nil {
+		t.Fatalf("create preferences: %v", err)
+	}
+	err := queries.UpdatePreferences(ctx, dbgen.UpdatePreferencesParams{
+		DiscordWebhook:     "https://discord.example/webhook",
+		NotifyWeeklyDigest: 1,
+		DigestDay:          strings.ToUpper(now.Weekday().String()[:3]),
+		UserID:             job.UserID,
+	})
+	if err != nil {
+		t.Fatalf("update preferences: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	scheduler := NewDigestScheduler(dbConn)
+	scheduler.newNotifier = func(dbgen.Preference) NotificationSender { return notifier }
+
+	if sent := scheduler.sendDue(ctx, now); sent != 1 {
+		t.Errorf("first check sent %d digests, want 1", sent)
+	}
+	if sent := scheduler.sendDue(ctx, now.Add(time.Hour)); sent != 0 {
+		t.Errorf("second check sent %d digests, want 0", sent)
+	}
+	if sent := scheduler.sendDue(ctx, now.Add(digestPeriod)); sent != 1 {
+		t.Errorf("check a week later sent %d digests, want 1", sent)
+	}
+	if len(notifier.messages) != 2 {
+		t.Errorf("notifier got %d messages, want 2", len(notifier.messages))
+	}
+}
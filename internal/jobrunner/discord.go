@@ -2,24 +2,86 @@ package jobrunner
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/util"
 )
 
 const (
 	discordMaxRetries = 3
 	discordRetryDelay = 2 * time.Second
+
+	// discordMaxContentLength is the most characters Discord accepts in a
+	// message's content.
+	discordMaxContentLength = 2000
+
+	// discordMaxFieldLength is the most characters Discord accepts in an
+	// embed field's value.
+	discordMaxFieldLength = 1024
+
+	// maxNotificationAttempts is how many flushes a queued notification may
+	// fail to send in before it is dropped.
+	maxNotificationAttempts = 5
+)
+
+// Discord embed colors for job outcomes.
+const (
+	discordColorSuccess = 0x36a64f
+	discordColorFailure = 0xff0000
 )
 
+// DiscordEmbed is a rich Discord message with a colored side bar, title,
+// fields and footer.
+type DiscordEmbed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      EmbedFooter  `json:"footer"`
+}
+
+// EmbedField is a name/value pair shown in a DiscordEmbed. Inline fields
+// are laid out side by side.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// EmbedFooter is the small text at the bottom of a DiscordEmbed.
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
 // SendDiscordNotification sends a message to a Discord webhook with retry logic.
 func SendDiscordNotification(webhookURL, message string) error {
+	return postDiscordWebhook(webhookURL, map[string]string{"content": message})
+}
+
+// SendDiscordEmbed sends an embed to a Discord webhook with retry logic.
+func SendDiscordEmbed(webhookURL string, embed DiscordEmbed) error {
+	return postDiscordWebhook(webhookURL, map[string][]DiscordEmbed{"embeds": {embed}})
+}
+
+// postDiscordWebhook posts payload as JSON, retrying failures with
+// exponential backoff.
+func postDiscordWebhook(webhookURL string, payload any) error {
 	if webhookURL == "" {
 		return nil
 	}
 
-	payload := map[string]string{"content": message}
 	jsonPayload, _ := json.Marshal(payload)
 
 	var lastErr error
@@ -51,3 +113,224 @@ func SendDiscordNotification(webhookURL, message string) error {
 
 	return lastErr
 }
+
+// jobResultEmbed describes a finished job run as a Discord embed: green with
+// article counts and duration on success, red with the error on failure.
+func jobResultEmbed(jobName string, result JobResult) DiscordEmbed {
+	embed := DiscordEmbed{
+		Title:  jobName,
+		Footer: EmbedFooter{Text: "News Agent"},
+	}
+	duration := EmbedField{Name: "Duration", Value: result.Duration.Round(time.Second).String(), Inline: true}
+
+	if result.Error != nil {
+		embed.Description = "Job failed"
+		embed.Color = discordColorFailure
+		embed.Fields = []EmbedField{{Name: "Error", Value: truncateFieldValue(result.Error.Error(), discordMaxFieldLength)}, duration}
+		return embed
+	}
+
+	embed.Description = "Job completed"
+	if result.ArticlesSaved == 0 {
+		embed.Description = "Job completed - no new articles found"
+	}
+	embed.Color = discordColorSuccess
+	embed.Fields = []EmbedField{
+		{Name: "Articles saved", Value: strconv.Itoa(result.ArticlesSaved), Inline: true},
+		{Name: "Duplicates skipped", Value: strconv.Itoa(result.DuplicatesSkipped), Inline: true},
+		duration,
+	}
+	if result.EstimatedCost > 0 {
+		embed.Fields = append(embed.Fields, EmbedField{Name: "Estimated cost", Value: util.FormatCost(result.EstimatedCost), Inline: true})
+	}
+	return embed
+}
+
+// BatcherConfig controls how a NotificationBatcher groups messages.
+type BatcherConfig struct {
+	Window   time.Duration // how often queued messages are sent
+	MaxBatch int           // most messages combined into one batch
+}
+
+// DefaultBatcherConfig returns the default batching: up to 10 messages
+// every 5 seconds.
+func DefaultBatcherConfig() BatcherConfig {
+	return BatcherConfig{Window: 5 * time.Second, MaxBatch: 10}
+}
+
+// NotificationBatcher combines Discord notifications sent to the same
+// webhook in quick succession into one message, so a burst of completed
+// jobs doesn't trip Discord's rate limit. Every job run is its own process,
+// so Send queues messages in the pending_notifications table, and the
+// process that called Start sends them once per window.
+type NotificationBatcher struct {
+	config  BatcherConfig
+	queries *dbgen.Queries
+	logger  *slog.Logger
+	send    func(webhookURL, message string) error
+}
+
+// NewNotificationBatcher returns a batcher queueing in db that delivers with
+// SendDiscordNotification. A Window that isn't positive gets the default.
+func NewNotificationBatcher(db *sql.DB, config BatcherConfig) *NotificationBatcher {
+	if config.Window <= 0 {
+		config.Window = DefaultBatcherConfig().Window
+	}
+	if config.MaxBatch < 1 {
+		config.MaxBatch = 1
+	}
+	return &NotificationBatcher{
+		config:  config,
+		queries: dbgen.New(db),
+		logger:  slog.Default(),
+		send:    SendDiscordNotification,
+	}
+}
+
+// Send queues msg for webhookURL until the next flush.
+func (b *NotificationBatcher) Send(ctx context.Context, webhookURL, msg string) error {
+	if webhookURL == "" {
+		return nil
+	}
+	return b.queries.CreatePendingNotification(ctx, dbgen.CreatePendingNotificationParams{
+		WebhookUrl: webhookURL,
+		Message:    msg,
+	})
+}
+
+// Start sends queued messages every window in the background until ctx is
+// cancelled, then sends whatever is left.
+func (b *NotificationBatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(b.config.Window)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				b.Flush(context.WithoutCancel(ctx))
+				return
+			case <-ticker.C:
+				b.Flush(ctx)
+			}
+		}
+	}()
+}
+
+// Flush takes every queued message and sends them, each webhook's in
+// batches of at most MaxBatch. It returns the number of messages taken.
+// Taking them removes them from the queue in one statement, so two
+// processes flushing at once never send a message twice. Messages that fail
+// to send are queued again for the next flush, up to
+// maxNotificationAttempts times.
+func (b *NotificationBatcher) Flush(ctx context.Context) int {
+	pending, err := b.queries.TakePendingNotifications(ctx)
+	if err != nil {
+		b.logger.Warn("take pending notifications", "error", err)
+		return 0
+	}
+	slices.SortFunc(pending, func(a, c dbgen.PendingNotification) int {
+		return cmp.Compare(a.ID, c.ID)
+	})
+
+	var webhooks []string
+	queued := make(map[string][]dbgen.PendingNotification) // webhook URL -> messages, oldest first
+	for _, p := range pending {
+		if _, ok := queued[p.WebhookUrl]; !ok {
+			webhooks = append(webhooks, p.WebhookUrl)
+		}
+		queued[p.WebhookUrl] = append(queued[p.WebhookUrl], p)
+	}
+	for _, webhookURL := range webhooks {
+		for batch := range slices.Chunk(queued[webhookURL], b.config.MaxBatch) {
+			b.deliver(ctx, webhookURL, batch)
+		}
+	}
+	return len(pending)
+}
+
+// deliver sends a batch as few Discord messages as fit the content limit,
+// requeueing the notifications of any message that fails to send.
+func (b *NotificationBatcher) deliver(ctx context.Context, webhookURL string, batch []dbgen.PendingNotification) {
+	messages := make([]string, len(batch))
+	for i, p := range batch {
+		messages[i] = p.Message
+	}
+	for _, group := range packMessages(messages, discordMaxContentLength) {
+		sent, rest := batch[:len(group)], batch[len(group):]
+		batch = rest
+		err := b.send(webhookURL, strings.Join(group, "\n"))
+		if err == nil {
+			continue
+		}
+		b.logger.Warn("send batched discord notification", "messages", len(sent), "error", err)
+		b.requeue(ctx, sent)
+	}
+}
+
+// requeue puts notifications that failed to send back in the queue, dropping
+// those that have failed maxNotificationAttempts times.
+func (b *NotificationBatcher) requeue(ctx context.Context, failed []dbgen.PendingNotification) {
+	for _, p := range failed {
+		if p.Attempts+1 >= maxNotificationAttempts {
+			b.logger.Error("dropping discord notification after repeated failures", "attempts", p.Attempts+1, "queued_at", p.CreatedAt)
+			continue
+		}
+		err := b.queries.RequeuePendingNotification(ctx, dbgen.RequeuePendingNotificationParams{
+			WebhookUrl: p.WebhookUrl,
+			Message:    p.Message,
+			Attempts:   p.Attempts + 1,
+			CreatedAt:  p.CreatedAt,
+		})
+		if err != nil {
+			b.logger.Error("requeue discord notification", "error", err)
+		}
+	}
+}
+
+// truncateFieldValue shortens s to at most limit characters, ending it with
+// an ellipsis when anything was cut.
+func truncateFieldValue(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
+
+// joinMessages joins messages with newlines into as few chunks of at most
+// limit bytes as possible. A single message longer than limit is truncated.
+func joinMessages(messages []string, limit int) []string {
+	var chunks []string
+	for _, group := range packMessages(messages, limit) {
+		chunks = append(chunks, strings.Join(group, "\n"))
+	}
+	return chunks
+}
+
+// packMessages splits messages, in order, into as few groups as possible
+// whose newline-joined length is at most limit bytes. A single message
+// longer than limit is truncated.
+func packMessages(messages []string, limit int) [][]string {
+	var groups [][]string
+	var current []string
+	size := 0
+	for _, msg := range messages {
+		if len(msg) > limit {
+			msg = strings.ToValidUTF8(msg[:limit], "")
+		}
+		if len(current) > 0 && size+1+len(msg) > limit {
+			groups = append(groups, current)
+			current, size = nil, 0
+		}
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, msg)
+		size += len(msg)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
@@ -0,0 +1,214 @@
+package jobrunner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+func TestNotificationBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var contents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Content string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		contents = append(contents, payload.Content)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	// Job runs in separate processes queue through the same database
+	_, dbConn, _ := newTestRunner(t, srv.URL)
+	config := BatcherConfig{Window: 200 * time.Millisecond, MaxBatch: 10}
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		if err := NewNotificationBatcher(dbConn, config).Send(ctx, srv.URL, fmt.Sprintf("job %d done", i)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	flushCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	NewNotificationBatcher(dbConn, config).Start(flushCtx)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(contents)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(contents) != 1 {
+		t.Fatalf("webhook called %d times, want 1: %q", len(contents), contents)
+	}
+	want := "job 1 done\njob 2 done\njob 3 done\njob 4 done\njob 5 done"
+	if contents[0] != want {
+		t.Errorf("content = %q, want %q", contents[0], want)
+	}
+}
+
+func TestNotificationBatcherMaxBatch(t *testing.T) {
+	_, dbConn, _ := newTestRunner(t, "")
+	batches := map[string][]string{}
+	b := NewNotificationBatcher(dbConn, BatcherConfig{Window: time.Hour, MaxBatch: 2})
+	b.send = func(webhookURL, message string) error {
+		batches[webhookURL] = append(batches[webhookURL], message)
+		return nil
+	}
+
+	// Each webhook is batched separately
+	ctx := context.Background()
+	for _, msg := range []string{"a1", "a2", "a3"} {
+		b.Send(ctx, "https://discord.example/a", msg)
+	}
+	b.Send(ctx, "https://discord.example/b", "b1")
+	if n := b.Flush(ctx); n != 4 {
+		t.Errorf("Flush() = %d, want 4", n)
+	}
+
+	got := fmt.Sprint(batches["https://discord.example/a"], batches["https://discord.example/b"])
+	if want := "[a1\na2 a3] [b1]"; got != want {
+		t.Errorf("batches = %q, want %q", got, want)
+	}
+
+	// Flushed messages leave the queue
+	if n := b.Flush(ctx); n != 0 {
+		t.Errorf("second Flush() = %d, want 0", n)
+	}
+}
+
+func TestNotificationBatcherRequeuesFailedSends(t *testing.T) {
+	_, dbConn, _ := newTestRunner(t, "")
+	b := NewNotificationBatcher(dbConn, BatcherConfig{Window: time.Hour, MaxBatch: 10})
+	failing := true
+	var sent []string
+	b.send = func(webhookURL, message string) error {
+		if failing {
+			return errors.New("discord unavailable")
+		}
+		sent = append(sent, message)
+		return nil
+	}
+
+	ctx := context.Background()
+	b.Send(ctx, "https://discord.example/a", "job 1 done")
+	b.Send(ctx, "https://discord.example/a", "job 2 done")
+	if n := b.Flush(ctx); n != 2 {
+		t.Fatalf("failing Flush() = %d, want 2", n)
+	}
+
+	// The failed messages are sent by the next flush
+	failing = false
+	if n := b.Flush(ctx); n != 2 {
+		t.Fatalf("second Flush() = %d, want the 2 requeued messages", n)
+	}
+	if want := []string{"job 1 done\njob 2 done"}; fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Errorf("sent = %q, want %q", sent, want)
+	}
+
+	// A message that keeps failing is eventually dropped
+	failing = true
+	b.Send(ctx, "https://discord.example/a", "job 3 done")
+	for range maxNotificationAttempts {
+		b.Flush(ctx)
+	}
+	if n := b.Flush(ctx); n != 0 {
+		t.Errorf("Flush() after %d failures = %d, want the message dropped", maxNotificationAttempts, n)
+	}
+}
+
+func TestJoinMessages(t *testing.T) {
+	chunks := joinMessages([]string{"aaaa", "bbbb", "cccc", strings.Repeat("d", 12)}, 10)
+	want := []string{"aaaa\nbbbb", "cccc", "dddddddddd"}
+	if fmt.Sprint(chunks) != fmt.Sprint(want) {
+		t.Errorf("joinMessages() = %q, want %q", chunks, want)
+	}
+}
+
+func TestJobResultEmbedTruncatesError(t *testing.T) {
+	embed := jobResultEmbed("Space News", JobResult{Error: errors.New(strings.Repeat("é", 2000))})
+	value := embed.Fields[0].Value
+	if n := utf8.RuneCountInString(value); n != discordMaxFieldLength {
+		t.Errorf("error field has %d characters, want %d", n, discordMaxFieldLength)
+	}
+	if !strings.HasSuffix(value, "…") {
+		t.Errorf("error field = %q, want a trailing ellipsis", value[len(value)-10:])
+	}
+
+	embed = jobResultEmbed("Space News", JobResult{Error: errors.New("shelley unavailable")})
+	if got := embed.Fields[0].Value; got != "shelley unavailable" {
+		t.Errorf("short error field = %q, want it unchanged", got)
+	}
+}
+
+func TestSendNotificationEmbed(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	runner := NewRunner(nil, DefaultConfig())
+	prefs := dbgen.Preference{DiscordWebhook: srv.URL, NotifySuccess: 1, NotifyFailure: 1, UseDiscordEmbeds: 1}
+	tests := []struct {
+		result JobResult
+		color  float64
+	}{
+		{JobResult{ArticlesSaved: 3, DuplicatesSkipped: 1, Duration: 90 * time.Second}, 0x36a64f},
+		{JobResult{Error: errors.New("shelley unavailable")}, 0xff0000},
+	}
+	for _, tt := range tests {
+		if !runner.sendNotification(context.Background(), prefs, "Space News", tt.result) {
+			t.Fatalf("sendNotification(%+v) = false, want true", tt.result)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != len(tests) {
+		t.Fatalf("webhook called %d times, want %d", len(payloads), len(tests))
+	}
+	for i, tt := range tests {
+		embeds, _ := payloads[i]["embeds"].([]any)
+		if len(embeds) != 1 {
+			t.Fatalf("payload %d embeds = %v, want one embed", i, payloads[i]["embeds"])
+		}
+		embed := embeds[0].(map[string]any)
+		if embed["color"] != tt.color {
+			t.Errorf("payload %d embeds[0].color = %v, want %v", i, embed["color"], tt.color)
+		}
+		if embed["title"] != "Space News" {
+			t.Errorf("payload %d embeds[0].title = %v, want the job name", i, embed["title"])
+		}
+	}
+
+	body, _ := json.Marshal(payloads[0]["embeds"])
+	if !strings.Contains(string(body), `"name":"Articles saved","value":"3"`) || !strings.Contains(string(body), `"value":"1m30s"`) {
+		t.Errorf("success embed = %s, want articles saved and duration fields", body)
+	}
+}
@@ -0,0 +1,114 @@
+package jobrunner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// MultiRoundConfig controls follow-up rounds: when a conversation's first
+// answer has too few articles, the runner asks Shelley for more in the same
+// conversation.
+type MultiRoundConfig struct {
+	EnableFollowUp      bool   // Send a follow-up when the first round is short
+	FollowUpPrompt      string // text/template rendered with FollowUpData
+	FollowUpMinArticles int    // Follow up when fewer articles than this are found
+}
+
+// DefaultFollowUpPrompt asks for more articles from a different angle,
+// listing those already found so they aren't repeated.
+const DefaultFollowUpPrompt = `You found {{.ArticleCount}} article(s), which is fewer than hoped. Search again for: {{.Prompt}}
+
+Approach it from a different angle this time: related subtopics, other kinds of sources, or different search terms.
+{{if .URLs}}
+Do not repeat these articles:
+{{range .URLs}}- {{.}}
+{{end}}{{end}}
+Respond with a JSON array of the new articles in the same format as before.`
+
+// FollowUpData is the data a follow-up prompt template is rendered with.
+type FollowUpData struct {
+	Prompt       string   // the job's prompt
+	ArticleCount int      // articles found in the first round
+	URLs         []string // URLs of the articles found in the first round
+}
+
+// BuildFollowUpPrompt renders the follow-up prompt template tmpl.
+func BuildFollowUpPrompt(tmpl string, data FollowUpData) (string, error) {
+	t, err := template.New("followup").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse follow-up prompt: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render follow-up prompt: %w", err)
+	}
+	return b.String(), nil
+}
+
+// mergeArticles appends the articles of more not already in articles,
+// matching by URL (or title, for articles without one).
+func mergeArticles(articles, more []ArticleInfo) []ArticleInfo {
+	key := func(a ArticleInfo) string {
+		if a.URL == "" {
+			return "title:" + a.Title
+		}
+		return a.URL
+	}
+	seen := make(map[string]bool, len(articles))
+	for _, a := range articles {
+		seen[key(a)] = true
+	}
+	for _, a := range more {
+		if !seen[key(a)] {
+			seen[key(a)] = true
+			articles = append(articles, a)
+		}
+	}
+	return articles
+}
+
+// followUp asks Shelley for more articles in conversation convID and merges
+// them into articles. If the follow-up fails, the first round's articles and
+// conversation are returned and the failure is logged, so a short first
+// round still saves what it found. timeout is what is left of the job's
+// timeout; the follow-up is skipped if none is.
+func (r *Runner) followUp(ctx context.Context, jobID int64, convID, prompt string, conv *Conversation, articles []ArticleInfo, timeout time.Duration, progress progressFunc) (*Conversation, []ArticleInfo) {
+	if timeout <= 0 {
+		r.logger.Warn("skipping follow-up, job timeout reached", "conversation_id", convID)
+		return conv, articles
+	}
+	data := FollowUpData{Prompt: prompt, ArticleCount: len(articles)}
+	for _, a := range articles {
+		if a.URL != "" {
+			data.URLs = append(data.URLs, a.URL)
+		}
+	}
+	message, err := BuildFollowUpPrompt(r.config.FollowUpPrompt, data)
+	if err != nil {
+		r.logger.Warn("skipping follow-up", "error", err)
+		return conv, articles
+	}
+
+	r.logger.Info("sending follow-up", "conversation_id", convID, "articles", len(articles), "min_articles", r.config.FollowUpMinArticles)
+	if err := r.shelley.SendMessage(ctx, jobID, convID, message); err != nil {
+		r.logger.Warn("send follow-up", "error", err)
+		return conv, articles
+	}
+	followUpConv, err := r.pollForCompletion(ctx, jobID, convID, timeout, progress)
+	if err != nil {
+		r.logger.Warn("poll follow-up", "error", err)
+		return conv, articles
+	}
+
+	more, err := ExtractConversationArticles(followUpConv)
+	if err != nil {
+		r.logger.Warn("extract follow-up articles", "error", err)
+		return followUpConv, articles
+	}
+	merged := mergeArticles(articles, more)
+	r.logger.Info("follow-up finished", "new_articles", len(merged)-len(articles))
+	return followUpConv, merged
+}
@@ -0,0 +1,165 @@
+package jobrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+)
+
+// newMockShelleyRounds is like newMockShelley, but the agent answers with
+// rounds[0] first and each message sent to the conversation gets the next
+// round's answer. It returns the messages sent.
+func newMockShelleyRounds(t *testing.T, rounds ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var sent []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/conversations/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"conversation_id": "conv-1"}`)
+	})
+	mux.HandleFunc("POST /api/conversation/{id}/message", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		sent = append(sent, body.Message)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("GET /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		answered := min(len(sent)+1, len(rounds))
+		mu.Unlock()
+
+		working := false
+		conv := Conversation{}
+		for _, text := range rounds[:answered] {
+			llmData, _ := json.Marshal(LLMData{Content: []ContentBlock{{Type: 2, Text: text}}})
+			conv.Messages = append(conv.Messages, Message{Type: "agent", EndOfTurn: true, LLMData: llmData})
+		}
+		conv.Conversation.ConversationID = r.PathValue("id")
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	})
+	mux.HandleFunc("POST /api/conversation/{id}/archive", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), sent...)
+	}
+}
+
+func TestRunFollowUp(t *testing.T) {
+	shelley, sent := newMockShelleyRounds(t,
+		`[{"title": "First round", "url": "https://news.invalid/first", "summary": "One."}]`,
+		`[{"title": "First round", "url": "https://news.invalid/first", "summary": "One."}, {"title": "Second round", "url": "https://news.invalid/second", "summary": "Two."}]`,
+	)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+	runner.config.EnableFollowUp = true
+	runner.config.FollowUpMinArticles = 2
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	messages := sent()
+	if len(messages) != 1 || !strings.Contains(messages[0], "You found 1 article(s)") {
+		t.Fatalf("follow-up messages = %q, want one rendered from the default prompt", messages)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("ListArticlesByJob() error = %v", err)
+	}
+	var titles []string
+	for _, a := range articles {
+		titles = append(titles, a.Title)
+	}
+	if len(titles) != 2 || !strings.Contains(strings.Join(titles, ","), "First round") || !strings.Contains(strings.Join(titles, ","), "Second round") {
+		t.Errorf("saved articles = %q, want one from each round", titles)
+	}
+}
+
+func TestRunFollowUpSkippedWhenEnough(t *testing.T) {
+	shelley, sent := newMockShelleyRounds(t, `[{"title": "First round", "url": "", "summary": "One."}]`)
+	runner, _, job := newTestRunner(t, shelley.URL)
+	runner.config.EnableFollowUp = true
+	runner.config.FollowUpMinArticles = 1
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if messages := sent(); len(messages) != 0 {
+		t.Errorf("follow-up messages = %q, want none", messages)
+	}
+}
+
+func TestFollowUpMergesByURL(t *testing.T) {
+	shelley, sent := newMockShelleyRounds(t,
+		`[{"title": "A", "url": "https://news.example/a", "summary": "a"}]`,
+		`[{"title": "A again", "url": "https://news.example/a", "summary": "a"}, {"title": "B", "url": "https://news.example/b", "summary": "b"}]`,
+	)
+	runner, _, job := newTestRunner(t, shelley.URL)
+	runner.config.FollowUpMinArticles = 5
+
+	ctx := context.Background()
+	conv, err := runner.shelley.GetConversation(ctx, job.ID, "conv-1")
+	if err != nil {
+		t.Fatalf("GetConversation() error = %v", err)
+	}
+	first, err := ExtractConversationArticles(conv)
+	if err != nil {
+		t.Fatalf("ExtractConversationArticles() error = %v", err)
+	}
+
+	_, merged := runner.followUp(ctx, job.ID, "conv-1", "space news", conv, first, runner.config.JobTimeout, nil)
+	var urls []string
+	for _, a := range merged {
+		urls = append(urls, a.URL)
+	}
+	if want := []string{"https://news.example/a", "https://news.example/b"}; strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("merged URLs = %q, want %q", urls, want)
+	}
+	if messages := sent(); len(messages) != 1 || !strings.Contains(messages[0], "- https://news.example/a\n") {
+		t.Errorf("follow-up messages = %q, want one listing the first round's URLs", messages)
+	}
+}
+
+func TestFollowUpSkippedPastTimeout(t *testing.T) {
+	shelley, sent := newMockShelleyRounds(t, `[{"title": "A", "url": "https://news.example/a", "summary": "a"}]`)
+	runner, _, job := newTestRunner(t, shelley.URL)
+
+	ctx := context.Background()
+	conv, err := runner.shelley.GetConversation(ctx, job.ID, "conv-1")
+	if err != nil {
+		t.Fatalf("GetConversation() error = %v", err)
+	}
+	first, err := ExtractConversationArticles(conv)
+	if err != nil {
+		t.Fatalf("ExtractConversationArticles() error = %v", err)
+	}
+
+	// The first round used up the job's timeout
+	_, articles := runner.followUp(ctx, job.ID, "conv-1", "space news", conv, first, 0, nil)
+	if len(articles) != 1 {
+		t.Errorf("followUp() returned %d articles, want the first round's 1", len(articles))
+	}
+	if messages := sent(); len(messages) != 0 {
+		t.Errorf("follow-up messages = %q, want none", messages)
+	}
+}
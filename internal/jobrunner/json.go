@@ -1,39 +1,131 @@
 package jobrunner
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
-var (
-	// Regex to find JSON array in text
-	jsonArrayRegex = regexp.MustCompile(`(?s)\[.*\]`)
+// keywordStopWords are common words ignored by ExtractKeywords. Words shorter
+// than minKeywordLength are dropped before this list is consulted.
+var keywordStopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "also": true, "been": true,
+	"before": true, "being": true, "could": true, "does": true, "from": true,
+	"have": true, "here": true, "into": true, "just": true, "more": true,
+	"most": true, "news": true, "only": true, "other": true, "over": true,
+	"said": true, "says": true, "some": true, "such": true, "than": true,
+	"that": true, "their": true, "them": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "those": true, "through": true,
+	"very": true, "were": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "will": true, "with": true, "would": true,
+	"your": true,
+}
+
+// minKeywordLength is the shortest word ExtractKeywords keeps, in runes.
+const minKeywordLength = 4
 
+// maxArticleURLLength is the longest article URL accepted from the agent.
+const maxArticleURLLength = 2048
+
+var (
 	// Regex to remove markdown code blocks
 	codeBlockStart = regexp.MustCompile("(?m)^\\s*```(?:json)?\\s*")
 	codeBlockEnd   = regexp.MustCompile("(?m)\\s*```\\s*")
 )
 
-// extractJSONArray finds and extracts a JSON array from text.
-func extractJSONArray(text string) (string, error) {
-	// Remove markdown code blocks
+// stripCodeBlocks removes markdown code fences from text.
+func stripCodeBlocks(text string) string {
 	text = codeBlockStart.ReplaceAllString(text, "")
 	text = codeBlockEnd.ReplaceAllString(text, "")
-	text = strings.TrimSpace(text)
+	return strings.TrimSpace(text)
+}
 
-	// Find JSON array
-	match := jsonArrayRegex.FindString(text)
-	if match == "" {
+// extractJSONArray finds the last top-level JSON array in text, which is
+// where agents put their final results.
+func extractJSONArray(text string) (string, error) {
+	arrays := findAllJSONArrays(stripCodeBlocks(text))
+	if len(arrays) == 0 {
 		return "", fmt.Errorf("no JSON array found in response")
 	}
+	return arrays[len(arrays)-1], nil
+}
+
+// findAllJSONArrays returns every top-level, bracket-balanced array in text,
+// in order. Brackets inside JSON strings are ignored; string state is only
+// tracked within arrays so quotes and apostrophes in surrounding prose don't
+// confuse it.
+//
+// An array still open at the end of text usually holds an unescaped quote,
+// such as the inch mark in `12" MacBook`, that flipped the string state. It
+// is returned up to the last ']' in text, for fixMalformedJSON to repair,
+// and dropped when no ']' follows it.
+func findAllJSONArrays(text string) []string {
+	var arrays []string
+	depth, start := 0, 0
+	inString, escapeNext := false, false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if depth == 0 {
+			if c == '[' {
+				depth, start = 1, i
+			}
+			continue
+		}
+
+		switch {
+		case escapeNext:
+			escapeNext = false
+		case inString:
+			if c == '\\' {
+				escapeNext = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				arrays = append(arrays, text[start:i+1])
+			}
+		}
+	}
+	if depth > 0 {
+		if end := strings.LastIndexByte(text, ']'); end > start {
+			arrays = append(arrays, text[start:end+1])
+		}
+	}
+	return arrays
+}
 
-	return match, nil
+// normalizeText replaces invalid UTF-8 sequences with U+FFFD and strips
+// ASCII control characters other than tab, newline and carriage return.
+// Valid text, including non-Latin scripts and directional marks, is left
+// unchanged.
+func normalizeText(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
 }
 
-// fixMalformedJSON attempts to fix common LLM JSON issues.
-// This handles unescaped quotes inside strings (common with Chinese text).
+// fixMalformedJSON attempts to fix common LLM JSON issues: unescaped quotes
+// inside strings (common with Chinese text), raw newlines, tabs and carriage
+// returns inside strings, stray control characters and invalid UTF-8.
 func fixMalformedJSON(s string) string {
+	s = normalizeText(s)
+
 	var result strings.Builder
 	result.Grow(len(s))
 
@@ -43,6 +135,21 @@ func fixMalformedJSON(s string) string {
 	for i := 0; i < len(s); i++ {
 		c := s[i]
 
+		if inString && !escapeNext {
+			// JSON strings can't contain raw control characters
+			switch c {
+			case '\n':
+				result.WriteString(`\n`)
+				continue
+			case '\r':
+				result.WriteString(`\r`)
+				continue
+			case '\t':
+				result.WriteString(`\t`)
+				continue
+			}
+		}
+
 		if escapeNext {
 			result.WriteByte(c)
 			escapeNext = false
@@ -86,3 +193,217 @@ func fixMalformedJSON(s string) string {
 
 	return result.String()
 }
+
+// textListItem matches the number starting an item of a numbered list,
+// such as "1." or "2)".
+var textListItem = regexp.MustCompile(`^\d+[.)]\s*`)
+
+// ExtractArticlesFromText parses a plain-text article listing, for agents
+// that answer with a numbered list instead of JSON:
+//
+//	Here are the articles I found:
+//	1. Title: Example
+//	URL: https://example.com/a
+//	Summary: An example.
+//
+// The Title, URL and Summary prefixes are case-insensitive, and markdown
+// bullets and bold around them are ignored. An item starts at a list number
+// or at a second Title line. Items without a title are dropped; ok reports
+// whether any article was found.
+func ExtractArticlesFromText(text string) (articles []ArticleInfo, ok bool) {
+	var cur ArticleInfo
+	flush := func() {
+		if cur.Title != "" {
+			articles = append(articles, cur)
+		}
+		cur = ArticleInfo{}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if loc := textListItem.FindStringIndex(line); loc != nil {
+			flush()
+			line = line[loc[1]:]
+		}
+		line = strings.TrimLeft(line, "-*• ")
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.Trim(key, "* "))
+		value = strings.TrimSpace(strings.TrimLeft(value, "* "))
+		switch key {
+		case "title":
+			if cur.Title != "" {
+				flush()
+			}
+			cur.Title = value
+		case "url":
+			cur.URL = strings.Trim(value, "<>")
+		case "summary":
+			cur.Summary = value
+		}
+	}
+	flush()
+	return articles, len(articles) > 0
+}
+
+// validateArticleURL rejects article URLs that are malformed or unsafe to
+// store: non-HTTP schemes, missing or loopback hosts, and overly long URLs.
+func validateArticleURL(rawURL string) error {
+	if len(rawURL) > maxArticleURLLength {
+		return fmt.Errorf("URL exceeds %d characters", maxArticleURLLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return errors.New("URL has no hostname")
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("URL points to localhost")
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return errors.New("URL points to a loopback address")
+	}
+
+	return nil
+}
+
+// parseJobFetchHeaders parses a job's fetch_headers column, a JSON object of
+// header names to values. An empty string means no custom headers.
+func parseJobFetchHeaders(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		return nil, fmt.Errorf("parse fetch headers: %w", err)
+	}
+	for name := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return headers, nil
+}
+
+// ValidateFetchHeaders reports whether raw is acceptable as a job's
+// fetch_headers value.
+func ValidateFetchHeaders(raw string) error {
+	_, err := parseJobFetchHeaders(raw)
+	return err
+}
+
+// RedactFetchHeaders returns a job's fetch_headers value with every header
+// value replaced by "xxxxx", so the headers a job sends can be shown without
+// its credentials. Headers that don't parse are replaced entirely.
+func RedactFetchHeaders(raw string) string {
+	headers, err := parseJobFetchHeaders(raw)
+	if err != nil {
+		return "(invalid headers)"
+	}
+	if headers == nil {
+		return raw
+	}
+	for name := range headers {
+		headers[name] = "xxxxx"
+	}
+	b, _ := json.Marshal(headers)
+	return string(b)
+}
+
+// parseDomainList splits a comma-separated list of domains, lowercasing them
+// and dropping blanks and leading "*." wildcards.
+func parseDomainList(raw string) []string {
+	var domains []string
+	for _, d := range strings.Split(raw, ",") {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "*.")
+		if d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// urlMatchesDomains reports whether rawURL's host is one of domains or a
+// subdomain of one. An empty list matches every URL.
+func urlMatchesDomains(rawURL string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// URLMatchesDomainList reports whether rawURL's host is on a comma-separated
+// domain list, in the form of a job's fetch header domains, or a subdomain of
+// one. An empty list matches every URL.
+func URLMatchesDomainList(rawURL, list string) bool {
+	return urlMatchesDomains(rawURL, parseDomainList(list))
+}
+
+// SanitizeArticleURL strips tracking query parameters (utm_*, fbclid, gclid)
+// from an article URL. URLs that cannot be parsed are returned unchanged.
+func SanitizeArticleURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	removed := false
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || lower == "fbclid" || lower == "gclid" {
+			query.Del(key)
+			removed = true
+		}
+	}
+	if !removed {
+		return rawURL
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// ExtractKeywords returns the significant words in text: lowercased,
+// stripped of punctuation, at least minKeywordLength runes long and not a
+// stop word. Duplicates are removed and first-seen order is kept.
+func ExtractKeywords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if len([]rune(w)) < minKeywordLength || keywordStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		keywords = append(keywords, w)
+	}
+	return keywords
+}
@@ -1,6 +1,8 @@
 package jobrunner
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -26,6 +28,11 @@ func TestExtractJSONArray(t *testing.T) {
 			input: `Here are the articles I found:\n[{"title": "Test"}]\nHope this helps!`,
 			want:  `[{"title": "Test"}]`,
 		},
+		{
+			name:  "last of several arrays",
+			input: "Plan: [\"search\", \"filter\"]\nResults: [{\"title\": \"Test [draft]\"}]",
+			want:  `[{"title": "Test [draft]"}]`,
+		},
 		{
 			name:    "no array",
 			input:   "No articles found.",
@@ -75,18 +82,66 @@ func TestFixMalformedJSON(t *testing.T) {
 	}
 }
 
+func TestFixMalformedJSONUnicode(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle string
+	}{
+		{
+			name:      "chinese title with control chars",
+			input:     "[{\"title\": \"人工智能\x07新闻\x1b\", \"url\": \"https://example.com/ai\"}]",
+			wantTitle: "人工智能新闻",
+		},
+		{
+			name:      "arabic title with rtl mark",
+			input:     "[{\"title\": \"\u200fأخبار التقنية\", \"url\": \"https://example.com/tech\"}]",
+			wantTitle: "\u200fأخبار التقنية",
+		},
+		{
+			name:      "invalid utf-8",
+			input:     "[{\"title\": \"caf\xe9 \xff news\", \"url\": \"https://example.com/cafe\"}]",
+			wantTitle: "caf\ufffd \ufffd news",
+		},
+		{
+			name:      "raw newline and tab in string",
+			input:     "[{\"title\": \"東京\nニュース\tまとめ\",\n  \"url\": \"https://example.com/tokyo\"}]",
+			wantTitle: "東京\nニュース\tまとめ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var articles []ArticleInfo
+			fixed := fixMalformedJSON(tt.input)
+			if err := json.Unmarshal([]byte(fixed), &articles); err != nil {
+				t.Fatalf("fixed JSON %q does not parse: %v", fixed, err)
+			}
+			if len(articles) != 1 || articles[0].Title != tt.wantTitle {
+				t.Errorf("articles = %+v, want title %q", articles, tt.wantTitle)
+			}
+		})
+	}
+
+	// Valid ASCII JSON, including whitespace between tokens, is untouched
+	valid := "[\n\t{\"title\": \"Plain \\\"quoted\\\" title\", \"url\": \"https://example.com/a\"}\r\n]"
+	if got := fixMalformedJSON(valid); got != valid {
+		t.Errorf("fixMalformedJSON(%q) = %q, want unchanged", valid, got)
+	}
+}
+
 func TestExtractArticlesJSON(t *testing.T) {
 	input := `[{"title": "News Article", "url": "https://example.com/article", "summary": "This is a test."}]`
-	
-	articles, err := ExtractArticlesJSON(input)
+
+	articles, _, err := ExtractArticlesJSON(input)
 	if err != nil {
 		t.Fatalf("ExtractArticlesJSON() error = %v", err)
 	}
-	
+
 	if len(articles) != 1 {
 		t.Fatalf("expected 1 article, got %d", len(articles))
 	}
-	
+
 	if articles[0].Title != "News Article" {
 		t.Errorf("Title = %v, want %v", articles[0].Title, "News Article")
 	}
@@ -94,3 +149,275 @@ func TestExtractArticlesJSON(t *testing.T) {
 		t.Errorf("URL = %v, want %v", articles[0].URL, "https://example.com/article")
 	}
 }
+
+func TestFindAllJSONArrays(t *testing.T) {
+	input := `Steps ["step1","step2"] then it's done: [{"title": "A \"quoted\" ] title", "tags": ["x"]}] and [unterminated`
+	got := findAllJSONArrays(input)
+	want := []string{`["step1","step2"]`, `[{"title": "A \"quoted\" ] title", "tags": ["x"]}]`}
+	if len(got) != len(want) {
+		t.Fatalf("findAllJSONArrays() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("array %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractArticlesJSONMultipleArrays(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle []string
+		wantErr   bool
+	}{
+		{
+			name:      "intro array before results",
+			input:     "I will:\n[\"step1\",\"step2\"]\nResults:\n[{\"title\": \"Real\", \"url\": \"https://example.com/real\"}]",
+			wantTitle: []string{"Real"},
+		},
+		{
+			name:      "results before trailing note array",
+			input:     "[{\"title\": \"Real\"}]\nSources checked: [\"a\", \"b\"]",
+			wantTitle: []string{"Real"},
+		},
+		{
+			name:  "only empty results",
+			input: "Nothing new today: []",
+		},
+		{
+			name:    "no article arrays",
+			input:   `["step1","step2"]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, _, err := ExtractArticlesJSON(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractArticlesJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var titles []string
+			for _, a := range articles {
+				titles = append(titles, a.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.wantTitle, ",") {
+				t.Errorf("titles = %q, want %q", titles, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestExtractArticlesFromText(t *testing.T) {
+	input := "Here is what I found:\n\n" +
+		"1. Title: First Story\nURL: https://example.com/1\nSummary: The first.\n\n" +
+		"2. title: Second Story\n   url: https://example.com/2\n   summary: The second.\n\n" +
+		"3. **Title:** Third Story\n- **URL:** https://example.com/3\n- **Summary:** The third.\n"
+	want := []ArticleInfo{
+		{Title: "First Story", URL: "https://example.com/1", Summary: "The first."},
+		{Title: "Second Story", URL: "https://example.com/2", Summary: "The second."},
+		{Title: "Third Story", URL: "https://example.com/3", Summary: "The third."},
+	}
+
+	articles, textFallback, err := ExtractArticlesJSON(input)
+	if err != nil || !textFallback {
+		t.Fatalf("ExtractArticlesJSON() textFallback = %v, error = %v, want the text fallback", textFallback, err)
+	}
+	if len(articles) != len(want) {
+		t.Fatalf("got %d articles, want %d: %+v", len(articles), len(want), articles)
+	}
+	for i := range want {
+		if articles[i] != want[i] {
+			t.Errorf("article %d = %+v, want %+v", i, articles[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"", "1.", "Title:", "URL: https://example.com/no-title", "2) :\n::\n9."} {
+		if got, ok := ExtractArticlesFromText(bad); ok {
+			t.Errorf("ExtractArticlesFromText(%q) = %+v, want no articles", bad, got)
+		}
+	}
+}
+
+func TestValidateArticleURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"valid https", "https://example.com/news/story", false},
+		{"valid http with port", "http://example.com:8080/a", false},
+		{"file scheme", "file:///etc/passwd", true},
+		{"javascript scheme", "javascript:alert(1)", true},
+		{"localhost", "http://localhost/admin", true},
+		{"localhost subdomain", "http://app.localhost/", true},
+		{"loopback ip", "http://127.0.0.1:9999/", true},
+		{"ipv6 loopback", "http://[::1]/", true},
+		{"no hostname", "https:///path", true},
+		{"too long", "https://example.com/" + strings.Repeat("a", 2048), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArticleURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateArticleURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseJobFetchHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"object", `{"X-API-Key": "abc"}`, map[string]string{"X-API-Key": "abc"}, false},
+		{"invalid json", `{"X-API-Key":`, nil, true},
+		{"not an object", `["X-API-Key"]`, nil, true},
+		{"non-string value", `{"X-Count": 1}`, nil, true},
+		{"invalid name", `{"Bad Header": "x"}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJobFetchHeaders(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJobFetchHeaders(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseJobFetchHeaders(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("header %q = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactFetchHeaders(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"", ""},
+		{`{"Authorization": "Bearer secret", "X-API-Key": "abc"}`, `{"Authorization":"xxxxx","X-API-Key":"xxxxx"}`},
+		{`{"Authorization": "Bearer secret"`, "(invalid headers)"},
+	}
+	for _, tt := range tests {
+		if got := RedactFetchHeaders(tt.raw); got != tt.want {
+			t.Errorf("RedactFetchHeaders(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestURLMatchesDomains(t *testing.T) {
+	domains := parseDomainList(" Example.com, *.news.org ,")
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/a", true},
+		{"https://www.example.com/a", true},
+		{"https://notexample.com/a", false},
+		{"https://feeds.news.org/rss", true},
+		{"https://news.org.evil.com/", false},
+	}
+
+	for _, tt := range tests {
+		if got := urlMatchesDomains(tt.url, domains); got != tt.want {
+			t.Errorf("urlMatchesDomains(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+	if !urlMatchesDomains("https://anything.test/", nil) {
+		t.Error("empty domain list should match every URL")
+	}
+}
+
+func TestSanitizeArticleURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"no query", "https://example.com/a", "https://example.com/a"},
+		{"untouched query", "https://example.com/a?id=1&b=2", "https://example.com/a?id=1&b=2"},
+		{"utm params", "https://example.com/a?utm_source=x&utm_medium=y", "https://example.com/a"},
+		{"mixed params", "https://example.com/a?id=1&fbclid=abc&gclid=def", "https://example.com/a?id=1"},
+		{"fragment kept", "https://example.com/a?utm_campaign=z#section", "https://example.com/a#section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeArticleURL(tt.url); got != tt.want {
+				t.Errorf("SanitizeArticleURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"short and stop words dropped", "This is what the city said", []string{"city"}},
+		{"punctuation and case", "Quantum, QUANTUM! computing.", []string{"quantum", "computing"}},
+		{"non-latin words kept", "Économie française", []string{"économie", "française"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractKeywords(tt.text)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ExtractKeywords(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArticlesJSONUnescapedInchMark(t *testing.T) {
+	input := `Here you go: [{"title": "Apple's new 12" MacBook", "url": "https://example.com/macbook", "summary": "The small laptop returns."}]`
+
+	articles, textFallback, err := ExtractArticlesJSON(input)
+	if err != nil {
+		t.Fatalf("ExtractArticlesJSON() error = %v", err)
+	}
+	if textFallback {
+		t.Error("textFallback = true, want the JSON array to be used")
+	}
+	if len(articles) != 1 {
+		t.Fatalf("expected 1 article, got %d", len(articles))
+	}
+	if want := `Apple's new 12" MacBook`; articles[0].Title != want {
+		t.Errorf("Title = %q, want %q", articles[0].Title, want)
+	}
+	if want := "https://example.com/macbook"; articles[0].URL != want {
+		t.Errorf("URL = %q, want %q", articles[0].URL, want)
+	}
+}
+
+func TestExtractArticlesJSONIgnoresRetrievedAt(t *testing.T) {
+	// Agents can't set when an article was retrieved, valid or not
+	input := `[{"title": "A", "url": "https://example.com/a", "retrieved_at": "2001-01-01T00:00:00Z"}, {"title": "B", "url": "https://example.com/b", "retrieved_at": "yesterday"}]`
+
+	articles, _, err := ExtractArticlesJSON(input)
+	if err != nil {
+		t.Fatalf("ExtractArticlesJSON() error = %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("expected 2 articles, got %d", len(articles))
+	}
+	for _, a := range articles {
+		if a.RetrievedAt != nil {
+			t.Errorf("%s: RetrievedAt = %v, want nil", a.Title, a.RetrievedAt)
+		}
+	}
+}
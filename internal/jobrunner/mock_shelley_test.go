@@ -0,0 +1,97 @@
+package jobrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// MockShelley is an in-memory ShelleyAPI. Every conversation it creates
+// finishes at once with AgentText as the agent's final message. It records
+// the methods called, in order.
+type MockShelley struct {
+	AgentText string
+	CreateErr error // returned by CreateConversation and CreateConversationWithKey
+	GetErr    error // returned by GetConversation and GetConversationStatus
+
+	mu            sync.Mutex
+	calls         []string
+	conversations int
+}
+
+var _ ShelleyAPI = (*MockShelley)(nil)
+
+func (m *MockShelley) record(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, method)
+}
+
+// Calls returns the names of the methods called so far.
+func (m *MockShelley) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+func (m *MockShelley) newConversation() (string, error) {
+	if m.CreateErr != nil {
+		return "", m.CreateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conversations++
+	return fmt.Sprintf("mock-conv-%d", m.conversations), nil
+}
+
+func (m *MockShelley) CreateConversation(ctx context.Context, jobID, runID int64, prompt string) (string, error) {
+	m.record("CreateConversation")
+	return m.newConversation()
+}
+
+func (m *MockShelley) CreateConversationWithKey(ctx context.Context, userID, model, prompt, idempotencyKey string) (string, error) {
+	m.record("CreateConversationWithKey")
+	return m.newConversation()
+}
+
+func (m *MockShelley) GetConversation(ctx context.Context, jobID int64, convID string) (*Conversation, error) {
+	m.record("GetConversation")
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	llmData, _ := json.Marshal(LLMData{Content: []ContentBlock{{Type: 2, Text: m.AgentText}}})
+	working := false
+	conv := &Conversation{Messages: []Message{{Type: "agent", EndOfTurn: true, LLMData: llmData}}}
+	conv.Conversation.ConversationID = convID
+	conv.Conversation.Working = &working
+	return conv, nil
+}
+
+func (m *MockShelley) GetConversationStatus(ctx context.Context, jobID int64, convID string) (*ConversationStatus, error) {
+	m.record("GetConversationStatus")
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	return &ConversationStatus{MessageCount: 1}, nil
+}
+
+func (m *MockShelley) SendMessage(ctx context.Context, jobID int64, convID, message string) error {
+	m.record("SendMessage")
+	return nil
+}
+
+func (m *MockShelley) DeleteConversation(ctx context.Context, jobID int64, convID string) error {
+	m.record("DeleteConversation")
+	return nil
+}
+
+func (m *MockShelley) ArchiveConversation(ctx context.Context, jobID int64, convID string) error {
+	m.record("ArchiveConversation")
+	return nil
+}
+
+func (m *MockShelley) ListSubagents(ctx context.Context, jobID int64, parentConvID string) ([]string, error) {
+	m.record("ListSubagents")
+	return nil, nil
+}
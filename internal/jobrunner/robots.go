@@ -0,0 +1,175 @@
+package jobrunner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RobotsUserAgent is the product token news-app matches against robots.txt
+// User-agent lines. Article requests still send a browser user agent.
+const RobotsUserAgent = "news-app"
+
+// robotsTxtTTL is how long a site's parsed robots.txt is reused.
+const robotsTxtTTL = time.Hour
+
+// maxRobotsTxtSize caps how much of a robots.txt file is read, as RFC 9309
+// allows crawlers to.
+const maxRobotsTxtSize = 500 * 1024
+
+// robotsRule is one Allow or Disallow line.
+type robotsRule struct {
+	path  string
+	allow bool
+}
+
+// robotsGroup is the rules following one or more User-agent lines.
+type robotsGroup struct {
+	agents []string // lowercased product tokens, "*" for any
+	rules  []robotsRule
+}
+
+// RobotsTxtParser holds the rules of a parsed robots.txt file. Rules match
+// path prefixes; the longest matching rule wins and Allow wins a tie. Wildcard
+// patterns within paths are not supported. The zero value allows everything.
+type RobotsTxtParser struct {
+	groups []robotsGroup
+}
+
+// ParseRobotsTxt parses the User-agent, Allow and Disallow lines of a
+// robots.txt file, ignoring everything else.
+func ParseRobotsTxt(r io.Reader) *RobotsTxtParser {
+	p := &RobotsTxtParser{}
+	var current *robotsGroup
+	inAgents := false // the previous line was a User-agent line
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				p.groups = append(p.groups, robotsGroup{})
+				current = &p.groups[len(p.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			// An empty Disallow allows everything, so it adds no rule
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{path: value, allow: key == "allow"})
+		}
+	}
+	return p
+}
+
+// IsAllowed reports whether ua may fetch path. The rules of groups naming
+// ua's product token are used if there are any, otherwise those for "*". As
+// in RFC 9309, a group's token must equal ua's product token, ignoring case.
+func (p *RobotsTxtParser) IsAllowed(ua, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	ua = productToken(ua)
+
+	var specific, wildcard []robotsRule
+	for _, g := range p.groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, g.rules...)
+			} else if agent != "" && productToken(agent) == ua {
+				specific = append(specific, g.rules...)
+			}
+		}
+	}
+	rules := wildcard
+	if specific != nil {
+		rules = specific
+	}
+
+	allowed, matched := true, -1
+	for _, rule := range rules {
+		if !strings.HasPrefix(path, rule.path) {
+			continue
+		}
+		if len(rule.path) > matched || (len(rule.path) == matched && rule.allow) {
+			allowed, matched = rule.allow, len(rule.path)
+		}
+	}
+	return allowed
+}
+
+// productToken returns the lowercased product token of a user agent such as
+// "news-app/1.0", dropping any version and comments.
+func productToken(ua string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(ua), "/")
+	if i := strings.IndexAny(token, " \t"); i >= 0 {
+		token = token[:i]
+	}
+	return strings.ToLower(token)
+}
+
+// robotsEntry is a cached robots.txt for one site.
+type robotsEntry struct {
+	parser    *RobotsTxtParser
+	fetchedAt time.Time
+}
+
+// robotsAllowed reports whether robots.txt on rawURL's site lets
+// RobotsUserAgent fetch it. Sites whose robots.txt can't be fetched, or
+// doesn't exist, allow everything.
+func (f *ArticleFetcher) robotsAllowed(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+	site := u.Scheme + "://" + u.Host
+
+	var parser *RobotsTxtParser
+	if v, ok := f.robots.Load(site); ok && time.Since(v.(robotsEntry).fetchedAt) < robotsTxtTTL {
+		parser = v.(robotsEntry).parser
+	} else {
+		parser = f.fetchRobotsTxt(ctx, site)
+		f.robots.Store(site, robotsEntry{parser: parser, fetchedAt: time.Now()})
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return parser.IsAllowed(RobotsUserAgent, path)
+}
+
+// fetchRobotsTxt downloads and parses site's robots.txt, returning a parser
+// that allows everything when there is none.
+func (f *ArticleFetcher) fetchRobotsTxt(ctx context.Context, site string) *RobotsTxtParser {
+	req, err := http.NewRequestWithContext(ctx, "GET", site+"/robots.txt", nil)
+	if err != nil {
+		return &RobotsTxtParser{}
+	}
+	req.Header.Set("User-Agent", RobotsUserAgent)
+
+	resp, err := f.do(req)
+	if err != nil {
+		return &RobotsTxtParser{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &RobotsTxtParser{}
+	}
+	return ParseRobotsTxt(io.LimitReader(resp.Body, maxRobotsTxtSize))
+}
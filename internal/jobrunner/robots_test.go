@@ -0,0 +1,85 @@
+package jobrunner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRobotsTxtParser(t *testing.T) {
+	p := ParseRobotsTxt(strings.NewReader(`
+# comments are ignored
+User-agent: *
+Disallow: /premium/
+Allow: /premium/free-
+Disallow:
+
+User-agent: otherbot
+User-agent: news-app
+Disallow: /private
+
+User-agent: news
+Disallow: /
+`))
+	tests := []struct {
+		ua, path string
+		want     bool
+	}{
+		{"somebot", "/premium/article", false},
+		{"somebot", "/premium/free-article", true},
+		{"somebot", "/news/article", true},
+		{"somebot", "/private/x", true},
+		// news-app has its own group, so only its rules apply
+		{"news-app", "/premium/article", true},
+		{"News-App/1.0", "/private/x", false},
+		// Tokens must match exactly, so the "news" group is not ours
+		{"news-app", "/news/article", true},
+		{"newsbot", "/news/article", true},
+	}
+	for _, tt := range tests {
+		if got := p.IsAllowed(tt.ua, tt.path); got != tt.want {
+			t.Errorf("IsAllowed(%q, %q) = %v, want %v", tt.ua, tt.path, got, tt.want)
+		}
+	}
+	if !(&RobotsTxtParser{}).IsAllowed("any", "/") {
+		t.Error("empty parser disallows, want everything allowed")
+	}
+}
+
+func TestFetchArticleContentRobotsTxt(t *testing.T) {
+	var robotsFetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches.Add(1)
+			w.Write([]byte("User-agent: *\nDisallow: /premium/\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("Free article text"))
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewArticleFetcher(FetchConfig{RespectRobotsTxt: true, CacheSize: -1})
+	ctx := context.Background()
+
+	content, err := f.FetchArticleContent(ctx, srv.URL+"/premium/article", nil)
+	if want := "[robots.txt] Disallowed: " + srv.URL + "/premium/article"; err != nil || content.Text != want {
+		t.Errorf("disallowed fetch = %q, %v, want %q", content.Text, err, want)
+	}
+	content, err = f.FetchArticleContent(ctx, srv.URL+"/news/article", nil)
+	if err != nil || content.Text != "Free article text" {
+		t.Errorf("allowed fetch = %q, %v, want the article", content.Text, err)
+	}
+	if n := robotsFetches.Load(); n != 1 {
+		t.Errorf("robots.txt fetched %d times, want once per site", n)
+	}
+
+	// Without the option robots.txt isn't consulted
+	f = NewArticleFetcher(FetchConfig{CacheSize: -1})
+	if content, _ := f.FetchArticleContent(ctx, srv.URL+"/premium/article", nil); content.Text != "Free article text" {
+		t.Errorf("fetch ignoring robots.txt = %q, want the article", content.Text)
+	}
+}
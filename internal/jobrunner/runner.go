@@ -6,15 +6,20 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/exedev/news-app/internal/db/dbgen"
@@ -27,19 +32,78 @@ type Config struct {
 	ArticlesDir  string
 	LogsDir      string
 	ShelleyAPI   string
+	Model        string             // Shelley model used for job conversations
+	ModelCostMap map[string]float64 // Estimated USD per million tokens, by model
 	JobTimeout   time.Duration
 	PollInterval time.Duration
 	StartDelay   time.Duration // Max random delay to stagger job starts
 	MaxParallel  int           // Max concurrent article fetches
+	UserAgents   []string      // User agents rotated across article fetches
+	ProxyURL     string        // Proxy for article fetches
+	ProxyURLs    []string      // Proxies rotated across article fetches
+	CacheSize    int           // Max cached article contents (negative disables)
+	CacheTTL     time.Duration // How long cached article content is reused
+
+	// StatusCheckInterval is how often a running conversation's status is
+	// checked; its messages are only fetched once it finishes. 0 uses
+	// PollInterval.
+	StatusCheckInterval time.Duration
+
+	RespectRobots bool // Skip articles disallowed by the site's robots.txt
+
+	ArticleDateDirs bool // Save article files in YYYY/MM subdirectories
+
+	MultiRoundConfig
+
+	BatchNotifications bool          // Combine Discord notifications sent within BatchWindow
+	BatchWindow        time.Duration // How often the web server sends queued notifications
+
+	MaxConcurrentRuns int // Runs executing at once across every process and user
+}
+
+// getEnvList returns the non-empty, trimmed entries of an env var split on
+// sep, or nil if it is unset.
+func getEnvList(key, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// defaultModelCosts are the estimated USD per million tokens used when
+// NEWS_MODEL_COSTS is unset. Most of a conversation's tokens are input, so
+// input pricing is used.
+var defaultModelCosts = map[string]float64{
+	"claude-sonnet-4.5": 3,
 }
 
-func getEnvInt(key string, defaultVal int) int {
-	if v := os.Getenv(key); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			return i
+// getEnvModelCosts parses a comma-separated list of model=usd_per_million
+// pairs (e.g. "claude-sonnet-4.5=3,gpt-5=1.25") from an env var. Invalid
+// entries are skipped; if it is unset, defaultVal is returned.
+func getEnvModelCosts(key string, defaultVal map[string]float64) map[string]float64 {
+	items := getEnvList(key, ",")
+	if items == nil {
+		return defaultVal
+	}
+	costs := make(map[string]float64, len(items))
+	for _, item := range items {
+		model, price, ok := strings.Cut(item, "=")
+		cost, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+		if !ok || err != nil || cost < 0 {
+			continue
 		}
+		costs[strings.TrimSpace(model)] = cost
 	}
-	return defaultVal
+	return costs
+}
+
+// CostPerToken returns the estimated USD cost of one token of model, or 0
+// if the model has no configured price.
+func (c Config) CostPerToken(model string) float64 {
+	return c.ModelCostMap[model] / 1e6
 }
 
 // DefaultConfig returns configuration with sensible defaults.
@@ -49,11 +113,142 @@ func DefaultConfig() Config {
 		ArticlesDir:  util.GetEnv("NEWS_APP_ARTICLES_DIR", "/home/exedev/news-app/articles"),
 		LogsDir:      util.GetEnv("NEWS_APP_LOGS_DIR", "/home/exedev/news-app/logs/runs"),
 		ShelleyAPI:   util.GetEnv("NEWS_APP_SHELLEY_API", "http://localhost:9999"),
-		JobTimeout:   time.Duration(getEnvInt("NEWS_JOB_TIMEOUT_SECS", 25*60)) * time.Second,
-		PollInterval: time.Duration(getEnvInt("NEWS_JOB_POLL_INTERVAL_SECS", 10)) * time.Second,
-		StartDelay:   time.Duration(getEnvInt("NEWS_JOB_START_DELAY_SECS", 60)) * time.Second,
-		MaxParallel:  getEnvInt("NEWS_JOB_MAX_PARALLEL", 5),
+		Model:        util.GetEnv("NEWS_SHELLEY_MODEL", DefaultModel),
+		ModelCostMap: getEnvModelCosts("NEWS_MODEL_COSTS", defaultModelCosts),
+		JobTimeout:   time.Duration(util.GetEnvInt("NEWS_JOB_TIMEOUT_SECS", 25*60)) * time.Second,
+		PollInterval: time.Duration(util.GetEnvInt("NEWS_JOB_POLL_INTERVAL_SECS", 10)) * time.Second,
+		StartDelay:   time.Duration(util.GetEnvInt("NEWS_JOB_START_DELAY_SECS", 60)) * time.Second,
+		MaxParallel:  util.GetEnvInt("NEWS_JOB_MAX_PARALLEL", 5),
+		UserAgents:   getEnvList("NEWS_FETCH_USER_AGENTS", "\n"),
+		ProxyURL:     os.Getenv("NEWS_FETCH_PROXY"),
+		ProxyURLs:    getEnvList("NEWS_FETCH_PROXIES", ","),
+		CacheSize:    util.GetEnvInt("NEWS_FETCH_CACHE_SIZE", 1000),
+		CacheTTL:     util.GetEnvDuration("NEWS_FETCH_CACHE_TTL", time.Hour),
+
+		StatusCheckInterval: time.Duration(util.GetEnvInt("NEWS_JOB_STATUS_CHECK_INTERVAL_SECS", 0)) * time.Second,
+
+		RespectRobots: util.GetEnvBool("NEWS_FETCH_RESPECT_ROBOTS", false),
+
+		ArticleDateDirs: util.GetEnvBool("NEWS_APP_ARTICLES_DATE_DIRS", false),
+
+		MultiRoundConfig: MultiRoundConfig{
+			EnableFollowUp:      util.GetEnvBool("NEWS_FOLLOWUP_ENABLE", false),
+			FollowUpPrompt:      util.GetEnv("NEWS_FOLLOWUP_PROMPT", DefaultFollowUpPrompt),
+			FollowUpMinArticles: util.GetEnvInt("NEWS_FOLLOWUP_MIN_ARTICLES", 5),
+		},
+
+		BatchNotifications: util.GetEnvBool("NEWS_NOTIFY_BATCH", false),
+		BatchWindow:        util.GetEnvDuration("NEWS_NOTIFY_BATCH_WINDOW", DefaultBatcherConfig().Window),
+
+		MaxConcurrentRuns: util.GetEnvInt("NEWS_MAX_CONCURRENT_RUNS", 5),
+	}
+}
+
+// Validate reports configuration values that will make job runs fail or
+// misbehave, joined into one error. It returns nil for a usable config.
+func (c Config) Validate() error {
+	var errs []error
+	paths := []struct{ name, path string }{
+		{"DBPath", c.DBPath},
+		{"ArticlesDir", c.ArticlesDir},
+		{"LogsDir", c.LogsDir},
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			errs = append(errs, fmt.Errorf("%s is empty", p.name))
+		}
+	}
+	// As with proxies, the URL isn't quoted since it may hold credentials
+	if u, err := url.Parse(c.ShelleyAPI); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, errors.New("ShelleyAPI is not an absolute URL such as http://localhost:9999"))
+	}
+	if c.JobTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("JobTimeout must be positive, got %s", c.JobTimeout))
+	}
+	if c.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("PollInterval must be positive, got %s", c.PollInterval))
+	}
+	if c.StatusCheckInterval < 0 {
+		errs = append(errs, fmt.Errorf("StatusCheckInterval must not be negative, got %s", c.StatusCheckInterval))
+	}
+	if c.MaxParallel < 1 {
+		errs = append(errs, fmt.Errorf("MaxParallel must be at least 1, got %d", c.MaxParallel))
+	}
+	if c.BatchNotifications && c.BatchWindow <= 0 {
+		errs = append(errs, fmt.Errorf("BatchWindow must be positive, got %s", c.BatchWindow))
+	}
+	if c.MaxConcurrentRuns < 1 {
+		errs = append(errs, fmt.Errorf("MaxConcurrentRuns must be at least 1, got %d", c.MaxConcurrentRuns))
+	}
+	for _, proxy := range append([]string{c.ProxyURL}, c.ProxyURLs...) {
+		if proxy == "" {
+			continue
+		}
+		// Parse errors quote the URL, which may hold a password
+		if _, err := url.Parse(proxy); err != nil {
+			errs = append(errs, errors.New("a proxy URL is invalid"))
+		}
+	}
+	if c.EnableFollowUp {
+		if _, err := template.New("followup").Parse(c.FollowUpPrompt); err != nil {
+			errs = append(errs, fmt.Errorf("FollowUpPrompt is not a valid template: %w", err))
+		}
+	}
+	if _, ok := c.ModelCostMap[c.Model]; !ok {
+		errs = append(errs, fmt.Errorf("model %q has no price in ModelCostMap; its runs get no cost estimate", c.Model))
+	}
+	return errors.Join(errs...)
+}
+
+// Job event types recorded in the job_events audit log.
+const (
+	EventRunStarted          = "run_started"
+	EventRunCompleted        = "run_completed"
+	EventRunFailed           = "run_failed"
+	EventConversationCreated = "conversation_created"
+	EventArticlesSaved       = "articles_saved"
+	EventNotificationSent    = "notification_sent"
+)
+
+// ErrQuotaExceeded is the error of a run skipped because its user has used
+// up their daily Shelley quota. Such runs end as quota_exceeded rather than
+// failed and send no failure notification.
+var ErrQuotaExceeded = errors.New("daily Shelley quota exceeded")
+
+// ShelleyUsageTracker counts the Shelley conversations users' runs started,
+// for enforcing Preference.ShelleyQuotaDaily.
+type ShelleyUsageTracker struct {
+	queries *dbgen.Queries
+}
+
+// NewShelleyUsageTracker creates a usage tracker reading from queries.
+func NewShelleyUsageTracker(queries *dbgen.Queries) *ShelleyUsageTracker {
+	return &ShelleyUsageTracker{queries: queries}
+}
+
+// DailyUsage returns how many of the user's runs started a Shelley
+// conversation since midnight UTC.
+func (t *ShelleyUsageTracker) DailyUsage(ctx context.Context, userID int64) (int64, error) {
+	return t.queries.CountUserConversationsSince(ctx, dbgen.CountUserConversationsSinceParams{
+		UserID:    userID,
+		StartedAt: time.Now().UTC().Truncate(24 * time.Hour),
+	})
+}
+
+// CheckQuota returns an error wrapping ErrQuotaExceeded if the user has
+// reached quota conversations today. A quota of 0 is unlimited.
+func (t *ShelleyUsageTracker) CheckQuota(ctx context.Context, userID, quota int64) error {
+	if quota <= 0 {
+		return nil
+	}
+	usage, err := t.DailyUsage(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get Shelley usage: %w", err)
 	}
+	if usage >= quota {
+		return fmt.Errorf("%w: %d of %d conversations used today", ErrQuotaExceeded, usage, quota)
+	}
+	return nil
 }
 
 // Runner executes news retrieval jobs.
@@ -61,20 +256,85 @@ type Runner struct {
 	config  Config
 	db      *sql.DB
 	queries *dbgen.Queries
-	shelley *ShelleyClient
+	shelley ShelleyAPI
+	usage   *ShelleyUsageTracker
+	fetcher *ArticleFetcher
 	logger  *slog.Logger
 	logFile *os.File
+	batcher *NotificationBatcher // nil unless Config.BatchNotifications
+
+	customLogger  *slog.Logger // set by WithLogger/SetLogger; nil means stdout
+	noFileLogging bool         // skip per-run log files
+
+	resume func(ctx context.Context, runID int64) error // used by ResumeOrphanedRuns; stubbed in tests
+
+	randomDelay func(max time.Duration) time.Duration // picks a start delay below max; stubbed in tests
+}
+
+// RunnerOption configures optional Runner behaviour.
+type RunnerOption func(*Runner)
+
+// WithLogger sends the runner's logs to l. Per-run log files are still
+// written unless WithNoFileLogging is also given.
+func WithLogger(l *slog.Logger) RunnerOption {
+	return func(r *Runner) {
+		r.SetLogger(l)
+	}
+}
+
+// WithNoFileLogging stops the runner from writing per-run log files.
+func WithNoFileLogging() RunnerOption {
+	return func(r *Runner) {
+		r.noFileLogging = true
+	}
+}
+
+// WithShelley makes the runner talk to shelley instead of a ShelleyClient
+// for Config.ShelleyAPI.
+func WithShelley(shelley ShelleyAPI) RunnerOption {
+	return func(r *Runner) {
+		r.shelley = shelley
+	}
+}
+
+// SetLogger sends the runner's logs to l instead of stdout.
+func (r *Runner) SetLogger(l *slog.Logger) {
+	r.customLogger = l
+	r.logger = l
 }
 
 // NewRunner creates a new job runner.
-func NewRunner(db *sql.DB, config Config) *Runner {
-	return &Runner{
+func NewRunner(db *sql.DB, config Config, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		config:  config,
 		db:      db,
 		queries: dbgen.New(db),
 		shelley: NewShelleyClient(config.ShelleyAPI),
+		usage:   NewShelleyUsageTracker(dbgen.New(db)),
 		logger:  slog.Default(),
+		fetcher: NewArticleFetcher(FetchConfig{
+			UserAgents: config.UserAgents,
+			ProxyURL:   config.ProxyURL,
+			ProxyURLs:  config.ProxyURLs,
+			CacheSize:  config.CacheSize,
+			CacheTTL:   config.CacheTTL,
+
+			RespectRobotsTxt: config.RespectRobots,
+		}),
+	}
+	r.resume = r.resumeOnNewRunner
+	r.randomDelay = func(max time.Duration) time.Duration {
+		return time.Duration(rand.Int63n(int64(max)))
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if config.BatchNotifications {
+		batcherConfig := DefaultBatcherConfig()
+		batcherConfig.Window = config.BatchWindow
+		r.batcher = NewNotificationBatcher(db, batcherConfig)
+	}
+	return r
 }
 
 // Run executes a job by ID. This is the main entry point.
@@ -123,7 +383,7 @@ func (r *Runner) Resume(ctx context.Context, runID int64) error {
 	)
 
 	// Execute the job (will check for existing conversation)
-	result := r.executeJob(ctx, job, prefs)
+	result := r.executeJob(ctx, job, run.ID, prefs, r.config.JobTimeout, nil)
 
 	// If the context was cancelled (e.g. SIGTERM during restart), leave the run
 	// in "running" state so it can be resumed on next startup. Don't finalize
@@ -140,12 +400,176 @@ func (r *Runner) Resume(ctx context.Context, runID int64) error {
 	return result.Error
 }
 
+// ResumeOrphanedRuns resumes every run left in the running state, e.g. by a
+// restart while jobs were running, and waits for them to finish. Runs whose
+// conversation already completed are finalized without starting a new one.
+func (r *Runner) ResumeOrphanedRuns(ctx context.Context) error {
+	runs, err := r.queries.ListAllRunningRuns(ctx)
+	if err != nil {
+		return fmt.Errorf("list running runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	r.logger.Info("resuming orphaned runs", "count", len(runs))
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Add(1)
+		go func(runID int64, jobName string) {
+			defer wg.Done()
+			if err := r.resume(ctx, runID); err != nil {
+				r.logger.Warn("resume orphaned run", "run_id", runID, "job_name", jobName, "error", err)
+			}
+		}(run.ID, run.JobName)
+	}
+	wg.Wait()
+	return nil
+}
+
+// resumeOnNewRunner resumes a run on its own Runner, since a Runner's log
+// state belongs to one run at a time. The new Runner shares r's logger.
+func (r *Runner) resumeOnNewRunner(ctx context.Context, runID int64) error {
+	run := NewRunner(r.db, r.config)
+	run.logger, run.customLogger, run.noFileLogging = r.logger, r.customLogger, r.noFileLogging
+	return run.Resume(ctx, runID)
+}
+
+// runSlotPollInterval is how often a run waiting for a free run slot checks
+// again.
+var runSlotPollInterval = time.Second
+
+// ActiveRuns returns how many job runs are executing, in any process. Each
+// run is its own process, so the running job_runs rows are the only shared
+// count. Runs started more than twice config.JobTimeout ago are taken to be
+// abandoned and don't count, so a crashed run can't hold a slot forever.
+func ActiveRuns(ctx context.Context, q *dbgen.Queries, config Config) (int64, error) {
+	return q.CountActiveRuns(ctx, time.Now().UTC().Add(-2*config.JobTimeout))
+}
+
+// RunSlotsFull reports whether config.MaxConcurrentRuns runs are already
+// executing across every process and user.
+func RunSlotsFull(ctx context.Context, q *dbgen.Queries, config Config) (bool, error) {
+	n, err := ActiveRuns(ctx, q, config)
+	if err != nil {
+		return false, err
+	}
+	return n >= int64(max(config.MaxConcurrentRuns, 1)), nil
+}
+
+// createRunInSlot waits until a run slot is free, then records a new run of
+// jobID, which holds the slot until it leaves the running state. It gives up
+// when ctx is done.
+func (r *Runner) createRunInSlot(ctx context.Context, jobID int64) (dbgen.JobRun, error) {
+	for {
+		run, ok, err := r.tryCreateRunInSlot(ctx, jobID)
+		if err != nil || ok {
+			return run, err
+		}
+		select {
+		case <-ctx.Done():
+			return dbgen.JobRun{}, fmt.Errorf("waiting for a run slot: %w", ctx.Err())
+		case <-time.After(runSlotPollInterval):
+		}
+	}
+}
+
+// tryCreateRunInSlot records a new run of jobID if a run slot is free. The
+// count and the insert share an IMMEDIATE transaction, which holds SQLite's
+// write lock, so two processes can't both take the last slot.
+func (r *Runner) tryCreateRunInSlot(ctx context.Context, jobID int64) (run dbgen.JobRun, ok bool, err error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return run, false, fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return run, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			conn.ExecContext(context.Background(), "ROLLBACK")
+		}
+	}()
+
+	queries := dbgen.New(conn)
+	full, err := RunSlotsFull(ctx, queries, r.config)
+	if err != nil {
+		return run, false, fmt.Errorf("count active runs: %w", err)
+	}
+	if full {
+		return run, false, nil
+	}
+	run, err = queries.CreateJobRun(ctx, jobID)
+	if err != nil {
+		return run, false, fmt.Errorf("create job run: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return run, false, fmt.Errorf("commit: %w", err)
+	}
+	committed = true
+	return run, true, nil
+}
+
+// RunOptions adjusts a single job run.
+type RunOptions struct {
+	DisableStartDelay bool          // skip the random start delay
+	OverrideTimeout   time.Duration // replaces Config.JobTimeout when positive
+
+	// ProgressCallback, if set, is called as the run progresses, so programs
+	// embedding the runner can show progress without reading its log. It is
+	// called synchronously and never concurrently, so it should return
+	// quickly.
+	ProgressCallback func(RunProgress)
+}
+
+// Progress stages reported in RunProgress.
+const (
+	ProgressPolling  = "polling"  // waiting for the agent
+	ProgressFetching = "fetching" // fetching article content
+	ProgressSaving   = "saving"   // saving articles
+)
+
+// RunProgress is a progress event of a job run. Current and Total count
+// articles when fetching and saving, and seconds waited against the
+// timeout when polling.
+type RunProgress struct {
+	Stage   string
+	Current int
+	Total   int
+	Message string
+}
+
+// progressFunc is a RunOptions.ProgressCallback that may be nil.
+type progressFunc func(RunProgress)
+
+func (p progressFunc) report(stage string, current, total int, message string) {
+	if p != nil {
+		p(RunProgress{Stage: stage, Current: current, Total: total, Message: message})
+	}
+}
+
+// startDelay returns the longest a run of job may be delayed: the job's
+// schedule_jitter_secs, or Config.StartDelay if it has none.
+func (r *Runner) startDelay(job dbgen.Job) time.Duration {
+	if job.ScheduleJitterSecs != nil {
+		return time.Duration(*job.ScheduleJitterSecs) * time.Second
+	}
+	return r.config.StartDelay
+}
+
+// Run executes a job with the runner's configured defaults.
 func (r *Runner) Run(ctx context.Context, jobID int64) error {
-	// Random delay to stagger concurrent job starts
-	if r.config.StartDelay > 0 {
-		delay := time.Duration(rand.Int63n(int64(r.config.StartDelay)))
-		r.logger.Info("delaying job start", "delay", delay)
-		time.Sleep(delay)
+	return r.RunWithOptions(ctx, jobID, RunOptions{})
+}
+
+// RunWithOptions executes a job, applying opts to this run only.
+func (r *Runner) RunWithOptions(ctx context.Context, jobID int64, opts RunOptions) error {
+	timeout := r.config.JobTimeout
+	if opts.OverrideTimeout > 0 {
+		timeout = opts.OverrideTimeout
 	}
 
 	// Load job and preferences
@@ -154,6 +578,17 @@ func (r *Runner) Run(ctx context.Context, jobID int64) error {
 		return fmt.Errorf("job not found: %w", err)
 	}
 
+	// Random delay to stagger concurrent job starts
+	if maxDelay := r.startDelay(job); maxDelay > 0 && !opts.DisableStartDelay {
+		delay := r.randomDelay(maxDelay)
+		r.logger.Info("delaying job start", "delay", delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
 	prefs, err := r.queries.GetPreferences(ctx, job.UserID)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("get preferences: %w", err)
@@ -164,10 +599,11 @@ func (r *Runner) Run(ctx context.Context, jobID int64) error {
 		r.logger.Warn("cancel orphaned runs", "error", err)
 	}
 
-	// Create job run record
-	run, err := r.queries.CreateJobRun(ctx, jobID)
+	// Create the job run record once fewer than MaxConcurrentRuns runs are
+	// executing, so a burst of runs doesn't overwhelm the database
+	run, err := r.createRunInSlot(ctx, jobID)
 	if err != nil {
-		return fmt.Errorf("create job run: %w", err)
+		return err
 	}
 
 	// Update job status
@@ -188,9 +624,10 @@ func (r *Runner) Run(ctx context.Context, jobID int64) error {
 		"run_id", run.ID,
 		"job_name", job.Name,
 	)
+	r.recordEvent(ctx, jobID, run.ID, EventRunStarted, fmt.Sprintf("Run started for job %q", job.Name))
 
 	// Execute the job
-	result := r.executeJob(ctx, job, prefs)
+	result := r.executeJob(ctx, job, run.ID, prefs, timeout, opts.ProgressCallback)
 
 	// If the context was cancelled (e.g. SIGTERM during restart), leave the run
 	// in "running" state so it can be resumed on next startup. Don't finalize
@@ -212,67 +649,107 @@ func (r *Runner) Run(ctx context.Context, jobID int64) error {
 
 // JobResult holds the outcome of a job execution.
 type JobResult struct {
-	ArticlesSaved     int
-	DuplicatesSkipped int
-	ConversationID    string
-	Error             error
+	ArticlesSaved        int
+	DuplicatesSkipped    int
+	ConversationID       string
+	ConversationMessages int
+	EstimatedTokens      int
+	EstimatedCost        float64       // USD, from EstimatedTokens and the model's price
+	Duration             time.Duration // set by finalizeRun from the run's start time
+	FetchTelemetry       []FetchTelemetry
+	Error                error
+
+	conversation *Conversation // final conversation state, snapshotted in finalizeRun
 }
 
-func (r *Runner) executeJob(ctx context.Context, job dbgen.Job, prefs dbgen.Preference) JobResult {
+func (r *Runner) executeJob(ctx context.Context, job dbgen.Job, runID int64, prefs dbgen.Preference, timeout time.Duration, progress progressFunc) JobResult {
 	result := JobResult{}
-
-	// Build prompt
-	prompt := r.buildPrompt(job, prefs)
+	// The follow-up shares timeout with the first round rather than getting
+	// its own
+	deadline := time.Now().Add(timeout)
 
 	// Create articles directory
-	jobArticlesDir := filepath.Join(r.config.ArticlesDir, fmt.Sprintf("job_%d", job.ID))
+	jobArticlesDir := r.articlesDir(fmt.Sprintf("job_%d", job.ID), time.Now())
 	if err := os.MkdirAll(jobArticlesDir, 0755); err != nil {
 		result.Error = fmt.Errorf("create articles dir: %w", err)
 		return result
 	}
 
+	// Feed jobs read a known feed instead of starting a conversation
+	if job.FeedUrl != "" {
+		return r.executeFeedJob(ctx, job, jobArticlesDir, progress)
+	}
+
+	// Build prompt
+	prompt := r.buildPrompt(job, prefs)
+
 	// Check for existing conversation
 	convID, shouldCreate := r.checkExistingConversation(ctx, job)
 
+	// Every attempt to create the run's conversation sends the same key
+	idempotencyKey := generateIdempotencyKey(JobUserID(job.ID), job.ID, runID)
+
 	// Create new conversation if needed
 	if shouldCreate {
+		if err := r.usage.CheckQuota(ctx, job.UserID, prefs.ShelleyQuotaDaily); err != nil {
+			r.logger.Warn("not starting conversation", "user_id", job.UserID, "error", err)
+			result.Error = err
+			return result
+		}
 		var err error
-		convID, err = r.shelley.CreateConversation(ctx, job.ID, prompt)
+		convID, err = r.createConversation(ctx, job.ID, runID, prompt, idempotencyKey)
 		if err != nil {
-			result.Error = fmt.Errorf("create conversation: %w", err)
+			result.Error = err
 			return result
 		}
-		r.logger.Info("created conversation", "conversation_id", convID)
+		progress.report(ProgressPolling, 0, int(timeout.Seconds()), "created conversation "+convID)
 	}
 	result.ConversationID = convID
-
-	// Store conversation ID
-	r.queries.UpdateJobConversation(ctx, dbgen.UpdateJobConversationParams{
-		ID:                    job.ID,
-		CurrentConversationID: &convID,
-	})
+	r.storeConversationID(ctx, job.ID, runID, convID)
 
 	// Poll for completion
-	conv, err := r.pollForCompletion(ctx, job.ID, convID)
+	conv, err := r.pollForCompletion(ctx, job.ID, convID, timeout, progress)
+	if isShelleyStatus(err, http.StatusNotFound) {
+		// The conversation disappeared, e.g. removed by cleanup; start over
+		r.logger.Warn("conversation not found, creating new", "conversation_id", convID)
+		// Under a new key, since the run's key would replay the missing conversation
+		idempotencyKey = replacementIdempotencyKey(idempotencyKey, convID)
+		convID, err = r.createConversation(ctx, job.ID, runID, prompt, idempotencyKey)
+		if err == nil {
+			progress.report(ProgressPolling, 0, int(timeout.Seconds()), "created conversation "+convID)
+			result.ConversationID = convID
+			r.storeConversationID(ctx, job.ID, runID, convID)
+			conv, err = r.pollForCompletion(ctx, job.ID, convID, timeout, progress)
+		}
+	}
 	if err != nil {
 		result.Error = err
 		return result
 	}
 
-	// Extract articles from response
-	responseText := conv.GetLastAgentText()
-	articles, err := ExtractArticlesJSON(responseText)
+	r.setConversationStats(&result, conv)
+
+	// Extract articles from every agent message, not just the last, so
+	// results reported in earlier messages aren't lost
+	articles, err := ExtractConversationArticles(conv)
 	if err != nil {
 		r.logger.Error("extract articles JSON", "error", err)
 		result.Error = fmt.Errorf("failed to extract articles: %w", err)
 		return result
 	}
 
+	// Ask for more in the same conversation if the first round came up short
+	if r.config.EnableFollowUp && len(articles) < r.config.FollowUpMinArticles {
+		conv, articles = r.followUp(ctx, job.ID, convID, job.Prompt, conv, articles, time.Until(deadline), progress)
+		r.setConversationStats(&result, conv)
+	}
+
 	// Fetch content and save articles
 	if len(articles) > 0 {
-		saved, dups := r.processArticles(ctx, job, articles, jobArticlesDir)
+		saved, dups, telemetry := r.processArticles(ctx, job, articles, jobArticlesDir, r.config.MaxParallel, progress)
 		result.ArticlesSaved = saved
 		result.DuplicatesSkipped = dups
+		result.FetchTelemetry = telemetry
 	}
 
 	// Archive conversation
@@ -281,7 +758,165 @@ func (r *Runner) executeJob(ctx context.Context, job dbgen.Job, prefs dbgen.Pref
 	return result
 }
 
+// executeFeedJob fetches articles from the job's RSS/Atom feed, applies the
+// job's keyword filter and saves them.
+func (r *Runner) executeFeedJob(ctx context.Context, job dbgen.Job, jobArticlesDir string, progress progressFunc) JobResult {
+	result := JobResult{}
+
+	r.logger.Info("fetching feed", "feed_url", job.FeedUrl)
+	articles, err := r.fetcher.FetchFeedArticles(ctx, job.FeedUrl)
+	if err != nil {
+		result.Error = fmt.Errorf("fetch feed: %w", err)
+		return result
+	}
+
+	total := len(articles)
+	articles = filterArticlesByKeywords(articles, job.Keywords)
+	r.logger.Info("feed articles", "total", total, "matching_keywords", len(articles))
+
+	if len(articles) > 0 {
+		saved, dups, telemetry := r.processArticles(ctx, job, articles, jobArticlesDir, r.config.MaxParallel, progress)
+		result.ArticlesSaved = saved
+		result.DuplicatesSkipped = dups
+		result.FetchTelemetry = telemetry
+	}
+	return result
+}
+
+// maxPromptTemplateSize is the longest prompt template a job may have.
+const maxPromptTemplateSize = 10 * 1024
+
+// PromptData holds the values available to a job's prompt template.
+type PromptData struct {
+	Prompt       string
+	Keywords     string
+	Sources      string
+	Region       string
+	SystemPrompt string
+}
+
+// ValidatePromptTemplate reports whether tmpl is acceptable as a job's
+// prompt_template value. Empty means the default prompt.
+func ValidatePromptTemplate(tmpl string) error {
+	if len(tmpl) > maxPromptTemplateSize {
+		return fmt.Errorf("prompt template is longer than %d bytes", maxPromptTemplateSize)
+	}
+	t, err := template.New("prompt").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("parse prompt template: %w", err)
+	}
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil && usesWholeData(tt.Tree.Root) {
+			return errors.New("prompt template may only use named fields, not {{.}}")
+		}
+	}
+	// Catch references to fields PromptData doesn't have
+	if err := t.Execute(io.Discard, PromptData{}); err != nil {
+		return fmt.Errorf("render prompt template: %w", err)
+	}
+	return nil
+}
+
+// usesWholeData reports whether the template tree under node refers to dot or
+// $ by itself, as {{.}}, {{print .}} or {{$x := .}} do, rather than to one of
+// their fields.
+func usesWholeData(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return true
+	case *parse.VariableNode:
+		return len(n.Ident) == 1 && n.Ident[0] == "$"
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesWholeData(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesWholeData(n.Pipe)
+	case *parse.IfNode:
+		return usesWholeData(&n.BranchNode)
+	case *parse.RangeNode:
+		return usesWholeData(&n.BranchNode)
+	case *parse.WithNode:
+		return usesWholeData(&n.BranchNode)
+	case *parse.BranchNode:
+		return usesWholeData(n.Pipe) || usesWholeData(n.List) || usesWholeData(n.ElseList)
+	case *parse.TemplateNode:
+		return usesWholeData(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesWholeData(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesWholeData(arg) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return usesWholeData(n.Node)
+	}
+	return false
+}
+
+// renderPromptTemplate executes a job's prompt template with data.
+func renderPromptTemplate(tmpl string, data PromptData) (string, error) {
+	if err := ValidatePromptTemplate(tmpl); err != nil {
+		return "", err
+	}
+	t, err := template.New("prompt").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render prompt template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// BuildJobPrompt returns the prompt sent to Shelley for job: its prompt
+// template rendered, or BuildPrompt's when it has none. If the template fails
+// to render, BuildPrompt's prompt is returned along with the error.
+func BuildJobPrompt(job dbgen.Job, prefs dbgen.Preference) (string, error) {
+	if job.PromptTemplate == "" {
+		return BuildPrompt(job, prefs), nil
+	}
+	prompt, err := renderPromptTemplate(job.PromptTemplate, PromptData{
+		Prompt:       job.Prompt,
+		Keywords:     job.Keywords,
+		Sources:      job.Sources,
+		Region:       job.Region,
+		SystemPrompt: prefs.SystemPrompt,
+	})
+	if err != nil {
+		return BuildPrompt(job, prefs), err
+	}
+	return prompt, nil
+}
+
+// buildPrompt is BuildJobPrompt, logging a template that fell back to the
+// default prompt.
 func (r *Runner) buildPrompt(job dbgen.Job, prefs dbgen.Preference) string {
+	prompt, err := BuildJobPrompt(job, prefs)
+	if err != nil {
+		r.logger.Warn("prompt template failed, using default prompt", "job_id", job.ID, "error", err)
+	}
+	return prompt
+}
+
+// BuildPrompt returns the default prompt sent to Shelley when job runs for a user
+// with prefs.
+func BuildPrompt(job dbgen.Job, prefs dbgen.Preference) string {
 	var b strings.Builder
 
 	b.WriteString(`You are a news retrieval agent. Your task is to search the web for news articles based on the user's request.
@@ -319,9 +954,10 @@ func (r *Runner) buildPrompt(job dbgen.Job, prefs dbgen.Preference) string {
 1. Title
 2. URL  
 3. Brief summary (2-3 sentences)
+4. Author, publication date (ISO 8601) and publication name, when available
 
-Format your response as a JSON array ONLY (no other text):
-[{"title": "...", "url": "...", "summary": "..."}]
+Format your response as a JSON array ONLY (no other text). Leave out author, published_at and source when they are unknown:
+[{"title": "...", "url": "...", "summary": "...", "author": "...", "published_at": "2024-01-15T09:30:00Z", "source": "..."}]
 
 **IMPORTANT**: When using a subagent to search the web, always wait for it to fully complete its work before returning. Do not return until the subagent has finished and provided its full results.
 
@@ -353,12 +989,106 @@ func (r *Runner) checkExistingConversation(ctx context.Context, job dbgen.Job) (
 	return convID, false
 }
 
-func (r *Runner) pollForCompletion(ctx context.Context, jobID int64, convID string) (*Conversation, error) {
-	timeout := time.After(r.config.JobTimeout)
-	ticker := time.NewTicker(r.config.PollInterval)
-	defer ticker.Stop()
+// createConversationAttempts is how many times createConversation tries to
+// create a conversation that fails without a response or with a 5xx.
+const createConversationAttempts = 3
+
+// createConversation starts a Shelley conversation for a job run under
+// idempotencyKey, which it first records on the run. A failed create is
+// retried with the same key, PollInterval apart, if Shelley may have
+// created the conversation anyway, so the retry returns it rather than
+// starting a second one.
+func (r *Runner) createConversation(ctx context.Context, jobID, runID int64, prompt, idempotencyKey string) (string, error) {
+	if err := r.queries.UpdateJobRunIdempotencyKey(ctx, dbgen.UpdateJobRunIdempotencyKeyParams{
+		IdempotencyKey: idempotencyKey,
+		ID:             runID,
+	}); err != nil {
+		r.logger.Warn("failed to store idempotency key", "run_id", runID, "error", err)
+	}
+
+	var convID string
+	var err error
+	for attempt := 1; ; attempt++ {
+		convID, err = r.shelley.CreateConversationWithKey(ctx, JobUserID(jobID), r.config.Model, prompt, idempotencyKey)
+		if err == nil || attempt == createConversationAttempts || !isRetryableCreateError(err) {
+			break
+		}
+		r.logger.Warn("create conversation failed, retrying", "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("create conversation: %w", ctx.Err())
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("create conversation: %w", err)
+	}
+	r.logger.Info("created conversation", "conversation_id", convID)
+	r.recordEvent(ctx, jobID, runID, EventConversationCreated, convID)
+	return convID, nil
+}
+
+// isRetryableCreateError reports whether a conversation create that failed
+// with err may have gone through: it got no response, e.g. it timed out, or
+// a 5xx.
+func isRetryableCreateError(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var shelleyErr *ShelleyError
+	if errors.As(err, &shelleyErr) {
+		return shelleyErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// storeConversationID records the conversation a job and run are using.
+func (r *Runner) storeConversationID(ctx context.Context, jobID, runID int64, convID string) {
+	r.queries.UpdateJobConversation(ctx, dbgen.UpdateJobConversationParams{
+		ID:                    jobID,
+		CurrentConversationID: &convID,
+	})
+	r.queries.UpdateJobRunConversation(ctx, dbgen.UpdateJobRunConversationParams{
+		ConversationID: convID,
+		ID:             runID,
+	})
+}
+
+// setConversationStats records conv and its message, token and cost
+// estimates on result. A model without a price in ModelCostMap leaves the
+// cost at 0 and logs a warning, so the missing price doesn't go unnoticed.
+func (r *Runner) setConversationStats(result *JobResult, conv *Conversation) {
+	result.conversation = conv
+	result.ConversationMessages, result.EstimatedTokens = conv.GetMessageStats()
+	if _, ok := r.config.ModelCostMap[r.config.Model]; !ok {
+		r.logger.Warn("model has no price in ModelCostMap, not estimating cost", "model", r.config.Model, "estimated_tokens", result.EstimatedTokens)
+		return
+	}
+	result.EstimatedCost = float64(result.EstimatedTokens) * r.config.CostPerToken(r.config.Model)
+}
+
+// maxShelleyServerErrors is how many consecutive 5xx responses polling
+// tolerates before failing the job.
+const maxShelleyServerErrors = 5
+
+// pollForCompletion polls a conversation until the agent finishes. A 429
+// delays the next poll by the response's Retry-After, a 404 is returned
+// at once so the caller can start a new conversation, and more than
+// maxShelleyServerErrors 5xx responses in a row fail the job. While the
+// client's circuit breaker is open polls are skipped, which doesn't count
+// as an error; only the job timeout ends the wait.
+func (r *Runner) pollForCompletion(ctx context.Context, jobID int64, convID string, jobTimeout time.Duration, progress progressFunc) (*Conversation, error) {
+	timeout := time.After(jobTimeout)
+	checkInterval := r.config.StatusCheckInterval
+	if checkInterval <= 0 {
+		checkInterval = r.config.PollInterval
+	}
+	interval := checkInterval
+	poll := time.NewTimer(interval)
+	defer poll.Stop()
 
 	waited := time.Duration(0)
+	serverErrors := 0
 
 	for {
 		select {
@@ -368,52 +1098,131 @@ func (r *Runner) pollForCompletion(ctx context.Context, jobID int64, convID stri
 		case <-timeout:
 			// Try to cancel stuck conversation
 			r.shelley.DeleteConversation(ctx, jobID, convID)
-			return nil, fmt.Errorf("job timed out after %v", r.config.JobTimeout)
-
-		case <-ticker.C:
-			waited += r.config.PollInterval
-
-			conv, err := r.shelley.GetConversation(ctx, jobID, convID)
+			return nil, fmt.Errorf("job timed out after %v", jobTimeout)
+
+		case <-poll.C:
+			waited += interval
+			interval = checkInterval
+
+			// Only the status is fetched until the agent stops working
+			var conv *Conversation
+			status, err := r.shelley.GetConversationStatus(ctx, jobID, convID)
+			if err == nil && !status.Working {
+				conv = status.conversation
+				if conv == nil {
+					conv, err = r.shelley.GetConversation(ctx, jobID, convID)
+				}
+			}
 			if err != nil {
-				r.logger.Warn("poll conversation", "error", err, "waited", waited)
+				var shelleyErr *ShelleyError
+				errors.As(err, &shelleyErr)
+				switch {
+				case errors.Is(err, ErrCircuitOpen):
+					r.logger.Debug("Shelley unreachable, skipping poll", "waited", waited)
+				case shelleyErr == nil:
+					r.logger.Warn("poll conversation", "error", err, "waited", waited)
+				case shelleyErr.StatusCode == http.StatusNotFound:
+					return nil, fmt.Errorf("poll conversation: %w", err)
+				case shelleyErr.StatusCode == http.StatusTooManyRequests:
+					if shelleyErr.RetryAfter != nil {
+						interval = max(interval, *shelleyErr.RetryAfter)
+					}
+					r.logger.Warn("rate limited by Shelley", "retry_in", interval, "waited", waited)
+				case shelleyErr.StatusCode >= 500:
+					serverErrors++
+					if serverErrors > maxShelleyServerErrors {
+						return nil, fmt.Errorf("poll conversation: %d consecutive server errors: %w", serverErrors, err)
+					}
+					r.logger.Warn("poll conversation", "error", err, "consecutive", serverErrors, "waited", waited)
+				default:
+					r.logger.Warn("poll conversation", "error", err, "waited", waited)
+				}
+				poll.Reset(interval)
 				continue
 			}
+			serverErrors = 0
 
-			if conv.IsComplete() {
+			if conv != nil && conv.IsComplete() {
 				r.logger.Info("agent finished", "waited", waited)
 				return conv, nil
 			}
 
 			r.logger.Debug("waiting for agent", "waited", waited)
+			progress.report(ProgressPolling, int(waited.Seconds()), int(jobTimeout.Seconds()), "waiting for agent")
+			poll.Reset(interval)
 		}
 	}
 }
 
+// ProcessArticlesOptions tunes a single ProcessArticles call.
+type ProcessArticlesOptions struct {
+	MaxParallel int // max concurrent article fetches; 0 uses Config.MaxParallel
+}
+
 // ProcessArticles processes and saves articles for a job (public wrapper).
-func (r *Runner) ProcessArticles(ctx context.Context, jobID int64, articles []ArticleInfo) (saved, dups int, err error) {
+func (r *Runner) ProcessArticles(ctx context.Context, jobID int64, articles []ArticleInfo, opts ProcessArticlesOptions) (saved, dups int, err error) {
 	job, err := r.queries.GetJobByID(ctx, jobID)
 	if err != nil {
 		return 0, 0, fmt.Errorf("get job: %w", err)
 	}
 
 	// Create articles directory for this user
-	articlesDir := filepath.Join(r.config.ArticlesDir, fmt.Sprintf("user_%d", job.UserID))
+	articlesDir := r.articlesDir(fmt.Sprintf("user_%d", job.UserID), time.Now())
 	if err := os.MkdirAll(articlesDir, 0755); err != nil {
 		return 0, 0, fmt.Errorf("create articles dir: %w", err)
 	}
 
-	saved, dups = r.processArticles(ctx, job, articles, articlesDir)
+	maxParallel := opts.MaxParallel
+	if maxParallel <= 0 {
+		maxParallel = r.config.MaxParallel
+	}
+
+	saved, dups, _ = r.processArticles(ctx, job, articles, articlesDir, maxParallel, nil)
 	return saved, dups, nil
 }
 
-func (r *Runner) processArticles(ctx context.Context, job dbgen.Job, articles []ArticleInfo, articlesDir string) (saved, dups int) {
-	timestamp := time.Now().Format("20060102_150405")
+// articlesDir returns the directory article files saved at now go in: name
+// under ArticlesDir, with a YYYY/MM subdirectory if ArticleDateDirs is set so
+// that busy jobs don't pile every file into one directory.
+func (r *Runner) articlesDir(name string, now time.Time) string {
+	dir := filepath.Join(r.config.ArticlesDir, name)
+	if r.config.ArticleDateDirs {
+		dir = filepath.Join(dir, now.Format("2006"), now.Format("01"))
+	}
+	return dir
+}
+
+func (r *Runner) processArticles(ctx context.Context, job dbgen.Job, articles []ArticleInfo, articlesDir string, maxParallel int, progress progressFunc) (saved, dups int, telemetry []FetchTelemetry) {
+	// Microseconds keep back-to-back calls, as in process-articles --dir,
+	// from overwriting each other's files
+	now := time.Now()
+	timestamp := fmt.Sprintf("%s_%06d", now.Format("20060102_150405"), now.Nanosecond()/1000)
+
+	// Drop malformed or suspicious URLs before fetching or writing anything
+	valid := make([]ArticleInfo, 0, len(articles))
+	for _, info := range articles {
+		info.URL = SanitizeArticleURL(info.URL)
+		if info.URL != "" {
+			if err := validateArticleURL(info.URL); err != nil {
+				r.logger.Warn("skipping article with invalid URL", "title", info.Title, "url", info.URL, "error", err)
+				continue
+			}
+		}
+		valid = append(valid, info)
+	}
+	articles = valid
 
 	// Fetch content in parallel
-	contents := r.fetchArticleContents(ctx, articles)
+	contents, telemetry := r.fetchArticleContents(ctx, articles, maxParallel, r.jobFetchHeaders(job), progress)
+	r.logFetchSummary(telemetry)
 
 	for i, info := range articles {
 		content := contents[i]
+		progress.report(ProgressSaving, i+1, len(articles), info.Title)
+
+		// Pin the retrieval time so the file and the row agree
+		retrievedAt := info.retrievedAt()
+		info.RetrievedAt = &retrievedAt
 
 		// Create article file
 		articleFile := filepath.Join(articlesDir, fmt.Sprintf("article_%d_%s.txt", i+1, timestamp))
@@ -423,7 +1232,7 @@ func (r *Runner) processArticles(ctx context.Context, job dbgen.Job, articles []
 		}
 
 		// Insert into database
-		inserted, err := r.insertArticle(ctx, job, info, articleFile)
+		inserted, err := r.insertArticle(ctx, job, info, articleFile, content)
 		if err != nil {
 			r.logger.Warn("insert article", "error", err)
 			continue
@@ -438,41 +1247,181 @@ func (r *Runner) processArticles(ctx context.Context, job dbgen.Job, articles []
 		}
 	}
 
-	return saved, dups
+	return saved, dups, telemetry
+}
+
+// fetchHeaders are the custom request headers a job sends when fetching
+// article content.
+type fetchHeaders struct {
+	headers map[string]string
+	domains []string // hosts the headers may be sent to; empty means any
+}
+
+// forURL returns the headers to send to rawURL. Hosts outside the job's
+// domains get none, so credentials aren't leaked to arbitrary sites.
+func (h fetchHeaders) forURL(rawURL string) map[string]string {
+	if len(h.headers) == 0 || !urlMatchesDomains(rawURL, h.domains) {
+		return nil
+	}
+	return h.headers
+}
+
+// jobFetchHeaders parses a job's custom fetch headers. Invalid headers are
+// logged and ignored rather than failing the run.
+func (r *Runner) jobFetchHeaders(job dbgen.Job) fetchHeaders {
+	headers, err := parseJobFetchHeaders(job.FetchHeaders)
+	if err != nil {
+		r.logger.Warn("ignoring invalid fetch headers", "job_id", job.ID, "error", err)
+		return fetchHeaders{}
+	}
+	return fetchHeaders{headers: headers, domains: parseDomainList(job.FetchHeaderDomains)}
+}
+
+// slowFetchThreshold is how long an article fetch may take before the run
+// warns about it.
+const slowFetchThreshold = 10 * time.Second
+
+// FetchTelemetry records how a single article fetch went.
+type FetchTelemetry struct {
+	URL           string
+	Duration      time.Duration
+	StatusCode    int    // 0 if no response was received
+	Error         string // empty on success
+	ContentLength int    // bytes of extracted content
 }
 
-func (r *Runner) fetchArticleContents(ctx context.Context, articles []ArticleInfo) []string {
-	contents := make([]string, len(articles))
+// fetchArticleContents fetches article bodies through the fetcher's
+// strategy chain with at most maxParallel fetches in flight, sending the
+// job's custom headers where allowed. It returns the contents in article
+// order along with the telemetry of every URL fetched. progress is told of
+// each fetch as it finishes.
+func (r *Runner) fetchArticleContents(ctx context.Context, articles []ArticleInfo, maxParallel int, headers fetchHeaders, progress progressFunc) ([]ArticleContent, []FetchTelemetry) {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	contents := make([]ArticleContent, len(articles))
+	telemetry := make([]FetchTelemetry, len(articles))
+	ctx = withFetchHeaderDomains(ctx, headers.domains)
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, r.config.MaxParallel)
+	sem := make(chan struct{}, maxParallel)
+	var progressMu sync.Mutex // serializes progress calls
+	done := 0
 
 	for i, info := range articles {
 		if info.URL == "" {
-			contents[i] = "(No URL provided)"
+			contents[i] = ArticleContent{Text: "(No URL provided)"}
 			continue
 		}
 
 		wg.Add(1)
 		go func(idx int, url string) {
 			defer wg.Done()
-			sem <- struct{}{}
+			// Fetches still waiting for a slot when the run is cancelled
+			// give up rather than queue behind ones that may be slow to stop
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				contents[idx] = ArticleContent{Text: fmt.Sprintf("[Error fetching article: %v]", ctx.Err())}
+				telemetry[idx] = FetchTelemetry{URL: url, Error: ctx.Err().Error()}
+				return
+			}
 			defer func() { <-sem }()
 
 			r.logger.Info("fetching content", "url", url)
-			content, err := FetchArticleContent(ctx, url)
+			start := time.Now()
+			content, err := r.fetcher.fetchWithStrategies(ctx, articles[idx], headers.forURL(url))
+			t := FetchTelemetry{URL: url, Duration: time.Since(start)}
 			if err != nil {
-				contents[idx] = fmt.Sprintf("[Error fetching article: %v]", err)
+				contents[idx] = ArticleContent{Text: fmt.Sprintf("[Error fetching article: %v]", err)}
+				t.Error = err.Error()
+				var statusErr *HTTPStatusError
+				if errors.As(err, &statusErr) {
+					t.StatusCode = statusErr.StatusCode
+				}
 			} else {
 				contents[idx] = content
+				t.StatusCode = http.StatusOK
+				t.ContentLength = len(content.Text)
+			}
+			telemetry[idx] = t
+
+			if progress != nil {
+				progressMu.Lock()
+				done++
+				progress.report(ProgressFetching, done, len(articles), url)
+				progressMu.Unlock()
 			}
 		}(i, info.URL)
 	}
 
 	wg.Wait()
-	return contents
+
+	// Articles without a URL weren't fetched
+	fetched := telemetry[:0]
+	for _, t := range telemetry {
+		if t.URL != "" {
+			fetched = append(fetched, t)
+		}
+	}
+	return contents, fetched
 }
 
-func (r *Runner) writeArticleFile(path string, info ArticleInfo, content string) error {
+// logFetchSummary logs the slowest fetch, the average fetch time and any
+// failed URLs.
+func (r *Runner) logFetchSummary(telemetry []FetchTelemetry) {
+	if len(telemetry) == 0 {
+		return
+	}
+
+	var total time.Duration
+	slowest := telemetry[0]
+	var failed []string
+	for _, t := range telemetry {
+		total += t.Duration
+		if t.Duration > slowest.Duration {
+			slowest = t
+		}
+		if t.Error != "" {
+			failed = append(failed, t.URL)
+		}
+	}
+
+	r.logger.Info("fetch summary",
+		"fetched", len(telemetry),
+		"average_duration", total/time.Duration(len(telemetry)),
+		"slowest_url", slowest.URL,
+		"slowest_duration", slowest.Duration,
+		"failed", len(failed))
+	if len(failed) > 0 {
+		r.logger.Warn("article fetches failed", "urls", failed)
+	}
+}
+
+// recordFetchTelemetry warns about slow fetches and stores the run's fetch
+// telemetry for later analysis.
+func (r *Runner) recordFetchTelemetry(ctx context.Context, jobID, runID int64, telemetry []FetchTelemetry) {
+	for _, t := range telemetry {
+		if t.Duration > slowFetchThreshold {
+			r.logger.Warn("slow article fetch", "url", t.URL, "duration", t.Duration, "status_code", t.StatusCode)
+		}
+
+		err := r.queries.InsertFetchTelemetry(ctx, dbgen.InsertFetchTelemetryParams{
+			JobID:         jobID,
+			RunID:         &runID,
+			Url:           t.URL,
+			DurationMs:    t.Duration.Milliseconds(),
+			StatusCode:    int64(t.StatusCode),
+			Error:         t.Error,
+			ContentLength: int64(t.ContentLength),
+		})
+		if err != nil {
+			r.logger.Warn("record fetch telemetry", "url", t.URL, "error", err)
+		}
+	}
+}
+
+func (r *Runner) writeArticleFile(path string, info ArticleInfo, content ArticleContent) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
@@ -481,18 +1430,30 @@ func (r *Runner) writeArticleFile(path string, info ArticleInfo, content string)
 
 	fmt.Fprintf(f, "Title: %s\n", info.Title)
 	fmt.Fprintf(f, "URL: %s\n", info.URL)
-	fmt.Fprintf(f, "Retrieved: %s\n", time.Now().Format(time.RFC3339))
+	if info.Author != "" {
+		fmt.Fprintf(f, "Author: %s\n", info.Author)
+	}
+	if info.PublishedAt != "" {
+		fmt.Fprintf(f, "Published: %s\n", info.PublishedAt)
+	}
+	if info.Source != "" {
+		fmt.Fprintf(f, "Source: %s\n", info.Source)
+	}
+	if content.ImageURL != "" {
+		fmt.Fprintf(f, "Image: %s\n", content.ImageURL)
+	}
+	fmt.Fprintf(f, "Retrieved: %s\n", info.retrievedAt().Format(time.RFC3339))
 	fmt.Fprintln(f)
 	fmt.Fprintln(f, "--- Summary ---")
 	fmt.Fprintln(f, info.Summary)
 	fmt.Fprintln(f)
 	fmt.Fprintln(f, "--- Full Content ---")
-	fmt.Fprintln(f, content)
+	fmt.Fprintln(f, content.Text)
 
 	return nil
 }
 
-func (r *Runner) insertArticle(ctx context.Context, job dbgen.Job, info ArticleInfo, contentPath string) (bool, error) {
+func (r *Runner) insertArticle(ctx context.Context, job dbgen.Job, info ArticleInfo, contentPath string, content ArticleContent) (bool, error) {
 	// Check if article already exists (by URL)
 	exists, err := r.queries.ArticleExistsByURL(ctx, dbgen.ArticleExistsByURLParams{
 		UserID: job.UserID,
@@ -512,6 +1473,12 @@ func (r *Runner) insertArticle(ctx context.Context, job dbgen.Job, info ArticleI
 		Url:         info.URL,
 		Summary:     info.Summary,
 		ContentPath: contentPath,
+		Author:      info.Author,
+		PublishedAt: info.PublishedAt,
+		Source:      info.Source,
+		ImageUrl:    content.ImageURL,
+		SourceName:  content.SourceName,
+		RetrievedAt: info.retrievedAt(),
 	})
 	if err != nil {
 		return false, err
@@ -547,21 +1514,43 @@ func (r *Runner) cancelOrphanedRuns(ctx context.Context, jobID int64) error {
 	return r.queries.CancelOrphanedRuns(ctx, jobID)
 }
 
+// nextScheduledRun returns when a recurring job runs after a run finishing
+// at now. A run that was due is followed by its scheduled time plus the
+// frequency, so a long run doesn't push the schedule back; intervals missed
+// entirely are skipped. Runs started early, such as manual ones, count from
+// now.
+func nextScheduledRun(job dbgen.Job, now time.Time) time.Time {
+	if job.NextRunAt == nil || job.NextRunAt.After(now) {
+		return util.CalculateNextRunFrom(job.Frequency, false, now)
+	}
+	next := util.CalculateNextRunFrom(job.Frequency, false, *job.NextRunAt)
+	for !next.After(now) {
+		next = util.CalculateNextRunFrom(job.Frequency, false, next)
+	}
+	return next
+}
+
 func (r *Runner) finalizeRun(ctx context.Context, job dbgen.Job, runID int64, result JobResult, prefs dbgen.Preference) {
 	// Check if run is still in running state (prevent double finalization)
 	var currentStatus string
-	err := r.db.QueryRowContext(ctx, "SELECT status FROM job_runs WHERE id = ?", runID).Scan(&currentStatus)
+	var startedAt time.Time
+	err := r.db.QueryRowContext(ctx, "SELECT status, started_at FROM job_runs WHERE id = ?", runID).Scan(&currentStatus, &startedAt)
 	if err != nil || currentStatus != "running" {
 		r.logger.Info("run already finalized, skipping", "run_id", runID, "current_status", currentStatus)
 		return
 	}
 
 	now := time.Now()
+	result.Duration = now.Sub(startedAt)
 
 	// Determine run status
 	var runStatus string
 	var errorMsg string
-	if result.Error != nil {
+	quotaExceeded := errors.Is(result.Error, ErrQuotaExceeded)
+	if quotaExceeded {
+		runStatus = util.StatusQuotaExceeded
+		errorMsg = result.Error.Error()
+	} else if result.Error != nil {
 		runStatus = util.StatusFailed
 		errorMsg = result.Error.Error()
 	} else if result.ArticlesSaved == 0 {
@@ -574,23 +1563,50 @@ func (r *Runner) finalizeRun(ctx context.Context, job dbgen.Job, runID int64, re
 	articlesSaved := int64(result.ArticlesSaved)
 	duplicatesSkipped := int64(result.DuplicatesSkipped)
 	r.queries.UpdateJobRunComplete(ctx, dbgen.UpdateJobRunCompleteParams{
-		ID:                runID,
-		Status:            runStatus,
-		ErrorMessage:      &errorMsg,
-		ArticlesSaved:     &articlesSaved,
-		DuplicatesSkipped: &duplicatesSkipped,
+		ID:                   runID,
+		Status:               runStatus,
+		ErrorMessage:         &errorMsg,
+		ArticlesSaved:        &articlesSaved,
+		DuplicatesSkipped:    &duplicatesSkipped,
+		ConversationMessages: int64(result.ConversationMessages),
+		EstimatedTokens:      int64(result.EstimatedTokens),
+		EstimatedCostUsd:     result.EstimatedCost,
+		ConversationID:       result.ConversationID,
 	})
+	if runStatus == util.StatusCompleted || runStatus == "completed_no_new" {
+		if err := r.queries.UpdateJobLastSuccess(ctx, dbgen.UpdateJobLastSuccessParams{LastSuccessAt: &now, ID: job.ID}); err != nil {
+			r.logger.Warn("record last success", "error", err)
+		}
+	}
+
+	// Keep a copy of the conversation in case it is archived in Shelley
+	if result.conversation != nil {
+		r.saveConversationSnapshot(ctx, runID, result.conversation)
+	}
+
+	r.recordFetchTelemetry(ctx, job.ID, runID, result.FetchTelemetry)
+
+	// Record the run summary in the audit log
+	r.recordEvent(ctx, job.ID, runID, EventArticlesSaved,
+		fmt.Sprintf("%d articles saved, %d duplicates skipped", result.ArticlesSaved, result.DuplicatesSkipped))
+	if result.Error != nil && !quotaExceeded {
+		r.recordEvent(ctx, job.ID, runID, EventRunFailed, errorMsg)
+	} else {
+		r.recordEvent(ctx, job.ID, runID, EventRunCompleted, runStatus)
+	}
 
-	// Calculate next run time
+	// Calculate next run time; a run skipped for quota tries again on schedule
 	var nextRunAt *time.Time
-	if job.IsOneTime == 0 && result.Error == nil {
-		next := util.CalculateNextRun(job.Frequency, false)
+	if job.IsOneTime == 0 && (result.Error == nil || quotaExceeded) {
+		next := nextScheduledRun(job, now)
 		nextRunAt = &next
 	}
 
 	// Update job status
 	jobStatus := util.StatusCompleted
-	if result.Error != nil {
+	if quotaExceeded {
+		jobStatus = util.StatusQuotaExceeded
+	} else if result.Error != nil {
 		jobStatus = util.StatusFailed
 	}
 
@@ -613,31 +1629,53 @@ func (r *Runner) finalizeRun(ctx context.Context, job dbgen.Job, runID int64, re
 		CurrentConversationID: &emptyConvID,
 	})
 
-	// Send notifications
-	r.sendNotification(prefs, job.Name, result)
+	// Send notifications; running out of quota isn't a failure to report
+	if !quotaExceeded && r.sendNotification(ctx, prefs, job.Name, result) {
+		r.recordEvent(ctx, job.ID, runID, EventNotificationSent, "discord")
+	}
 
 	r.logger.Info("job run completed",
 		"status", runStatus,
 		"articles_saved", result.ArticlesSaved,
 		"duplicates_skipped", result.DuplicatesSkipped,
+		"conversation_messages", result.ConversationMessages,
+		"estimated_tokens", result.EstimatedTokens,
+		"estimated_cost_usd", result.EstimatedCost,
 	)
 }
 
 
-func (r *Runner) sendNotification(prefs dbgen.Preference, jobName string, result JobResult) {
-	if prefs.DiscordWebhook == "" {
+// saveConversationSnapshot stores a filtered copy of a run's conversation.
+func (r *Runner) saveConversationSnapshot(ctx context.Context, runID int64, conv *Conversation) {
+	snapshot, err := json.Marshal(NewConversationView(conv))
+	if err != nil {
+		r.logger.Warn("marshal conversation snapshot", "error", err)
 		return
 	}
+	if err := r.queries.UpdateJobRunSnapshot(ctx, dbgen.UpdateJobRunSnapshotParams{
+		ConversationSnapshot: string(snapshot),
+		ID:                   runID,
+	}); err != nil {
+		r.logger.Warn("save conversation snapshot", "run_id", runID, "error", err)
+	}
+}
+
+// sendNotification notifies the user of a run's outcome and reports whether a
+// notification was delivered, or queued when batching is enabled.
+func (r *Runner) sendNotification(ctx context.Context, prefs dbgen.Preference, jobName string, result JobResult) bool {
+	if prefs.DiscordWebhook == "" {
+		return false
+	}
 
 	var msg string
 	if result.Error != nil {
 		if prefs.NotifyFailure == 0 {
-			return
+			return false
 		}
 		msg = fmt.Sprintf("❌ News job '%s' failed: %v", jobName, result.Error)
 	} else {
 		if prefs.NotifySuccess == 0 {
-			return
+			return false
 		}
 		if result.ArticlesSaved == 0 {
 			msg = fmt.Sprintf("ℹ️ News job '%s' completed - no new articles found", jobName)
@@ -646,12 +1684,46 @@ func (r *Runner) sendNotification(prefs dbgen.Preference, jobName string, result
 		}
 	}
 
+	// Embeds carry their own fields, so they aren't batched with text
+	if prefs.UseDiscordEmbeds == 1 {
+		if err := SendDiscordEmbed(prefs.DiscordWebhook, jobResultEmbed(jobName, result)); err != nil {
+			r.logger.Warn("send discord embed", "error", err)
+			return false
+		}
+		return true
+	}
+	if r.batcher != nil {
+		err := r.batcher.Send(ctx, prefs.DiscordWebhook, msg)
+		if err == nil {
+			return true
+		}
+		r.logger.Warn("queue discord notification; sending it directly", "error", err)
+	}
 	if err := SendDiscordNotification(prefs.DiscordWebhook, msg); err != nil {
 		r.logger.Warn("send discord notification", "error", err)
+		return false
+	}
+	return true
+}
+
+// recordEvent appends an entry to the job_events audit log. Failures are only
+// logged so that auditing never interrupts a run.
+func (r *Runner) recordEvent(ctx context.Context, jobID, runID int64, eventType, message string) {
+	err := r.queries.InsertJobEvent(ctx, dbgen.InsertJobEventParams{
+		JobID:     jobID,
+		RunID:     &runID,
+		EventType: eventType,
+		Message:   message,
+	})
+	if err != nil {
+		r.logger.Warn("record job event", "event_type", eventType, "error", err)
 	}
 }
 
 func (r *Runner) setupLogging(runID int64) error {
+	if r.noFileLogging {
+		return nil
+	}
 	if err := os.MkdirAll(r.config.LogsDir, 0755); err != nil {
 		return err
 	}
@@ -670,7 +1742,12 @@ func (r *Runner) setupLogging(runID int64) error {
 		return err
 	}
 
-	// Create multi-writer for stdout + file
+	// Log to the file and to stdout or the injected logger
+	fileHandler := slog.NewTextHandler(r.logFile, &slog.HandlerOptions{Level: slog.LevelInfo})
+	if r.customLogger != nil {
+		r.logger = slog.New(teeHandler{fileHandler, r.customLogger.Handler()})
+		return nil
+	}
 	multiWriter := io.MultiWriter(os.Stdout, r.logFile)
 	r.logger = slog.New(slog.NewTextHandler(multiWriter, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -680,6 +1757,10 @@ func (r *Runner) setupLogging(runID int64) error {
 }
 
 func (r *Runner) setupLoggingAppend(logPath string) error {
+	if r.noFileLogging {
+		return nil
+	}
+
 	// Open existing log file in append mode
 	var err error
 	r.logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
@@ -687,6 +1768,11 @@ func (r *Runner) setupLoggingAppend(logPath string) error {
 		return err
 	}
 
+	if r.customLogger != nil {
+		r.logger = slog.New(teeHandler{slog.NewTextHandler(r.logFile, nil), r.customLogger.Handler()})
+		return nil
+	}
+
 	// Create multi-writer: log to both file and original logger
 	multi := io.MultiWriter(r.logFile, os.Stderr)
 
@@ -703,20 +1789,117 @@ func (r *Runner) closeLogging() {
 	}
 }
 
+// teeHandler passes each record to every handler that accepts its level.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
 // ArticleInfo holds metadata about an article from the agent's response.
 type ArticleInfo struct {
-	Title   string `json:"title"`
-	URL     string `json:"url"`
-	Summary string `json:"summary"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Summary     string `json:"summary"`
+	Author      string `json:"author,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"` // ISO 8601, as reported by the agent
+	Source      string `json:"source,omitempty"`       // publication name
+	// RetrievedAt overrides the time the article is recorded as retrieved,
+	// e.g. when importing articles saved elsewhere. Nil means now. It is
+	// never decoded from agent output, so the agent can't backdate articles.
+	RetrievedAt *time.Time `json:"-"`
 }
 
-// ExtractArticlesJSON extracts and parses the JSON array from agent response.
-func ExtractArticlesJSON(text string) ([]ArticleInfo, error) {
-	jsonStr, err := extractJSONArray(text)
-	if err != nil {
-		return nil, err
+// retrievedAt returns when info counts as retrieved, in UTC to the second
+// like CURRENT_TIMESTAMP.
+func (info ArticleInfo) retrievedAt() time.Time {
+	t := time.Now()
+	if info.RetrievedAt != nil {
+		t = *info.RetrievedAt
 	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// ExtractArticlesJSON extracts and parses the articles array from an agent
+// response. When the response holds several arrays (e.g. a list of steps
+// before the results) they are tried from last to first, and the first one
+// that parses into at least one article wins. A response whose arrays parse
+// but are all empty yields no articles and no error.
+//
+// If no array parses, the response is read as a plain-text listing with
+// ExtractArticlesFromText, and textFallback reports that it was.
+func ExtractArticlesJSON(text string) (articles []ArticleInfo, textFallback bool, err error) {
+	articles, err = extractArticlesArray(text)
+	if err == nil {
+		return articles, false, nil
+	}
+	if listed, ok := ExtractArticlesFromText(text); ok {
+		slog.Warn("no JSON articles in agent response, using plain-text listing", "error", err, "articles", len(listed))
+		return listed, true, nil
+	}
+	return nil, false, err
+}
 
+// extractArticlesArray is the JSON part of ExtractArticlesJSON.
+func extractArticlesArray(text string) ([]ArticleInfo, error) {
+	candidates := findAllJSONArrays(stripCodeBlocks(text))
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no JSON array found in response")
+	}
+
+	var lastErr error
+	parsedEmpty := false
+	for i := len(candidates) - 1; i >= 0; i-- {
+		articles, err := parseArticlesArray(candidates[i])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(articles) > 0 {
+			return articles, nil
+		}
+		parsedEmpty = true
+	}
+	if parsedEmpty {
+		return []ArticleInfo{}, nil
+	}
+	return nil, lastErr
+}
+
+// parseArticlesArray unmarshals a JSON array of articles, retrying with
+// fixMalformedJSON if it doesn't parse as-is.
+func parseArticlesArray(jsonStr string) ([]ArticleInfo, error) {
 	var articles []ArticleInfo
 	if err := json.Unmarshal([]byte(jsonStr), &articles); err != nil {
 		// Try fixing malformed JSON
@@ -725,6 +1908,46 @@ func ExtractArticlesJSON(text string) ([]ArticleInfo, error) {
 			return nil, fmt.Errorf("parse JSON: %w", err)
 		}
 	}
+	return articles, nil
+}
 
+// ExtractConversationArticles extracts articles from every agent text block
+// in conv, oldest first. Articles repeated in later messages (for example a
+// final list that restates partial results) are only returned once. Blocks
+// without an article array are skipped; an error is returned only when no
+// block has one.
+func ExtractConversationArticles(conv *Conversation) ([]ArticleInfo, error) {
+	texts := conv.GetAllAgentText()
+	if len(texts) == 0 {
+		articles, _, err := ExtractArticlesJSON("")
+		return articles, err
+	}
+
+	var articles []ArticleInfo
+	var lastErr error
+	found := false
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		extracted, _, err := ExtractArticlesJSON(text)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		found = true
+		for _, a := range extracted {
+			key := a.URL
+			if key == "" {
+				key = "title:" + a.Title
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			articles = append(articles, a)
+		}
+	}
+	if !found {
+		return nil, lastErr
+	}
 	return articles, nil
 }
@@ -0,0 +1,1092 @@
+package jobrunner
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"go.uber.org/goleak"
+)
+
+// TestMain fails the package's tests if any goroutine outlives them.
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestFetchArticleContentsMaxParallel(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		fmt.Fprint(w, "<html><body><article><p>Article body text.</p></article></body></html>")
+	}))
+	defer srv.Close()
+
+	articles := make([]ArticleInfo, 5)
+	for i := range articles {
+		articles[i] = ArticleInfo{Title: fmt.Sprintf("Article %d", i), URL: fmt.Sprintf("%s/%d", srv.URL, i)}
+	}
+
+	tests := []struct {
+		name        string
+		maxParallel int
+		wantPeak    int
+	}{
+		{"serial", 1, 1},
+		{"zero treated as serial", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peak = 0
+			r := NewRunner(nil, DefaultConfig())
+			contents, _ := r.fetchArticleContents(context.Background(), articles, tt.maxParallel, fetchHeaders{}, nil)
+
+			if len(contents) != len(articles) {
+				t.Fatalf("got %d contents, want %d", len(contents), len(articles))
+			}
+			if peak != tt.wantPeak {
+				t.Errorf("peak concurrent fetches = %d, want %d", peak, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestFetchArticleContentsTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<html><body><article><p>Article body text.</p></article></body></html>")
+	}))
+	defer srv.Close()
+
+	runner, dbConn, job := newTestRunner(t, "")
+	articles := []ArticleInfo{
+		{Title: "Fast", URL: srv.URL + "/fast"},
+		{Title: "Slow", URL: srv.URL + "/slow"},
+		{Title: "Missing", URL: srv.URL + "/missing"},
+		{Title: "No URL"},
+	}
+	_, telemetry := runner.fetchArticleContents(context.Background(), articles, 3, fetchHeaders{}, nil)
+
+	if len(telemetry) != 3 {
+		t.Fatalf("got %d telemetry entries, want 3 (unfetched articles skipped): %+v", len(telemetry), telemetry)
+	}
+	byURL := map[string]FetchTelemetry{}
+	for _, tel := range telemetry {
+		byURL[tel.URL] = tel
+	}
+	if slow := byURL[srv.URL+"/slow"]; slow.Duration < 100*time.Millisecond || slow.StatusCode != http.StatusOK || slow.ContentLength == 0 {
+		t.Errorf("slow fetch telemetry = %+v, want >= 100ms, status 200 and content", slow)
+	}
+	if missing := byURL[srv.URL+"/missing"]; missing.StatusCode != http.StatusNotFound || missing.Error == "" {
+		t.Errorf("missing fetch telemetry = %+v, want status 404 and an error", missing)
+	}
+
+	// finalizeRun stores telemetry against the run
+	ctx := context.Background()
+	run, err := dbgen.New(dbConn).CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	runner.recordFetchTelemetry(ctx, job.ID, run.ID, telemetry)
+	var stored int
+	var slowMs int64
+	if err := dbConn.QueryRow("SELECT COUNT(*), MAX(duration_ms) FROM fetch_telemetry WHERE run_id = ?", run.ID).Scan(&stored, &slowMs); err != nil {
+		t.Fatalf("query fetch_telemetry: %v", err)
+	}
+	if stored != 3 || slowMs < 100 {
+		t.Errorf("stored %d rows with max duration %dms, want 3 rows and >= 100ms", stored, slowMs)
+	}
+}
+
+// newMockShelley serves a minimal Shelley API whose conversations finish
+// immediately with agentText as the final agent message.
+func newMockShelley(t *testing.T, agentText string) *httptest.Server {
+	t.Helper()
+
+	llmData, _ := json.Marshal(LLMData{Content: []ContentBlock{{Type: 2, Text: agentText}}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/conversations/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"conversation_id": "conv-1"}`)
+	})
+	mux.HandleFunc("GET /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		working := false
+		conv := Conversation{Messages: []Message{{Type: "agent", EndOfTurn: true, LLMData: llmData}}}
+		conv.Conversation.ConversationID = r.PathValue("id")
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	})
+	mux.HandleFunc("POST /api/conversation/{id}/archive", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestRunner opens a temporary database and returns a runner configured to
+// talk to shelleyURL, along with a job to run.
+func newTestRunner(t *testing.T, shelleyURL string, opts ...RunnerOption) (*Runner, *sql.DB, dbgen.Job) {
+	t.Helper()
+	dir := t.TempDir()
+
+	dbConn, err := db.Open(filepath.Join(dir, "test.sqlite3"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+	if err := db.RunMigrations(dbConn); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	user, err := queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "test-user", Email: "test@example.com"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	job, err := queries.CreateJob(ctx, dbgen.CreateJobParams{
+		UserID:    user.ID,
+		Name:      "Test Job",
+		Prompt:    "test",
+		Frequency: "daily",
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.ArticlesDir = filepath.Join(dir, "articles")
+	config.LogsDir = filepath.Join(dir, "logs")
+	config.ShelleyAPI = shelleyURL
+	config.StartDelay = 0
+	config.PollInterval = 10 * time.Millisecond
+	config.JobTimeout = 5 * time.Second
+
+	return NewRunner(dbConn, config, opts...), dbConn, job
+}
+
+func TestRunRecordsEvents(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	events, err := dbgen.New(dbConn).ListJobEvents(context.Background(), dbgen.ListJobEventsParams{JobID: job.ID, Limit: 100})
+	if err != nil {
+		t.Fatalf("ListJobEvents() error = %v", err)
+	}
+
+	// ListJobEvents returns newest first
+	var got []string
+	for i := len(events) - 1; i >= 0; i-- {
+		got = append(got, events[i].EventType)
+	}
+	want := []string{EventRunStarted, EventConversationCreated, EventArticlesSaved, EventRunCompleted}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRunWithMockShelley(t *testing.T) {
+	shelley := &MockShelley{AgentText: `[{"title": "Mock Article", "url": "", "summary": "From the mock."}]`}
+	runner, dbConn, job := newTestRunner(t, "http://shelley.invalid", WithShelley(shelley))
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(context.Background(), job.ID)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(articles), err)
+	}
+	if articles[0].Title != "Mock Article" || articles[0].Summary != "From the mock." {
+		t.Errorf("saved article = %q, %q", articles[0].Title, articles[0].Summary)
+	}
+	calls := strings.Join(shelley.Calls(), ",")
+	if !strings.HasPrefix(calls, "CreateConversationWithKey,GetConversationStatus,GetConversation") || !strings.Contains(calls, "ArchiveConversation") {
+		t.Errorf("calls = %s, want the conversation created, polled and archived", calls)
+	}
+}
+
+func TestRunRetriesCreateWithRunKey(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	var mu sync.Mutex
+	var keys []string
+	// The first create fails as if its response were lost; the rest go on
+	// to the mock
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/api/conversations/new" {
+			mu.Lock()
+			keys = append(keys, r.Header.Get("X-Idempotency-Key"))
+			first := len(keys) == 1
+			mu.Unlock()
+			if first {
+				http.Error(w, "upstream timeout", http.StatusBadGateway)
+				return
+			}
+		}
+		shelley.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	runner, dbConn, job := newTestRunner(t, srv.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	run, err := dbgen.New(dbConn).GetLatestJobRun(context.Background(), dbgen.GetLatestJobRunParams{JobID: job.ID, UserID: job.UserID})
+	if err != nil {
+		t.Fatalf("GetLatestJobRun() error = %v", err)
+	}
+	want := generateIdempotencyKey(JobUserID(job.ID), job.ID, run.ID)
+	if run.IdempotencyKey != want {
+		t.Errorf("run idempotency key = %q, want %q", run.IdempotencyKey, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(keys) != 2 || keys[0] != want || keys[1] != want {
+		t.Errorf("create requests sent keys %q, want the run's key twice", keys)
+	}
+}
+
+func TestRunScheduleJitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		jitter    any           // schedule_jitter_secs; nil leaves it NULL
+		wantDelay time.Duration // the max delay drawn from; 0 means no delay
+	}{
+		{"job without jitter uses StartDelay", nil, 2 * time.Minute},
+		{"zero jitter starts at once", 0, 0},
+		{"job jitter overrides StartDelay", 3600, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shelley := &MockShelley{AgentText: `[]`}
+			runner, dbConn, job := newTestRunner(t, "http://shelley.invalid", WithShelley(shelley))
+			runner.config.StartDelay = 2 * time.Minute
+			if _, err := dbConn.Exec("UPDATE jobs SET schedule_jitter_secs = ? WHERE id = ?", tt.jitter, job.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// Stand in a short delay for the random one, remembering its bound
+			const delay = 50 * time.Millisecond
+			var gotMax time.Duration
+			runner.randomDelay = func(max time.Duration) time.Duration {
+				gotMax = max
+				return delay
+			}
+
+			start := time.Now()
+			if err := runner.Run(context.Background(), job.ID); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			elapsed := time.Since(start)
+			if gotMax != tt.wantDelay {
+				t.Errorf("delay drawn below %v, want %v", gotMax, tt.wantDelay)
+			}
+			if tt.wantDelay > 0 && elapsed < delay {
+				t.Errorf("Run() took %v, want at least the %v delay", elapsed, delay)
+			}
+		})
+	}
+}
+
+func TestRunWithInjectedLogger(t *testing.T) {
+	shelley := newMockShelley(t, `[]`)
+	_, dbConn, job := newTestRunner(t, shelley.URL)
+
+	var buf bytes.Buffer
+	config := DefaultConfig()
+	config.LogsDir = filepath.Join(t.TempDir(), "logs")
+	config.ArticlesDir = t.TempDir()
+	config.ShelleyAPI = shelley.URL
+	config.StartDelay = 0
+	config.PollInterval = 10 * time.Millisecond
+	runner := NewRunner(dbConn, config, WithLogger(slog.New(slog.NewTextHandler(&buf, nil))), WithNoFileLogging())
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, want := range []string{"job run started", "created conversation", "agent finished"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("injected logger output is missing %q:\n%s", want, buf.String())
+		}
+	}
+	if _, err := os.Stat(config.LogsDir); !os.IsNotExist(err) {
+		t.Errorf("logs dir was created despite WithNoFileLogging: %v", err)
+	}
+}
+
+func TestRunRecordsTokenStats(t *testing.T) {
+	agentText := `[{"title": "Test Article", "url": "", "summary": "A test."}]`
+	shelley := newMockShelley(t, agentText)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runs, err := dbgen.New(dbConn).ListJobRunsByJob(context.Background(), job.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListJobRunsByJob() = %d runs, error = %v", len(runs), err)
+	}
+	if runs[0].ConversationMessages != 1 {
+		t.Errorf("conversation_messages = %d, want 1", runs[0].ConversationMessages)
+	}
+	if want := int64(len(agentText) / 4); runs[0].EstimatedTokens != want {
+		t.Errorf("estimated_tokens = %d, want %d", runs[0].EstimatedTokens, want)
+	}
+}
+
+func TestSetConversationStatsUnpricedModel(t *testing.T) {
+	var buf bytes.Buffer
+	config := DefaultConfig()
+	config.Model = "unpriced-model"
+	runner := NewRunner(nil, config, WithLogger(slog.New(slog.NewTextHandler(&buf, nil))), WithNoFileLogging())
+
+	conv := &Conversation{Messages: []Message{{Type: "agent", LLMData: json.RawMessage(`{"Content": [{"Type": 2, "Text": "some text"}]}`)}}}
+	var result JobResult
+	runner.setConversationStats(&result, conv)
+	if result.EstimatedCost != 0 {
+		t.Errorf("EstimatedCost = %v, want 0 for a model without a price", result.EstimatedCost)
+	}
+	if !strings.Contains(buf.String(), "model has no price") || !strings.Contains(buf.String(), "unpriced-model") {
+		t.Errorf("log = %q, want a warning naming the model", buf.String())
+	}
+}
+
+func TestRunRecordsConversationID(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runs, err := dbgen.New(dbConn).ListJobRunsByJob(context.Background(), job.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListJobRunsByJob() = %d runs, error = %v", len(runs), err)
+	}
+	if runs[0].ConversationID != "conv-1" {
+		t.Errorf("conversation_id = %q, want %q", runs[0].ConversationID, "conv-1")
+	}
+}
+
+func TestRunRecordsLastSuccess(t *testing.T) {
+	failing := newMockShelley(t, "Sorry, I couldn't find any articles.")
+	runner, dbConn, job := newTestRunner(t, failing.URL)
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+
+	runner.Run(ctx, job.ID)
+	got, err := queries.GetJobByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID() error = %v", err)
+	}
+	if got.LastSuccessAt != nil {
+		t.Fatalf("last_success_at = %v after a failed run, want nil", got.LastSuccessAt)
+	}
+
+	succeeding := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner.shelley = NewShelleyClient(succeeding.URL)
+	before := time.Now().Add(-time.Second)
+	if err := runner.Run(ctx, job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	got, err = queries.GetJobByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJobByID() error = %v", err)
+	}
+	if got.LastSuccessAt == nil || got.LastSuccessAt.Before(before) {
+		t.Errorf("last_success_at = %v after a successful run, want about now", got.LastSuccessAt)
+	}
+}
+
+func TestRunStoresArticleMetadata(t *testing.T) {
+	agentText := `[{"title": "Rocket launch", "url": "", "summary": "A launch.", "author": "Jane Doe", "published_at": "2024-01-15T09:30:00Z", "source": "Space Daily"}]`
+	shelley := newMockShelley(t, agentText)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(context.Background(), job.ID)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(articles), err)
+	}
+	a := articles[0]
+	if a.Author != "Jane Doe" || a.PublishedAt != "2024-01-15T09:30:00Z" || a.Source != "Space Daily" {
+		t.Errorf("author, published_at, source = %q, %q, %q, want the agent's values", a.Author, a.PublishedAt, a.Source)
+	}
+
+	content, err := os.ReadFile(a.ContentPath)
+	if err != nil {
+		t.Fatalf("read article file: %v", err)
+	}
+	for _, header := range []string{"Author: Jane Doe\n", "Published: 2024-01-15T09:30:00Z\n"} {
+		if !strings.Contains(string(content), header) {
+			t.Errorf("article file is missing %q:\n%s", header, content)
+		}
+	}
+}
+
+// articleImageFixture is an article page whose lead image is given by
+// og:image, relative to the page.
+const articleImageFixture = `<html><head>
+<title>Rocket launch</title>
+<meta property="og:image" content="/images/rocket.jpg">
+</head><body><article><h1>Rocket launch</h1>
+<p>The rocket lifted off on schedule this morning, carrying a new weather satellite into orbit.</p>
+<p>Engineers said every stage performed as planned and the satellite is now sending data.</p>
+</article></body></html>`
+
+func TestArticleImageURL(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, articleImageFixture)
+	}))
+	defer page.Close()
+
+	shelley := newMockShelley(t, `[{"title": "Rocket launch", "url": "http://news.example/launch", "summary": "A launch."}]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+	// Loopback article URLs are rejected, so send news.example to the fixture
+	runner.fetcher.client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, page.Listener.Addr().String())
+		},
+	}
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(context.Background(), job.ID)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(articles), err)
+	}
+	want := "http://news.example/images/rocket.jpg"
+	if articles[0].ImageUrl != want {
+		t.Errorf("image_url = %q, want %q", articles[0].ImageUrl, want)
+	}
+	content, err := os.ReadFile(articles[0].ContentPath)
+	if err != nil {
+		t.Fatalf("read article file: %v", err)
+	}
+	if !strings.Contains(string(content), "Image: "+want+"\n") {
+		t.Errorf("article file is missing the Image header:\n%s", content)
+	}
+}
+
+// articleSiteNameFixture is an article page that names its publication
+// with og:site_name.
+const articleSiteNameFixture = `<html><head>
+<title>Storm warning</title>
+<meta property="og:site_name" content="The Guardian">
+</head><body><article><h1>Storm warning</h1>
+<p>Forecasters issued a storm warning for the coast, with gusts expected to reach gale force overnight.</p>
+<p>Ferry services have been suspended and residents were asked to secure loose objects.</p>
+</article></body></html>`
+
+func TestArticleSourceName(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/storm" {
+			fmt.Fprint(w, articleSiteNameFixture)
+			return
+		}
+		fmt.Fprint(w, articleImageFixture)
+	}))
+	defer page.Close()
+
+	shelley := newMockShelley(t, `[
+		{"title": "Storm warning", "url": "http://www.theguardian.example/storm", "summary": "A storm."},
+		{"title": "Rocket launch", "url": "http://www.news.example/launch", "summary": "A launch."}
+	]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+	runner.fetcher.client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, page.Listener.Addr().String())
+		},
+	}
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(context.Background(), job.ID)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(articles), err)
+	}
+	want := map[string]string{
+		"Storm warning": "The Guardian",
+		"Rocket launch": "news.example", // no og:site_name, so the hostname
+	}
+	for _, a := range articles {
+		if a.SourceName != want[a.Title] {
+			t.Errorf("%q source_name = %q, want %q", a.Title, a.SourceName, want[a.Title])
+		}
+	}
+}
+
+func TestProcessArticlesRetrievedAt(t *testing.T) {
+	runner, dbConn, job := newTestRunner(t, "http://127.0.0.1:1")
+	ctx := context.Background()
+
+	// The .invalid URL fails to fetch immediately, which doesn't stop saving
+	retrievedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	articles := []ArticleInfo{{Title: "Old news", URL: "https://news.invalid/old", RetrievedAt: &retrievedAt}}
+	if saved, _, err := runner.ProcessArticles(ctx, job.ID, articles, ProcessArticlesOptions{}); err != nil || saved != 1 {
+		t.Fatalf("ProcessArticles() = %d saved, error = %v", saved, err)
+	}
+
+	saved, err := dbgen.New(dbConn).ListArticlesByJob(ctx, job.ID)
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(saved), err)
+	}
+	if !saved[0].RetrievedAt.Equal(retrievedAt) {
+		t.Errorf("retrieved_at = %v, want %v", saved[0].RetrievedAt, retrievedAt)
+	}
+	// The per-day counts rely on SQLite being able to read the stored value
+	var day string
+	if err := dbConn.QueryRow("SELECT strftime('%Y-%m-%d', retrieved_at) FROM articles WHERE id = ?", saved[0].ID).Scan(&day); err != nil || day != "2020-01-01" {
+		t.Errorf("strftime(retrieved_at) = %q, %v, want 2020-01-01", day, err)
+	}
+	data, err := os.ReadFile(saved[0].ContentPath)
+	if err != nil || !strings.Contains(string(data), "Retrieved: 2020-01-01T00:00:00Z") {
+		t.Errorf("article file = %q, %v, want the given retrieval time", data, err)
+	}
+}
+
+func TestArticleDateDirs(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+	runner.config.ArticleDateDirs = true
+	ctx := context.Background()
+
+	if err := runner.Run(ctx, job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	saved, _, err := runner.ProcessArticles(ctx, job.ID, []ArticleInfo{{Title: "Imported", URL: "https://news.invalid/imported", Summary: "Via ProcessArticles."}}, ProcessArticlesOptions{})
+	if err != nil || saved != 1 {
+		t.Fatalf("ProcessArticles() = %d saved, error = %v", saved, err)
+	}
+
+	articles, err := dbgen.New(dbConn).ListArticlesByJob(ctx, job.ID)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("ListArticlesByJob() = %d articles, error = %v", len(articles), err)
+	}
+	month := time.Now().Format("2006/01")
+	for _, a := range articles {
+		dir := filepath.ToSlash(filepath.Dir(a.ContentPath))
+		if !strings.HasSuffix(dir, "/"+month) {
+			t.Errorf("article %q saved in %s, want a %s subdirectory", a.Title, dir, month)
+		}
+		if _, err := os.Stat(a.ContentPath); err != nil {
+			t.Errorf("article file: %v", err)
+		}
+	}
+}
+
+func TestRunWithOptionsDisableStartDelay(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner, _, job := newTestRunner(t, shelley.URL)
+	runner.config.StartDelay = time.Minute
+
+	start := time.Now()
+	err := runner.RunWithOptions(context.Background(), job.ID, RunOptions{DisableStartDelay: true})
+	if err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("run took %v, want under 100ms with the start delay disabled", elapsed)
+	}
+}
+
+func TestRunWithOptionsOverrideTimeout(t *testing.T) {
+	shelley := newMockShelley(t, `[]`)
+	runner, _, job := newTestRunner(t, shelley.URL)
+	runner.config.PollInterval = time.Hour // never poll, so the run can only time out
+
+	err := runner.RunWithOptions(context.Background(), job.ID, RunOptions{OverrideTimeout: 20 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("RunWithOptions() error = %v, want timeout after 20ms", err)
+	}
+}
+
+func TestRunWithOptionsProgressCallback(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "One", "url": "https://news.invalid/1", "summary": "1"}, {"title": "Two", "url": "https://news.invalid/2", "summary": "2"}]`)
+	runner, _, job := newTestRunner(t, shelley.URL)
+
+	var events []RunProgress
+	opts := RunOptions{DisableStartDelay: true, ProgressCallback: func(p RunProgress) { events = append(events, p) }}
+	if err := runner.RunWithOptions(context.Background(), job.ID, opts); err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+
+	stages := make(map[string]int)
+	for _, e := range events {
+		stages[e.Stage]++
+		if e.Stage == ProgressSaving && e.Total != 2 {
+			t.Errorf("saving event %+v, want a total of 2", e)
+		}
+	}
+	if stages[ProgressPolling] == 0 || stages[ProgressFetching] != 2 || stages[ProgressSaving] != 2 {
+		t.Errorf("progress stages = %v, want polling events and two each of fetching and saving", stages)
+	}
+}
+
+func TestRunStoresConversationSnapshot(t *testing.T) {
+	shelley := newMockShelley(t, `[{"title": "Test Article", "url": "", "summary": "A test."}]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runs, err := dbgen.New(dbConn).ListJobRunsByJob(context.Background(), job.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListJobRunsByJob() = %d runs, error = %v", len(runs), err)
+	}
+	if runs[0].ConversationID != "conv-1" {
+		t.Errorf("conversation_id = %q, want conv-1", runs[0].ConversationID)
+	}
+
+	var view ConversationView
+	if err := json.Unmarshal([]byte(runs[0].ConversationSnapshot), &view); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if len(view.Messages) != 1 || view.Messages[0].Type != "agent" || !strings.Contains(view.Messages[0].Text, "Test Article") {
+		t.Errorf("snapshot = %+v, want the single agent message", view)
+	}
+}
+
+func TestPollForCompletionRateLimited(t *testing.T) {
+	var mu sync.Mutex
+	var polls []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		working := false
+		conv := Conversation{}
+		conv.Conversation.Working = &working
+		if !strings.HasSuffix(r.URL.Path, "/status") {
+			json.NewEncoder(w).Encode(conv)
+			return
+		}
+
+		mu.Lock()
+		polls = append(polls, time.Now())
+		n := len(polls)
+		mu.Unlock()
+
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"working": false, "message_count": 0}`)
+	}))
+	t.Cleanup(srv.Close)
+	runner, _, job := newTestRunner(t, srv.URL)
+
+	if _, err := runner.pollForCompletion(context.Background(), job.ID, "conv-1", 5*time.Second, nil); err != nil {
+		t.Fatalf("pollForCompletion() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(polls) != 2 {
+		t.Fatalf("got %d polls, want 2", len(polls))
+	}
+	if gap := polls[1].Sub(polls[0]); gap < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", gap)
+	}
+}
+
+func TestPollForCompletionChecksStatus(t *testing.T) {
+	llmData, _ := json.Marshal(LLMData{Content: []ContentBlock{{Type: 2, Text: "[]"}}})
+	var mu sync.Mutex
+	var statusChecks, fullFetches int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/conversation/{id}/status", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		statusChecks++
+		working := statusChecks < 5
+		mu.Unlock()
+		json.NewEncoder(w).Encode(ConversationStatus{Working: working, MessageCount: 1})
+	})
+	mux.HandleFunc("GET /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fullFetches++
+		mu.Unlock()
+		working := false
+		conv := Conversation{Messages: []Message{{Type: "agent", EndOfTurn: true, LLMData: llmData}}}
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	runner, _, job := newTestRunner(t, srv.URL)
+	runner.config.PollInterval = time.Hour
+	runner.config.StatusCheckInterval = time.Millisecond
+
+	conv, err := runner.pollForCompletion(context.Background(), job.ID, "conv-1", 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("pollForCompletion() error = %v", err)
+	}
+	if len(conv.Messages) != 1 {
+		t.Errorf("got %d messages, want the full conversation", len(conv.Messages))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if statusChecks != 5 || fullFetches != 1 {
+		t.Errorf("got %d status checks and %d full fetches, want 5 and 1", statusChecks, fullFetches)
+	}
+}
+
+func TestGetConversationStatusFallback(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/status") {
+			http.NotFound(w, r)
+			return
+		}
+		working := true
+		conv := Conversation{Messages: []Message{{Type: "user"}, {Type: "agent"}}}
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	}))
+	t.Cleanup(srv.Close)
+	client := NewShelleyClient(srv.URL)
+
+	for range 3 {
+		status, err := client.GetConversationStatus(context.Background(), 1, "conv-1")
+		if err != nil {
+			t.Fatalf("GetConversationStatus() error = %v", err)
+		}
+		if !status.Working || status.MessageCount != 2 {
+			t.Errorf("GetConversationStatus() = %+v, want working with 2 messages", status)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/api/conversation/conv-1/status"] != 1 || requests["/api/conversation/conv-1"] != 3 {
+		t.Errorf("requests = %v, want the status endpoint tried once and the conversation fetched each time", requests)
+	}
+}
+
+func TestPollForCompletionServerErrors(t *testing.T) {
+	var polls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	runner, _, job := newTestRunner(t, srv.URL)
+
+	_, err := runner.pollForCompletion(context.Background(), job.ID, "conv-1", 5*time.Second, nil)
+	if !isShelleyStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("pollForCompletion() error = %v, want the 503", err)
+	}
+	if polls != maxShelleyServerErrors+1 {
+		t.Errorf("gave up after %d polls, want %d", polls, maxShelleyServerErrors+1)
+	}
+}
+
+// failingTransport fails its first failures requests as if the server
+// were unreachable, then passes requests on.
+type failingTransport struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.calls++
+	fail := f.calls <= f.failures
+	f.mu.Unlock()
+	if fail {
+		return nil, errors.New("connection refused")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPollForCompletionCircuitOpen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		working := false
+		conv := Conversation{Messages: []Message{{Type: "user"}, {Type: "agent"}}}
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	}))
+	t.Cleanup(srv.Close)
+	runner, _, job := newTestRunner(t, srv.URL)
+	client := NewShelleyClient(srv.URL, WithCircuitBreaker(2, 50*time.Millisecond))
+	transport := &failingTransport{failures: 3}
+	client.httpClient.Transport = transport
+	runner.shelley = client
+
+	// Two failures open the circuit and the first probe fails too; the
+	// polls skipped meanwhile don't fail the job
+	conv, err := runner.pollForCompletion(context.Background(), job.ID, "conv-1", 5*time.Second, nil)
+	if err != nil || conv == nil {
+		t.Fatalf("pollForCompletion() = %v, %v, want the finished conversation", conv, err)
+	}
+	if calls := transport.calls; calls > 5 {
+		t.Errorf("Shelley was called %d times, want polls skipped while the circuit was open", calls)
+	}
+}
+
+func TestRunRecreatesMissingConversation(t *testing.T) {
+	llmData, _ := json.Marshal(LLMData{Content: []ContentBlock{{Type: 2, Text: `[{"title": "Test Article", "url": ""}]`}}})
+	var created int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/conversations/new", func(w http.ResponseWriter, r *http.Request) {
+		created++
+		fmt.Fprintf(w, `{"conversation_id": "conv-%d"}`, created)
+	})
+	mux.HandleFunc("GET /api/conversation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// The first conversation was deleted behind the runner's back
+		if r.PathValue("id") == "conv-1" {
+			http.NotFound(w, r)
+			return
+		}
+		working := false
+		conv := Conversation{Messages: []Message{{Type: "agent", EndOfTurn: true, LLMData: llmData}}}
+		conv.Conversation.Working = &working
+		json.NewEncoder(w).Encode(conv)
+	})
+	mux.HandleFunc("POST /api/conversation/{id}/archive", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	runner, dbConn, job := newTestRunner(t, srv.URL)
+
+	if err := runner.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if created != 2 {
+		t.Errorf("created %d conversations, want 2", created)
+	}
+	runs, err := dbgen.New(dbConn).ListJobRunsByJob(context.Background(), job.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("runs = %d (err %v), want 1", len(runs), err)
+	}
+	if runs[0].Status != "completed" || runs[0].ArticlesSaved == nil || *runs[0].ArticlesSaved != 1 {
+		t.Errorf("run status = %q, saved %v, want completed with 1 article", runs[0].Status, runs[0].ArticlesSaved)
+	}
+}
+
+func TestRunWaitsForGlobalSlot(t *testing.T) {
+	runner, dbConn, job := newTestRunner(t, "http://127.0.0.1:1")
+	runner.config.MaxConcurrentRuns = 2
+	queries := dbgen.New(dbConn)
+	ctx := context.Background()
+
+	origInterval := runSlotPollInterval
+	runSlotPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { runSlotPollInterval = origInterval })
+
+	// Runs of another job, as if started by other processes, take every slot
+	other, err := queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: job.UserID, Name: "Other", Prompt: "p", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	for range runner.config.MaxConcurrentRuns {
+		if _, err := queries.CreateJobRun(ctx, other.ID); err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+	}
+
+	// Waiting for a slot stops when the context is cancelled
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := runner.Run(waitCtx, job.ID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want a deadline error while queued", err)
+	}
+	runs, _ := queries.ListJobRunsByJob(ctx, job.ID)
+	if len(runs) != 0 {
+		t.Errorf("runs = %d, want none created while queued", len(runs))
+	}
+
+	// A run left running long past the job timeout no longer holds a slot
+	if full, err := RunSlotsFull(ctx, queries, runner.config); err != nil || !full {
+		t.Fatalf("RunSlotsFull() = %v, %v, want true", full, err)
+	}
+	if _, err := dbConn.Exec("UPDATE job_runs SET started_at = datetime('now', '-1 day') WHERE id = (SELECT MIN(id) FROM job_runs)"); err != nil {
+		t.Fatalf("age run: %v", err)
+	}
+	if full, err := RunSlotsFull(ctx, queries, runner.config); err != nil || full {
+		t.Errorf("RunSlotsFull() = %v, %v, want false with an abandoned run", full, err)
+	}
+	if run, ok, err := runner.tryCreateRunInSlot(ctx, job.ID); err != nil || !ok || run.JobID != job.ID {
+		t.Errorf("tryCreateRunInSlot() = %+v, %v, %v, want a new run", run, ok, err)
+	}
+}
+
+func TestRunShelleyQuota(t *testing.T) {
+	shelley := newMockShelley(t, `[]`)
+	runner, dbConn, job := newTestRunner(t, shelley.URL)
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+	if _, err := queries.CreatePreferences(ctx, job.UserID); err != nil {
+		t.Fatalf("create preferences: %v", err)
+	}
+	if _, err := queries.UpdateShelleyQuota(ctx, dbgen.UpdateShelleyQuotaParams{ShelleyQuotaDaily: 2, UserID: job.UserID}); err != nil {
+		t.Fatalf("set quota: %v", err)
+	}
+
+	for i := range 2 {
+		if err := runner.Run(ctx, job.ID); err != nil {
+			t.Fatalf("run %d: Run() error = %v", i+1, err)
+		}
+	}
+	if err := runner.Run(ctx, job.ID); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("run 3: Run() error = %v, want ErrQuotaExceeded", err)
+	}
+
+	runs, err := queries.ListJobRunsByJob(ctx, job.ID)
+	if err != nil || len(runs) != 3 {
+		t.Fatalf("runs = %d (err %v), want 3", len(runs), err)
+	}
+	statuses := map[string]int{}
+	for _, run := range runs {
+		statuses[run.Status]++
+	}
+	if statuses["quota_exceeded"] != 1 || statuses["failed"] != 0 {
+		t.Errorf("run statuses = %v, want one quota_exceeded and none failed", statuses)
+	}
+	if usage, err := runner.usage.DailyUsage(ctx, job.UserID); err != nil || usage != 2 {
+		t.Errorf("DailyUsage() = %d, %v, want 2", usage, err)
+	}
+}
+
+func TestBuildJobPrompt(t *testing.T) {
+	job := dbgen.Job{ID: 1, Prompt: "space news", Keywords: "mars, rovers", PromptTemplate: "Find {{.Prompt}} about {{.Keywords}}. {{.SystemPrompt}}"}
+	prefs := dbgen.Preference{SystemPrompt: "Be brief."}
+	prompt, err := BuildJobPrompt(job, prefs)
+	if err != nil || prompt != "Find space news about mars, rovers. Be brief." {
+		t.Errorf("BuildJobPrompt() = %q, %v", prompt, err)
+	}
+
+	// A template that fails to render falls back to the default prompt
+	job.PromptTemplate = "{{.Missing}}"
+	prompt, err = BuildJobPrompt(job, prefs)
+	if err == nil || prompt != BuildPrompt(job, prefs) {
+		t.Errorf("BuildJobPrompt() with a bad template = %q, %v, want the default prompt and an error", prompt, err)
+	}
+
+	for _, tmpl := range []string{"{{.}}", "All: {{- . -}}", "{{print .}}", "{{printf \"%v\" $}}", "{{$x := .}}{{$x}}", "{{with .Prompt}}{{template \"t\" $}}{{end}}", "{{define \"t\"}}{{.}}{{end}}", "{{.Missing}}", "{{if}}", strings.Repeat("x", maxPromptTemplateSize+1)} {
+		if err := ValidatePromptTemplate(tmpl); err == nil {
+			t.Errorf("ValidatePromptTemplate(%.20q) = nil, want an error", tmpl)
+		}
+	}
+	if err := ValidatePromptTemplate("{{if .Keywords}}{{$k := .Keywords}}{{printf \"%s\" $k}}{{end}}"); err != nil {
+		t.Errorf("ValidatePromptTemplate() with named fields = %v, want nil", err)
+	}
+	if err := ValidatePromptTemplate(""); err != nil {
+		t.Errorf("ValidatePromptTemplate(\"\") = %v, want nil", err)
+	}
+}
+
+func TestNextScheduledRun(t *testing.T) {
+	scheduled := time.Date(2026, 3, 14, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		nextRunAt *time.Time
+		now       time.Time
+		want      time.Time
+	}{
+		{"long run keeps cadence", &scheduled, scheduled.Add(30 * time.Minute), scheduled.Add(24 * time.Hour)},
+		{"missed days are skipped", &scheduled, scheduled.Add(50 * time.Hour), scheduled.Add(72 * time.Hour)},
+		{"early manual run", &scheduled, scheduled.Add(-2 * time.Hour), scheduled.Add(22 * time.Hour)},
+		{"never scheduled", nil, scheduled, scheduled.Add(24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		job := dbgen.Job{Frequency: "daily", NextRunAt: tt.nextRunAt}
+		if got := nextScheduledRun(job, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: nextScheduledRun() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResumeOrphanedRuns(t *testing.T) {
+	runner, dbConn, job := newTestRunner(t, "http://shelley.invalid")
+	ctx := context.Background()
+	queries := dbgen.New(dbConn)
+
+	orphan, err := queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	finished, err := queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if _, err := dbConn.Exec("UPDATE job_runs SET status = 'completed' WHERE id = ?", finished.ID); err != nil {
+		t.Fatalf("complete run: %v", err)
+	}
+
+	var mu sync.Mutex
+	var resumed []int64
+	runner.resume = func(ctx context.Context, runID int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		resumed = append(resumed, runID)
+		return nil
+	}
+
+	if err := runner.ResumeOrphanedRuns(ctx); err != nil {
+		t.Fatalf("ResumeOrphanedRuns() error = %v", err)
+	}
+	if len(resumed) != 1 || resumed[0] != orphan.ID {
+		t.Errorf("resumed runs %v, want [%d]", resumed, orphan.ID)
+	}
+}
@@ -3,10 +3,18 @@ package jobrunner
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"errors"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,33 +22,320 @@ import (
 type ShelleyClient struct {
 	baseURL    string
 	httpClient *http.Client
+	timeouts   map[string]time.Duration // per-method request deadlines, by method name
+	breaker    *CircuitBreaker
+
+	// noStatusEndpoint is set once the server is found not to serve
+	// conversation status, so GetConversationStatus goes straight to
+	// GetConversation.
+	noStatusEndpoint atomic.Bool
+}
+
+// ShelleyAPI is the part of the Shelley API a Runner uses. ShelleyClient
+// implements it; tests can give a Runner a fake with WithShelley.
+type ShelleyAPI interface {
+	CreateConversation(ctx context.Context, jobID, runID int64, prompt string) (string, error)
+	CreateConversationWithKey(ctx context.Context, userID, model, prompt, idempotencyKey string) (string, error)
+	GetConversation(ctx context.Context, jobID int64, convID string) (*Conversation, error)
+	GetConversationStatus(ctx context.Context, jobID int64, convID string) (*ConversationStatus, error)
+	SendMessage(ctx context.Context, jobID int64, convID, message string) error
+	DeleteConversation(ctx context.Context, jobID int64, convID string) error
+	ArchiveConversation(ctx context.Context, jobID int64, convID string) error
+	ListSubagents(ctx context.Context, jobID int64, parentConvID string) ([]string, error)
+}
+
+// defaultRequestTimeout bounds Shelley calls without a timeout of their own.
+const defaultRequestTimeout = 30 * time.Second
+
+// defaultShelleyTimeouts are the request deadlines of each ShelleyClient
+// method. Polling calls are short so a stalled request is retried quickly.
+var defaultShelleyTimeouts = map[string]time.Duration{
+	"CreateConversation":    30 * time.Second,
+	"GetConversation":       10 * time.Second,
+	"GetConversationStatus": 10 * time.Second,
+	"SendMessage":           30 * time.Second,
+	"ArchiveConversation":   5 * time.Second,
+	"DeleteConversation":    10 * time.Second,
+	"ListSubagents":         10 * time.Second,
+	"ListConversations":     10 * time.Second,
+}
+
+// ShelleyClientOption configures a ShelleyClient.
+type ShelleyClientOption func(*ShelleyClient)
+
+// WithRequestTimeout sets the deadline for requests made by the named
+// ShelleyClient method, e.g. "GetConversation". The variants of a method
+// (CreateConversationAs, DeleteConversationAsCleanup) share its timeout.
+func WithRequestTimeout(method string, d time.Duration) ShelleyClientOption {
+	return func(c *ShelleyClient) {
+		c.timeouts[method] = d
+	}
+}
+
+// WithCircuitBreaker sets how many requests in a row must fail to reach
+// Shelley before the client stops calling it, and for how long.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ShelleyClientOption {
+	return func(c *ShelleyClient) {
+		c.breaker = NewCircuitBreaker(threshold, cooldown)
+	}
 }
 
+// DefaultModel is the model conversations use unless configured otherwise.
+const DefaultModel = "claude-sonnet-4.5"
+
 // NewShelleyClient creates a new Shelley API client.
-func NewShelleyClient(baseURL string) *ShelleyClient {
-	return &ShelleyClient{
+func NewShelleyClient(baseURL string, opts ...ShelleyClientOption) *ShelleyClient {
+	c := &ShelleyClient{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		// Requests are bounded by per-method context deadlines instead
+		httpClient: &http.Client{},
+		timeouts:   make(map[string]time.Duration, len(defaultShelleyTimeouts)),
+		breaker:    NewCircuitBreaker(DefaultCircuitThreshold, DefaultCircuitCooldown),
+	}
+	for method, d := range defaultShelleyTimeouts {
+		c.timeouts[method] = d
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// requestContext derives the context for one call to method from ctx,
+// applying the method's timeout.
+func (c *ShelleyClient) requestContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	d, ok := c.timeouts[method]
+	if !ok {
+		d = defaultRequestTimeout
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// do sends req unless the circuit breaker is open. Only failing to get a
+// response counts against the breaker: a 5xx means Shelley is up, and
+// pollForCompletion has its own limit for those.
+func (c *ShelleyClient) do(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var doErr error
+	err := c.breaker.Execute(func() error {
+		resp, doErr = c.httpClient.Do(req)
+		if errors.Is(doErr, context.Canceled) {
+			return nil // the caller gave up, which says nothing about Shelley
+		}
+		return doErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, doErr
+}
+
+// ErrCircuitOpen is returned by ShelleyClient calls made while its circuit
+// breaker is open, without contacting Shelley.
+var ErrCircuitOpen = errors.New("shelley unavailable: circuit breaker open")
+
+// Circuit breaker defaults for NewShelleyClient.
+const (
+	DefaultCircuitThreshold = 5
+	DefaultCircuitCooldown  = 60 * time.Second
+)
+
+// circuitState is the state of a CircuitBreaker.
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // calls go through
+	circuitOpen                         // calls fail with ErrCircuitOpen
+	circuitHalfOpen                     // one probe call is in flight
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops calls to a service that keeps failing. After
+// Threshold consecutive failures it opens for CooldownDuration, failing
+// calls with ErrCircuitOpen; then it lets one probe call through, which
+// closes the circuit if it succeeds and opens it again if it fails. A
+// Threshold of 0 or less never opens the circuit.
+type CircuitBreaker struct {
+	Threshold        int
+	CooldownDuration time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int // consecutive
+	openedAt time.Time
+	now      func() time.Time // replaced by tests
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, CooldownDuration: cooldown, now: time.Now}
+}
+
+// Execute calls fn unless the circuit is open, recording whether it
+// returned an error.
+func (b *CircuitBreaker) Execute(fn func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// allow reports ErrCircuitOpen if a call may not go through, moving an
+// open circuit whose cooldown has passed to half-open.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.CooldownDuration {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+	case circuitHalfOpen:
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record updates the circuit with the outcome of a call.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || (b.Threshold > 0 && b.failures >= b.Threshold) {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// currentState returns the circuit's state.
+func (b *CircuitBreaker) currentState() circuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// ShelleyError is a non-2xx response from the Shelley API.
+type ShelleyError struct {
+	StatusCode int
+	Message    string         // response body, if any
+	RetryAfter *time.Duration // from the Retry-After header, if present
+}
+
+func (e *ShelleyError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// maxErrorBodyBytes bounds how much of an error response is kept.
+const maxErrorBodyBytes = 1024
+
+// newShelleyError builds a ShelleyError from a failed response.
+func newShelleyError(resp *http.Response) *ShelleyError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	e := &ShelleyError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		e.RetryAfter = &d
+	}
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header given either as seconds or
+// as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
 	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// isShelleyStatus reports whether err is a ShelleyError with the given
+// status code.
+func isShelleyStatus(err error, status int) bool {
+	var shelleyErr *ShelleyError
+	return errors.As(err, &shelleyErr) && shelleyErr.StatusCode == status
 }
 
-// jobUserID returns the exe.dev user ID header value for a job.
-func jobUserID(jobID int64) string {
+// JobUserID returns the exe.dev user ID header value for a job.
+func JobUserID(jobID int64) string {
 	return fmt.Sprintf("news-job-%d", jobID)
 }
 
-// CreateConversation creates a new conversation with the given prompt.
-func (c *ShelleyClient) CreateConversation(ctx context.Context, jobID int64, prompt string) (string, error) {
-	return c.CreateConversationAs(ctx, jobUserID(jobID), prompt)
+// CreateConversation creates a new conversation with the given prompt for
+// a job run, keyed by generateIdempotencyKey so that calling it again for
+// the same run returns the conversation already created.
+func (c *ShelleyClient) CreateConversation(ctx context.Context, jobID, runID int64, prompt string) (string, error) {
+	userID := JobUserID(jobID)
+	return c.CreateConversationWithKey(ctx, userID, DefaultModel, prompt, generateIdempotencyKey(userID, jobID, runID))
+}
+
+// generateIdempotencyKey returns the X-Idempotency-Key of a conversation
+// created as userID for a job run: a SHA-256 of the three. It depends on
+// nothing else, so every attempt to create the run's conversation sends the
+// same key.
+func generateIdempotencyKey(userID string, jobID, runID int64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", userID, jobID, runID)))
+	return hex.EncodeToString(sum[:])
+}
+
+// replacementIdempotencyKey returns the key to create a conversation under
+// in place of convID, which was created under key and has since gone.
+func replacementIdempotencyKey(key, convID string) string {
+	sum := sha256.Sum256([]byte(key + "\x00" + convID))
+	return hex.EncodeToString(sum[:])
 }
 
-// CreateConversationAs creates a new conversation with a custom user ID.
+// CreateConversationAs creates a new conversation with a custom user ID,
+// using DefaultModel.
 func (c *ShelleyClient) CreateConversationAs(ctx context.Context, userID, prompt string) (string, error) {
+	return c.CreateConversationWithModel(ctx, userID, DefaultModel, prompt)
+}
+
+// CreateConversationWithModel creates a new conversation with a custom user
+// ID and model.
+func (c *ShelleyClient) CreateConversationWithModel(ctx context.Context, userID, model, prompt string) (string, error) {
+	return c.CreateConversationWithKey(ctx, userID, model, prompt, "")
+}
+
+// CreateConversationWithKey is CreateConversationWithModel sending
+// idempotencyKey, if not empty, as X-Idempotency-Key. Shelley answers a
+// repeated key with the conversation it created for the first request,
+// which is returned like a new one.
+func (c *ShelleyClient) CreateConversationWithKey(ctx context.Context, userID, model, prompt, idempotencyKey string) (string, error) {
+	ctx, cancel := c.requestContext(ctx, "CreateConversation")
+	defer cancel()
+
 	reqBody := map[string]string{
 		"message": prompt,
-		"model":   "claude-sonnet-4.5",
+		"model":   model,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
@@ -52,8 +347,11 @@ func (c *ShelleyClient) CreateConversationAs(ctx context.Context, userID, prompt
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Exedev-Userid", userID)
 	req.Header.Set("X-Shelley-Request", "1")
+	if idempotencyKey != "" {
+		req.Header.Set("X-Idempotency-Key", idempotencyKey)
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -61,8 +359,7 @@ func (c *ShelleyClient) CreateConversationAs(ctx context.Context, userID, prompt
 
 	// Accept 2xx status codes (200 OK, 201 Created, 202 Accepted)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return "", newShelleyError(resp)
 	}
 
 	var result struct {
@@ -125,17 +422,8 @@ func (c *Conversation) IsComplete() bool {
 func (c *Conversation) GetLastAgentText() string {
 	for i := len(c.Messages) - 1; i >= 0; i-- {
 		if c.Messages[i].Type == "agent" {
-			// LLMData may be a JSON string or an object
-			var llmDataStr string
-			var data LLMData
-			
-			// Try parsing as string first (API returns JSON-encoded string)
-			if err := json.Unmarshal(c.Messages[i].LLMData, &llmDataStr); err == nil {
-				if err := json.Unmarshal([]byte(llmDataStr), &data); err != nil {
-					continue
-				}
-			} else if err := json.Unmarshal(c.Messages[i].LLMData, &data); err != nil {
-				// Try parsing directly as object
+			data, ok := c.Messages[i].parseLLMData()
+			if !ok {
 				continue
 			}
 			
@@ -149,23 +437,124 @@ func (c *Conversation) GetLastAgentText() string {
 	return ""
 }
 
+// GetAllAgentText returns the text blocks of every agent message, in
+// conversation order. Unlike GetLastAgentText it includes intermediate
+// messages, which may hold partial results.
+func (c *Conversation) GetAllAgentText() []string {
+	var texts []string
+	for _, m := range c.Messages {
+		if m.Type != "agent" {
+			continue
+		}
+		data, ok := m.parseLLMData()
+		if !ok {
+			continue
+		}
+		for _, block := range data.Content {
+			if block.Type == 2 && block.Text != "" {
+				texts = append(texts, block.Text)
+			}
+		}
+	}
+	return texts
+}
+
+// GetFullAgentText returns all agent text joined by newlines.
+func (c *Conversation) GetFullAgentText() string {
+	return strings.Join(c.GetAllAgentText(), "\n")
+}
+
+// GetMessageStats returns the number of messages in the conversation and a
+// rough token estimate of their text, at about four characters per token.
+func (c *Conversation) GetMessageStats() (messages int, estimatedTokens int) {
+	for _, m := range c.Messages {
+		messages++
+		data, ok := m.parseLLMData()
+		if !ok {
+			continue
+		}
+		for _, block := range data.Content {
+			estimatedTokens += len(block.Text) / 4
+		}
+	}
+	return messages, estimatedTokens
+}
+
+// ConversationView is a conversation reduced to what a user may review: each
+// message's type, turn marker and text, without raw LLM data.
+type ConversationView struct {
+	ConversationID string        `json:"conversation_id"`
+	Messages       []MessageView `json:"messages"`
+}
+
+// MessageView is a single message in a ConversationView.
+type MessageView struct {
+	Type      string `json:"type"`
+	EndOfTurn bool   `json:"end_of_turn"`
+	Text      string `json:"text"`
+}
+
+// NewConversationView builds a ConversationView from a conversation,
+// joining the text blocks of each message.
+func NewConversationView(c *Conversation) ConversationView {
+	view := ConversationView{
+		ConversationID: c.Conversation.ConversationID,
+		Messages:       make([]MessageView, 0, len(c.Messages)),
+	}
+	for _, m := range c.Messages {
+		mv := MessageView{Type: m.Type, EndOfTurn: m.EndOfTurn}
+		if data, ok := m.parseLLMData(); ok {
+			var texts []string
+			for _, block := range data.Content {
+				if block.Type == 2 && block.Text != "" {
+					texts = append(texts, block.Text)
+				}
+			}
+			mv.Text = strings.Join(texts, "\n\n")
+		}
+		view.Messages = append(view.Messages, mv)
+	}
+	return view
+}
+
+// parseLLMData decodes a message's LLM data, which may be a JSON-encoded
+// string or an object.
+func (m Message) parseLLMData() (LLMData, bool) {
+	var llmDataStr string
+	var data LLMData
+
+	// Try parsing as string first (API returns JSON-encoded string)
+	if err := json.Unmarshal(m.LLMData, &llmDataStr); err == nil {
+		if err := json.Unmarshal([]byte(llmDataStr), &data); err != nil {
+			return data, false
+		}
+	} else if err := json.Unmarshal(m.LLMData, &data); err != nil {
+		// Try parsing directly as object
+		return data, false
+	}
+	return data, true
+}
+
 // GetConversation retrieves a conversation by ID.
 func (c *ShelleyClient) GetConversation(ctx context.Context, jobID int64, convID string) (*Conversation, error) {
+	ctx, cancel := c.requestContext(ctx, "GetConversation")
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/conversation/"+convID, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("X-Exedev-Userid", jobUserID(jobID))
+	req.Header.Set("X-Exedev-Userid", JobUserID(jobID))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d", resp.StatusCode)
+		return nil, newShelleyError(resp)
 	}
 
 	var conv Conversation
@@ -176,9 +565,70 @@ func (c *ShelleyClient) GetConversation(ctx context.Context, jobID int64, convID
 	return &conv, nil
 }
 
+// ConversationStatus is whether a conversation's agent is still working,
+// without its messages.
+type ConversationStatus struct {
+	Working      bool `json:"working"`
+	MessageCount int  `json:"message_count"`
+
+	// conversation is the full conversation when the status was taken from
+	// one, so callers needn't fetch it again.
+	conversation *Conversation
+}
+
+// GetConversationStatus retrieves the status of a conversation from its
+// lightweight status endpoint. Servers without the endpoint answer 404, in
+// which case the status is taken from GetConversation instead, as it is
+// for every later call once GetConversation succeeds.
+func (c *ShelleyClient) GetConversationStatus(ctx context.Context, jobID int64, convID string) (*ConversationStatus, error) {
+	if !c.noStatusEndpoint.Load() {
+		status, err := c.getConversationStatus(ctx, jobID, convID)
+		if !isShelleyStatus(err, http.StatusNotFound) {
+			return status, err
+		}
+	}
+
+	// A 404 may also mean the conversation doesn't exist, in which case
+	// GetConversation fails the same way
+	conv, err := c.GetConversation(ctx, jobID, convID)
+	if err != nil {
+		return nil, err
+	}
+	c.noStatusEndpoint.Store(true)
+	return &ConversationStatus{Working: !conv.IsComplete(), MessageCount: len(conv.Messages), conversation: conv}, nil
+}
+
+func (c *ShelleyClient) getConversationStatus(ctx context.Context, jobID int64, convID string) (*ConversationStatus, error) {
+	ctx, cancel := c.requestContext(ctx, "GetConversationStatus")
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/conversation/"+convID+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Exedev-Userid", JobUserID(jobID))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newShelleyError(resp)
+	}
+
+	var status ConversationStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
 // DeleteConversation deletes/cancels a conversation.
 func (c *ShelleyClient) DeleteConversation(ctx context.Context, jobID int64, convID string) error {
-	return c.deleteConversationAs(ctx, jobUserID(jobID), convID)
+	return c.deleteConversationAs(ctx, JobUserID(jobID), convID)
 }
 
 // DeleteConversationAsCleanup deletes a conversation using the cleanup user ID.
@@ -187,6 +637,9 @@ func (c *ShelleyClient) DeleteConversationAsCleanup(ctx context.Context, convID
 }
 
 func (c *ShelleyClient) deleteConversationAs(ctx context.Context, userID, convID string) error {
+	ctx, cancel := c.requestContext(ctx, "DeleteConversation")
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+"/api/conversation/"+convID, nil)
 	if err != nil {
 		return err
@@ -194,26 +647,32 @@ func (c *ShelleyClient) deleteConversationAs(ctx context.Context, userID, convID
 
 	req.Header.Set("X-Exedev-Userid", userID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newShelleyError(resp)
+	}
 	return nil
 }
 
 // ArchiveConversation archives a conversation.
 func (c *ShelleyClient) ArchiveConversation(ctx context.Context, jobID int64, convID string) error {
+	ctx, cancel := c.requestContext(ctx, "ArchiveConversation")
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/conversation/"+convID+"/archive", nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("X-Exedev-Userid", jobUserID(jobID))
+	req.Header.Set("X-Exedev-Userid", JobUserID(jobID))
 	req.Header.Set("X-Shelley-Request", "1")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -222,16 +681,48 @@ func (c *ShelleyClient) ArchiveConversation(ctx context.Context, jobID int64, co
 	return nil
 }
 
+// SendMessage adds a user message to an existing conversation, starting
+// another agent turn. Poll with GetConversation for the reply.
+func (c *ShelleyClient) SendMessage(ctx context.Context, jobID int64, convID, message string) error {
+	ctx, cancel := c.requestContext(ctx, "SendMessage")
+	defer cancel()
+
+	jsonBody, _ := json.Marshal(map[string]string{"message": message})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/conversation/"+convID+"/message", bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Exedev-Userid", JobUserID(jobID))
+	req.Header.Set("X-Shelley-Request", "1")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newShelleyError(resp)
+	}
+	return nil
+}
+
 // ListSubagents returns conversation IDs of subagents for a parent conversation.
 func (c *ShelleyClient) ListSubagents(ctx context.Context, jobID int64, parentConvID string) ([]string, error) {
+	ctx, cancel := c.requestContext(ctx, "ListSubagents")
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/conversations", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("X-Exedev-Userid", jobUserID(jobID))
+	req.Header.Set("X-Exedev-Userid", JobUserID(jobID))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -255,3 +746,47 @@ func (c *ShelleyClient) ListSubagents(ctx context.Context, jobID int64, parentCo
 
 	return subagents, nil
 }
+
+// ConversationSummary is a conversation as listed by ListConversations.
+type ConversationSummary struct {
+	ConversationID       string    `json:"conversation_id"`
+	UserID               string    `json:"user_id"`
+	CreatedAt            time.Time `json:"created_at"`
+	ParentConversationID string    `json:"parent_conversation_id"` // empty for top-level conversations
+	Cwd                  string    `json:"cwd"`                    // empty for API-created conversations
+}
+
+// ListConversations returns one page of the conversations visible to userID,
+// limited to those created as ownerID unless it is empty. Pages start at 1;
+// a page shorter than limit is the last.
+func (c *ShelleyClient) ListConversations(ctx context.Context, userID, ownerID string, page, limit int) ([]ConversationSummary, error) {
+	ctx, cancel := c.requestContext(ctx, "ListConversations")
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/conversations?page=%d&limit=%d", c.baseURL, page, limit)
+	if ownerID != "" {
+		url += "&user_id=" + neturl.QueryEscape(ownerID)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Exedev-Userid", userID)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newShelleyError(resp)
+	}
+
+	var conversations []ConversationSummary
+	if err := json.NewDecoder(resp.Body).Decode(&conversations); err != nil {
+		return nil, fmt.Errorf("decode conversations: %w", err)
+	}
+	return conversations, nil
+}
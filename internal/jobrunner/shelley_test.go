@@ -0,0 +1,241 @@
+package jobrunner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetMessageStats(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "conversation.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var conv Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+
+	// The fixture holds 5 messages carrying 2600 characters of text in total,
+	// mixing string-encoded and object llm_data plus one message without any.
+	const wantMessages = 5
+	const wantTokens = 2600 / 4
+
+	messages, tokens := conv.GetMessageStats()
+	if messages != wantMessages {
+		t.Errorf("messages = %d, want %d", messages, wantMessages)
+	}
+	if diff := math.Abs(float64(tokens-wantTokens)) / wantTokens; diff > 0.1 {
+		t.Errorf("estimated tokens = %d, want within 10%% of %d", tokens, wantTokens)
+	}
+}
+
+func TestGetMessageStatsEmpty(t *testing.T) {
+	var conv Conversation
+	if messages, tokens := conv.GetMessageStats(); messages != 0 || tokens != 0 {
+		t.Errorf("GetMessageStats() = (%d, %d), want (0, 0)", messages, tokens)
+	}
+}
+
+func TestGetAllAgentText(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "conversation_partial.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var conv Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+
+	// Two agent messages each report part of the results; the tool message
+	// in between must be ignored.
+	texts := conv.GetAllAgentText()
+	if len(texts) != 2 {
+		t.Fatalf("GetAllAgentText() returned %d texts, want 2: %q", len(texts), texts)
+	}
+	if !strings.HasPrefix(texts[0], "Found these so far") || !strings.Contains(texts[1], "GopherCon") {
+		t.Errorf("GetAllAgentText() = %q, want both agent messages in order", texts)
+	}
+	if full := conv.GetFullAgentText(); full != texts[0]+"\n"+texts[1] {
+		t.Errorf("GetFullAgentText() = %q, want texts joined by newline", full)
+	}
+
+	articles, err := ExtractConversationArticles(&conv)
+	if err != nil {
+		t.Fatalf("ExtractConversationArticles() error = %v", err)
+	}
+	var urls []string
+	for _, a := range articles {
+		urls = append(urls, a.URL)
+	}
+	// The final message repeats one earlier article and drops another
+	want := []string{"https://example.com/go-1-24", "https://example.com/tooling", "https://example.com/gophercon"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("extracted URLs = %v, want %v", urls, want)
+	}
+}
+
+func TestShelleyRequestTimeout(t *testing.T) {
+	// A stalled Shelley; scaled down from a 15s stall against a 10s timeout
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(1500 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := NewShelleyClient(srv.URL, WithRequestTimeout("GetConversation", 100*time.Millisecond))
+	if client.httpClient.Timeout != 0 {
+		t.Errorf("http.Client.Timeout = %v, want 0 (context deadlines only)", client.httpClient.Timeout)
+	}
+	if got := client.timeouts["ArchiveConversation"]; got != 5*time.Second {
+		t.Errorf("ArchiveConversation timeout = %v, want the 5s default", got)
+	}
+
+	start := time.Now()
+	_, err := client.GetConversation(context.Background(), 1, "conv-1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetConversation() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetConversation() took %v, want it cut off at the 100ms timeout", elapsed)
+	}
+}
+
+func TestCreateConversationIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	byKey := map[string]string{}
+	var requests, unkeyed int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		key := r.Header.Get("X-Idempotency-Key")
+		if key == "" {
+			unkeyed++
+			fmt.Fprintf(w, `{"conversation_id": "conv-unkeyed-%d"}`, unkeyed)
+			return
+		}
+		id, ok := byKey[key]
+		if !ok {
+			id = fmt.Sprintf("conv-%d", len(byKey)+1)
+			byKey[key] = id
+		}
+		fmt.Fprintf(w, `{"conversation_id": %q}`, id)
+	}))
+	defer srv.Close()
+	client := NewShelleyClient(srv.URL)
+	ctx := context.Background()
+	userID := JobUserID(7)
+
+	first, err := client.CreateConversationWithKey(ctx, userID, DefaultModel, "find news", generateIdempotencyKey(userID, 7, 1))
+	if err != nil {
+		t.Fatalf("CreateConversationWithKey() error = %v", err)
+	}
+	// A retry after a lost response replays the first creation
+	again, err := client.CreateConversationWithKey(ctx, userID, DefaultModel, "find news", generateIdempotencyKey(userID, 7, 1))
+	if err != nil {
+		t.Fatalf("CreateConversationWithKey() retry error = %v", err)
+	}
+	next, err := client.CreateConversationWithKey(ctx, userID, DefaultModel, "find news", generateIdempotencyKey(userID, 7, 2))
+	if err != nil {
+		t.Fatalf("CreateConversationWithKey() for the next run error = %v", err)
+	}
+	if again != first {
+		t.Errorf("retry returned %q, want the first conversation %q", again, first)
+	}
+	if next == first {
+		t.Errorf("the job's next run got the same conversation %q", next)
+	}
+
+	// CreateConversation sends the run's key itself
+	viaRun, err := client.CreateConversation(ctx, 7, 1, "find news")
+	if err != nil {
+		t.Fatalf("CreateConversation() error = %v", err)
+	}
+	if viaRun != first {
+		t.Errorf("CreateConversation() = %q, want the run's conversation %q", viaRun, first)
+	}
+	if requests != 4 || unkeyed != 0 {
+		t.Errorf("got %d requests, %d without a key, want 4 and 0", requests, unkeyed)
+	}
+	if key := generateIdempotencyKey(userID, 7, 1); replacementIdempotencyKey(key, first) == key {
+		t.Error("replacementIdempotencyKey() returned the key it replaces")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+	fail := errors.New("connection refused")
+
+	steps := []struct {
+		advance time.Duration
+		callErr error // what the call returns if it runs
+		wantErr error
+		want    circuitState
+	}{
+		{0, fail, fail, circuitClosed},
+		{0, nil, nil, circuitClosed}, // a success resets the count
+		{0, fail, fail, circuitClosed},
+		{0, fail, fail, circuitClosed},
+		{0, fail, fail, circuitOpen},
+		{30 * time.Second, nil, ErrCircuitOpen, circuitOpen},
+		{30 * time.Second, fail, fail, circuitOpen}, // failed probe
+		{59 * time.Second, nil, ErrCircuitOpen, circuitOpen},
+		{time.Second, nil, nil, circuitClosed}, // successful probe
+		{0, fail, fail, circuitClosed},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		called := false
+		err := b.Execute(func() error {
+			called = true
+			return step.callErr
+		})
+		if err != step.wantErr {
+			t.Errorf("step %d: Execute() = %v, want %v", i, err, step.wantErr)
+		}
+		if called != (step.wantErr != ErrCircuitOpen) {
+			t.Errorf("step %d: called = %v with error %v", i, called, err)
+		}
+		if got := b.currentState(); got != step.want {
+			t.Errorf("step %d: state = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOneProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+	b.Execute(func() error { return errors.New("timeout") })
+	now = now.Add(time.Minute)
+
+	// Calls made while the probe is in flight are refused
+	err := b.Execute(func() error {
+		if b.currentState() != circuitHalfOpen {
+			t.Errorf("state during probe = %v, want half-open", b.currentState())
+		}
+		if err := b.Execute(func() error { return nil }); err != ErrCircuitOpen {
+			t.Errorf("Execute() during probe = %v, want ErrCircuitOpen", err)
+		}
+		return nil
+	})
+	if err != nil || b.currentState() != circuitClosed {
+		t.Errorf("probe = %v, state %v, want closed", err, b.currentState())
+	}
+}
@@ -0,0 +1,212 @@
+package jobrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FetchStrategy is one way of getting an article's content. An
+// ArticleFetcher tries its strategies in order, using the first whose
+// CanHandle accepts the URL.
+type FetchStrategy interface {
+	CanHandle(url string) bool
+	Fetch(ctx context.Context, url string) (ArticleContent, error)
+}
+
+var (
+	// ErrFetchNotHandled is returned by a strategy's Fetch when the URL
+	// turned out not to be one it handles, passing it on to the next
+	// strategy in the chain.
+	ErrFetchNotHandled = errors.New("fetch strategy does not handle this URL")
+
+	// ErrUseSummary is returned by a strategy's Fetch to have the article's
+	// summary stand in for its content.
+	ErrUseSummary = errors.New("use the article summary as content")
+)
+
+// RegisterStrategy adds s to the fetcher's chain, ahead of the default
+// strategies and after any registered before it.
+func (f *ArticleFetcher) RegisterStrategy(s FetchStrategy) {
+	f.strategiesMu.Lock()
+	defer f.strategiesMu.Unlock()
+	// Build a new slice so chains already handed to fetches don't change
+	chain := make([]FetchStrategy, 0, len(f.strategies)+1)
+	chain = append(chain, f.strategies[:f.registered]...)
+	chain = append(chain, s)
+	f.strategies = append(chain, f.strategies[f.registered:]...)
+	f.registered++
+}
+
+// defaultStrategies is the chain every fetcher starts with: feeds, then
+// any HTTP page, then the summary for anything else.
+func (f *ArticleFetcher) defaultStrategies() []FetchStrategy {
+	return []FetchStrategy{&FeedFetchStrategy{fetcher: f}, &HTTPFetchStrategy{fetcher: f}, &SkipFetchStrategy{}}
+}
+
+// fetchWithStrategies gets the content of info's URL from the first
+// strategy in the chain that handles it, sending headers with any HTTP
+// requests. A URL no strategy handles gets the article's summary.
+func (f *ArticleFetcher) fetchWithStrategies(ctx context.Context, info ArticleInfo, headers map[string]string) (ArticleContent, error) {
+	f.strategiesMu.RLock()
+	chain := f.strategies
+	f.strategiesMu.RUnlock()
+
+	ctx = withFetchHeaders(ctx, headers)
+	for _, s := range chain {
+		if !s.CanHandle(info.URL) {
+			continue
+		}
+		content, err := s.Fetch(ctx, info.URL)
+		switch {
+		case errors.Is(err, ErrFetchNotHandled):
+			continue
+		case errors.Is(err, ErrUseSummary):
+			return summaryContent(info), nil
+		}
+		return content, err
+	}
+	return summaryContent(info), nil
+}
+
+// summaryContent is the content of an article that isn't fetched.
+func summaryContent(info ArticleInfo) ArticleContent {
+	if info.Summary == "" {
+		return ArticleContent{Text: "[Content not fetched]"}
+	}
+	return ArticleContent{Text: info.Summary}
+}
+
+type fetchHeadersKey struct{}
+
+// withFetchHeaders attaches the custom headers of a fetch to ctx, since
+// FetchStrategy.Fetch only takes a URL.
+func withFetchHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fetchHeadersKey{}, headers)
+}
+
+// fetchHeadersFromContext returns the headers attached by withFetchHeaders.
+func fetchHeadersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(fetchHeadersKey{}).(map[string]string)
+	return headers
+}
+
+type fetchHeaderDomainsKey struct{}
+
+// withFetchHeaderDomains attaches the domains a job's custom headers may be
+// sent to, so redirects elsewhere can drop them.
+func withFetchHeaderDomains(ctx context.Context, domains []string) context.Context {
+	if len(domains) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fetchHeaderDomainsKey{}, domains)
+}
+
+// fetchHeaderDomainsFromContext returns the domains attached by
+// withFetchHeaderDomains; none means any domain.
+func fetchHeaderDomainsFromContext(ctx context.Context) []string {
+	domains, _ := ctx.Value(fetchHeaderDomainsKey{}).([]string)
+	return domains
+}
+
+// HTTPFetchStrategy fetches http and https pages with
+// ArticleFetcher.FetchArticleContent.
+type HTTPFetchStrategy struct {
+	fetcher *ArticleFetcher
+}
+
+func (s *HTTPFetchStrategy) CanHandle(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func (s *HTTPFetchStrategy) Fetch(ctx context.Context, url string) (ArticleContent, error) {
+	return s.fetcher.FetchArticleContent(ctx, url, fetchHeadersFromContext(ctx))
+}
+
+// feedPathSuffixes are the URL path endings FeedFetchStrategy takes to mean
+// an RSS or Atom feed.
+var feedPathSuffixes = []string{".rss", ".atom", "/rss.xml", "/atom.xml", "/feed.xml", "/feed", "/rss", "/atom"}
+
+// FeedFetchStrategy fetches URLs that look like RSS or Atom feeds and parses
+// them as feeds whatever Content-Type they are served with, which is often
+// application/xml or text/html. Responses that aren't feeds are passed on to
+// the next strategy.
+type FeedFetchStrategy struct {
+	fetcher *ArticleFetcher
+}
+
+func (s *FeedFetchStrategy) CanHandle(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	path := strings.TrimSuffix(strings.ToLower(u.Path), "/")
+	for _, suffix := range feedPathSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FeedFetchStrategy) Fetch(ctx context.Context, url string) (ArticleContent, error) {
+	f := s.fetcher
+	if f.config.RespectRobotsTxt && !f.robotsAllowed(ctx, url) {
+		return ArticleContent{Text: "[robots.txt] Disallowed: " + url}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return ArticleContent{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent())
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	for k, v := range fetchHeadersFromContext(ctx) {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := f.do(req)
+	if err != nil {
+		return ArticleContent{}, fmt.Errorf("fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ArticleContent{}, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 5*1024*1024))
+	if err != nil {
+		return ArticleContent{}, fmt.Errorf("read feed: %w", err)
+	}
+	content, err := parseFeedXML(data)
+	if err != nil {
+		return ArticleContent{}, fmt.Errorf("%w: %v", ErrFetchNotHandled, err)
+	}
+	if content == "" {
+		return ArticleContent{Text: "[Content could not be extracted from this page]"}, nil
+	}
+	return ArticleContent{Text: content}, nil
+}
+
+// SkipFetchStrategy doesn't fetch anything, using the article's summary as
+// its content. It handles URLs on Domains (lowercase, subdomains included),
+// or every URL when Domains is empty, as the last of the default chain does.
+type SkipFetchStrategy struct {
+	Domains []string
+}
+
+func (s *SkipFetchStrategy) CanHandle(url string) bool {
+	return urlMatchesDomains(url, s.Domains)
+}
+
+func (s *SkipFetchStrategy) Fetch(ctx context.Context, url string) (ArticleContent, error) {
+	return ArticleContent{}, ErrUseSummary
+}
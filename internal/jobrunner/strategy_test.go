@@ -0,0 +1,78 @@
+package jobrunner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// recordingStrategy claims every URL and records what it fetched.
+type recordingStrategy struct {
+	fetched []string
+}
+
+func (s *recordingStrategy) CanHandle(url string) bool { return true }
+
+func (s *recordingStrategy) Fetch(ctx context.Context, url string) (ArticleContent, error) {
+	s.fetched = append(s.fetched, url)
+	return ArticleContent{Text: "from strategy"}, nil
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, "<html><body><p>From HTTP.</p></body></html>")
+	}))
+	t.Cleanup(srv.Close)
+
+	runner, _, _ := newTestRunner(t, "http://shelley.invalid")
+	strategy := &recordingStrategy{}
+	runner.fetcher.RegisterStrategy(strategy)
+
+	contents, _ := runner.fetchArticleContents(context.Background(), []ArticleInfo{{Title: "A", URL: srv.URL + "/a"}}, 1, fetchHeaders{}, nil)
+	if contents[0].Text != "from strategy" || len(strategy.fetched) != 1 {
+		t.Errorf("content = %q, strategy fetched %q, want the registered strategy used", contents[0].Text, strategy.fetched)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("HTTP strategy made %d requests, want none", n)
+	}
+}
+
+func TestDefaultFetchStrategies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Both are served as HTML, so only the URL says which is a feed
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/news/feed":
+			fmt.Fprint(w, `<rss><channel><item><title>T</title><description>Feed item text.</description></item></channel></rss>`)
+		default:
+			fmt.Fprint(w, "<html><body><article><p>Page text that readability can find.</p></article></body></html>")
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewArticleFetcher(FetchConfig{CacheSize: -1})
+	f.RegisterStrategy(&SkipFetchStrategy{Domains: []string{"paywalled.invalid"}})
+	ctx := context.Background()
+
+	tests := []struct {
+		info ArticleInfo
+		want string
+	}{
+		{ArticleInfo{URL: srv.URL + "/news/feed"}, "Feed item text."},
+		{ArticleInfo{URL: srv.URL + "/story/feed"}, "Page text"}, // not a feed, so passed on to HTTP
+		{ArticleInfo{URL: "https://www.paywalled.invalid/a", Summary: "The summary."}, "The summary."},
+		{ArticleInfo{URL: "ftp://files.invalid/a", Summary: "Unfetchable."}, "Unfetchable."},
+	}
+	for _, tt := range tests {
+		content, err := f.fetchWithStrategies(ctx, tt.info, nil)
+		if err != nil || !strings.Contains(content.Text, tt.want) {
+			t.Errorf("fetch %s = %q, %v, want %q", tt.info.URL, content.Text, err, tt.want)
+		}
+	}
+}
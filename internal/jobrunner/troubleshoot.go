@@ -9,8 +9,16 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/exedev/news-app/internal/db"
+	"github.com/exedev/news-app/internal/util"
 )
 
+// JobRunner runs a job by ID. *Runner satisfies it; tests substitute a mock.
+type JobRunner interface {
+	Run(ctx context.Context, jobID int64) error
+}
+
 // TroubleshootConfig holds configuration for troubleshooting.
 type TroubleshootConfig struct {
 	DBPath     string
@@ -18,6 +26,8 @@ type TroubleshootConfig struct {
 	Lookback   time.Duration
 	LogDir     string
 	DryRun     bool
+	Fix        bool      // re-run failed jobs instead of creating a conversation
+	Runner     JobRunner // used by AutoFix to re-run jobs
 }
 
 // DefaultTroubleshootConfig returns default troubleshoot configuration.
@@ -47,6 +57,20 @@ type ProblemRun struct {
 type TroubleshootResult struct {
 	ProblemsFound  int
 	ConversationID string
+	Fix            *FixResult // set when cfg.Fix is true
+}
+
+// FixResult holds the outcome of automatically re-running failed jobs.
+type FixResult struct {
+	JobIDs    []int64         // jobs selected for a re-run
+	Succeeded []int64         // jobs that re-ran successfully
+	Failures  map[int64]error // jobs whose re-run failed, keyed by job ID
+	DryRun    bool
+}
+
+// OK reports whether every attempted fix succeeded.
+func (f *FixResult) OK() bool {
+	return len(f.Failures) == 0
 }
 
 // Troubleshoot identifies problematic job runs and creates a Shelley conversation.
@@ -66,14 +90,14 @@ func Troubleshoot(ctx context.Context, cfg TroubleshootConfig) (*TroubleshootRes
 	}
 
 	// Open database
-	db, err := sql.Open("sqlite", cfg.DBPath)
+	dbConn, err := db.OpenReadOnly(cfg.DBPath)
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
-	defer db.Close()
+	defer dbConn.Close()
 
 	// Find problematic runs
-	problems, err := findProblemRuns(ctx, db, cfg.Lookback)
+	problems, err := findProblemRuns(ctx, dbConn, cfg.Lookback)
 	if err != nil {
 		return nil, fmt.Errorf("find problem runs: %w", err)
 	}
@@ -96,6 +120,11 @@ func Troubleshoot(ctx context.Context, cfg TroubleshootConfig) (*TroubleshootRes
 			"error", p.ErrorMessage)
 	}
 
+	if cfg.Fix {
+		result.Fix = AutoFix(ctx, cfg, problems)
+		return result, nil
+	}
+
 	if cfg.DryRun {
 		logger.Info("dry run - not creating conversation")
 		return result, nil
@@ -116,6 +145,52 @@ func Troubleshoot(ctx context.Context, cfg TroubleshootConfig) (*TroubleshootRes
 	return result, nil
 }
 
+// AutoFix re-runs each job that has a failed run among problems. Jobs with
+// several failed runs are only re-run once. In dry-run mode the jobs are
+// selected and logged but not run.
+func AutoFix(ctx context.Context, cfg TroubleshootConfig, problems []ProblemRun) *FixResult {
+	logger := slog.Default()
+	result := &FixResult{
+		Failures: make(map[int64]error),
+		DryRun:   cfg.DryRun,
+	}
+
+	seen := make(map[int64]bool)
+	for _, p := range problems {
+		if p.Status != util.StatusFailed || seen[p.JobID] {
+			continue
+		}
+		seen[p.JobID] = true
+		result.JobIDs = append(result.JobIDs, p.JobID)
+	}
+
+	for _, jobID := range result.JobIDs {
+		if cfg.DryRun {
+			logger.Info("dry run - would re-run job", "job_id", jobID)
+			continue
+		}
+		if cfg.Runner == nil {
+			result.Failures[jobID] = fmt.Errorf("no job runner configured")
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			result.Failures[jobID] = err
+			continue
+		}
+
+		logger.Info("re-running failed job", "job_id", jobID)
+		if err := cfg.Runner.Run(ctx, jobID); err != nil {
+			logger.Warn("re-run failed", "job_id", jobID, "error", err)
+			result.Failures[jobID] = err
+			continue
+		}
+		logger.Info("re-run succeeded", "job_id", jobID)
+		result.Succeeded = append(result.Succeeded, jobID)
+	}
+
+	return result
+}
+
 func findProblemRuns(ctx context.Context, db *sql.DB, lookback time.Duration) ([]ProblemRun, error) {
 	cutoff := time.Now().Add(-lookback).UTC().Format("2006-01-02 15:04:05")
 
@@ -0,0 +1,75 @@
+package jobrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// mockJobRunner records the job IDs it is asked to run.
+type mockJobRunner struct {
+	ran  []int64
+	fail map[int64]bool
+}
+
+func (m *mockJobRunner) Run(ctx context.Context, jobID int64) error {
+	m.ran = append(m.ran, jobID)
+	if m.fail[jobID] {
+		return errors.New("run failed")
+	}
+	return nil
+}
+
+func TestAutoFix(t *testing.T) {
+	problems := []ProblemRun{
+		{RunID: 1, JobID: 10, Status: "failed"},
+		{RunID: 2, JobID: 20, Status: "cancelled"},
+		{RunID: 3, JobID: 10, Status: "failed"},
+		{RunID: 4, JobID: 30, Status: "completed"},
+		{RunID: 5, JobID: 40, Status: "failed"},
+	}
+
+	runner := &mockJobRunner{fail: map[int64]bool{40: true}}
+	cfg := DefaultTroubleshootConfig()
+	cfg.Runner = runner
+
+	result := AutoFix(context.Background(), cfg, problems)
+
+	if got, want := fmt.Sprint(runner.ran), "[10 40]"; got != want {
+		t.Errorf("ran jobs = %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprint(result.Succeeded), "[10]"; got != want {
+		t.Errorf("Succeeded = %s, want %s", got, want)
+	}
+	if _, ok := result.Failures[40]; !ok || len(result.Failures) != 1 {
+		t.Errorf("Failures = %v, want only job 40", result.Failures)
+	}
+	if result.OK() {
+		t.Error("OK() = true, want false")
+	}
+}
+
+func TestAutoFixDryRun(t *testing.T) {
+	problems := []ProblemRun{
+		{RunID: 1, JobID: 10, Status: "failed"},
+		{RunID: 2, JobID: 20, Status: "failed"},
+	}
+
+	runner := &mockJobRunner{}
+	cfg := DefaultTroubleshootConfig()
+	cfg.Runner = runner
+	cfg.DryRun = true
+
+	result := AutoFix(context.Background(), cfg, problems)
+
+	if len(runner.ran) != 0 {
+		t.Errorf("dry run ran jobs %v, want none", runner.ran)
+	}
+	if got, want := fmt.Sprint(result.JobIDs), "[10 20]"; got != want {
+		t.Errorf("JobIDs = %s, want %s", got, want)
+	}
+	if !result.OK() {
+		t.Errorf("OK() = false, want true")
+	}
+}
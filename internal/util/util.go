@@ -2,7 +2,10 @@
 package util
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,12 +20,13 @@ const (
 
 // Job/run status constants
 const (
-	StatusPending   = "pending"
-	StatusRunning   = "running"
-	StatusCompleted = "completed"
-	StatusFailed    = "failed"
-	StatusStopped   = "stopped"
-	StatusCancelled = "cancelled"
+	StatusPending       = "pending"
+	StatusRunning       = "running"
+	StatusCompleted     = "completed"
+	StatusFailed        = "failed"
+	StatusStopped       = "stopped"
+	StatusCancelled     = "cancelled"
+	StatusQuotaExceeded = "quota_exceeded"
 )
 
 // GetEnv returns the value of the environment variable, or the default if not set.
@@ -33,24 +37,69 @@ func GetEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+// GetEnvDuration parses a Go duration (e.g. "24h") from an env var, falling
+// back to defaultVal when it is unset or invalid.
+func GetEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if d, err := time.ParseDuration(GetEnv(key, "")); err == nil {
+		return d
+	}
+	return defaultVal
+}
+
+// GetEnvInt parses an integer env var, falling back to defaultVal when it is
+// unset or invalid.
+func GetEnvInt(key string, defaultVal int) int {
+	if i, err := strconv.Atoi(GetEnv(key, "")); err == nil {
+		return i
+	}
+	return defaultVal
+}
+
+// GetEnvBool parses a boolean env var ("1", "true", "0", "false", ...),
+// falling back to defaultVal when it is unset or invalid.
+func GetEnvBool(key string, defaultVal bool) bool {
+	if b, err := strconv.ParseBool(GetEnv(key, "")); err == nil {
+		return b
+	}
+	return defaultVal
+}
+
+// RedactURL masks the password in a URL, keeping the rest for debugging.
+func RedactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
+
 // CalculateNextRun returns the next scheduled run time based on frequency.
 // If isOneTime is true, returns a time 10 seconds in the future.
 func CalculateNextRun(frequency string, isOneTime bool) time.Time {
-	now := time.Now()
+	return CalculateNextRunFrom(frequency, isOneTime, time.Now())
+}
+
+// CalculateNextRunFrom is CalculateNextRun measured from a given time rather
+// than now, so a job can keep its cadence by passing its previous scheduled
+// run instead of the time a run finished.
+func CalculateNextRunFrom(frequency string, isOneTime bool, from time.Time) time.Time {
 	if isOneTime {
-		return now.Add(10 * time.Second)
+		return from.Add(10 * time.Second)
 	}
 	switch frequency {
 	case FreqHourly:
-		return now.Add(1 * time.Hour)
+		return from.Add(1 * time.Hour)
 	case Freq6Hours:
-		return now.Add(6 * time.Hour)
+		return from.Add(6 * time.Hour)
 	case FreqDaily:
-		return now.Add(24 * time.Hour)
+		return from.Add(24 * time.Hour)
 	case FreqWeekly:
-		return now.Add(7 * 24 * time.Hour)
+		return from.Add(7 * 24 * time.Hour)
 	default:
-		return now.Add(24 * time.Hour)
+		return from.Add(24 * time.Hour)
 	}
 }
 
@@ -70,3 +119,112 @@ func FrequencyToCalendar(freq string) string {
 	}
 }
 
+// DefaultScheduleTime is the time of day daily and weekly jobs run at.
+// It matches the OnCalendar specs produced by FrequencyToCalendar.
+const DefaultScheduleTime = "06:00"
+
+// NextRunTimes returns the next n times a job with the given frequency will be
+// triggered after from. The times follow the systemd calendar specs produced by
+// FrequencyToCalendar; scheduleTime ("15:04") overrides the time of day used for
+// daily and weekly jobs and defaults to DefaultScheduleTime when empty. A
+// negative n is an error.
+func NextRunTimes(freq, scheduleTime string, from time.Time, n int) ([]time.Time, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid run count %d", n)
+	}
+	if scheduleTime == "" {
+		scheduleTime = DefaultScheduleTime
+	}
+	tod, err := time.Parse("15:04", scheduleTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule time %q: %w", scheduleTime, err)
+	}
+
+	times := make([]time.Time, 0, n)
+	next := from
+	for len(times) < n {
+		next = nextTrigger(freq, tod, next)
+		times = append(times, next)
+	}
+	return times, nil
+}
+
+// nextTrigger returns the first calendar trigger strictly after t.
+func nextTrigger(freq string, tod, t time.Time) time.Time {
+	loc := t.Location()
+	y, m, d := t.Date()
+	atTime := func(day, hour, min int) time.Time {
+		return time.Date(y, m, day, hour, min, 0, 0, loc)
+	}
+
+	switch freq {
+	case FreqHourly:
+		// From the local hour rather than t.Truncate(time.Hour), which
+		// rounds in UTC and is off in zones with a half-hour offset
+		next := atTime(d, t.Hour(), 0)
+		for !next.After(t) {
+			next = next.Add(time.Hour)
+		}
+		return next
+	case Freq6Hours:
+		next := atTime(d, t.Hour()-t.Hour()%6, 0)
+		for !next.After(t) {
+			next = next.Add(6 * time.Hour)
+		}
+		return next
+	case FreqWeekly:
+		next := atTime(d, tod.Hour(), tod.Minute())
+		for next.Weekday() != time.Monday || !next.After(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	default: // FreqDaily and unknown frequencies
+		next := atTime(d, tod.Hour(), tod.Minute())
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+// FormatCost formats an amount in US dollars to the cent, such as "$0.12".
+// Amounts too small to show as a cent are written "<$0.01".
+func FormatCost(usd float64) string {
+	if usd > 0 && usd < 0.01 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// FormatRelativeTime returns a short human-readable label for a future time,
+// such as "in 20 minutes", "today at 18:00", "tomorrow at 06:00" or "in 3 days".
+func FormatRelativeTime(t time.Time) string {
+	return formatRelativeTime(t, time.Now())
+}
+
+func formatRelativeTime(t, now time.Time) string {
+	diff := t.Sub(now)
+	switch {
+	case diff < time.Minute:
+		return "now"
+	case diff < time.Hour:
+		mins := int(diff / time.Minute)
+		if mins == 1 {
+			return "in 1 minute"
+		}
+		return fmt.Sprintf("in %d minutes", mins)
+	}
+
+	t = t.In(now.Location())
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	days := int(t.Sub(today).Hours() / 24)
+	switch days {
+	case 0:
+		return "today at " + t.Format("15:04")
+	case 1:
+		return "tomorrow at " + t.Format("15:04")
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
@@ -46,3 +46,151 @@ func TestCalculateNextRun(t *testing.T) {
 	}
 }
 
+func TestCalculateNextRunFrom(t *testing.T) {
+	from := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	cases := []struct {
+		freq      string
+		isOneTime bool
+		want      time.Duration
+	}{
+		{"hourly", false, time.Hour},
+		{"6hours", false, 6 * time.Hour},
+		{"daily", false, 24 * time.Hour},
+		{"weekly", false, 7 * 24 * time.Hour},
+		{"unknown", false, 24 * time.Hour},
+		{"weekly", true, 10 * time.Second},
+	}
+	for _, tc := range cases {
+		if got := CalculateNextRunFrom(tc.freq, tc.isOneTime, from); !got.Equal(from.Add(tc.want)) {
+			t.Errorf("CalculateNextRunFrom(%q, %v) = %v, want %v", tc.freq, tc.isOneTime, got, from.Add(tc.want))
+		}
+	}
+}
+
+func TestNextRunTimes(t *testing.T) {
+	from := time.Now()
+	times, err := NextRunTimes("daily", "", from, 5)
+	if err != nil {
+		t.Fatalf("NextRunTimes() error = %v", err)
+	}
+	if len(times) != 5 {
+		t.Fatalf("expected 5 times, got %d", len(times))
+	}
+
+	prev := from
+	for i, next := range times {
+		if !next.After(prev) {
+			t.Errorf("time %d (%v) is not after %v", i, next, prev)
+		}
+		if i > 0 {
+			diff := next.Sub(prev)
+			// Allow 1 hour tolerance for DST transitions
+			if diff < 23*time.Hour || diff > 25*time.Hour {
+				t.Errorf("time %d: expected ~24h after previous, got %v", i, diff)
+			}
+		}
+		prev = next
+	}
+}
+
+func TestNextRunTimesCalendar(t *testing.T) {
+	// Wednesday 2024-01-03 10:30 UTC
+	from := time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		freq string
+		want time.Time
+	}{
+		{"hourly", time.Date(2024, 1, 3, 11, 0, 0, 0, time.UTC)},
+		{"6hours", time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+		{"daily", time.Date(2024, 1, 4, 6, 0, 0, 0, time.UTC)},
+		{"weekly", time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		times, err := NextRunTimes(tc.freq, "", from, 1)
+		if err != nil {
+			t.Fatalf("frequency %q: error = %v", tc.freq, err)
+		}
+		if !times[0].Equal(tc.want) {
+			t.Errorf("frequency %q: expected %v, got %v", tc.freq, tc.want, times[0])
+		}
+	}
+
+	// Hourly runs are on the local hour, also in zones offset by a half hour
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	times, err := NextRunTimes("hourly", "", time.Date(2024, 1, 3, 10, 45, 0, 0, kolkata), 2)
+	if err != nil {
+		t.Fatalf("hourly in IST: error = %v", err)
+	}
+	if want := time.Date(2024, 1, 3, 11, 0, 0, 0, kolkata); !times[0].Equal(want) || !times[1].Equal(want.Add(time.Hour)) {
+		t.Errorf("hourly in IST: got %v, want %v and an hour later", times, want)
+	}
+
+	if _, err := NextRunTimes("daily", "", from, -1); err == nil {
+		t.Error("expected error for negative count")
+	}
+	if _, err := NextRunTimes("daily", "25:99", from, 1); err == nil {
+		t.Error("expected error for invalid schedule time")
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(20 * time.Minute), "in 20 minutes"},
+		{time.Date(2024, 1, 3, 18, 0, 0, 0, time.UTC), "today at 18:00"},
+		{time.Date(2024, 1, 4, 6, 0, 0, 0, time.UTC), "tomorrow at 06:00"},
+		{time.Date(2024, 1, 6, 6, 0, 0, 0, time.UTC), "in 3 days"},
+	}
+
+	for _, tc := range cases {
+		if got := formatRelativeTime(tc.t, now); got != tc.want {
+			t.Errorf("formatRelativeTime(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	cases := []struct {
+		value string
+		def   bool
+		want  bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{"1", false, true},
+		{"true", false, true},
+		{"0", true, false},
+		{"false", true, false},
+		{"yes", true, true},
+		{"yes", false, false},
+	}
+
+	for _, tc := range cases {
+		t.Setenv("NEWS_TEST_BOOL", tc.value)
+		if got := GetEnvBool("NEWS_TEST_BOOL", tc.def); got != tc.want {
+			t.Errorf("GetEnvBool(%q, %v) = %v, want %v", tc.value, tc.def, got, tc.want)
+		}
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	cases := []struct {
+		usd  float64
+		want string
+	}{
+		{0, "$0.00"},
+		{0.004, "<$0.01"},
+		{0.12, "$0.12"},
+		{3.456, "$3.46"},
+	}
+
+	for _, tc := range cases {
+		if got := FormatCost(tc.usd); got != tc.want {
+			t.Errorf("FormatCost(%v) = %q, want %q", tc.usd, got, tc.want)
+		}
+	}
+}
@@ -1,104 +1,226 @@
 package web
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
 	"github.com/exedev/news-app/internal/util"
 )
 
 type CreateJobRequest struct {
-	Name      string `json:"name"`
-	Prompt    string `json:"prompt"`
-	Keywords  string `json:"keywords"`
-	Sources   string `json:"sources"`
-	Region    string `json:"region"`
-	Frequency string `json:"frequency"`
-	IsOneTime bool   `json:"is_one_time"`
+	Name               string `json:"name"`
+	Prompt             string `json:"prompt"`
+	Keywords           string `json:"keywords"`
+	Sources            string `json:"sources"`
+	Region             string `json:"region"`
+	Frequency          string `json:"frequency"`
+	IsOneTime          bool   `json:"is_one_time"`
+	FeedURL            string `json:"feed_url"`
+	FetchHeaders       string `json:"fetch_headers"`
+	FetchHeaderDomains string `json:"fetch_header_domains"`
+	PromptTemplate     string   `json:"prompt_template"`
+	Tags               []string `json:"tags"`
+	ScheduleJitterSecs *int64   `json:"schedule_jitter_secs"` // nil uses NEWS_JOB_START_DELAY_SECS
 }
 
 type UpdateJobRequest struct {
-	Name      string `json:"name"`
-	Prompt    string `json:"prompt"`
-	Keywords  string `json:"keywords"`
-	Sources   string `json:"sources"`
-	Region    string `json:"region"`
-	Frequency string `json:"frequency"`
-	IsActive  bool   `json:"is_active"`
+	Name               string `json:"name"`
+	Prompt             string `json:"prompt"`
+	Keywords           string `json:"keywords"`
+	Sources            string `json:"sources"`
+	Region             string `json:"region"`
+	Frequency          string `json:"frequency"`
+	IsActive           bool    `json:"is_active"`
+	FeedURL            *string `json:"feed_url"` // nil keeps the job's feed
+	FetchHeaders       string  `json:"fetch_headers"`
+	FetchHeaderDomains string `json:"fetch_header_domains"`
+	PromptTemplate     string        `json:"prompt_template"`
+	Tags               []string      `json:"tags"`
+	ScheduleJitterSecs optionalInt64 `json:"schedule_jitter_secs"` // omitted keeps the job's value; null uses NEWS_JOB_START_DELAY_SECS
+}
+
+// optionalInt64 is a nullable JSON integer that also records whether the
+// field was present, so an update can tell an omitted field from null.
+type optionalInt64 struct {
+	Set   bool
+	Value *int64
+}
+
+func (o *optionalInt64) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// missingJobFields returns the error for a job without its required fields,
+// or "" if it has them: every job needs a name, and jobs that search
+// rather than read a feed also need a prompt.
+func missingJobFields(name, prompt, feedURL string) string {
+	switch {
+	case feedURL != "" && name == "":
+		return "name is required"
+	case feedURL == "" && (name == "" || prompt == ""):
+		return "name and prompt are required"
+	}
+	return ""
+}
+
+// validateScheduleJitter checks a schedule_jitter_secs value: unset, or
+// between zero and one period of the job's frequency. The cap keeps the
+// delay from running into the next scheduled run or overflowing a
+// time.Duration.
+func validateScheduleJitter(secs *int64, frequency string) error {
+	if secs == nil {
+		return nil
+	}
+	now := time.Now()
+	max := int64(util.CalculateNextRunFrom(frequency, false, now).Sub(now) / time.Second)
+	if *secs < 0 || *secs > max {
+		return fmt.Errorf("schedule_jitter_secs must be between 0 and %d for this frequency", max)
+	}
+	return nil
 }
 
 type UpdatePreferencesRequest struct {
-	SystemPrompt  string `json:"system_prompt"`
-	DiscordWebhook string `json:"discord_webhook"`
-	NotifySuccess bool   `json:"notify_success"`
-	NotifyFailure bool   `json:"notify_failure"`
+	SystemPrompt       string `json:"system_prompt"`
+	DiscordWebhook     string `json:"discord_webhook"`
+	NotifySuccess      bool   `json:"notify_success"`
+	NotifyFailure      bool   `json:"notify_failure"`
+	NotifyWeeklyDigest bool   `json:"notify_weekly_digest"`
+	DigestDay          string `json:"digest_day"`
+	UseDiscordEmbeds   bool   `json:"use_discord_embeds"`
+}
+
+// SetQuotaRequest sets a user's daily Shelley conversation quota. 0 means
+// unlimited.
+type SetQuotaRequest struct {
+	DailyQuota int64 `json:"daily_quota"`
+}
+
+// DeleteUserRequest confirms an account deletion. Confirm must equal
+// deleteAccountConfirmation.
+type DeleteUserRequest struct {
+	Confirm string `json:"confirm"`
 }
 
+// deleteAccountConfirmation is the phrase a user must send to delete their
+// own account.
+const deleteAccountConfirmation = "DELETE MY ACCOUNT"
+
 func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
 	// Rate limit job creation per user
 	rateLimitKey := fmt.Sprintf("create-job:%d", user.ID)
-	if !s.rateLimiter.Allow(rateLimitKey) {
-		s.jsonError(w, "Rate limit exceeded: please wait before creating another job", http.StatusTooManyRequests)
+	if !s.allowRequest(r, rateLimitKey) {
+		s.jsonError(w, r, "Rate limit exceeded: please wait before creating another job", http.StatusTooManyRequests, ErrCodeRateLimited)
 		return
 	}
 	
 	var req CreateJobRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	
-	if req.Name == "" || req.Prompt == "" {
-		s.jsonError(w, "Invalid request: name and prompt are required", http.StatusBadRequest)
+	// Feed jobs don't use a prompt, so only require one for Shelley jobs
+	if msg := missingJobFields(req.Name, req.Prompt, req.FeedURL); msg != "" {
+		s.jsonError(w, r, "Invalid request: "+msg, http.StatusBadRequest)
+		return
+	}
+	if req.FeedURL != "" && !isHTTPURL(req.FeedURL) {
+		s.jsonError(w, r, "Invalid request: feed_url must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+	if err := jobrunner.ValidateFetchHeaders(req.FetchHeaders); err != nil {
+		s.jsonError(w, r, "Invalid request: fetch_headers must be a JSON object of header names to values", http.StatusBadRequest)
+		return
+	}
+	if err := jobrunner.ValidatePromptTemplate(req.PromptTemplate); err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateScheduleJitter(req.ScheduleJitterSecs, req.Frequency); err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	
-	nextRun := util.CalculateNextRun(req.Frequency, req.IsOneTime)
+	nextRun := util.CalculateNextRunFrom(req.Frequency, req.IsOneTime, time.Now())
 	
 	job, err := s.Queries.CreateJob(r.Context(), dbgen.CreateJobParams{
-		UserID:    user.ID,
-		Name:      req.Name,
-		Prompt:    req.Prompt,
-		Keywords:  req.Keywords,
-		Sources:   req.Sources,
-		Region:    req.Region,
-		Frequency: req.Frequency,
-		IsOneTime: boolToInt64(req.IsOneTime),
-		NextRunAt: &nextRun,
+		UserID:             user.ID,
+		Name:               req.Name,
+		Prompt:             req.Prompt,
+		Keywords:           req.Keywords,
+		Sources:            req.Sources,
+		Region:             req.Region,
+		Frequency:          req.Frequency,
+		IsOneTime:          boolToInt64(req.IsOneTime),
+		FeedUrl:            req.FeedURL,
+		FetchHeaders:       req.FetchHeaders,
+		FetchHeaderDomains: req.FetchHeaderDomains,
+		PromptTemplate:     req.PromptTemplate,
+		ScheduleJitterSecs: req.ScheduleJitterSecs,
+		NextRunAt:          &nextRun,
 	})
 	if err != nil {
-		s.jsonError(w, "Failed to create job", http.StatusInternalServerError)
+		s.jsonError(w, r, "Failed to create job", http.StatusInternalServerError)
 		return
 	}
 	
+	if len(tags) > 0 {
+		if err := s.setJobTags(r.Context(), user.ID, job.ID, tags); err != nil {
+			slog.Error("failed to set job tags", "job_id", job.ID, "user_id", user.ID, "error", err)
+		}
+	}
+	
 	// Create systemd timer
 	if err := createSystemdTimer(job); err != nil {
 		slog.Warn("failed to create systemd timer", "job_id", job.ID, "error", err)
 	}
 	
 	slog.Info("job created", "job_id", job.ID, "user_id", user.ID, "name", job.Name)
-	s.jsonOK(w, job)
+	s.jsonOK(w, redactJob(job))
+}
+
+// redactJob returns job with its fetch header values hidden, since they may
+// hold credentials, so it can be returned from the API.
+func redactJob(job dbgen.Job) dbgen.Job {
+	job.FetchHeaders = jobrunner.RedactFetchHeaders(job.FetchHeaders)
+	return job
 }
 
 func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -109,27 +231,80 @@ func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	
 	var req UpdateJobRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	current, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+	feedURL := current.FeedUrl
+	if req.FeedURL != nil {
+		feedURL = *req.FeedURL
+	}
+	if msg := missingJobFields(req.Name, req.Prompt, feedURL); msg != "" {
+		s.jsonError(w, r, "Invalid request: "+msg, http.StatusBadRequest)
+		return
+	}
+	if feedURL != "" && !isHTTPURL(feedURL) {
+		s.jsonError(w, r, "Invalid request: feed_url must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+	if err := jobrunner.ValidateFetchHeaders(req.FetchHeaders); err != nil {
+		s.jsonError(w, r, "Invalid request: fetch_headers must be a JSON object of header names to values", http.StatusBadRequest)
+		return
+	}
+	if err := jobrunner.ValidatePromptTemplate(req.PromptTemplate); err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The edit page doesn't send it, so leaving it out keeps the job's delay
+	jitter := current.ScheduleJitterSecs
+	if req.ScheduleJitterSecs.Set {
+		jitter = req.ScheduleJitterSecs.Value
+	}
+	if err := validateScheduleJitter(jitter, req.Frequency); err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	
 	err = s.Queries.UpdateJob(r.Context(), dbgen.UpdateJobParams{
-		Name:      req.Name,
-		Prompt:    req.Prompt,
-		Keywords:  req.Keywords,
-		Sources:   req.Sources,
-		Region:    req.Region,
-		Frequency: req.Frequency,
-		IsActive:  boolToInt64(req.IsActive),
-		ID:        id,
-		UserID:    user.ID,
+		Name:               req.Name,
+		Prompt:             req.Prompt,
+		Keywords:           req.Keywords,
+		Sources:            req.Sources,
+		Region:             req.Region,
+		Frequency:          req.Frequency,
+		IsActive:           boolToInt64(req.IsActive),
+		FeedUrl:            feedURL,
+		FetchHeaders:       req.FetchHeaders,
+		FetchHeaderDomains: req.FetchHeaderDomains,
+		PromptTemplate:     req.PromptTemplate,
+		ScheduleJitterSecs: jitter,
+		ID:                 id,
+		UserID:             user.ID,
 	})
 	if err != nil {
 		slog.Error("failed to update job", "job_id", id, "user_id", user.ID, "error", err)
-		s.jsonError(w, "Failed to update job", http.StatusInternalServerError)
+		s.jsonError(w, r, "Failed to update job", http.StatusInternalServerError)
 		return
 	}
 	
+	// Omitted tags are left alone; an empty list clears them
+	if req.Tags != nil {
+		if err := s.setJobTags(r.Context(), user.ID, id, tags); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("failed to set job tags", "job_id", id, "user_id", user.ID, "error", err)
+			s.jsonError(w, r, "Failed to update job tags", http.StatusInternalServerError)
+			return
+		}
+	}
+	
 	// Update systemd timer
 	job, _ := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
 	updateSystemdTimer(job)
@@ -141,7 +316,7 @@ func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -150,13 +325,9 @@ func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Remove systemd timer first
-	removeSystemdTimer(id)
-	
-	err = s.Queries.DeleteJob(r.Context(), dbgen.DeleteJobParams{ID: id, UserID: user.ID})
-	if err != nil {
+	if err := s.deleteJob(r.Context(), user.ID, id); err != nil {
 		slog.Error("failed to delete job", "job_id", id, "user_id", user.ID, "error", err)
-		s.jsonError(w, "Failed to delete job", http.StatusInternalServerError)
+		s.jsonError(w, r, "Failed to delete job", http.StatusInternalServerError)
 		return
 	}
 	
@@ -164,17 +335,131 @@ func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 	s.jsonStatus(w, "ok")
 }
 
+// deleteJob removes a job's systemd timer and then the job itself.
+func (s *Server) deleteJob(ctx context.Context, userID, id int64) error {
+	// Remove systemd timer first
+	removeSystemdTimer(id)
+	return s.Queries.DeleteJob(ctx, dbgen.DeleteJobParams{ID: id, UserID: userID})
+}
+
+// bulkJobResult summarises a bulk job operation. Jobs that don't exist or
+// belong to another user are skipped without an error.
+type bulkJobResult struct {
+	Updated int      `json:"updated"`
+	Deleted int      `json:"deleted"`
+	Errors  []string `json:"errors"`
+}
+
+func (s *Server) handleBulkToggleJobs(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req struct {
+		IDs    []int64 `json:"ids"`
+		Active *bool   `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 || req.Active == nil {
+		s.jsonError(w, r, "Invalid request: ids and active are required", http.StatusBadRequest)
+		return
+	}
+
+	result := bulkJobResult{Errors: []string{}}
+	for _, id := range req.IDs {
+		job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+		if err != nil {
+			continue
+		}
+
+		job.IsActive = boolToInt64(*req.Active)
+		err = s.Queries.UpdateJob(r.Context(), dbgen.UpdateJobParams{
+			Name:               job.Name,
+			Prompt:             job.Prompt,
+			Keywords:           job.Keywords,
+			Sources:            job.Sources,
+			Region:             job.Region,
+			Frequency:          job.Frequency,
+			IsActive:           job.IsActive,
+			FeedUrl:            job.FeedUrl,
+			FetchHeaders:       job.FetchHeaders,
+			FetchHeaderDomains: job.FetchHeaderDomains,
+			PromptTemplate:     job.PromptTemplate,
+			ScheduleJitterSecs: job.ScheduleJitterSecs,
+			ID:                 job.ID,
+			UserID:             user.ID,
+		})
+		if err != nil {
+			slog.Error("failed to update job", "job_id", id, "user_id", user.ID, "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("job %d: failed to update", id))
+			continue
+		}
+		if err := updateSystemdTimer(job); err != nil {
+			slog.Warn("failed to update systemd timer", "job_id", id, "error", err)
+		}
+		result.Updated++
+	}
+
+	slog.Info("jobs bulk toggled", "user_id", user.ID, "active", *req.Active, "updated", result.Updated)
+	s.jsonOK(w, result)
+}
+
+func (s *Server) handleBulkDeleteJobs(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 {
+		s.jsonError(w, r, "Invalid request: no jobs specified", http.StatusBadRequest)
+		return
+	}
+
+	result := bulkJobResult{Errors: []string{}}
+	for _, id := range req.IDs {
+		// Verify ownership before touching the systemd timer
+		if _, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID}); err != nil {
+			continue
+		}
+		if err := s.deleteJob(r.Context(), user.ID, id); err != nil {
+			slog.Error("failed to delete job", "job_id", id, "user_id", user.ID, "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("job %d: failed to delete", id))
+			continue
+		}
+		result.Deleted++
+	}
+
+	slog.Info("jobs bulk deleted", "user_id", user.ID, "deleted", result.Deleted)
+	s.jsonOK(w, result)
+}
+
+// maxRunParallel caps the max_parallel override accepted by handleRunJob.
+const maxRunParallel = 20
+
 func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
 	// Rate limit job runs per user
 	rateLimitKey := fmt.Sprintf("run-job:%d", user.ID)
-	if !s.rateLimiter.Allow(rateLimitKey) {
-		s.jsonError(w, "Rate limit exceeded: please wait before running another job", http.StatusTooManyRequests)
+	if !s.allowRequest(r, rateLimitKey) {
+		s.jsonError(w, r, "Rate limit exceeded: please wait before running another job", http.StatusTooManyRequests, ErrCodeRateLimited)
 		return
 	}
 	
@@ -185,21 +470,51 @@ func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
 	
 	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
 	if err != nil {
-		s.jsonError(w, "Job not found", 404)
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
 		return
 	}
 	
 	if job.Status == util.StatusRunning {
-		s.jsonError(w, "Job is already running", http.StatusBadRequest)
+		s.jsonError(w, r, "Job is already running", http.StatusBadRequest, ErrCodeJobAlreadyRunning)
 		return
 	}
 	
-	// Run immediately via systemd
-	serviceName := jobServiceName(job.ID)
-	cmd := exec.Command("sudo", "systemctl", "start", serviceName+".service")
-	if err := cmd.Run(); err != nil {
-		slog.Warn("systemd start failed, running directly", "job_id", job.ID, "error", err)
-		go runJobDirectly(job.ID)
+	// Refuse rather than queue when every global run slot is taken
+	full, err := jobrunner.RunSlotsFull(r.Context(), s.Queries, s.runConfig)
+	if err != nil {
+		slog.Error("failed to count active runs", "error", err)
+		s.jsonError(w, r, "Failed to start job", http.StatusInternalServerError)
+		return
+	}
+	if full {
+		s.jsonError(w, r, "Server busy, too many concurrent runs", http.StatusServiceUnavailable, ErrCodeServerBusy)
+		return
+	}
+	
+	// Optional per-run overrides
+	var req struct {
+		MaxParallel int `json:"max_parallel"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MaxParallel < 0 || req.MaxParallel > maxRunParallel {
+		s.jsonError(w, r, fmt.Sprintf("max_parallel must be between 1 and %d", maxRunParallel), http.StatusBadRequest)
+		return
+	}
+	
+	if req.MaxParallel > 0 {
+		// systemd units can't take per-run settings, so run the override directly
+		go s.runJob(job.ID, req.MaxParallel)
+	} else {
+		// Run immediately via systemd
+		serviceName := jobServiceName(job.ID)
+		cmd := exec.Command("sudo", "systemctl", "start", serviceName+".service")
+		if err := cmd.Run(); err != nil {
+			slog.Warn("systemd start failed, running directly", "job_id", job.ID, "error", err)
+			go s.runJob(job.ID, 0)
+		}
 	}
 	
 	slog.Info("job started", "job_id", job.ID, "user_id", user.ID, "name", job.Name)
@@ -209,7 +524,7 @@ func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleStopJob(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -220,12 +535,12 @@ func (s *Server) handleStopJob(w http.ResponseWriter, r *http.Request) {
 	
 	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
 	if err != nil {
-		s.jsonError(w, "Job not found", 404)
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
 		return
 	}
 	
 	if job.Status != util.StatusRunning {
-		s.jsonError(w, "Job is not running", http.StatusBadRequest)
+		s.jsonError(w, r, "Job is not running", http.StatusBadRequest, ErrCodeNotRunning)
 		return
 	}
 	
@@ -247,10 +562,59 @@ func (s *Server) handleStopJob(w http.ResponseWriter, r *http.Request) {
 	s.jsonStatus(w, "stopped")
 }
 
+// NextRun is a single upcoming scheduled run of a job.
+type NextRun struct {
+	Time  time.Time `json:"time"`
+	Label string    `json:"label"`
+}
+
+// nextRunsCount is the number of upcoming runs returned by handleJobNextRuns.
+const nextRunsCount = 5
+
+func (s *Server) handleJobNextRuns(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	runs := []NextRun{}
+	switch {
+	case job.IsActive == 0:
+		// Inactive jobs have no upcoming runs
+	case job.IsOneTime == 1:
+		if job.Status == util.StatusPending && job.NextRunAt != nil {
+			runs = append(runs, NextRun{Time: *job.NextRunAt, Label: util.FormatRelativeTime(*job.NextRunAt)})
+		}
+	default:
+		times, err := util.NextRunTimes(job.Frequency, "", time.Now(), nextRunsCount)
+		if err != nil {
+			s.jsonError(w, r, "Failed to compute schedule", http.StatusInternalServerError)
+			return
+		}
+		for _, t := range times {
+			runs = append(runs, NextRun{Time: t, Label: util.FormatRelativeTime(t)})
+		}
+	}
+
+	s.jsonOK(w, runs)
+}
+
 func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -262,12 +626,12 @@ func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
 	// Verify the run belongs to this user
 	run, err := s.Queries.GetJobRun(r.Context(), dbgen.GetJobRunParams{ID: id, UserID: user.ID})
 	if err != nil {
-		s.jsonError(w, "Run not found", 404)
+		s.jsonError(w, r, "Run not found", 404, ErrCodeRunNotFound)
 		return
 	}
 	
 	if run.Status != util.StatusRunning {
-		s.jsonError(w, "Run is not running", http.StatusBadRequest)
+		s.jsonError(w, r, "Run is not running", http.StatusBadRequest, ErrCodeNotRunning)
 		return
 	}
 	
@@ -279,7 +643,7 @@ func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
 	// Mark the run as cancelled
 	if err := s.Queries.CancelJobRun(r.Context(), id); err != nil {
 		slog.Error("failed to cancel run", "run_id", id, "user_id", user.ID, "error", err)
-		s.jsonError(w, "Failed to cancel run", http.StatusInternalServerError)
+		s.jsonError(w, r, "Failed to cancel run", http.StatusInternalServerError)
 		return
 	}
 	
@@ -299,16 +663,68 @@ func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
 	s.jsonStatus(w, "cancelled")
 }
 
+// MaskedPreference is a user's preferences with secrets such as the Discord
+// webhook URL masked, safe to return from the API.
+type MaskedPreference struct {
+	dbgen.Preference
+	DiscordWebhook string `json:"discord_webhook"`
+}
+
+// maskSensitiveField hides all but the last four characters of a secret,
+// e.g. "…abcd". Empty values stay empty so clients can tell they're unset.
+func maskSensitiveField(s string) string {
+	if s == "" {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return "…"
+	}
+	return "…" + string(runes[len(runes)-4:])
+}
+
+// handleGetPreferences returns the user's preferences with secrets masked.
+func (s *Server) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	prefs, err := s.Queries.GetPreferences(r.Context(), user.ID)
+	if err == sql.ErrNoRows {
+		prefs, err = s.Queries.CreatePreferences(r.Context(), user.ID)
+	}
+	if err != nil {
+		slog.Error("failed to get preferences", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to get preferences", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, MaskedPreference{
+		Preference:     prefs,
+		DiscordWebhook: maskSensitiveField(prefs.DiscordWebhook),
+	})
+}
+
 func (s *Server) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
 	var req UpdatePreferencesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	
+	if req.DigestDay == "" {
+		req.DigestDay = jobrunner.DefaultDigestDay
+	}
+	if _, ok := jobrunner.DigestWeekday(req.DigestDay); !ok {
+		s.jsonError(w, r, "Digest day must be one of MON, TUE, WED, THU, FRI, SAT, SUN", http.StatusBadRequest)
 		return
 	}
 	
@@ -319,24 +735,49 @@ func (s *Server) handleUpdatePreferences(w http.ResponseWriter, r *http.Request)
 	}
 	
 	err = s.Queries.UpdatePreferences(r.Context(), dbgen.UpdatePreferencesParams{
-		SystemPrompt:   req.SystemPrompt,
-		DiscordWebhook: req.DiscordWebhook,
-		NotifySuccess:  boolToInt64(req.NotifySuccess),
-		NotifyFailure:  boolToInt64(req.NotifyFailure),
-		UserID:         user.ID,
+		SystemPrompt:       req.SystemPrompt,
+		DiscordWebhook:     req.DiscordWebhook,
+		NotifySuccess:      boolToInt64(req.NotifySuccess),
+		NotifyFailure:      boolToInt64(req.NotifyFailure),
+		NotifyWeeklyDigest: boolToInt64(req.NotifyWeeklyDigest),
+		DigestDay:          strings.ToUpper(req.DigestDay),
+		UseDiscordEmbeds:   boolToInt64(req.UseDiscordEmbeds),
+		UserID:             user.ID,
 	})
 	if err != nil {
-		s.jsonError(w, "Failed to update preferences", http.StatusInternalServerError)
+		s.jsonError(w, r, "Failed to update preferences", http.StatusInternalServerError)
 		return
 	}
 	
 	s.jsonStatus(w, "ok")
 }
 
+// handleGetArticle returns a single article, including its image URL
+// whether or not pages would display it.
+func (s *Server) handleGetArticle(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	article, err := s.Queries.GetArticle(r.Context(), dbgen.GetArticleParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Article not found", 404, ErrCodeArticleNotFound)
+		return
+	}
+	s.jsonOK(w, article)
+}
+
 func (s *Server) handleArticleContent(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -356,13 +797,124 @@ func (s *Server) handleArticleContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// ?inline=1 lets browsers show the file rather than download it
+	if r.URL.Query().Get("inline") != "1" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.txt"`, slugify(article.Title)))
+	}
+	w.Header().Set("Cache-Control", "no-store")
 	http.ServeFile(w, r, article.ContentPath)
 }
 
+// csvExportColumns are the columns of an article CSV export. They include
+// those news-app import-articles reads, so an export can be imported again.
+var csvExportColumns = []string{"id", "job_id", "title", "url", "summary", "author", "published_at", "source", "retrieved_at"}
+
+// csvFlushRows is how many rows StreamArticlesToCSV writes between flushes.
+const csvFlushRows = 500
+
+// handleExportCSV streams the user's articles matching the article list
+// filters as CSV, oldest first.
+func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="articles-%s.csv"`, time.Now().Format("2006-01-02")))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	
+	if err := StreamArticlesToCSV(r.Context(), s.DB, user.ID, w, parseArticlesFilters(r)); err != nil {
+		var started *csvStreamError
+		if errors.As(err, &started) {
+			// Rows have already gone out, so all that's left is to stop
+			slog.Error("article CSV export interrupted", "error", err, "user_id", user.ID)
+			return
+		}
+		slog.Error("failed to export articles", "error", err, "user_id", user.ID)
+		w.Header().Del("Content-Disposition")
+		s.jsonError(w, r, "Failed to export articles", http.StatusInternalServerError)
+	}
+}
+
+// csvStreamError is an error StreamArticlesToCSV hits after it has started
+// writing.
+type csvStreamError struct {
+	err error
+}
+
+func (e *csvStreamError) Error() string { return e.err.Error() }
+
+func (e *csvStreamError) Unwrap() error { return e.err }
+
+// StreamArticlesToCSV writes userID's unarchived articles matching filter to
+// w as CSV, oldest first, one row at a time from a database cursor so
+// memory use doesn't grow with the number of articles. Paging is ignored.
+// When w is an http.ResponseWriter it is flushed every csvFlushRows rows.
+// Errors after the header row has been written are *csvStreamError.
+func StreamArticlesToCSV(ctx context.Context, db *sql.DB, userID int64, w io.Writer, filter articlesFilter) error {
+	query, args := newArticleQueryBuilder(userID, filter).buildExportQuery(filter)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query articles: %w", err)
+	}
+	defer rows.Close()
+	
+	flush := func() error { return nil }
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rc := http.NewResponseController(rw)
+		flush = func() error {
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			return nil
+		}
+	}
+	
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvExportColumns); err != nil {
+		return &csvStreamError{err}
+	}
+	record := make([]string, len(csvExportColumns))
+	for n := 1; rows.Next(); n++ {
+		var a dbgen.Article
+		if err := rows.Scan(&a.ID, &a.JobID, &a.Title, &a.Url, &a.Summary, &a.Author, &a.PublishedAt, &a.Source, &a.RetrievedAt); err != nil {
+			return &csvStreamError{fmt.Errorf("scan article: %w", err)}
+		}
+		record[0] = strconv.FormatInt(a.ID, 10)
+		record[1] = strconv.FormatInt(a.JobID, 10)
+		record[2], record[3], record[4] = a.Title, a.Url, a.Summary
+		record[5], record[6], record[7] = a.Author, a.PublishedAt, a.Source
+		record[8] = a.RetrievedAt.UTC().Format(time.RFC3339)
+		if err := cw.Write(record); err != nil {
+			return &csvStreamError{err}
+		}
+		if n%csvFlushRows == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return &csvStreamError{err}
+			}
+			if err := flush(); err != nil {
+				return &csvStreamError{err}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &csvStreamError{fmt.Errorf("read articles: %w", err)}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return &csvStreamError{err}
+	}
+	return nil
+}
+
 func (s *Server) handleRunLog(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 	
@@ -377,11 +929,18 @@ func (s *Server) handleRunLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	serveRunLog(w, r, id, logPath)
+}
+
+// serveRunLog sends the log file of run runID as a download.
+func serveRunLog(w http.ResponseWriter, r *http.Request, runID int64, logPath string) {
 	if logPath == "" {
 		http.Error(w, "No log available for this run", 404)
 		return
 	}
-	
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="run_%d.log"`, runID))
+	w.Header().Set("Cache-Control", "no-store")
 	http.ServeFile(w, r, logPath)
 }
 
@@ -389,7 +948,7 @@ func (s *Server) handleRunLog(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleDeleteArticles(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
-		s.jsonUnauthorized(w)
+		s.jsonUnauthorized(w, r)
 		return
 	}
 
@@ -397,58 +956,195 @@ func (s *Server) handleDeleteArticles(w http.ResponseWriter, r *http.Request) {
 		IDs []int64 `json:"ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	if len(req.IDs) == 0 {
-		s.jsonError(w, "Invalid request: no articles specified", http.StatusBadRequest)
+		s.jsonError(w, r, "Invalid request: no articles specified", http.StatusBadRequest)
 		return
 	}
 
-	deleted, err := s.deleteArticlesWithFiles(r.Context(), user.ID, req.IDs)
+	// Deleting archives; archived articles are purged for good later
+	deleted, err := s.archiveArticles(r.Context(), user.ID, req.IDs)
 	if err != nil {
-		slog.Error("failed to delete articles", "error", err)
-		s.jsonError(w, "Failed to delete articles", http.StatusInternalServerError)
+		slog.Error("failed to archive articles", "error", err)
+		s.jsonError(w, r, "Failed to delete articles", http.StatusInternalServerError)
 		return
 	}
 
 	s.jsonOK(w, map[string]interface{}{"deleted": deleted})
 }
 
-// deleteArticlesWithFiles deletes articles and their content files.
-func (s *Server) deleteArticlesWithFiles(ctx context.Context, userID int64, ids []int64) (int64, error) {
+// archiveArticles marks articles as archived, hiding them from listings.
+// Articles that are already archived are left alone.
+func (s *Server) archiveArticles(ctx context.Context, userID int64, ids []int64) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
 
 	placeholders, args := buildINClause(userID, ids)
-
-	// Get content paths before deleting
 	query := fmt.Sprintf(
-		"SELECT content_path FROM articles WHERE user_id = ? AND id IN (%s) AND content_path != ''",
+		"UPDATE articles SET archived_at = CURRENT_TIMESTAMP WHERE user_id = ? AND id IN (%s) AND archived_at IS NULL",
 		placeholders,
 	)
-	rows, err := s.DB.QueryContext(ctx, query, args...)
+	result, err := s.DB.ExecContext(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("query content paths: %w", err)
+		return 0, fmt.Errorf("archive articles: %w", err)
 	}
+	return result.RowsAffected()
+}
 
-	// Delete files (ignoring errors - files may already be gone)
-	for rows.Next() {
-		var path string
-		if rows.Scan(&path) == nil && path != "" {
-			os.Remove(path)
-		}
+// ArchivedArticlesResponse is one page of a user's archived articles.
+type ArchivedArticlesResponse struct {
+	Articles []dbgen.Article `json:"articles"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	Pages    int             `json:"pages"`
+}
+
+// handleListArchivedArticles lists the user's archived articles, most
+// recently archived first.
+func (s *Server) handleListArchivedArticles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
 	}
-	rows.Close()
 
-	// Delete from database
-	deleteQuery := fmt.Sprintf("DELETE FROM articles WHERE user_id = ? AND id IN (%s)", placeholders)
-	result, err := s.DB.ExecContext(ctx, deleteQuery, args...)
+	page, limit, offset := parsePage(r)
+	articles, count, err := s.listArchivedArticles(r.Context(), user.ID, limit, offset)
 	if err != nil {
-		return 0, fmt.Errorf("delete articles: %w", err)
+		slog.Error("failed to list archived articles", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to list archived articles", http.StatusInternalServerError)
+		return
 	}
-	return result.RowsAffected()
+
+	setPaginationHeaders(w, r, page, count, limit)
+	s.jsonOK(w, ArchivedArticlesResponse{
+		Articles: articles,
+		Total:    count,
+		Page:     page,
+		Pages:    pageCount(count, limit),
+	})
+}
+
+// listArchivedArticles returns one page of a user's archived articles and
+// the total number archived.
+func (s *Server) listArchivedArticles(ctx context.Context, userID, limit, offset int64) ([]dbgen.Article, int64, error) {
+	articles, err := s.Queries.ListArchivedArticles(ctx, dbgen.ListArchivedArticlesParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("list archived articles: %w", err)
+	}
+	count, err := s.Queries.CountArchivedArticles(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count archived articles: %w", err)
+	}
+	return articles, count, nil
+}
+
+// handleRestoreArticle moves an archived article back into the user's
+// article list.
+func (s *Server) handleRestoreArticle(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	restored, err := s.Queries.RestoreArticle(r.Context(), dbgen.RestoreArticleParams{ID: id, UserID: user.ID})
+	if err != nil {
+		slog.Error("failed to restore article", "error", err, "article_id", id)
+		s.jsonError(w, r, "Failed to restore article", http.StatusInternalServerError)
+		return
+	}
+	if restored == 0 {
+		s.jsonError(w, r, "Archived article not found", 404, ErrCodeArticleNotFound)
+		return
+	}
+
+	s.jsonStatus(w, "restored")
+}
+
+// handlePurgeArticles permanently deletes the user's articles that have
+// been archived for longer than ArchiveRetention, along with their files.
+func (s *Server) handlePurgeArticles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	cutoff := time.Now().Add(-ArchiveRetention).UTC()
+	ids, err := s.Queries.ListPurgeableArticleIDs(r.Context(), dbgen.ListPurgeableArticleIDsParams{
+		UserID:     user.ID,
+		ArchivedAt: &cutoff,
+	})
+	if err != nil {
+		slog.Error("failed to list purgeable articles", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to purge articles", http.StatusInternalServerError)
+		return
+	}
+
+	purged, err := s.deleteArticlesWithFiles(r.Context(), user.ID, ids)
+	if err != nil {
+		slog.Error("failed to purge articles", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to purge articles", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, map[string]interface{}{"purged": purged})
+}
+
+// deleteArticlesWithFiles permanently deletes articles and their content
+// files. It backs purging the archive and deleting whole accounts.
+func (s *Server) deleteArticlesWithFiles(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders, args := buildINClause(userID, ids)
+
+	// Get content paths before deleting
+	query := fmt.Sprintf(
+		"SELECT content_path FROM articles WHERE user_id = ? AND id IN (%s) AND content_path != ''",
+		placeholders,
+	)
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query content paths: %w", err)
+	}
+
+	// Delete files (ignoring errors - files may already be gone)
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil && path != "" {
+			os.Remove(path)
+		}
+	}
+	rows.Close()
+
+	// Delete from database
+	deleteQuery := fmt.Sprintf("DELETE FROM articles WHERE user_id = ? AND id IN (%s)", placeholders)
+	result, err := s.DB.ExecContext(ctx, deleteQuery, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete articles: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
 func boolToInt64(b bool) int64 {
@@ -470,3 +1166,1556 @@ func buildINClause(userID int64, ids []int64) (string, []interface{}) {
 	}
 	return strings.Join(placeholders, ","), args
 }
+
+func (s *Server) handleGetReadingList(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	articles, err := s.Queries.GetReadingList(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to get reading list", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to get reading list", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, articles)
+}
+
+func (s *Server) handleAddToReadingList(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Verify the article belongs to this user
+	if _, err := s.Queries.GetArticle(r.Context(), dbgen.GetArticleParams{ID: req.ArticleID, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Article not found", 404, ErrCodeArticleNotFound)
+		return
+	}
+
+	added, err := s.Queries.AddToReadingList(r.Context(), dbgen.AddToReadingListParams{
+		UserID:    user.ID,
+		ArticleID: req.ArticleID,
+		UserID_2:  user.ID,
+	})
+	if err != nil {
+		slog.Error("failed to add to reading list", "article_id", req.ArticleID, "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to add to reading list", http.StatusInternalServerError)
+		return
+	}
+	if added == 0 {
+		s.jsonError(w, r, "Article is already in the reading list", http.StatusConflict)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+func (s *Server) handleRemoveFromReadingList(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	removed, err := s.Queries.RemoveFromReadingList(r.Context(), dbgen.RemoveFromReadingListParams{
+		UserID:    user.ID,
+		ArticleID: id,
+	})
+	if err != nil {
+		slog.Error("failed to remove from reading list", "article_id", id, "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to remove from reading list", http.StatusInternalServerError)
+		return
+	}
+	if removed == 0 {
+		s.jsonError(w, r, "Article is not in the reading list", 404)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+func (s *Server) handleReorderReadingList(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.reorderReadingList(r.Context(), user.ID, req.IDs); err != nil {
+		slog.Error("failed to reorder reading list", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to reorder reading list", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+// reorderReadingList assigns new positions to a user's reading list in a single
+// transaction. Listed articles come first in the given order; articles that are
+// not listed keep their relative order after them. Unknown IDs are ignored.
+func (s *Server) reorderReadingList(ctx context.Context, userID int64, ids []int64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.Queries.WithTx(tx)
+	current, err := q.GetReadingList(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get reading list: %w", err)
+	}
+
+	inList := make(map[int64]bool, len(current))
+	for _, a := range current {
+		inList[a.ID] = true
+	}
+
+	order := make([]int64, 0, len(current))
+	placed := make(map[int64]bool, len(current))
+	for _, id := range ids {
+		if inList[id] && !placed[id] {
+			order = append(order, id)
+			placed[id] = true
+		}
+	}
+	for _, a := range current {
+		if !placed[a.ID] {
+			order = append(order, a.ID)
+		}
+	}
+
+	for i, id := range order {
+		err := q.UpdateReadingListPosition(ctx, dbgen.UpdateReadingListPositionParams{
+			Position:  int64(i + 1),
+			UserID:    userID,
+			ArticleID: id,
+		})
+		if err != nil {
+			return fmt.Errorf("update position: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// collectionTree is a top-level collection with its nested children.
+type collectionTree struct {
+	dbgen.Collection
+	Children []dbgen.Collection `json:"children"`
+}
+
+// buildCollectionTree groups collections under their parents. Collections
+// whose parent is missing are treated as top-level.
+func buildCollectionTree(collections []dbgen.Collection) []collectionTree {
+	index := make(map[int64]int, len(collections))
+	tree := []collectionTree{}
+	for _, c := range collections {
+		if c.ParentID == nil {
+			index[c.ID] = len(tree)
+			tree = append(tree, collectionTree{Collection: c, Children: []dbgen.Collection{}})
+		}
+	}
+	for _, c := range collections {
+		if c.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*c.ParentID]; ok {
+			tree[i].Children = append(tree[i].Children, c)
+		} else {
+			tree = append(tree, collectionTree{Collection: c, Children: []dbgen.Collection{}})
+		}
+	}
+	return tree
+}
+
+func (s *Server) handleListCollections(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	collections, err := s.Queries.ListCollections(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list collections", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to list collections", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, buildCollectionTree(collections))
+}
+
+func (s *Server) handleCreateCollection(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req struct {
+		Name     string `json:"name"`
+		ParentID *int64 `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		s.jsonError(w, r, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.Queries.GetCollection(r.Context(), dbgen.GetCollectionParams{ID: *req.ParentID, UserID: user.ID})
+		if err != nil {
+			s.jsonError(w, r, "Parent collection not found", 404, ErrCodeCollectionNotFound)
+			return
+		}
+		if parent.ParentID != nil {
+			s.jsonError(w, r, "Collections can only be nested one level deep", http.StatusBadRequest)
+			return
+		}
+	}
+
+	collection, err := s.Queries.CreateCollection(r.Context(), dbgen.CreateCollectionParams{
+		UserID:   user.ID,
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		slog.Error("failed to create collection", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to create collection", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, collection)
+}
+
+func (s *Server) handleDeleteCollection(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid collection ID")
+	if !ok {
+		return
+	}
+
+	if err := s.deleteCollection(r.Context(), user.ID, id); err != nil {
+		if err == sql.ErrNoRows {
+			s.jsonError(w, r, "Collection not found", 404, ErrCodeCollectionNotFound)
+			return
+		}
+		slog.Error("failed to delete collection", "collection_id", id, "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to delete collection", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+// deleteCollection removes a collection in a single transaction. Its articles
+// move to the parent collection, or are left uncollected for a top-level
+// collection; its children become top-level collections.
+func (s *Server) deleteCollection(ctx context.Context, userID, id int64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.Queries.WithTx(tx)
+	collection, err := q.GetCollection(ctx, dbgen.GetCollectionParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	if collection.ParentID != nil {
+		err := q.MoveCollectionArticles(ctx, dbgen.MoveCollectionArticlesParams{
+			CollectionID:   *collection.ParentID,
+			CollectionID_2: collection.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("move articles to parent: %w", err)
+		}
+	}
+
+	if _, err := q.DeleteCollection(ctx, dbgen.DeleteCollectionParams{ID: id, UserID: userID}); err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *Server) handleAddArticleToCollection(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid collection ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ArticleID int64 `json:"article_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.Queries.GetCollection(r.Context(), dbgen.GetCollectionParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Collection not found", 404, ErrCodeCollectionNotFound)
+		return
+	}
+	if _, err := s.Queries.GetArticle(r.Context(), dbgen.GetArticleParams{ID: req.ArticleID, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Article not found", 404, ErrCodeArticleNotFound)
+		return
+	}
+
+	added, err := s.Queries.AddArticleToCollection(r.Context(), dbgen.AddArticleToCollectionParams{
+		CollectionID: id,
+		ArticleID:    req.ArticleID,
+	})
+	if err != nil {
+		slog.Error("failed to add article to collection", "collection_id", id, "article_id", req.ArticleID, "error", err)
+		s.jsonError(w, r, "Failed to add article to collection", http.StatusInternalServerError)
+		return
+	}
+	if added == 0 {
+		s.jsonError(w, r, "Article is already in the collection", http.StatusConflict)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+func (s *Server) handleRemoveArticleFromCollection(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid collection ID")
+	if !ok {
+		return
+	}
+	articleID, err := strconv.ParseInt(r.PathValue("article_id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, r, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.Queries.GetCollection(r.Context(), dbgen.GetCollectionParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Collection not found", 404, ErrCodeCollectionNotFound)
+		return
+	}
+
+	removed, err := s.Queries.RemoveArticleFromCollection(r.Context(), dbgen.RemoveArticleFromCollectionParams{
+		CollectionID: id,
+		ArticleID:    articleID,
+	})
+	if err != nil {
+		slog.Error("failed to remove article from collection", "collection_id", id, "article_id", articleID, "error", err)
+		s.jsonError(w, r, "Failed to remove article from collection", http.StatusInternalServerError)
+		return
+	}
+	if removed == 0 {
+		s.jsonError(w, r, "Article is not in the collection", 404)
+		return
+	}
+
+	s.jsonStatus(w, "ok")
+}
+
+const (
+	defaultSimilarLimit = 5
+	maxSimilarLimit     = 20
+	maxSimilarKeywords  = 10 // bounds the size of the generated query
+)
+
+func (s *Server) handleSimilarArticles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	limit := defaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSimilarLimit {
+			s.jsonError(w, r, fmt.Sprintf("limit must be between 1 and %d", maxSimilarLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	article, err := s.Queries.GetArticle(r.Context(), dbgen.GetArticleParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Article not found", 404, ErrCodeArticleNotFound)
+		return
+	}
+
+	similar, err := s.findSimilarArticles(r.Context(), article, limit)
+	if err != nil {
+		slog.Error("failed to find similar articles", "article_id", id, "error", err)
+		s.jsonError(w, r, "Failed to find similar articles", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, similar)
+}
+
+// findSimilarArticles returns up to limit of the owner's other articles whose
+// title or summary contains keywords from the given article, ranked by how
+// many keywords match.
+func (s *Server) findSimilarArticles(ctx context.Context, article dbgen.Article, limit int) ([]dbgen.Article, error) {
+	keywords := jobrunner.ExtractKeywords(article.Title + " " + article.Summary)
+	if len(keywords) > maxSimilarKeywords {
+		keywords = keywords[:maxSimilarKeywords]
+	}
+	if len(keywords) == 0 {
+		return []dbgen.Article{}, nil
+	}
+
+	matches := make([]string, len(keywords))
+	var scoreArgs, matchArgs []interface{}
+	for i, kw := range keywords {
+		pattern := "%" + kw + "%"
+		matches[i] = "(title LIKE ? OR summary LIKE ?)"
+		scoreArgs = append(scoreArgs, pattern, pattern)
+		matchArgs = append(matchArgs, pattern, pattern)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, author, published_at, source, image_url, source_name, %s AS score "+
+			"FROM articles WHERE user_id = ? AND id != ? AND archived_at IS NULL AND (%s) "+
+			"ORDER BY score DESC, retrieved_at DESC LIMIT ?",
+		strings.Join(matches, " + "),
+		strings.Join(matches, " OR "),
+	)
+	args := append(scoreArgs, article.UserID, article.ID)
+	args = append(args, matchArgs...)
+	args = append(args, limit)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	similar := []dbgen.Article{}
+	for rows.Next() {
+		var a dbgen.Article
+		var score int64
+		if err := rows.Scan(&a.ID, &a.JobID, &a.UserID, &a.Title, &a.Url, &a.Summary, &a.ContentPath, &a.RetrievedAt, &a.Author, &a.PublishedAt, &a.Source, &a.ImageUrl, &a.SourceName, &score); err != nil {
+			return nil, err
+		}
+		similar = append(similar, a)
+	}
+	return similar, rows.Err()
+}
+
+// maxJobEvents is the number of recent events returned for a job.
+const maxJobEvents = 100
+
+// maxFullTextResults caps full_text searches, which read every result's
+// content file from disk.
+const maxFullTextResults = 50
+
+// matchContextWidth is the length of the snippet returned around a full-text match.
+const matchContextWidth = 200
+
+// articleSearchResult is an article returned by the search API, with a snippet
+// of its content when a full-text search matched the content file.
+type articleSearchResult struct {
+	dbgen.Article
+	MatchContext string `json:"match_context,omitempty"`
+}
+
+// handleSearchArticles searches article titles and summaries. With
+// full_text=1 it also looks for the first search term in each result's
+// content file and returns the text around the match.
+func (s *Server) handleSearchArticles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	f := parseArticlesFilters(r)
+	if f.ParsedTerms.IsEmpty() {
+		s.jsonError(w, r, "Invalid request: q is required", http.StatusBadRequest)
+		return
+	}
+
+	fullText := r.URL.Query().Get("full_text") == "1"
+	if fullText {
+		f.Limit = min(f.Limit, maxFullTextResults)
+		f.Offset = int64(f.Page-1) * f.Limit
+	}
+
+	articles, count := s.queryArticles(r, user.ID, f)
+	setPaginationHeaders(w, r, f.Page, count, f.Limit)
+
+	results := make([]articleSearchResult, len(articles))
+	for i, a := range articles {
+		results[i].Article = a
+		// Snippets show the first included term; exclusions have nothing to show
+		if !fullText || a.ContentPath == "" || len(f.ParsedTerms.Include) == 0 {
+			continue
+		}
+		snippet, found, err := findContextInFile(a.ContentPath, f.ParsedTerms.Include[0])
+		if err != nil {
+			slog.Warn("failed to search article content", "article_id", a.ID, "error", err)
+			continue
+		}
+		if found {
+			results[i].MatchContext = snippet
+		}
+	}
+
+	s.jsonOK(w, results)
+}
+
+// findContextInFile searches a text file line by line for keyword, ignoring
+// case, and returns up to matchContextWidth characters around the first match.
+func findContextInFile(path, keyword string) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	needle := []rune(strings.ToLower(keyword))
+	if len(needle) == 0 {
+		return "", false, nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := []rune(scanner.Text())
+		idx := indexRunesFold(line, needle)
+		if idx < 0 {
+			continue
+		}
+
+		start := max(0, idx-(matchContextWidth-len(needle))/2)
+		end := min(len(line), start+matchContextWidth)
+		start = max(0, end-matchContextWidth)
+		return strings.TrimSpace(string(line[start:end])), true, nil
+	}
+	return "", false, scanner.Err()
+}
+
+// indexRunesFold returns the rune index of the first case-insensitive
+// occurrence of the lowercase needle in s, or -1.
+func indexRunesFold(s, needle []rune) int {
+	for i := 0; i+len(needle) <= len(s); i++ {
+		match := true
+		for j, r := range needle {
+			if unicode.ToLower(s[i+j]) != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	// Verify the job belongs to this user
+	if _, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	events, err := s.Queries.ListJobEvents(r.Context(), dbgen.ListJobEventsParams{JobID: id, Limit: maxJobEvents})
+	if err != nil {
+		slog.Error("failed to list job events", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to list job events", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, events)
+}
+
+// handleJobStats returns aggregate run statistics for a job, including the
+// Shelley conversation size used to monitor API cost.
+func (s *Server) handleJobStats(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	// Verify the job belongs to this user
+	if _, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	stats, err := s.Queries.GetJobRunStats(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to get job stats", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to get job stats", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, stats)
+}
+
+const (
+	defaultCountByDateDays = 30
+	maxCountByDateDays     = 365
+)
+
+// ArticleDateCount is the number of articles retrieved on one UTC day.
+type ArticleDateCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// handleArticleCountByDate returns the user's articles per day for the past
+// days days (including today, UTC), with days that have none counted as 0.
+// job_id narrows the counts to one job.
+func (s *Server) handleArticleCountByDate(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	days := defaultCountByDateDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCountByDateDays {
+			s.jsonError(w, r, fmt.Sprintf("days must be between 1 and %d", maxCountByDateDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	var jobID int64
+	if v := r.URL.Query().Get("job_id"); v != "" {
+		jobID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || jobID < 1 {
+			s.jsonError(w, r, "Invalid job ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+	rows, err := s.Queries.CountArticlesByUserPerDay(r.Context(), dbgen.CountArticlesByUserPerDayParams{
+		UserID: user.ID,
+		Since:  since,
+		JobID:  jobID,
+	})
+	if err != nil {
+		slog.Error("failed to count articles by date", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to count articles", http.StatusInternalServerError)
+		return
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Date] = row.Count
+	}
+	resp := make([]ArticleDateCount, 0, days)
+	for d := since; !d.After(today); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		resp = append(resp, ArticleDateCount{Date: date, Count: counts[date]})
+	}
+	s.jsonOK(w, resp)
+}
+
+// ArticleSource is one site the user's articles come from.
+type ArticleSource struct {
+	Domain     string `json:"domain"`
+	SourceName string `json:"source_name"` // from the newest article that has one
+	Count      int64  `json:"count"`
+}
+
+// handleArticleSources lists the domains of the user's articles with their
+// publication names, most articles first.
+func (s *Server) handleArticleSources(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	rows, err := s.Queries.ListArticleSources(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list article sources", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to list article sources", http.StatusInternalServerError)
+		return
+	}
+
+	resp := []ArticleSource{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		u, err := url.Parse(row.Url)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		domain := strings.TrimPrefix(u.Hostname(), "www.")
+		i, ok := index[domain]
+		if !ok {
+			i = len(resp)
+			index[domain] = i
+			resp = append(resp, ArticleSource{Domain: domain})
+		}
+		resp[i].Count++
+		if resp[i].SourceName == "" {
+			resp[i].SourceName = row.SourceName
+		}
+	}
+	slices.SortStableFunc(resp, func(a, b ArticleSource) int {
+		if a.Count != b.Count {
+			return int(b.Count - a.Count)
+		}
+		return strings.Compare(a.Domain, b.Domain)
+	})
+	s.jsonOK(w, resp)
+}
+
+// costSummaryPeriod is how far back GET /api/user/cost-summary looks.
+const costSummaryPeriod = 30 * 24 * time.Hour
+
+// CostSummaryResponse is a user's estimated Shelley spend over the last
+// costSummaryPeriod, in US dollars.
+type CostSummaryResponse struct {
+	TotalEstimatedUSD float64                `json:"total_estimated_usd"`
+	ByJob             []dbgen.CostSummaryRow `json:"by_job"`
+	Period            string                 `json:"period"`
+}
+
+// handleCostSummary returns the user's estimated Shelley cost for the last
+// 30 days, in total and per job.
+func (s *Server) handleCostSummary(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	byJob, err := s.Queries.CostSummary(r.Context(), dbgen.CostSummaryParams{
+		UserID:    user.ID,
+		StartedAt: time.Now().Add(-costSummaryPeriod).UTC(),
+	})
+	if err != nil {
+		slog.Error("failed to get cost summary", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to get cost summary", http.StatusInternalServerError)
+		return
+	}
+
+	resp := CostSummaryResponse{ByJob: byJob, Period: "30d"}
+	for _, job := range byJob {
+		resp.TotalEstimatedUSD += job.Cost
+	}
+	s.jsonOK(w, resp)
+}
+
+// UsageResponse is the user's Shelley usage today against their quota.
+type UsageResponse struct {
+	ShelleyCallsToday int64 `json:"shelley_calls_today"`
+	DailyQuota        int64 `json:"daily_quota"` // 0 means unlimited
+}
+
+// handleUsage returns how many Shelley conversations the user's runs have
+// started today (UTC) and their daily quota.
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	prefs, err := s.Queries.GetPreferences(r.Context(), user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("failed to get preferences", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to get usage", http.StatusInternalServerError)
+		return
+	}
+	calls, err := jobrunner.NewShelleyUsageTracker(s.Queries).DailyUsage(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to get Shelley usage", "error", err, "user_id", user.ID)
+		s.jsonError(w, r, "Failed to get usage", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, UsageResponse{ShelleyCallsToday: calls, DailyQuota: prefs.ShelleyQuotaDaily})
+}
+
+// PromptPreviewResponse is the prompt a job would send to Shelley.
+type PromptPreviewResponse struct {
+	Prompt          string `json:"prompt"`
+	EstimatedTokens int    `json:"estimated_tokens"`
+	TemplateError   string `json:"template_error,omitempty"` // why the job's prompt template fell back to the default prompt
+}
+
+// handleJobPromptPreview renders the prompt a job would send to Shelley
+// without running it, so users can check it before paying for a call.
+func (s *Server) handleJobPromptPreview(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	preview, err := s.promptPreview(r.Context(), job)
+	if err != nil {
+		slog.Error("failed to build prompt preview", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to build prompt preview", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, preview)
+}
+
+// promptPreview builds job's prompt with its owner's preferences.
+func (s *Server) promptPreview(ctx context.Context, job dbgen.Job) (PromptPreviewResponse, error) {
+	prefs, err := s.Queries.GetPreferences(ctx, job.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		return PromptPreviewResponse{}, fmt.Errorf("get preferences: %w", err)
+	}
+	prompt, templateErr := jobrunner.BuildJobPrompt(job, prefs)
+	preview := PromptPreviewResponse{Prompt: prompt, EstimatedTokens: estimateTokens(prompt)}
+	if templateErr != nil {
+		preview.TemplateError = templateErr.Error()
+	}
+	return preview, nil
+}
+
+// estimateTokens roughly estimates the number of tokens in text at 1.3
+// tokens per word.
+func estimateTokens(text string) int {
+	return int(float64(len(strings.Fields(text))) * 1.3)
+}
+
+// handleListJobs returns the user's jobs with their schedule, status and
+// number of saved articles, accepting the same sort, order and tag parameters
+// as the jobs page.
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	jobs, err := s.Queries.ListJobsWithArticleCounts(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list jobs", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+	sorted, err := s.resolveJobListQuery(parseJobSort(r))(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to sort jobs", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+
+	jobs = orderJobRows(jobs, sorted)
+
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		tagged, err := s.taggedJobIDs(r.Context(), user.ID, tag)
+		if err != nil {
+			slog.Error("failed to filter jobs by tag", "user_id", user.ID, "error", err)
+			s.jsonError(w, r, "Failed to list jobs", http.StatusInternalServerError)
+			return
+		}
+		jobs = slices.DeleteFunc(jobs, func(job dbgen.ListJobsWithArticleCountsRow) bool { return !tagged[job.ID] })
+	}
+
+	s.jsonOK(w, jobs)
+}
+
+// orderJobRows puts rows in the order of sorted, which lists the same jobs.
+// Rows missing from sorted, e.g. created between the two queries, go last.
+func orderJobRows(rows []dbgen.ListJobsWithArticleCountsRow, sorted []dbgen.Job) []dbgen.ListJobsWithArticleCountsRow {
+	position := make(map[int64]int, len(sorted))
+	for i, job := range sorted {
+		position[job.ID] = i
+	}
+	slices.SortStableFunc(rows, func(a, b dbgen.ListJobsWithArticleCountsRow) int {
+		pa, okA := position[a.ID]
+		pb, okB := position[b.ID]
+		if !okA {
+			pa = len(sorted)
+		}
+		if !okB {
+			pb = len(sorted)
+		}
+		return pa - pb
+	})
+	return rows
+}
+
+const (
+	maxJobTags      = 20
+	maxJobTagLength = 32
+)
+
+// normalizeTags trims and lowercases tags, dropping empty and repeated ones.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		if len(tag) > maxJobTagLength {
+			return nil, fmt.Errorf("tags must be at most %d characters", maxJobTagLength)
+		}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxJobTags {
+		return nil, fmt.Errorf("a job can have at most %d tags", maxJobTags)
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// setJobTags replaces a job's tags in a single transaction. It returns
+// sql.ErrNoRows if the user has no such job.
+func (s *Server) setJobTags(ctx context.Context, userID, jobID int64, tags []string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.Queries.WithTx(tx)
+	if _, err := q.GetJob(ctx, dbgen.GetJobParams{ID: jobID, UserID: userID}); err != nil {
+		return err
+	}
+	if err := q.ClearJobTags(ctx, jobID); err != nil {
+		return fmt.Errorf("clear tags: %w", err)
+	}
+	for _, tag := range tags {
+		if err := q.AddJobTag(ctx, dbgen.AddJobTagParams{JobID: jobID, UserID: userID, TagName: tag}); err != nil {
+			return fmt.Errorf("add tag %q: %w", tag, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// jobTagsByUser maps each of the user's tagged jobs to its tags.
+func (s *Server) jobTagsByUser(ctx context.Context, userID int64) (map[int64][]string, error) {
+	rows, err := s.Queries.ListJobTagsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[int64][]string)
+	for _, row := range rows {
+		tags[row.JobID] = append(tags[row.JobID], row.TagName)
+	}
+	return tags, nil
+}
+
+// taggedJobIDs returns the IDs of the user's jobs tagged tag.
+func (s *Server) taggedJobIDs(ctx context.Context, userID int64, tag string) (map[int64]bool, error) {
+	jobs, err := s.Queries.ListJobsByTag(ctx, dbgen.ListJobsByTagParams{UserID: userID, TagName: strings.ToLower(strings.TrimSpace(tag))})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int64]bool, len(jobs))
+	for _, job := range jobs {
+		ids[job.ID] = true
+	}
+	return ids, nil
+}
+
+// JobTags is the body of PUT /api/jobs/{id}/tags and its response.
+type JobTags struct {
+	Tags []string `json:"tags"`
+}
+
+// handleSetJobTags replaces a job's tags.
+func (s *Server) handleSetJobTags(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	var req JobTags
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		s.jsonError(w, r, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.setJobTags(r.Context(), user.ID, id, tags); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+			return
+		}
+		slog.Error("failed to set job tags", "job_id", id, "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to update job tags", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, JobTags{Tags: tags})
+}
+
+// handleListTags returns the distinct tags on the user's jobs.
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	tags, err := s.Queries.ListUserTags(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list tags", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to list tags", http.StatusInternalServerError)
+		return
+	}
+	s.jsonOK(w, tags)
+}
+
+// JobArticlesResponse is one page of a job's articles.
+type JobArticlesResponse struct {
+	Articles []dbgen.Article `json:"articles"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	Pages    int             `json:"pages"`
+}
+
+// handleJobArticles lists a job's articles, accepting the same q, from, to
+// and page parameters as the articles page.
+func (s *Server) handleJobArticles(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	// Verify the job belongs to this user
+	if _, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	f := parseArticlesFilters(r)
+	f.JobFilter = id
+	articles, count := s.queryArticles(r, user.ID, f)
+	if articles == nil {
+		articles = []dbgen.Article{}
+	}
+
+	setPaginationHeaders(w, r, f.Page, count, f.Limit)
+	s.jsonOK(w, JobArticlesResponse{
+		Articles: articles,
+		Total:    count,
+		Page:     f.Page,
+		Pages:    pageCount(count, f.Limit),
+	})
+}
+
+// handleRunConversation returns the Shelley conversation behind a job run.
+// The live conversation is preferred; if Shelley can no longer return it
+// (e.g. it was archived), the snapshot taken when the run finished is used.
+func (s *Server) handleRunConversation(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	jobID, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+	runID, err := strconv.ParseInt(r.PathValue("run_id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, r, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := s.Queries.GetJobRun(r.Context(), dbgen.GetJobRunParams{ID: runID, UserID: user.ID})
+	if err != nil || run.JobID != jobID {
+		s.jsonError(w, r, "Run not found", 404, ErrCodeRunNotFound)
+		return
+	}
+	if run.ConversationID == "" {
+		s.jsonError(w, r, "No conversation recorded for this run", 404)
+		return
+	}
+
+	conv, err := s.shelley.GetConversation(r.Context(), jobID, run.ConversationID)
+	if err == nil {
+		s.jsonOK(w, jobrunner.NewConversationView(conv))
+		return
+	}
+	slog.Warn("failed to fetch live conversation", "run_id", runID, "conversation_id", run.ConversationID, "error", err)
+
+	if run.ConversationSnapshot == "" {
+		s.jsonError(w, r, "Conversation is no longer available", 404)
+		return
+	}
+	var view jobrunner.ConversationView
+	if err := json.Unmarshal([]byte(run.ConversationSnapshot), &view); err != nil {
+		slog.Error("failed to decode conversation snapshot", "run_id", runID, "error", err)
+		s.jsonError(w, r, "Failed to load conversation", http.StatusInternalServerError)
+		return
+	}
+	s.jsonOK(w, view)
+}
+
+func (s *Server) handleRunEvents(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid run ID")
+	if !ok {
+		return
+	}
+
+	// Verify the run belongs to this user
+	if _, err := s.Queries.GetJobRun(r.Context(), dbgen.GetJobRunParams{ID: id, UserID: user.ID}); err != nil {
+		s.jsonError(w, r, "Run not found", 404, ErrCodeRunNotFound)
+		return
+	}
+
+	events, err := s.Queries.ListRunEvents(r.Context(), &id)
+	if err != nil {
+		slog.Error("failed to list run events", "run_id", id, "error", err)
+		s.jsonError(w, r, "Failed to list run events", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonOK(w, events)
+}
+
+// RunDetail is the JSON view of a single job run. LogPath is only the log
+// file's name; the directory it lives in is not exposed.
+type RunDetail struct {
+	ID                int64      `json:"id"`
+	JobID             int64      `json:"job_id"`
+	JobName           string     `json:"job_name"`
+	Status            string     `json:"status"`
+	StartedAt         time.Time  `json:"started_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	ArticlesSaved     *int64     `json:"articles_saved"`
+	DuplicatesSkipped *int64     `json:"duplicates_skipped"`
+	ErrorMessage      *string    `json:"error_message"`
+	LogPath           string     `json:"log_path"`
+	ConversationID    string     `json:"conversation_id"`
+}
+
+// newRunDetail builds the RunDetail for run, which belongs to the job named
+// jobName.
+func newRunDetail(run dbgen.JobRun, jobName string) RunDetail {
+	var logFile string
+	if run.LogPath != "" {
+		logFile = filepath.Base(run.LogPath)
+	}
+	return RunDetail{
+		ID:                run.ID,
+		JobID:             run.JobID,
+		JobName:           jobName,
+		Status:            run.Status,
+		StartedAt:         run.StartedAt,
+		CompletedAt:       run.CompletedAt,
+		ArticlesSaved:     run.ArticlesSaved,
+		DuplicatesSkipped: run.DuplicatesSkipped,
+		ErrorMessage:      run.ErrorMessage,
+		LogPath:           logFile,
+		ConversationID:    run.ConversationID,
+	}
+}
+
+// handleGetRun returns the details of one of the user's job runs.
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid run ID")
+	if !ok {
+		return
+	}
+
+	run, err := s.Queries.GetJobRun(r.Context(), dbgen.GetJobRunParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Run not found", 404, ErrCodeRunNotFound)
+		return
+	}
+
+	s.jsonOK(w, newRunDetail(dbgen.JobRun{
+		ID:                run.ID,
+		JobID:             run.JobID,
+		Status:            run.Status,
+		ErrorMessage:      run.ErrorMessage,
+		StartedAt:         run.StartedAt,
+		CompletedAt:       run.CompletedAt,
+		ArticlesSaved:     run.ArticlesSaved,
+		DuplicatesSkipped: run.DuplicatesSkipped,
+		LogPath:           run.LogPath,
+		ConversationID:    run.ConversationID,
+	}, run.JobName))
+}
+
+// latestRun looks up the most recent run of the job in the request path,
+// writing an error response and returning false if there is none.
+func (s *Server) latestRun(w http.ResponseWriter, r *http.Request) (dbgen.JobRun, string, bool) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return dbgen.JobRun{}, "", false
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return dbgen.JobRun{}, "", false
+	}
+
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return dbgen.JobRun{}, "", false
+	}
+
+	run, err := s.Queries.GetLatestJobRun(r.Context(), dbgen.GetLatestJobRunParams{JobID: id, UserID: user.ID})
+	if err == sql.ErrNoRows {
+		s.jsonError(w, r, "Job has no runs", 404, ErrCodeRunNotFound)
+		return dbgen.JobRun{}, "", false
+	}
+	if err != nil {
+		slog.Error("failed to get latest job run", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to get latest run", http.StatusInternalServerError)
+		return dbgen.JobRun{}, "", false
+	}
+	return run, job.Name, true
+}
+
+// handleLatestRun returns the details of a job's most recent run.
+func (s *Server) handleLatestRun(w http.ResponseWriter, r *http.Request) {
+	run, jobName, ok := s.latestRun(w, r)
+	if !ok {
+		return
+	}
+	s.jsonOK(w, newRunDetail(run, jobName))
+}
+
+// handleLatestRunLog sends the log of a job's most recent run, like
+// handleRunLog.
+func (s *Server) handleLatestRunLog(w http.ResponseWriter, r *http.Request) {
+	run, _, ok := s.latestRun(w, r)
+	if !ok {
+		return
+	}
+	serveRunLog(w, r, run.ID, run.LogPath)
+}
+
+// JobRunsResponse is one page of a job's run history, newest first.
+type JobRunsResponse struct {
+	Runs  []RunDetail `json:"runs"`
+	Total int64       `json:"total"`
+	Page  int         `json:"page"`
+	Pages int         `json:"pages"`
+}
+
+// handleJobRuns lists a job's runs, DefaultPageLimit per page.
+func (s *Server) handleJobRuns(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+
+	// Verify the job belongs to this user
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		s.jsonError(w, r, "Job not found", 404, ErrCodeJobNotFound)
+		return
+	}
+
+	page, limit, offset := parsePage(r)
+	runs, err := s.Queries.ListJobRunsByJobPage(r.Context(), dbgen.ListJobRunsByJobPageParams{
+		JobID:  id,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		slog.Error("failed to list job runs", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to list job runs", http.StatusInternalServerError)
+		return
+	}
+	count, err := s.Queries.CountJobRunsByJob(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to count job runs", "job_id", id, "error", err)
+		s.jsonError(w, r, "Failed to list job runs", http.StatusInternalServerError)
+		return
+	}
+
+	details := make([]RunDetail, len(runs))
+	for i, run := range runs {
+		details[i] = newRunDetail(run, job.Name)
+	}
+
+	setPaginationHeaders(w, r, page, count, limit)
+	s.jsonOK(w, JobRunsResponse{
+		Runs:  details,
+		Total: count,
+		Page:  page,
+		Pages: pageCount(count, limit),
+	})
+}
+
+// adminDeleteBatchSize bounds the number of article IDs deleted per query.
+const adminDeleteBatchSize = 500
+
+func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.Queries.ListAllUsersWithCounts(r.Context())
+	if err != nil {
+		slog.Error("admin: failed to list users", "error", err)
+		s.jsonError(w, r, "Failed to list users", http.StatusInternalServerError)
+		return
+	}
+	s.jsonOK(w, users)
+}
+
+func (s *Server) handleAdminJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.Queries.ListAllActiveJobs(r.Context())
+	if err != nil {
+		slog.Error("admin: failed to list jobs", "error", err)
+		s.jsonError(w, r, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+	for i, job := range jobs {
+		jobs[i] = redactJob(job)
+	}
+	s.jsonOK(w, jobs)
+}
+
+func (s *Server) handleAdminRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.Queries.ListAllRunningRuns(r.Context())
+	if err != nil {
+		slog.Error("admin: failed to list running runs", "error", err)
+		s.jsonError(w, r, "Failed to list runs", http.StatusInternalServerError)
+		return
+	}
+	s.jsonOK(w, runs)
+}
+
+func (s *Server) handleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathID(w, r, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	deleted, err := s.deleteUserData(r.Context(), id)
+	if err != nil {
+		slog.Error("admin: failed to delete user", "user_id", id, "error", err)
+		s.jsonError(w, r, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		s.jsonError(w, r, "User not found", 404, ErrCodeUserNotFound)
+		return
+	}
+
+	slog.Info("admin: deleted user", "user_id", id)
+	s.jsonStatus(w, "ok")
+}
+
+// handleAdminSetQuota sets a user's daily Shelley conversation quota.
+func (s *Server) handleAdminSetQuota(w http.ResponseWriter, r *http.Request) {
+	id, ok := parsePathID(w, r, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req SetQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DailyQuota < 0 {
+		s.jsonError(w, r, "Invalid request: daily_quota must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	n, err := s.Queries.UpdateShelleyQuota(r.Context(), dbgen.UpdateShelleyQuotaParams{ShelleyQuotaDaily: req.DailyQuota, UserID: id})
+	if err != nil {
+		slog.Error("admin: failed to set quota", "user_id", id, "error", err)
+		s.jsonError(w, r, "Failed to set quota", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		s.jsonError(w, r, "User not found", 404, ErrCodeUserNotFound)
+		return
+	}
+
+	slog.Info("admin: set quota", "user_id", id, "daily_quota", req.DailyQuota)
+	s.jsonStatus(w, "ok")
+}
+
+// handleDeleteAccount deletes the signed-in user and all of their data.
+func (s *Server) handleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		s.jsonUnauthorized(w, r)
+		return
+	}
+
+	var req DeleteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Confirm != deleteAccountConfirmation {
+		s.jsonError(w, r, fmt.Sprintf("Invalid request: confirm must be %q", deleteAccountConfirmation), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.deleteUserData(r.Context(), user.ID); err != nil {
+		slog.Error("failed to delete account", "user_id", user.ID, "error", err)
+		s.jsonError(w, r, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("user deleted own account", "user_id", user.ID)
+	s.jsonStatus(w, "ok")
+}
+
+// deleteUserData removes the systemd timers of a user's jobs and their
+// article files and articles, then deletes the user in a transaction,
+// cascading to their jobs, runs, preferences, reading list and collections.
+// It reports whether the user existed.
+func (s *Server) deleteUserData(ctx context.Context, userID int64) (bool, error) {
+	jobs, err := s.Queries.ListJobsByUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("list jobs: %w", err)
+	}
+	for _, job := range jobs {
+		removeSystemdTimer(job.ID)
+	}
+
+	ids, err := s.Queries.ListArticleIDsByUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("list articles: %w", err)
+	}
+	for start := 0; start < len(ids); start += adminDeleteBatchSize {
+		end := min(start+adminDeleteBatchSize, len(ids))
+		if _, err := s.deleteArticlesWithFiles(ctx, userID, ids[start:end]); err != nil {
+			return false, err
+		}
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	n, err := s.Queries.WithTx(tx).DeleteUserCascade(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("delete user: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorCode is a machine-readable identifier for an API error, so clients can
+// branch on the kind of failure without parsing the message.
+type ErrorCode string
+
+const (
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrCodeInvalidCSRFToken   ErrorCode = "INVALID_CSRF_TOKEN"
+	ErrCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrCodeJobNotFound        ErrorCode = "JOB_NOT_FOUND"
+	ErrCodeRunNotFound        ErrorCode = "RUN_NOT_FOUND"
+	ErrCodeArticleNotFound    ErrorCode = "ARTICLE_NOT_FOUND"
+	ErrCodeCollectionNotFound ErrorCode = "COLLECTION_NOT_FOUND"
+	ErrCodeUserNotFound       ErrorCode = "USER_NOT_FOUND"
+	ErrCodeConflict           ErrorCode = "CONFLICT"
+	ErrCodeRateLimited        ErrorCode = "RATE_LIMITED"
+	ErrCodeRequestTooLarge    ErrorCode = "REQUEST_TOO_LARGE"
+	ErrCodeJobAlreadyRunning  ErrorCode = "JOB_ALREADY_RUNNING"
+	ErrCodeNotRunning         ErrorCode = "NOT_RUNNING"
+	ErrCodeServerBusy         ErrorCode = "SERVER_BUSY"
+	ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrResponse is the JSON body of every API error response.
+type ErrResponse struct {
+	Error     string    `json:"error"`
+	Code      ErrorCode `json:"code"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// defaultErrorCode maps an HTTP status to the generic code used when a caller
+// doesn't give a more specific one.
+func defaultErrorCode(status int) ErrorCode {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case status == http.StatusForbidden:
+		return ErrCodeForbidden
+	case status == http.StatusNotFound:
+		return ErrCodeNotFound
+	case status == http.StatusConflict:
+		return ErrCodeConflict
+	case status == http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrCodeRequestTooLarge
+	case status >= 500:
+		return ErrCodeInternal
+	default:
+		return ErrCodeInvalidRequest
+	}
+}
+
+// jsonError writes an ErrResponse with the given HTTP status. The error code
+// defaults to one derived from the status; pass code to be more specific.
+func (s *Server) jsonError(w http.ResponseWriter, r *http.Request, msg string, status int, code ...ErrorCode) {
+	writeJSONError(w, r, msg, status, code...)
+}
+
+// writeJSONError is jsonError for middleware that has no Server.
+func writeJSONError(w http.ResponseWriter, r *http.Request, msg string, status int, code ...ErrorCode) {
+	resp := ErrResponse{
+		Error:     msg,
+		Code:      defaultErrorCode(status),
+		RequestID: requestIDFromContext(r.Context()),
+	}
+	if len(code) > 0 {
+		resp.Code = code[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) jsonUnauthorized(w http.ResponseWriter, r *http.Request) {
+	s.jsonError(w, r, "Unauthorized", http.StatusUnauthorized, ErrCodeUnauthorized)
+}
@@ -1,20 +1,28 @@
 package web
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
+	"github.com/exedev/news-app/internal/util"
 )
 
-// searchTermsRE matches quoted strings or non-space sequences for search parsing.
-var searchTermsRE = regexp.MustCompile(`"([^"]+)"|'([^']+)'|(\S+)`)
+// searchTermsRE matches quoted strings or non-space sequences for search
+// parsing, each optionally preceded by a "-" that excludes it.
+var searchTermsRE = regexp.MustCompile(`(-?)(?:"([^"]+)"|'([^']+)'|(\S+))`)
 
 func redirectToLogin(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/__exe.dev/login?redirect="+r.URL.Path, http.StatusFound)
@@ -29,17 +37,157 @@ func parsePage(r *http.Request) (page int, limit, offset int64) {
 	return
 }
 
-// parseSearchTerms splits a search query into terms, keeping quoted phrases together
-func parseSearchTerms(query string) []string {
-	var terms []string
+// Job list sort fields accepted in the sort query parameter.
+const (
+	jobSortNextRun = "next_run"
+	jobSortLastRun = "last_run"
+	jobSortName    = "name"
+	jobSortStatus  = "status"
+)
+
+// parseJobSort reads the sort and order query parameters for a job list.
+// Missing or unknown values fall back to next_run ascending, soonest first.
+func parseJobSort(r *http.Request) (sort, order string) {
+	sort = r.URL.Query().Get("sort")
+	switch sort {
+	case jobSortNextRun, jobSortLastRun, jobSortName, jobSortStatus:
+	default:
+		sort = jobSortNextRun
+	}
+	order = "asc"
+	if r.URL.Query().Get("order") == "desc" {
+		order = "desc"
+	}
+	return sort, order
+}
+
+// resolveJobListQuery returns the query listing a user's jobs in the given
+// sort and order, as returned by parseJobSort. Jobs without a next or last
+// run time come last in either order.
+func (s *Server) resolveJobListQuery(sort, order string) func(ctx context.Context, userID int64) ([]dbgen.Job, error) {
+	desc := order == "desc"
+	switch sort {
+	case jobSortLastRun:
+		return func(ctx context.Context, userID int64) ([]dbgen.Job, error) {
+			return s.Queries.ListJobsByUserSortedByLastRun(ctx, dbgen.ListJobsByUserSortedByLastRunParams{UserID: userID, Desc: desc})
+		}
+	case jobSortName:
+		return func(ctx context.Context, userID int64) ([]dbgen.Job, error) {
+			return s.Queries.ListJobsByUserSortedByName(ctx, dbgen.ListJobsByUserSortedByNameParams{UserID: userID, Desc: desc})
+		}
+	case jobSortStatus:
+		return func(ctx context.Context, userID int64) ([]dbgen.Job, error) {
+			return s.Queries.ListJobsByUserSortedByStatus(ctx, dbgen.ListJobsByUserSortedByStatusParams{UserID: userID, Desc: desc})
+		}
+	default:
+		return func(ctx context.Context, userID int64) ([]dbgen.Job, error) {
+			return s.Queries.ListJobsByUserSortedByNextRun(ctx, dbgen.ListJobsByUserSortedByNextRunParams{UserID: userID, Desc: desc})
+		}
+	}
+}
+
+// Article list sort fields accepted in the sort query parameter.
+const (
+	articleSortRetrieved = "retrieved_at"
+	articleSortTitle     = "title"
+	articleSortSource    = "source_name"
+	articleSortJob       = "job"
+)
+
+// articleSortOrderBy is the allowlist of article sorts, mapping each to
+// the ORDER BY clause articleQueryBuilder uses for it, with a %s for the
+// direction. Sort fields never reach SQL any other way.
+var articleSortOrderBy = map[string]string{
+	articleSortRetrieved: "retrieved_at %s, id %[1]s",
+	articleSortTitle:     "lower(title) %s, retrieved_at DESC, id",
+	articleSortSource:    "source_name = '', lower(source_name) %s, retrieved_at DESC, id",
+	articleSortJob:       "(SELECT lower(name) FROM jobs WHERE jobs.id = articles.job_id) %s, retrieved_at DESC, id",
+}
+
+// parseArticleSort reads the sort and order query parameters for an
+// article list. Missing or unknown sorts fall back to retrieved_at. The
+// order defaults to newest first for retrieved_at and A to Z otherwise.
+func parseArticleSort(r *http.Request) (sort, order string) {
+	sort = r.URL.Query().Get("sort")
+	if _, ok := articleSortOrderBy[sort]; !ok {
+		sort = articleSortRetrieved
+	}
+	order = r.URL.Query().Get("order")
+	if order != "asc" && order != "desc" {
+		order = defaultArticleSortOrder(sort)
+	}
+	return sort, order
+}
+
+// defaultArticleSortOrder is the order of an article sort without an order
+// parameter: newest first for retrieved_at, A to Z otherwise.
+func defaultArticleSortOrder(sort string) string {
+	if sort == articleSortRetrieved {
+		return "desc"
+	}
+	return "asc"
+}
+
+// articleListQuery lists one page of a user's unarchived articles.
+type articleListQuery func(ctx context.Context, userID, limit, offset int64) ([]dbgen.Article, error)
+
+// resolveArticleSort returns the query listing a user's articles in the
+// given sort and order, as returned by parseArticleSort, or false for
+// retrieved_at, which articleQueryBuilder handles. Every sort lists the
+// same articles, so they share CountArticlesByUser.
+func (s *Server) resolveArticleSort(sort, order string) (articleListQuery, bool) {
+	desc := order == "desc"
+	switch sort {
+	case articleSortTitle:
+		return func(ctx context.Context, userID, limit, offset int64) ([]dbgen.Article, error) {
+			return s.Queries.ListArticlesByUserSortedByTitle(ctx, dbgen.ListArticlesByUserSortedByTitleParams{UserID: userID, Desc: desc, Limit: limit, Offset: offset})
+		}, true
+	case articleSortSource:
+		return func(ctx context.Context, userID, limit, offset int64) ([]dbgen.Article, error) {
+			return s.Queries.ListArticlesByUserSortedBySource(ctx, dbgen.ListArticlesByUserSortedBySourceParams{UserID: userID, Desc: desc, Limit: limit, Offset: offset})
+		}, true
+	case articleSortJob:
+		return func(ctx context.Context, userID, limit, offset int64) ([]dbgen.Article, error) {
+			return s.Queries.ListArticlesByUserSortedByJob(ctx, dbgen.ListArticlesByUserSortedByJobParams{UserID: userID, Desc: desc, Limit: limit, Offset: offset})
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// SearchTerms is a parsed search query: the terms articles must match and
+// those, written with a leading "-", they must not.
+type SearchTerms struct {
+	Include []string
+	Exclude []string
+}
+
+// IsEmpty reports whether the query had no terms at all.
+func (t SearchTerms) IsEmpty() bool {
+	return len(t.Include) == 0 && len(t.Exclude) == 0
+}
+
+// parseSearchTerms splits a search query into terms, keeping quoted phrases
+// together. Terms (or phrases) prefixed with "-" are excluded; a lone "-" is
+// an ordinary term.
+func parseSearchTerms(query string) SearchTerms {
+	var terms SearchTerms
 	matches := searchTermsRE.FindAllStringSubmatch(query, -1)
 	for _, match := range matches {
-		if match[1] != "" {
-			terms = append(terms, match[1]) // double-quoted
-		} else if match[2] != "" {
-			terms = append(terms, match[2]) // single-quoted
+		var term string
+		if match[2] != "" {
+			term = match[2] // double-quoted
 		} else if match[3] != "" {
-			terms = append(terms, match[3]) // unquoted word
+			term = match[3] // single-quoted
+		} else if match[4] != "" {
+			term = match[4] // unquoted word
+		}
+		switch {
+		case term == "":
+		case match[1] == "-":
+			terms.Exclude = append(terms.Exclude, term)
+		default:
+			terms.Include = append(terms.Include, term)
 		}
 	}
 	return terms
@@ -47,17 +195,26 @@ func parseSearchTerms(query string) []string {
 
 // articlesFilter holds parsed filter parameters for article listing
 type articlesFilter struct {
-	Page           int
-	Limit          int64
-	Offset         int64
-	SearchQuery    string
-	JobFilter      int64
-	DateFilter     string
-	DateFrom       string
-	DateTo         string
-	SinceTime      time.Time
-	UntilTime      time.Time
-	UseCustomRange bool
+	Page             int
+	Limit            int64
+	Offset           int64
+	SearchQuery      string
+	ParsedTerms      SearchTerms // SearchQuery split into terms
+	JobFilter        int64
+	CollectionFilter int64
+	SourceNameFilter string
+	SortBy           string // one of the articleSortOrderBy keys
+	SortOrder        string // "asc" or "desc"
+	DateFilter       string
+	DateFrom         string
+	DateTo           string
+	TimeFrom         string
+	TimeTo           string
+	SinceTime        time.Time
+	UntilTime        time.Time
+	SinceTimeExact   time.Time // from from_time; overrides SinceTime when set
+	UntilTimeExact   time.Time // from to_time; overrides UntilTime when set
+	UseCustomRange   bool
 }
 
 // parseArticlesFilters extracts filter parameters from the request.
@@ -65,16 +222,25 @@ func parseArticlesFilters(r *http.Request) articlesFilter {
 	q := r.URL.Query()
 	page, limit, offset := parsePage(r)
 	jobFilter, _ := strconv.ParseInt(q.Get("job"), 10, 64)
+	collectionFilter, _ := strconv.ParseInt(q.Get("collection"), 10, 64)
+	sortBy, sortOrder := parseArticleSort(r)
 
 	f := articlesFilter{
-		Page:        page,
-		Limit:       limit,
-		Offset:      offset,
-		SearchQuery: q.Get("q"),
-		JobFilter:   jobFilter,
-		DateFilter:  q.Get("filter"),
-		DateFrom:    q.Get("from"),
-		DateTo:      q.Get("to"),
+		Page:             page,
+		Limit:            limit,
+		Offset:           offset,
+		SearchQuery:      q.Get("q"),
+		ParsedTerms:      parseSearchTerms(q.Get("q")),
+		JobFilter:        jobFilter,
+		CollectionFilter: collectionFilter,
+		SourceNameFilter: q.Get("source_name"),
+		SortBy:           sortBy,
+		SortOrder:        sortOrder,
+		DateFilter:       q.Get("filter"),
+		DateFrom:         q.Get("from"),
+		DateTo:           q.Get("to"),
+		TimeFrom:         q.Get("from_time"),
+		TimeTo:           q.Get("to_time"),
 	}
 
 	f.parseDateFilters()
@@ -84,7 +250,7 @@ func parseArticlesFilters(r *http.Request) articlesFilter {
 // parseDateFilters sets SinceTime/UntilTime based on date filter params.
 func (f *articlesFilter) parseDateFilters() {
 	// Custom date range takes priority
-	if f.DateFrom != "" || f.DateTo != "" {
+	if f.DateFrom != "" || f.DateTo != "" || f.TimeFrom != "" || f.TimeTo != "" {
 		f.parseCustomDateRange()
 		return
 	}
@@ -109,6 +275,26 @@ func (f *articlesFilter) parseCustomDateRange() {
 	} else {
 		f.UntilTime = time.Now()
 	}
+
+	// RFC 3339 timestamps are more precise than dates, so they win
+	if t, err := time.Parse(time.RFC3339, f.TimeFrom); err == nil {
+		f.SinceTimeExact = t.UTC()
+	}
+	if t, err := time.Parse(time.RFC3339, f.TimeTo); err == nil {
+		f.UntilTimeExact = t.UTC()
+	}
+}
+
+// rangeBounds returns the custom date range, preferring the exact times.
+func (f *articlesFilter) rangeBounds() (since, until time.Time) {
+	since, until = f.SinceTime, f.UntilTime
+	if !f.SinceTimeExact.IsZero() {
+		since = f.SinceTimeExact
+	}
+	if !f.UntilTimeExact.IsZero() {
+		until = f.UntilTimeExact
+	}
+	return since, until
 }
 
 func (f *articlesFilter) predefinedDateOffset() time.Time {
@@ -127,23 +313,107 @@ func (f *articlesFilter) predefinedDateOffset() time.Time {
 
 
 type PageData struct {
-	User         *dbgen.User
-	Preferences  *dbgen.Preference
-	Jobs         []dbgen.Job
-	Job          *dbgen.Job
-	Articles     []dbgen.Article
-	Article      *dbgen.Article
-	RunningRuns  []dbgen.ListRunningJobRunsRow
-	RecentRuns   []dbgen.ListRecentJobRunsRow
-	TotalCount   int64
-	Page         int
-	DateFilter   string
-	DateFrom     string
-	DateTo       string
-	SearchQuery  string
-	JobFilter    int64
-	LoginURL     string
-	CSRFToken    string
+	User             *dbgen.User
+	Preferences      *dbgen.Preference
+	Jobs             []dbgen.Job
+	Job              *dbgen.Job
+	PromptPreview    *PromptPreviewResponse
+	Articles         []dbgen.Article
+	Article          *dbgen.Article
+	RunningRuns      []dbgen.ListRunningJobRunsRow
+	RecentRuns       []dbgen.ListRecentJobRunsRow
+	ReadingList      []dbgen.Article
+	Collections      []dbgen.Collection
+	TotalCount       int64
+	Page             int
+	DateFilter       string
+	DateFrom         string
+	DateTo           string
+	TimeFrom         string
+	TimeTo           string
+	SearchQuery      string
+	JobFilter        int64
+	CollectionFilter int64
+	SourceNameFilter string
+	SortBy           string // article sort, from parseArticleSort
+	SortOrder        string
+	ArticlesSortable bool // whether the article listing honours SortBy, so the sort links are shown
+	JobSort          string
+	JobOrder         string
+	JobTags          map[int64][]string // job ID to its tags
+	StaleJobs        map[int64]bool     // IDs of jobs IsJobStale reports
+	Tags             []string           // every tag the user has used
+	TagFilter        string
+	LoginURL         string
+	CSRFToken        string
+	FormErrors       map[string]string // form field name to its error, for forms submitted without JavaScript
+	FormValues       map[string]string // submitted form values to show again with FormErrors
+}
+
+// ArticleSortURL returns the articles page sorted by sort, keeping the
+// current filters. Sorting by the current sort again reverses the order.
+func (d PageData) ArticleSortURL(sort string) string {
+	q := url.Values{}
+	for name, value := range map[string]string{
+		"q":           d.SearchQuery,
+		"filter":      d.DateFilter,
+		"from":        d.DateFrom,
+		"to":          d.DateTo,
+		"from_time":   d.TimeFrom,
+		"to_time":     d.TimeTo,
+		"source_name": d.SourceNameFilter,
+	} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	if d.JobFilter > 0 {
+		q.Set("job", strconv.FormatInt(d.JobFilter, 10))
+	}
+	if d.CollectionFilter > 0 {
+		q.Set("collection", strconv.FormatInt(d.CollectionFilter, 10))
+	}
+	order := defaultArticleSortOrder(sort)
+	if sort == d.SortBy {
+		order = "asc"
+		if d.SortOrder == "asc" {
+			order = "desc"
+		}
+	}
+	q.Set("sort", sort)
+	q.Set("order", order)
+	return "/articles?" + q.Encode()
+}
+
+// ArticleSortArrow returns an arrow showing the order of the article list
+// if it is sorted by sort, or "" if it isn't.
+func (d PageData) ArticleSortArrow(sort string) string {
+	switch {
+	case sort != d.SortBy:
+		return ""
+	case d.SortOrder == "asc":
+		return "↑"
+	default:
+		return "↓"
+	}
+}
+
+// FormValue returns the submitted value of a form field, or fallback if the
+// form wasn't submitted.
+func (d PageData) FormValue(name, fallback string) string {
+	if d.FormValues == nil {
+		return fallback
+	}
+	return d.FormValues[name]
+}
+
+// FormChecked reports whether a submitted checkbox was checked, or returns
+// fallback if the form wasn't submitted.
+func (d PageData) FormChecked(name string, fallback bool) bool {
+	if d.FormValues == nil {
+		return fallback
+	}
+	return d.FormValues[name] != ""
 }
 
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
@@ -179,15 +449,66 @@ func (s *Server) handleJobsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	jobs, err := s.Queries.ListJobsByUser(r.Context(), user.ID)
+	sort, order := parseJobSort(r)
+	jobs, err := s.resolveJobListQuery(sort, order)(r.Context(), user.ID)
 	if err != nil {
 		slog.Error("failed to list jobs", "error", err, "user_id", user.ID)
 	}
 	
-	data := PageData{User: user, Jobs: jobs, CSRFToken: s.getCSRFToken(r)}
+	tag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
+	if tag != "" {
+		tagged, err := s.taggedJobIDs(r.Context(), user.ID, tag)
+		if err != nil {
+			slog.Error("failed to filter jobs by tag", "error", err, "user_id", user.ID)
+		}
+		jobs = slices.DeleteFunc(jobs, func(job dbgen.Job) bool { return !tagged[job.ID] })
+	}
+	jobTags, err := s.jobTagsByUser(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list job tags", "error", err, "user_id", user.ID)
+	}
+	tags, err := s.Queries.ListUserTags(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to list tags", "error", err, "user_id", user.ID)
+	}
+	
+	stale := make(map[int64]bool)
+	for _, job := range jobs {
+		if IsJobStale(job) {
+			stale[job.ID] = true
+		}
+	}
+	
+	data := PageData{
+		User:      user,
+		Jobs:      jobs,
+		JobSort:   sort,
+		JobOrder:  order,
+		JobTags:   jobTags,
+		StaleJobs: stale,
+		Tags:      tags,
+		TagFilter: tag,
+		CSRFToken: s.getCSRFToken(r),
+	}
 	s.renderTemplate(w, "jobs.html", data)
 }
 
+// IsJobStale reports whether an active recurring job has gone more than two
+// of its frequency intervals without a successful run. A job that has never
+// succeeded counts from its creation, so new jobs aren't flagged before
+// their first run.
+func IsJobStale(job dbgen.Job) bool {
+	if job.IsActive == 0 || job.IsOneTime == 1 {
+		return false
+	}
+	since := job.CreatedAt
+	if job.LastSuccessAt != nil {
+		since = *job.LastSuccessAt
+	}
+	interval := util.CalculateNextRunFrom(job.Frequency, false, since).Sub(since)
+	return time.Since(since) > 2*interval
+}
+
 func (s *Server) handleJobNew(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
@@ -237,6 +558,7 @@ func (s *Server) handleJobDetail(w http.ResponseWriter, r *http.Request) {
 		slog.Error("failed to count articles for job", "error", err, "job_id", job.ID)
 	}
 	
+	setPaginationHeaders(w, r, page, count, limit)
 	data := PageData{User: user, Job: &job, Articles: articles, TotalCount: count, Page: page, CSRFToken: s.getCSRFToken(r)}
 	s.renderTemplate(w, "job_detail.html", data)
 }
@@ -259,13 +581,174 @@ func (s *Server) handleJobEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	data := PageData{User: user, Job: &job, CSRFToken: s.getCSRFToken(r)}
+	tags, err := s.Queries.ListJobTags(r.Context(), job.ID)
+	if err != nil {
+		slog.Error("failed to list job tags", "error", err, "job_id", job.ID)
+	}
+	
+	data := PageData{User: user, Job: &job, JobTags: map[int64][]string{job.ID: tags}, CSRFToken: s.getCSRFToken(r)}
 	s.renderTemplate(w, "job_edit.html", data)
 }
 
+// jobEditFormFields are the fields of the job_edit.html form.
+var jobEditFormFields = []string{"name", "prompt", "feedUrl", "keywords", "sources", "region", "tags", "fetchHeaders", "fetchHeaderDomains", "promptTemplate", "frequency", "isActive"}
+
+// handleJobEditForm saves the job_edit.html form when it is submitted
+// without JavaScript, redirecting to the job on success and showing the
+// form again with its errors otherwise.
+func (s *Server) handleJobEditForm(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		redirectToLogin(w, r)
+		return
+	}
+	
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+	
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	if !s.csrfTokens.ValidateToken(user.ExeUserID, r.PostFormValue("csrf_token")) {
+		http.Error(w, "Forbidden: invalid CSRF token", http.StatusForbidden)
+		return
+	}
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		http.Error(w, "Job not found", 404)
+		return
+	}
+	
+	values := make(map[string]string, len(jobEditFormFields))
+	for _, field := range jobEditFormFields {
+		values[field] = strings.TrimSpace(r.PostFormValue(field))
+	}
+	// One-time jobs' frequency select is disabled, so it isn't submitted
+	if values["frequency"] == "" {
+		values["frequency"] = job.Frequency
+	}
+	
+	formErrors := make(map[string]string)
+	if values["name"] == "" {
+		formErrors["name"] = "Name is required"
+	}
+	if values["prompt"] == "" && values["feedUrl"] == "" {
+		formErrors["prompt"] = "Prompt is required"
+	}
+	if values["feedUrl"] != "" && !isHTTPURL(values["feedUrl"]) {
+		formErrors["feedUrl"] = "Feed URL must be an http or https URL"
+	}
+	switch values["frequency"] {
+	case util.FreqHourly, util.Freq6Hours, util.FreqDaily, util.FreqWeekly:
+	default:
+		formErrors["frequency"] = "Choose a frequency from the list"
+	}
+	if err := jobrunner.ValidateFetchHeaders(values["fetchHeaders"]); err != nil {
+		formErrors["fetchHeaders"] = "Fetch headers must be a JSON object of header names to values"
+	}
+	if err := jobrunner.ValidatePromptTemplate(values["promptTemplate"]); err != nil {
+		formErrors["promptTemplate"] = err.Error()
+	}
+	tags, err := normalizeTags(strings.Split(values["tags"], ","))
+	if err != nil {
+		formErrors["tags"] = err.Error()
+	}
+	if len(formErrors) > 0 {
+		jobTags, _ := s.Queries.ListJobTags(r.Context(), job.ID)
+		data := PageData{
+			User:       user,
+			Job:        &job,
+			JobTags:    map[int64][]string{job.ID: jobTags},
+			CSRFToken:  s.getCSRFToken(r),
+			FormErrors: formErrors,
+			FormValues: values,
+		}
+		s.renderTemplateStatus(w, http.StatusUnprocessableEntity, "job_edit.html", data)
+		return
+	}
+	
+	err = s.Queries.UpdateJob(r.Context(), dbgen.UpdateJobParams{
+		Name:               values["name"],
+		Prompt:             values["prompt"],
+		Keywords:           values["keywords"],
+		Sources:            values["sources"],
+		Region:             values["region"],
+		Frequency:          values["frequency"],
+		IsActive:           boolToInt64(values["isActive"] != ""),
+		FeedUrl:            values["feedUrl"],
+		FetchHeaders:       values["fetchHeaders"],
+		FetchHeaderDomains: values["fetchHeaderDomains"],
+		PromptTemplate:     values["promptTemplate"],
+		ScheduleJitterSecs: job.ScheduleJitterSecs,
+		ID:                 id,
+		UserID:             user.ID,
+	})
+	if err != nil {
+		slog.Error("failed to update job", "job_id", id, "user_id", user.ID, "error", err)
+		http.Error(w, "Failed to update job", http.StatusInternalServerError)
+		return
+	}
+	if err := s.setJobTags(r.Context(), user.ID, id, tags); err != nil {
+		slog.Error("failed to set job tags", "job_id", id, "user_id", user.ID, "error", err)
+		http.Error(w, "Failed to update job tags", http.StatusInternalServerError)
+		return
+	}
+	
+	job, _ = s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	updateSystemdTimer(job)
+	
+	// Each token is good for one change, as with the API
+	s.csrfTokens.RotateToken(user.ExeUserID, r.PostFormValue("csrf_token"))
+	
+	slog.Info("job updated", "job_id", id, "user_id", user.ID, "via", "form")
+	http.Redirect(w, r, fmt.Sprintf("/jobs/%d", id), http.StatusSeeOther)
+}
+
+func (s *Server) handleJobPreview(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		redirectToLogin(w, r)
+		return
+	}
+	
+	id, ok := parsePathID(w, r, "Invalid job ID")
+	if !ok {
+		return
+	}
+	
+	job, err := s.Queries.GetJob(r.Context(), dbgen.GetJobParams{ID: id, UserID: user.ID})
+	if err != nil {
+		http.Error(w, "Job not found", 404)
+		return
+	}
+	
+	preview, err := s.promptPreview(r.Context(), job)
+	if err != nil {
+		slog.Error("failed to build prompt preview", "error", err, "job_id", job.ID)
+		http.Error(w, "Failed to build prompt preview", http.StatusInternalServerError)
+		return
+	}
+	
+	data := PageData{User: user, Job: &job, PromptPreview: &preview, CSRFToken: s.getCSRFToken(r)}
+	s.renderTemplate(w, "job_preview.html", data)
+}
+
 // queryArticles builds and executes a dynamic query based on filters.
 // This replaces multiple sqlc queries with a single flexible implementation.
+// Searches go through the full-text index instead, unless they only
+// exclude terms, and unfiltered listings sorted by anything but
+// retrieved_at use the query resolveArticleSort picks.
 func (s *Server) queryArticles(r *http.Request, userID int64, f articlesFilter) ([]dbgen.Article, int64) {
+	if len(f.ParsedTerms.Include) > 0 {
+		return s.searchArticles(r, userID, f, f.ParsedTerms)
+	}
+	if query, ok := s.resolveArticleSort(f.SortBy, f.SortOrder); ok && !f.narrowsListing() {
+		return s.listSortedArticles(r, userID, f, query)
+	}
+
 	qb := newArticleQueryBuilder(userID, f)
 
 	// Get count
@@ -284,38 +767,183 @@ func (s *Server) queryArticles(r *http.Request, userID int64, f articlesFilter)
 	var articles []dbgen.Article
 	for rows.Next() {
 		var a dbgen.Article
-		rows.Scan(&a.ID, &a.JobID, &a.UserID, &a.Title, &a.Url, &a.Summary, &a.ContentPath, &a.RetrievedAt)
+		rows.Scan(&a.ID, &a.JobID, &a.UserID, &a.Title, &a.Url, &a.Summary, &a.ContentPath, &a.RetrievedAt, &a.Author, &a.PublishedAt, &a.Source, &a.ImageUrl, &a.SourceName)
 		articles = append(articles, a)
 	}
 	return articles, count
 }
 
+// listSortedArticles lists one page of all of a user's articles with query.
+func (s *Server) listSortedArticles(r *http.Request, userID int64, f articlesFilter, query articleListQuery) ([]dbgen.Article, int64) {
+	articles, err := query(r.Context(), userID, f.Limit, f.Offset)
+	if err != nil {
+		slog.Error("failed to list sorted articles", "error", err, "sort", f.SortBy)
+	}
+	count, err := s.Queries.CountArticlesByUser(r.Context(), userID)
+	if err != nil {
+		slog.Error("failed to count articles", "error", err, "user_id", userID)
+	}
+	return articles, count
+}
+
+// searchArticles lists one page of the articles whose title or summary
+// matches every included search term and no excluded one, best matches
+// first. The job filter still applies; the date filters don't.
+func (s *Server) searchArticles(r *http.Request, userID int64, f articlesFilter, terms SearchTerms) ([]dbgen.Article, int64) {
+	query := termsToFTS5Query(terms)
+	articles, err := s.Queries.SearchArticles(r.Context(), dbgen.SearchArticlesParams{
+		Query:  query,
+		UserID: userID,
+		JobID:  f.JobFilter,
+		Limit:  f.Limit,
+		Offset: f.Offset,
+	})
+	if err != nil {
+		slog.Error("failed to search articles", "error", err, "query", query)
+	}
+	count, err := s.Queries.CountSearchArticles(r.Context(), dbgen.CountSearchArticlesParams{
+		Query:  query,
+		UserID: userID,
+		JobID:  f.JobFilter,
+	})
+	if err != nil {
+		slog.Error("failed to count search results", "error", err, "query", query)
+	}
+	return articles, count
+}
+
+// termsToFTS5Query converts search terms to an FTS5 MATCH expression that
+// requires all of the included terms and none of the excluded ones. Phrases
+// are quoted so their words must appear together; single words match as
+// prefixes, so "launch" finds "launches". Words that aren't plain FTS5
+// barewords, such as "c++" or "AND", are quoted before the prefix marker.
+// FTS5 can't exclude without including, so terms.Include must not be empty.
+func termsToFTS5Query(terms SearchTerms) string {
+	parts := make([]string, 0, len(terms.Include))
+	for _, term := range terms.Include {
+		parts = append(parts, fts5Term(term))
+	}
+	query := strings.Join(parts, " ")
+	for _, term := range terms.Exclude {
+		query += " NOT " + fts5Term(term)
+	}
+	return query
+}
+
+// fts5Term formats one search term for termsToFTS5Query.
+func fts5Term(term string) string {
+	quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	switch {
+	case strings.ContainsFunc(term, unicode.IsSpace):
+		return quoted
+	case isFTS5Bareword(term):
+		return term + "*"
+	default:
+		return quoted + "*"
+	}
+}
+
+// isFTS5Bareword reports whether FTS5 reads term as a plain word: letters,
+// digits, underscores and non-ASCII characters, and not an operator keyword.
+func isFTS5Bareword(term string) bool {
+	switch term {
+	case "", "AND", "OR", "NOT", "NEAR":
+		return false
+	}
+	for _, r := range term {
+		if r < utf8.RuneSelf && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// queryCollectionArticles lists one page of the articles in a collection.
+// The collection filter takes precedence over the other article filters.
+func (s *Server) queryCollectionArticles(r *http.Request, userID int64, f articlesFilter) ([]dbgen.Article, int64) {
+	articles, err := s.Queries.ListArticlesByCollection(r.Context(), dbgen.ListArticlesByCollectionParams{
+		UserID:       userID,
+		CollectionID: f.CollectionFilter,
+		Limit:        f.Limit,
+		Offset:       f.Offset,
+	})
+	if err != nil {
+		slog.Error("failed to list collection articles", "error", err, "collection_id", f.CollectionFilter)
+	}
+	count, err := s.Queries.CountArticlesByCollection(r.Context(), dbgen.CountArticlesByCollectionParams{
+		UserID:       userID,
+		CollectionID: f.CollectionFilter,
+	})
+	if err != nil {
+		slog.Error("failed to count collection articles", "error", err, "collection_id", f.CollectionFilter)
+	}
+	return articles, count
+}
+
+// querySourceArticles lists one page of the articles from a publication,
+// going by their source name. Like the collection filter, it takes
+// precedence over the other article filters.
+func (s *Server) querySourceArticles(r *http.Request, userID int64, f articlesFilter) ([]dbgen.Article, int64) {
+	articles, err := s.Queries.ListArticlesBySourceName(r.Context(), dbgen.ListArticlesBySourceNameParams{
+		UserID:     userID,
+		SourceName: f.SourceNameFilter,
+		Limit:      f.Limit,
+		Offset:     f.Offset,
+	})
+	if err != nil {
+		slog.Error("failed to list source articles", "error", err, "source_name", f.SourceNameFilter)
+	}
+	count, err := s.Queries.CountArticlesBySourceName(r.Context(), dbgen.CountArticlesBySourceNameParams{
+		UserID:     userID,
+		SourceName: f.SourceNameFilter,
+	})
+	if err != nil {
+		slog.Error("failed to count source articles", "error", err, "source_name", f.SourceNameFilter)
+	}
+	return articles, count
+}
+
 // articleQueryBuilder constructs SQL queries for article listing with filters.
 type articleQueryBuilder struct {
 	conditions []string
 	args       []interface{}
+	orderBy    string
 	limit      int64
 	offset     int64
 }
 
 func newArticleQueryBuilder(userID int64, f articlesFilter) *articleQueryBuilder {
 	qb := &articleQueryBuilder{
-		conditions: []string{"user_id = ?"},
+		conditions: []string{"user_id = ?", "archived_at IS NULL"},
 		args:       []interface{}{userID},
+		orderBy:    articleOrderBy(f.SortBy, f.SortOrder),
 		limit:      f.Limit,
 		offset:     f.Offset,
 	}
 
-	// Add filters (priority: search > job > date)
-	switch {
-	case f.SearchQuery != "":
-		qb.addSearchFilter(f.SearchQuery)
-	case f.JobFilter > 0:
+	// The job filter narrows any other filter, so a search within a job
+	// never returns another job's articles
+	if f.JobFilter > 0 {
 		qb.conditions = append(qb.conditions, "job_id = ?")
 		qb.args = append(qb.args, f.JobFilter)
+	}
+
+	// Searches that only exclude terms can't use the full-text index, so
+	// they filter the listing instead
+	for _, term := range f.ParsedTerms.Exclude {
+		pattern := "%" + escapeLike(term) + "%"
+		qb.conditions = append(qb.conditions, `NOT (title LIKE ? ESCAPE '\' OR summary LIKE ? ESCAPE '\')`)
+		qb.args = append(qb.args, pattern, pattern)
+	}
+
+	// Add date filters; other searches are handled by searchArticles
+	switch {
 	case f.UseCustomRange:
+		// Bind in the layout CURRENT_TIMESTAMP stores so the text comparison
+		// is exact down to the second
+		since, until := f.rangeBounds()
 		qb.conditions = append(qb.conditions, "retrieved_at >= ?", "retrieved_at <= ?")
-		qb.args = append(qb.args, f.SinceTime, f.UntilTime)
+		qb.args = append(qb.args, sqliteTimestamp(since), sqliteTimestamp(until))
 	case f.DateFilter != "":
 		qb.conditions = append(qb.conditions, "retrieved_at >= ?")
 		qb.args = append(qb.args, f.SinceTime)
@@ -324,13 +952,42 @@ func newArticleQueryBuilder(userID int64, f articlesFilter) *articleQueryBuilder
 	return qb
 }
 
-func (qb *articleQueryBuilder) addSearchFilter(query string) {
-	terms := parseSearchTerms(query)
-	for _, term := range terms {
-		pattern := "%" + term + "%"
-		qb.conditions = append(qb.conditions, "(title LIKE ? OR summary LIKE ?)")
-		qb.args = append(qb.args, pattern, pattern)
+// sortable reports whether the listing for f honours SortBy. Collection and
+// source lists stay newest first and searches keep their relevance order.
+func (f *articlesFilter) sortable() bool {
+	return f.CollectionFilter == 0 && f.SourceNameFilter == "" && len(f.ParsedTerms.Include) == 0
+}
+
+// narrowsListing reports whether f's job, date or exclusion filters leave
+// out some of the user's articles, so the listing needs
+// articleQueryBuilder.
+func (f *articlesFilter) narrowsListing() bool {
+	return f.JobFilter > 0 || len(f.ParsedTerms.Exclude) > 0 || f.UseCustomRange || f.DateFilter != ""
+}
+
+// articleOrderBy returns the ORDER BY clause for an article sort, newest
+// first if the sort isn't in articleSortOrderBy.
+func articleOrderBy(sort, order string) string {
+	orderBy, ok := articleSortOrderBy[sort]
+	if !ok {
+		orderBy, order = articleSortOrderBy[articleSortRetrieved], "desc"
 	}
+	direction := "ASC"
+	if order == "desc" {
+		direction = "DESC"
+	}
+	return fmt.Sprintf(orderBy, direction)
+}
+
+// escapeLike escapes the LIKE wildcards in s, for patterns using ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// sqliteTimestamp formats t the way SQLite's CURRENT_TIMESTAMP does: UTC,
+// to the second.
+func sqliteTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
 }
 
 func (qb *articleQueryBuilder) whereClause() string {
@@ -342,11 +999,33 @@ func (qb *articleQueryBuilder) buildCountQuery() (string, []interface{}) {
 	return query, qb.args
 }
 
+// buildExportQuery selects every matching article for a CSV export, oldest
+// first. Unlike listings, it applies f's included search terms and
+// collection itself, through the full-text index and collection_articles.
+func (qb *articleQueryBuilder) buildExportQuery(f articlesFilter) (string, []interface{}) {
+	conditions := qb.conditions
+	args := qb.args
+	if len(f.ParsedTerms.Include) > 0 {
+		conditions = append(conditions, "id IN (SELECT rowid FROM articles_fts WHERE articles_fts MATCH ?)")
+		args = append(args, termsToFTS5Query(f.ParsedTerms))
+	}
+	if f.CollectionFilter > 0 {
+		conditions = append(conditions, "id IN (SELECT article_id FROM collection_articles WHERE collection_id = ?)")
+		args = append(args, f.CollectionFilter)
+	}
+	query := fmt.Sprintf(
+		"SELECT id, job_id, title, url, summary, author, published_at, source, retrieved_at "+
+			"FROM articles WHERE %s ORDER BY retrieved_at ASC, id ASC",
+		strings.Join(conditions, " AND "),
+	)
+	return query, args
+}
+
 func (qb *articleQueryBuilder) buildSelectQuery() (string, []interface{}) {
 	query := fmt.Sprintf(
-		"SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at "+
-			"FROM articles WHERE %s ORDER BY retrieved_at DESC LIMIT ? OFFSET ?",
-		qb.whereClause(),
+		"SELECT id, job_id, user_id, title, url, summary, content_path, retrieved_at, author, published_at, source, image_url, source_name "+
+			"FROM articles WHERE %s ORDER BY %s LIMIT ? OFFSET ?",
+		qb.whereClause(), qb.orderBy,
 	)
 	args := append(qb.args, qb.limit, qb.offset)
 	return query, args
@@ -360,27 +1039,67 @@ func (s *Server) handleArticlesList(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	f := parseArticlesFilters(r)
-	articles, count := s.queryArticles(r, user.ID, f)
+	var articles []dbgen.Article
+	var count int64
+	if f.CollectionFilter > 0 {
+		articles, count = s.queryCollectionArticles(r, user.ID, f)
+	} else if f.SourceNameFilter != "" {
+		articles, count = s.querySourceArticles(r, user.ID, f)
+	} else {
+		articles, count = s.queryArticles(r, user.ID, f)
+	}
 	
-	// Get jobs list for the filter dropdown
+	// Get jobs and collections for the filter dropdowns
 	jobs, _ := s.Queries.ListJobsByUser(r.Context(), user.ID)
+	collections, _ := s.Queries.ListCollections(r.Context(), user.ID)
+	
+	setPaginationHeaders(w, r, f.Page, count, f.Limit)
 	
 	data := PageData{
-		User:        user,
-		Jobs:        jobs,
-		Articles:    articles,
-		TotalCount:  count,
-		Page:        f.Page,
-		DateFilter:  f.DateFilter,
-		DateFrom:    f.DateFrom,
-		DateTo:      f.DateTo,
-		SearchQuery: f.SearchQuery,
-		JobFilter:   f.JobFilter,
-		CSRFToken:   s.getCSRFToken(r),
+		User:             user,
+		Jobs:             jobs,
+		Articles:         articles,
+		TotalCount:       count,
+		Page:             f.Page,
+		DateFilter:       f.DateFilter,
+		DateFrom:         f.DateFrom,
+		DateTo:           f.DateTo,
+		TimeFrom:         f.TimeFrom,
+		TimeTo:           f.TimeTo,
+		SearchQuery:      f.SearchQuery,
+		JobFilter:        f.JobFilter,
+		Collections:      collections,
+		CollectionFilter: f.CollectionFilter,
+		SourceNameFilter: f.SourceNameFilter,
+		SortBy:           f.SortBy,
+		SortOrder:        f.SortOrder,
+		ArticlesSortable: f.sortable(),
+		CSRFToken:        s.getCSRFToken(r),
 	}
+	s.hideDisallowedImages(articles)
 	s.renderTemplate(w, "articles.html", data)
 }
 
+// handleArchive lists the user's archived articles with restore and purge
+// controls.
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		redirectToLogin(w, r)
+		return
+	}
+	
+	page, limit, offset := parsePage(r)
+	articles, count, err := s.listArchivedArticles(r.Context(), user.ID, limit, offset)
+	if err != nil {
+		slog.Error("failed to list archived articles", "error", err, "user_id", user.ID)
+	}
+	
+	setPaginationHeaders(w, r, page, count, limit)
+	data := PageData{User: user, Articles: articles, TotalCount: count, Page: page, CSRFToken: s.getCSRFToken(r)}
+	s.renderTemplate(w, "archive.html", data)
+}
+
 func (s *Server) handleArticleDetail(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
@@ -399,10 +1118,30 @@ func (s *Server) handleArticleDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if !s.imageAllowed(article.ImageUrl) {
+		article.ImageUrl = ""
+	}
 	data := PageData{User: user, Article: &article, CSRFToken: s.getCSRFToken(r)}
 	s.renderTemplate(w, "article_detail.html", data)
 }
 
+// imageAllowed reports whether pages may display an article image: it must
+// be served over HTTPS from one of NEWS_APP_IMAGE_DOMAINS, or from anywhere
+// when that is unset.
+func (s *Server) imageAllowed(imageURL string) bool {
+	return strings.HasPrefix(imageURL, "https://") && jobrunner.URLMatchesDomainList(imageURL, s.imageDomains)
+}
+
+// hideDisallowedImages clears the image URLs of articles whose images pages
+// may not display.
+func (s *Server) hideDisallowedImages(articles []dbgen.Article) {
+	for i := range articles {
+		if !s.imageAllowed(articles[i].ImageUrl) {
+			articles[i].ImageUrl = ""
+		}
+	}
+}
+
 func (s *Server) handlePreferences(w http.ResponseWriter, r *http.Request) {
 	user, err := s.getOrCreateUser(r)
 	if err != nil {
@@ -441,3 +1180,19 @@ func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
 	data := PageData{User: user, RunningRuns: runningRuns, RecentRuns: recentRuns, CSRFToken: s.getCSRFToken(r)}
 	s.renderTemplate(w, "runs.html", data)
 }
+
+func (s *Server) handleReadingList(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getOrCreateUser(r)
+	if err != nil {
+		redirectToLogin(w, r)
+		return
+	}
+
+	readingList, err := s.Queries.GetReadingList(r.Context(), user.ID)
+	if err != nil {
+		slog.Error("failed to get reading list", "error", err, "user_id", user.ID)
+	}
+
+	data := PageData{User: user, ReadingList: readingList, CSRFToken: s.getCSRFToken(r)}
+	s.renderTemplate(w, "reading_list.html", data)
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setPaginationHeaders sets RFC 5988 Link headers (first, prev, next, last)
+// plus X-Total-Count and X-Total-Pages for a paginated response. Link URLs
+// are absolute and keep the request's query parameters, changing only page.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, page int, totalCount, limit int64) {
+	totalPages := pageCount(totalCount, limit)
+
+	links := []string{
+		paginationLink(r, 1, "first"),
+	}
+	if page > 1 {
+		links = append(links, paginationLink(r, min(page-1, totalPages), "prev"))
+	}
+	if page < totalPages {
+		links = append(links, paginationLink(r, page+1, "next"))
+	}
+	links = append(links, paginationLink(r, totalPages, "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+	w.Header().Set("X-Total-Count", strconv.FormatInt(totalCount, 10))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(totalPages))
+}
+
+// pageCount returns the number of pages needed to show totalCount items limit
+// at a time, which is at least one.
+func pageCount(totalCount, limit int64) int {
+	if limit > 0 && totalCount > 0 {
+		return int((totalCount + limit - 1) / limit)
+	}
+	return 1
+}
+
+// paginationLink formats a single Link header entry pointing at page.
+func paginationLink(r *http.Request, page int, rel string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	return fmt.Sprintf(`<%s://%s%s?%s>; rel="%s"`, scheme, r.Host, r.URL.Path, q.Encode(), rel)
+}
+
+// maxSlugLength is the longest slug slugify returns.
+const maxSlugLength = 64
+
+// slugify turns title into a lowercase file name: runs of anything but ASCII
+// letters and digits become single dashes, with none at either end, and the
+// result is cut to maxSlugLength. Titles with nothing usable become
+// "article".
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return "article"
+	}
+	return slug
+}
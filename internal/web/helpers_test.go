@@ -0,0 +1,112 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var linkEntryRE = regexp.MustCompile(`<([^>]+)>; rel="(\w+)"`)
+
+// parseLinkHeader maps each rel in a Link header to its URL.
+func parseLinkHeader(t *testing.T, header string) map[string]*url.URL {
+	t.Helper()
+	links := make(map[string]*url.URL)
+	for _, m := range linkEntryRE.FindAllStringSubmatch(header, -1) {
+		u, err := url.Parse(m[1])
+		if err != nil {
+			t.Fatalf("invalid link URL %q: %v", m[1], err)
+		}
+		links[m[2]] = u
+	}
+	return links
+}
+
+func TestSetPaginationHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       int
+		wantPages  map[string]string
+		wantAbsent []string
+	}{
+		{"first page", 1, map[string]string{"first": "1", "next": "2", "last": "3"}, []string{"prev"}},
+		{"middle page", 2, map[string]string{"first": "1", "prev": "1", "next": "3", "last": "3"}, nil},
+		{"last page", 3, map[string]string{"first": "1", "prev": "2", "last": "3"}, []string{"next"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://news.example.com/articles?q=go&filter=week&page=9", nil)
+			w := httptest.NewRecorder()
+			setPaginationHeaders(w, req, tt.page, 25, 10)
+
+			links := parseLinkHeader(t, w.Header().Get("Link"))
+			for rel, wantPage := range tt.wantPages {
+				u, ok := links[rel]
+				if !ok {
+					t.Errorf("missing rel=%q link", rel)
+					continue
+				}
+				if got := u.Query().Get("page"); got != wantPage {
+					t.Errorf("rel=%q page = %s, want %s", rel, got, wantPage)
+				}
+				if u.Host != "news.example.com" || u.Path != "/articles" {
+					t.Errorf("rel=%q URL = %s, want absolute /articles URL", rel, u)
+				}
+				if u.Query().Get("q") != "go" || u.Query().Get("filter") != "week" {
+					t.Errorf("rel=%q URL = %s, want existing query params preserved", rel, u)
+				}
+			}
+			for _, rel := range tt.wantAbsent {
+				if _, ok := links[rel]; ok {
+					t.Errorf("unexpected rel=%q link", rel)
+				}
+			}
+
+			if got := w.Header().Get("X-Total-Count"); got != "25" {
+				t.Errorf("X-Total-Count = %s, want 25", got)
+			}
+			if got := w.Header().Get("X-Total-Pages"); got != "3" {
+				t.Errorf("X-Total-Pages = %s, want 3", got)
+			}
+		})
+	}
+}
+
+func TestArticlesListPaginationHeaders(t *testing.T) {
+	server := newTestServer(t)
+	createTestArticles(t, server, "Article 1", "Article 2", "Article 3")
+
+	w := httptest.NewRecorder()
+	server.handleArticlesList(w, authedRequest(http.MethodGet, "/articles", nil))
+
+	if got := w.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %s, want 3", got)
+	}
+	links := parseLinkHeader(t, w.Header().Get("Link"))
+	if u, ok := links["last"]; !ok || u.Query().Get("page") != "1" {
+		t.Errorf("last link = %v, want page 1", u)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Mars Rover Finds Water", "mars-rover-finds-water"},
+		{"  --Breaking: AI & Jobs (2024)!  ", "breaking-ai-jobs-2024"},
+		{"Café société", "caf-soci-t"},
+		{"日本語", "article"},
+		{"", "article"},
+		{strings.Repeat("word ", 20), strings.TrimRight(strings.Repeat("word-", 13), "-")},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.title); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adminHeaderName is the request header carrying the admin token.
+const adminHeaderName = "X-Admin-Token"
+
+// AdminMiddleware restricts a handler to requests whose X-Admin-Token header
+// matches NEWS_APP_ADMIN_TOKEN. Admin endpoints are disabled entirely when
+// the token is not configured.
+func (s *Server) AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			s.jsonError(w, r, "Not found", http.StatusNotFound)
+			return
+		}
+
+		token := r.Header.Get(adminHeaderName)
+		if token == "" {
+			s.jsonUnauthorized(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			slog.Warn("rejected admin request", "method", r.Method, "path", r.URL.Path)
+			s.jsonError(w, r, "Forbidden: invalid admin token", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// trackInFlight counts requests while they are being served so Drain can
+// wait for them before the server restarts.
+func (s *Server) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// requestIDMiddleware tags each request with an ID, reusing a well-formed
+// X-Request-ID from the client or proxy, and echoes it in the response so
+// errors can be correlated with server logs.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID accepts short IDs of letters, digits, '-' and '_' so that
+// client-supplied values can't inject anything into logs or responses.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFromContext returns the request ID set by requestIDMiddleware, or
+// "" outside of it.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type clientIPKey struct{}
+
+// getClientIP returns the address a request came from: X-Real-IP, else the
+// first X-Forwarded-For entry, as set by the proxy in front of the server,
+// else the connection's remote address. Headers that don't hold an IP are
+// ignored.
+func getClientIP(r *http.Request) string {
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		if ip := strings.TrimSpace(first); net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// clientIPFromContext returns the client IP stored by csrfProtect, or ""
+// outside of it.
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// requestBodyLimitMiddleware caps POST, PUT and PATCH bodies at maxBytes.
+// Requests that declare a larger Content-Length are rejected up front; if a
+// handler fails because it read past the limit, its error response is
+// replaced with a 413.
+func requestBodyLimitMiddleware(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxBytes <= 0 || (r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > maxBytes {
+			writeJSONError(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxBytes)}
+		r.Body = body
+		next.ServeHTTP(&bodyLimitWriter{ResponseWriter: w, r: r, body: body}, r)
+	})
+}
+
+// limitedBody records whether a read hit the body size limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// bodyLimitWriter turns the error response a handler writes after reading
+// past the body limit into a 413.
+type bodyLimitWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	body        *limitedBody
+	replaced    bool
+	wroteHeader bool
+}
+
+func (w *bodyLimitWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded && status >= 400 {
+		w.replaced = true
+		writeJSONError(w.ResponseWriter, w.r, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *bodyLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *bodyLimitWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// SecurityConfig holds the security headers set on every response. Empty
+// values leave the header unset.
+type SecurityConfig struct {
+	HTMLContentSecurityPolicy string        // for pages, which use inline scripts and styles
+	APIContentSecurityPolicy  string        // for JSON responses, which load nothing
+	FrameOptions              string        // X-Frame-Options
+	ReferrerPolicy            string        // Referrer-Policy
+	HSTSMaxAge                time.Duration // Strict-Transport-Security max-age; 0 omits the header
+}
+
+// DefaultSecurityConfig returns the headers New uses unless
+// WithSecurityConfig replaces them. Pages may load scripts, styles and
+// fonts from the app itself and images from anywhere over HTTPS; nothing
+// may frame the app or load plugins.
+func DefaultSecurityConfig() SecurityConfig {
+	return SecurityConfig{
+		HTMLContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; " +
+			"img-src 'self' data: https:; object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'none'",
+		APIContentSecurityPolicy: "default-src 'none'; object-src 'none'; frame-ancestors 'none'",
+		FrameOptions:             "DENY",
+		ReferrerPolicy:           "strict-origin-when-cross-origin",
+		HSTSMaxAge:               365 * 24 * time.Hour,
+	}
+}
+
+// isAPIPath reports whether path serves JSON rather than a page.
+func isAPIPath(path string) bool {
+	return strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/admin/") || path == "/health"
+}
+
+// securityHeadersMiddleware sets config's security headers on every
+// response, choosing the Content-Security-Policy by whether the request is
+// for the API or a page.
+func securityHeadersMiddleware(config SecurityConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		csp := config.HTMLContentSecurityPolicy
+		if isAPIPath(r.URL.Path) {
+			csp = config.APIContentSecurityPolicy
+		}
+		setHeader(h, "Content-Security-Policy", csp)
+		h.Set("X-Content-Type-Options", "nosniff")
+		setHeader(h, "X-Frame-Options", config.FrameOptions)
+		setHeader(h, "Referrer-Policy", config.ReferrerPolicy)
+		if config.HSTSMaxAge > 0 {
+			h.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d", int64(config.HSTSMaxAge.Seconds())))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setHeader sets key to value unless value is empty.
+func setHeader(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
@@ -9,16 +9,20 @@ import (
 	"fmt"
 	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/exedev/news-app/internal/db"
 	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
 	"github.com/exedev/news-app/internal/util"
 )
 
@@ -30,19 +34,43 @@ type Server struct {
 	StaticDir    string
 	ArticlesDir  string
 	templates    map[string]*template.Template
+	templatesMu  sync.RWMutex
+	hotReload    bool
 	rateLimiter  *RateLimiter
+	ipLimiter    *RateLimiter     // stricter limit per user and source IP
 	csrfTokens   *CSRFStore
+	adminToken   string
+	shelley      *jobrunner.ShelleyClient
+	httpServer   atomic.Pointer[http.Server]
+	inFlight     sync.WaitGroup   // requests being served, for Drain
+	maxBodySize  int64            // POST/PUT/PATCH body limit in bytes
+	autoResume   bool             // resume runs left running on startup
+	security     SecurityConfig   // headers set on every response
+	imageDomains string           // comma-separated hosts article images may be shown from; empty allows any
+	runConfig    jobrunner.Config // run limits shared with the job processes the server starts
+
+	// runJob starts a job process when systemd can't; runJobDirectly
+	// outside tests
+	runJob func(jobID int64, maxParallel int)
 }
 
 // CSRFStore manages CSRF tokens per user
 type CSRFStore struct {
 	mu     sync.RWMutex
 	tokens map[string]csrfEntry // userID -> token entry
+	ttl    time.Duration
+	now    func() time.Time // stubbed in tests
 }
 
 type csrfEntry struct {
 	token     string
 	expiresAt time.Time
+
+	// previous is the token rotated out, still accepted until
+	// previousUntil so that requests already sent with it, e.g. from
+	// another tab, don't fail
+	previous      string
+	previousUntil time.Time
 }
 
 // Application constants
@@ -50,46 +78,86 @@ const (
 	// Pagination
 	DefaultPageLimit = 50
 
+	// Archived articles become eligible for purging after this long
+	ArchiveRetention = 30 * 24 * time.Hour
+
 	// Rate limiting
-	RateLimitWindow   = time.Minute
-	RateLimitRequests = 10
+	RateLimitWindow        = time.Minute
+	RateLimitRequests      = 10
+	RateLimitRequestsPerIP = 5
 
 	// Static file caching (seconds)
 	StaticCacheMaxAge = 86400 // 1 day
 
 	// CSRF
-	csrfTokenLength = 32
-	csrfTokenTTL    = 24 * time.Hour
-	csrfHeaderName  = "X-CSRF-Token"
+	csrfTokenLength        = 32
+	defaultCSRFTokenTTL    = 24 * time.Hour
+	csrfRotationGrace      = 5 * time.Minute // how long a rotated-out token stays valid
+	csrfHeaderName         = "X-CSRF-Token"
+	csrfNewTokenHeaderName = "X-New-CSRF-Token"
 )
 
-func NewCSRFStore() *CSRFStore {
+// NewCSRFStore creates a store whose tokens expire after ttl, or after
+// defaultCSRFTokenTTL if ttl isn't positive.
+func NewCSRFStore(ttl time.Duration) *CSRFStore {
+	if ttl <= 0 {
+		ttl = defaultCSRFTokenTTL
+	}
 	return &CSRFStore{
 		tokens: make(map[string]csrfEntry),
+		ttl:    ttl,
+		now:    time.Now,
 	}
 }
 
+// issueToken generates and stores a fresh token for the user, keeping the
+// one it replaces valid for csrfRotationGrace. The caller must hold cs.mu.
+func (cs *CSRFStore) issueToken(userID string) string {
+	b := make([]byte, csrfTokenLength)
+	rand.Read(b)
+	token := base64.URLEncoding.EncodeToString(b)
+	
+	now := cs.now()
+	old := cs.tokens[userID]
+	previousUntil := now.Add(csrfRotationGrace)
+	if old.expiresAt.Before(previousUntil) {
+		previousUntil = old.expiresAt
+	}
+	cs.tokens[userID] = csrfEntry{
+		token:         token,
+		expiresAt:     now.Add(cs.ttl),
+		previous:      old.token,
+		previousUntil: previousUntil,
+	}
+	return token
+}
+
 // GetOrCreateToken returns a valid CSRF token for the user, creating one if needed
 func (cs *CSRFStore) GetOrCreateToken(userID string) string {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 	
 	entry, exists := cs.tokens[userID]
-	if exists && time.Now().Before(entry.expiresAt) {
+	if exists && cs.now().Before(entry.expiresAt) {
 		return entry.token
 	}
 	
-	// Generate new token
-	b := make([]byte, csrfTokenLength)
-	rand.Read(b)
-	token := base64.URLEncoding.EncodeToString(b)
+	return cs.issueToken(userID)
+}
+
+// RotateToken retires used, a token the user just made a change with, and
+// returns the one to use next. If used is the user's current token, a new
+// one replaces it and used stays valid for csrfRotationGrace. If used was
+// already replaced, e.g. by a request from another tab, the current token
+// is returned so that both tabs end up with it.
+func (cs *CSRFStore) RotateToken(userID, used string) string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 	
-	cs.tokens[userID] = csrfEntry{
-		token:     token,
-		expiresAt: time.Now().Add(csrfTokenTTL),
+	if entry, exists := cs.tokens[userID]; exists && used != entry.token && cs.now().Before(entry.expiresAt) {
+		return entry.token
 	}
-	
-	return token
+	return cs.issueToken(userID)
 }
 
 // ValidateToken checks if the provided token is valid for the user
@@ -98,10 +166,14 @@ func (cs *CSRFStore) ValidateToken(userID, token string) bool {
 	defer cs.mu.RUnlock()
 	
 	entry, exists := cs.tokens[userID]
-	if !exists {
+	if !exists || token == "" {
 		return false
 	}
-	if time.Now().After(entry.expiresAt) {
+	now := cs.now()
+	if token == entry.previous && now.Before(entry.previousUntil) {
+		return true
+	}
+	if now.After(entry.expiresAt) {
 		return false
 	}
 	return entry.token == token
@@ -149,7 +221,76 @@ func (rl *RateLimiter) Allow(key string) bool {
 	return true
 }
 
-func New(dbPath, hostname string) (*Server, error) {
+// AllowWithIP is Allow for key from one source IP: each IP gets its own
+// bucket. An empty ip is the same as Allow(key).
+func (rl *RateLimiter) AllowWithIP(key, ip string) bool {
+	if ip == "" {
+		return rl.Allow(key)
+	}
+	return rl.Allow(key + ":" + ip)
+}
+
+// allowRequest applies the rate limit for key to r: a stricter limit per
+// source IP, then the limit for the user as a whole. The user-wide bucket
+// stays because forwarding headers are set by clients as well as proxies,
+// so the IP alone can't be trusted to limit anyone.
+func (s *Server) allowRequest(r *http.Request, key string) bool {
+	if ip := clientIPFromContext(r.Context()); ip != "" && !s.ipLimiter.AllowWithIP(key, ip) {
+		return false
+	}
+	return s.rateLimiter.Allow(key)
+}
+
+// ServerOption configures optional Server behaviour.
+type ServerOption func(*Server)
+
+// WithTemplateHotReload makes the server re-parse templates from disk on
+// every request and watch the templates directory for changes. Intended for
+// development; templates are still parsed once at startup to catch errors.
+func WithTemplateHotReload(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.hotReload = enabled
+	}
+}
+
+// DefaultMaxBodySize is the default request body limit, overridable with
+// NEWS_HTTP_MAX_BODY_SIZE_KB or WithMaxBodySize.
+const DefaultMaxBodySize = 1 << 20
+
+// WithMaxBodySize limits POST, PUT and PATCH request bodies to n bytes.
+// Zero keeps the default.
+func WithMaxBodySize(n int64) ServerOption {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxBodySize = n
+		}
+	}
+}
+
+// WithQueries makes the server run its queries through q instead of
+// querying its database directly, e.g. so tests can record query calls.
+func WithQueries(q *dbgen.Queries) ServerOption {
+	return func(s *Server) {
+		s.Queries = q
+	}
+}
+
+// WithSecurityConfig replaces the security headers set on every response.
+func WithSecurityConfig(config SecurityConfig) ServerOption {
+	return func(s *Server) {
+		s.security = config
+	}
+}
+
+// WithAutoResume controls whether job runs left in the running state, e.g.
+// by a restart, are resumed when the server starts. It is on by default.
+func WithAutoResume(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.autoResume = enabled
+	}
+}
+
+func New(dbPath, hostname string, opts ...ServerOption) (*Server, error) {
 	_, thisFile, _, _ := runtime.Caller(0)
 	baseDir := filepath.Dir(thisFile)
 	
@@ -163,7 +304,19 @@ func New(dbPath, hostname string) (*Server, error) {
 		ArticlesDir:  articlesDir,
 		templates:    make(map[string]*template.Template),
 		rateLimiter:  NewRateLimiter(RateLimitWindow, RateLimitRequests),
-		csrfTokens:   NewCSRFStore(),
+		ipLimiter:    NewRateLimiter(RateLimitWindow, RateLimitRequestsPerIP),
+		csrfTokens:   NewCSRFStore(util.GetEnvDuration("NEWS_CSRF_TOKEN_TTL", defaultCSRFTokenTTL)),
+		adminToken:   util.GetEnv("NEWS_APP_ADMIN_TOKEN", ""),
+		shelley:      jobrunner.NewShelleyClient(util.GetEnv("NEWS_APP_SHELLEY_API", "http://localhost:9999")),
+		maxBodySize:  int64(util.GetEnvInt("NEWS_HTTP_MAX_BODY_SIZE_KB", DefaultMaxBodySize>>10)) << 10,
+		autoResume:   true,
+		security:     DefaultSecurityConfig(),
+		imageDomains: util.GetEnv("NEWS_APP_IMAGE_DOMAINS", ""),
+		runConfig:    jobrunner.DefaultConfig(),
+		runJob:       runJobDirectly,
+	}
+	for _, opt := range opts {
+		opt(srv)
 	}
 	if err := srv.setUpDatabase(dbPath); err != nil {
 		return nil, err
@@ -174,6 +327,12 @@ func New(dbPath, hostname string) (*Server, error) {
 	return srv, nil
 }
 
+// Close closes the server's database. Serve doesn't need it, as the database
+// lives as long as the process, but servers that are only used for their
+// handlers, as in tests, should be closed.
+func (s *Server) Close() error {
+	return s.DB.Close()
+}
 
 func (s *Server) setUpDatabase(dbPath string) error {
 	wdb, err := db.Open(dbPath)
@@ -181,20 +340,71 @@ func (s *Server) setUpDatabase(dbPath string) error {
 		return fmt.Errorf("failed to open db: %w", err)
 	}
 	s.DB = wdb
-	s.Queries = dbgen.New(wdb)
+	if s.Queries == nil {
+		s.Queries = dbgen.New(wdb)
+	}
 	if err := db.RunMigrations(wdb); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	// Recover any jobs stuck in "running" state on startup
-	if err := s.recoverStuckJobs(); err != nil {
-		slog.Warn("failed to recover stuck jobs", "error", err)
+	if s.autoResume {
+		if err := s.recoverStuckJobs(); err != nil {
+			slog.Warn("failed to recover stuck jobs", "error", err)
+		}
 	}
 
 	return nil
 }
 
+// alertNotifier returns the notifier for operational alerts, or nil if
+// NEWS_APP_ALERT_WEBHOOK is unset and alerts should only be logged.
+func (s *Server) alertNotifier() db.NotificationSender {
+	webhook := util.GetEnv("NEWS_APP_ALERT_WEBHOOK", "")
+	if webhook == "" {
+		return nil
+	}
+	return jobrunner.DiscordNotifier{WebhookURL: webhook}
+}
+
+// Serve listens on the TCP address addr and serves the app until Drain is
+// called.
 func (s *Server) Serve(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeListener(ln)
+}
+
+// ServeListener is like Serve, but accepts connections on ln, such as a Unix
+// socket or one passed by systemd socket activation. It closes ln when it
+// returns.
+func (s *Server) ServeListener(ln net.Listener) error {
+	if s.hotReload {
+		stop := watchTemplates(s.TemplatesDir, s.loadTemplates)
+		defer stop()
+		slog.Info("template hot-reload enabled", "dir", s.TemplatesDir)
+	}
+
+	// Weekly digest notifications run for as long as the server does
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobrunner.NewDigestScheduler(s.DB).Start(ctx)
+
+	// Job runs queue batched notifications for the server to send
+	if s.runConfig.BatchNotifications {
+		batcherConfig := jobrunner.DefaultBatcherConfig()
+		batcherConfig.Window = s.runConfig.BatchWindow
+		jobrunner.NewNotificationBatcher(s.DB, batcherConfig).Start(ctx)
+	}
+
+	// So do database integrity checks, unless disabled with a zero interval
+	if interval := util.GetEnvDuration("NEWS_DB_MONITOR_INTERVAL", 24*time.Hour); interval > 0 {
+		walMax := int64(util.GetEnvInt("NEWS_DB_WAL_MAX_MB", db.DefaultWALMaxBytes>>20)) << 20
+		go db.MonitorDBIntegrity(ctx, s.DB, s.alertNotifier(), interval, walMax)
+	}
+
 	mux := http.NewServeMux()
 
 	// Health check (no auth required)
@@ -205,30 +415,114 @@ func (s *Server) Serve(addr string) error {
 	mux.HandleFunc("GET /jobs", s.handleJobsList)
 	mux.HandleFunc("GET /jobs/new", s.handleJobNew)
 	mux.HandleFunc("GET /jobs/{id}/edit", s.handleJobEdit)
+	mux.HandleFunc("POST /jobs/{id}/edit", s.handleJobEditForm)
+	mux.HandleFunc("GET /jobs/{id}/preview", s.handleJobPreview)
 	mux.HandleFunc("GET /jobs/{id}", s.handleJobDetail)
 	mux.HandleFunc("GET /articles", s.handleArticlesList)
+	mux.HandleFunc("GET /articles/archive", s.handleArchive)
 	mux.HandleFunc("GET /articles/{id}", s.handleArticleDetail)
 	mux.HandleFunc("GET /preferences", s.handlePreferences)
 	mux.HandleFunc("GET /runs", s.handleRuns)
+	mux.HandleFunc("GET /reading-list", s.handleReadingList)
 
 	// API (protected by CSRF)
+	mux.HandleFunc("GET /api/jobs", s.handleListJobs)
+	mux.HandleFunc("GET /api/jobs/tags", s.handleListTags)
 	mux.HandleFunc("POST /api/jobs", s.csrfProtect(s.handleCreateJob))
+	mux.HandleFunc("POST /api/jobs/bulk-toggle", s.csrfProtect(s.handleBulkToggleJobs))
+	mux.HandleFunc("POST /api/jobs/bulk-delete", s.csrfProtect(s.handleBulkDeleteJobs))
 	mux.HandleFunc("PUT /api/jobs/{id}", s.csrfProtect(s.handleUpdateJob))
+	mux.HandleFunc("PUT /api/jobs/{id}/tags", s.csrfProtect(s.handleSetJobTags))
 	mux.HandleFunc("DELETE /api/jobs/{id}", s.csrfProtect(s.handleDeleteJob))
 	mux.HandleFunc("POST /api/jobs/{id}/run", s.csrfProtect(s.handleRunJob))
 	mux.HandleFunc("POST /api/jobs/{id}/stop", s.csrfProtect(s.handleStopJob))
+	mux.HandleFunc("GET /api/jobs/{id}/next-runs", s.handleJobNextRuns)
+	mux.HandleFunc("GET /api/jobs/{id}/events", s.handleJobEvents)
+	mux.HandleFunc("GET /api/jobs/{id}/stats", s.handleJobStats)
+	mux.HandleFunc("GET /api/jobs/{id}/prompt-preview", s.handleJobPromptPreview)
+	mux.HandleFunc("GET /api/jobs/{id}/articles", s.handleJobArticles)
+	mux.HandleFunc("GET /api/jobs/{id}/runs", s.handleJobRuns)
+	mux.HandleFunc("GET /api/jobs/{id}/runs/latest", s.handleLatestRun)
+	mux.HandleFunc("GET /api/jobs/{id}/runs/latest/log", s.handleLatestRunLog)
+	mux.HandleFunc("GET /api/jobs/{id}/runs/{run_id}/conversation", s.handleRunConversation)
 	mux.HandleFunc("POST /api/runs/{id}/cancel", s.csrfProtect(s.handleCancelRun))
 	mux.HandleFunc("POST /api/articles/delete", s.csrfProtect(s.handleDeleteArticles))
+	mux.HandleFunc("GET /api/articles/archive", s.handleListArchivedArticles)
+	mux.HandleFunc("POST /api/articles/{id}/restore", s.csrfProtect(s.handleRestoreArticle))
+	mux.HandleFunc("POST /api/articles/purge", s.csrfProtect(s.handlePurgeArticles))
+	mux.HandleFunc("GET /api/preferences", s.handleGetPreferences)
 	mux.HandleFunc("POST /api/preferences", s.csrfProtect(s.handleUpdatePreferences))
+	mux.HandleFunc("DELETE /api/user", s.csrfProtect(s.handleDeleteAccount))
+	mux.HandleFunc("GET /api/user/cost-summary", s.handleCostSummary)
+	mux.HandleFunc("GET /api/user/usage", s.handleUsage)
+	mux.HandleFunc("POST /api/reading-list", s.csrfProtect(s.handleAddToReadingList))
+	mux.HandleFunc("PUT /api/reading-list/reorder", s.csrfProtect(s.handleReorderReadingList))
+	mux.HandleFunc("DELETE /api/reading-list/{id}", s.csrfProtect(s.handleRemoveFromReadingList))
+	mux.HandleFunc("GET /api/reading-list", s.handleGetReadingList)
+	mux.HandleFunc("GET /api/collections", s.handleListCollections)
+	mux.HandleFunc("POST /api/collections", s.csrfProtect(s.handleCreateCollection))
+	mux.HandleFunc("DELETE /api/collections/{id}", s.csrfProtect(s.handleDeleteCollection))
+	mux.HandleFunc("POST /api/collections/{id}/articles", s.csrfProtect(s.handleAddArticleToCollection))
+	mux.HandleFunc("DELETE /api/collections/{id}/articles/{article_id}", s.csrfProtect(s.handleRemoveArticleFromCollection))
+	mux.HandleFunc("GET /api/articles/search", s.handleSearchArticles)
+	mux.HandleFunc("GET /api/articles/count-by-date", s.handleArticleCountByDate)
+	mux.HandleFunc("GET /api/articles/sources", s.handleArticleSources)
+	mux.HandleFunc("GET /api/articles/export/csv", s.handleExportCSV)
+	mux.HandleFunc("GET /api/articles/{id}", s.handleGetArticle)
 	mux.HandleFunc("GET /api/articles/{id}/content", s.handleArticleContent)
+	mux.HandleFunc("GET /api/articles/{id}/similar", s.handleSimilarArticles)
+	mux.HandleFunc("GET /api/runs/{id}", s.handleGetRun)
 	mux.HandleFunc("GET /api/runs/{id}/log", s.handleRunLog)
+	mux.HandleFunc("GET /api/runs/{id}/events", s.handleRunEvents)
+
+	// Admin (cross-user, protected by admin token)
+	mux.HandleFunc("GET /admin/users", s.AdminMiddleware(s.handleAdminUsers))
+	mux.HandleFunc("GET /admin/jobs", s.AdminMiddleware(s.handleAdminJobs))
+	mux.HandleFunc("GET /admin/runs", s.AdminMiddleware(s.handleAdminRuns))
+	mux.HandleFunc("DELETE /admin/users/{id}", s.AdminMiddleware(s.handleAdminDeleteUser))
+	mux.HandleFunc("PUT /admin/users/{id}/quota", s.AdminMiddleware(s.handleAdminSetQuota))
 
 	// Static files with caching
 	staticHandler := http.StripPrefix("/static/", http.FileServer(http.Dir(s.StaticDir)))
 	mux.Handle("/static/", cacheControl(staticHandler, StaticCacheMaxAge))
 
-	slog.Info("starting server", "addr", addr)
-	return http.ListenAndServe(addr, requestLogger(mux))
+	handler := requestIDMiddleware(requestLogger(securityHeadersMiddleware(s.security, requestBodyLimitMiddleware(s.maxBodySize, mux))))
+	srv := &http.Server{Handler: s.trackInFlight(handler)}
+	s.httpServer.Store(srv)
+
+	slog.Info("starting server", "addr", ln.Addr().String(), "network", ln.Addr().Network())
+	return srv.Serve(ln)
+}
+
+// Drain stops accepting new connections and waits for in-flight requests to
+// finish. It returns ctx's error if ctx is done first. Job processes started
+// by the server run detached and outlive it, so Drain doesn't wait for them.
+// Serve returns http.ErrServerClosed once Drain is called.
+func (s *Server) Drain(ctx context.Context) error {
+	if srv := s.httpServer.Load(); srv != nil {
+		if err := srv.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shut down listener: %w", err)
+		}
+	}
+	if err := waitContext(ctx, &s.inFlight); err != nil {
+		return fmt.Errorf("wait for requests: %w", err)
+	}
+	return nil
+}
+
+// waitContext waits for wg, giving up when ctx is done.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // handleHealth returns service health status
@@ -271,6 +565,11 @@ func (rr *responseRecorder) WriteHeader(code int) {
 	rr.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
 // requestLogger logs HTTP requests with method, path, status, and duration
 func requestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -295,6 +594,7 @@ func requestLogger(next http.Handler) http.Handler {
 			"status", rr.status,
 			"duration", duration.Round(time.Millisecond),
 			"user_id", userID,
+			"request_id", requestIDFromContext(r.Context()),
 		}
 		
 		if rr.status >= 500 {
@@ -312,25 +612,64 @@ func (s *Server) csrfProtect(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := strings.TrimSpace(r.Header.Get("X-ExeDev-UserID"))
 		if userID == "" {
-			s.jsonUnauthorized(w)
+			s.jsonUnauthorized(w, r)
 			return
 		}
 		
 		token := r.Header.Get(csrfHeaderName)
 		if token == "" {
-			s.jsonError(w, "Forbidden: missing CSRF token", http.StatusForbidden)
+			s.jsonError(w, r, "Forbidden: missing CSRF token", http.StatusForbidden, ErrCodeInvalidCSRFToken)
 			return
 		}
 		
 		if !s.csrfTokens.ValidateToken(userID, token) {
-			s.jsonError(w, "Forbidden: invalid CSRF token", http.StatusForbidden)
+			s.jsonError(w, r, "Forbidden: invalid CSRF token", http.StatusForbidden, ErrCodeInvalidCSRFToken)
 			return
 		}
 		
-		next(w, r)
+		// Rate limits also count requests per source IP
+		cw := &csrfRotatingWriter{ResponseWriter: w, store: s.csrfTokens, userID: userID, token: token}
+		next(cw, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, getClientIP(r))))
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
 	}
 }
 
+// csrfRotatingWriter rotates the user's CSRF token once a protected request
+// turns out to have succeeded, so each token is good for one change; the
+// page picks up the replacement from the response header. A failed request
+// leaves the token as it was.
+type csrfRotatingWriter struct {
+	http.ResponseWriter
+	store       *CSRFStore
+	userID      string
+	token       string // the token the request was made with
+	wroteHeader bool
+}
+
+func (w *csrfRotatingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status < http.StatusBadRequest {
+			w.Header().Set(csrfNewTokenHeaderName, w.store.RotateToken(w.userID, w.token))
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *csrfRotatingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *csrfRotatingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // getOrCreateUser ensures a user exists and returns their ID
 func (s *Server) getOrCreateUser(r *http.Request) (*dbgen.User, error) {
 	exeUserID := strings.TrimSpace(r.Header.Get("X-ExeDev-UserID"))
@@ -376,50 +715,138 @@ func templateFuncMap() template.FuncMap {
 		"add":      func(a, b int) int { return a + b },
 		"subtract": func(a, b int) int { return a - b },
 		"multiply": func(a, b int) int64 { return int64(a) * int64(b) },
+		"join":     strings.Join,
 	}
 }
 
+// templateFiles lists the page templates, each rendered inside layout.html.
+var templateFiles = []string{
+	"dashboard.html",
+	"jobs.html",
+	"job_new.html",
+	"job_edit.html",
+	"job_detail.html",
+	"job_preview.html",
+	"articles.html",
+	"article_detail.html",
+	"preferences.html",
+	"runs.html",
+	"reading_list.html",
+	"archive.html",
+}
+
 // loadTemplates parses all templates at startup
 func (s *Server) loadTemplates() error {
-	layoutPath := filepath.Join(s.TemplatesDir, "layout.html")
-	
-	templateFiles := []string{
-		"dashboard.html",
-		"jobs.html",
-		"job_new.html",
-		"job_edit.html",
-		"job_detail.html",
-		"articles.html",
-		"article_detail.html",
-		"preferences.html",
-		"runs.html",
-	}
-	
+	templates := make(map[string]*template.Template, len(templateFiles))
 	for _, name := range templateFiles {
-		path := filepath.Join(s.TemplatesDir, name)
-		tmpl, err := template.New("").Funcs(templateFuncMap()).ParseFiles(layoutPath, path)
+		tmpl, err := s.parseTemplate(name)
 		if err != nil {
-			return fmt.Errorf("parse template %q: %w", name, err)
+			return err
 		}
-		s.templates[name] = tmpl
+		templates[name] = tmpl
 	}
 	
-	slog.Info("loaded templates", "count", len(s.templates))
+	s.templatesMu.Lock()
+	s.templates = templates
+	s.templatesMu.Unlock()
+	
+	slog.Info("loaded templates", "count", len(templates))
 	return nil
 }
 
+// parseTemplate parses a page template together with the shared layout.
+func (s *Server) parseTemplate(name string) (*template.Template, error) {
+	layoutPath := filepath.Join(s.TemplatesDir, "layout.html")
+	path := filepath.Join(s.TemplatesDir, name)
+	tmpl, err := template.New("").Funcs(templateFuncMap()).ParseFiles(layoutPath, path)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", name, err)
+	}
+	return tmpl, nil
+}
+
 func (s *Server) renderTemplate(w http.ResponseWriter, name string, data any) {
+	s.renderTemplateStatus(w, http.StatusOK, name, data)
+}
+
+// renderTemplateStatus is renderTemplate responding with status, which is
+// written after the Content-Type header.
+func (s *Server) renderTemplateStatus(w http.ResponseWriter, status int, name string, data any) {
+	s.templatesMu.RLock()
 	tmpl, ok := s.templates[name]
+	s.templatesMu.RUnlock()
 	if !ok {
 		http.Error(w, fmt.Sprintf("template %q not found", name), 500)
 		return
 	}
+	if s.hotReload {
+		fresh, err := s.parseTemplate(name)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		tmpl = fresh
+	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
 		http.Error(w, err.Error(), 500)
 	}
 }
 
+// templatePollInterval is how often watchTemplates checks for changes.
+var templatePollInterval = 500 * time.Millisecond
+
+// watchTemplates polls dir for modified files and calls reload whenever the
+// newest modification time changes. The returned func stops the watcher.
+func watchTemplates(dir string, reload func() error) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	last := latestModTime(dir)
+	
+	go func() {
+		ticker := time.NewTicker(templatePollInterval)
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mod := latestModTime(dir)
+				if mod.Equal(last) {
+					continue
+				}
+				last = mod
+				if err := reload(); err != nil {
+					slog.Warn("template reload failed", "error", err)
+				}
+			}
+		}
+	}()
+	
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// latestModTime returns the newest modification time of the files in dir.
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return latest
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
 // parsePathID extracts and parses the "id" path parameter.
 // Returns the ID and true on success, or writes an error response and returns false.
 func parsePathID(w http.ResponseWriter, r *http.Request, errMsg string) (int64, bool) {
@@ -431,16 +858,6 @@ func parsePathID(w http.ResponseWriter, r *http.Request, errMsg string) (int64,
 	return id, true
 }
 
-func (s *Server) jsonError(w http.ResponseWriter, msg string, code int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": msg})
-}
-
-func (s *Server) jsonUnauthorized(w http.ResponseWriter) {
-	s.jsonError(w, "Unauthorized", http.StatusUnauthorized)
-}
-
 func (s *Server) jsonOK(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
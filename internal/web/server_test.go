@@ -1,14 +1,41 @@
 package web
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/exedev/news-app/internal/db/dbgen"
+	"github.com/exedev/news-app/internal/jobrunner"
+	"go.uber.org/goleak"
 )
 
+// TestMain fails the package's tests if any goroutine outlives them.
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
 func TestServerSetup(t *testing.T) {
 	tempDB := filepath.Join(t.TempDir(), "test_server.sqlite3")
 	t.Cleanup(func() { os.Remove(tempDB) })
@@ -17,6 +44,7 @@ func TestServerSetup(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create server: %v", err)
 	}
+	t.Cleanup(func() { server.Close() })
 
 	if server.DB == nil {
 		t.Error("expected DB to be initialized")
@@ -34,6 +62,7 @@ func TestDashboardRequiresAuth(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create server: %v", err)
 	}
+	t.Cleanup(func() { server.Close() })
 
 	// Test without auth - should redirect
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -57,6 +86,7 @@ func TestDashboardWithAuth(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create server: %v", err)
 	}
+	t.Cleanup(func() { server.Close() })
 
 	// Test with auth headers
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -86,6 +116,7 @@ func TestJobDetailInvalidID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create server: %v", err)
 	}
+	t.Cleanup(func() { server.Close() })
 
 	// Test with invalid ID
 	req := httptest.NewRequest(http.MethodGet, "/jobs/invalid", nil)
@@ -108,6 +139,7 @@ func TestJobDetailNotFound(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create server: %v", err)
 	}
+	t.Cleanup(func() { server.Close() })
 
 	// Test with non-existent ID
 	req := httptest.NewRequest(http.MethodGet, "/jobs/99999", nil)
@@ -121,3 +153,2580 @@ func TestJobDetailNotFound(t *testing.T) {
 		t.Errorf("expected status 404 for non-existent job, got %d", w.Code)
 	}
 }
+
+// newTestServer creates a server backed by a temporary database.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	tempDB := filepath.Join(t.TempDir(), "test_server.sqlite3")
+	t.Cleanup(func() { os.Remove(tempDB) })
+
+	server, err := New(tempDB, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// authedRequest builds a request carrying the test user's auth headers.
+func authedRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("X-ExeDev-UserID", "test-user-123")
+	req.Header.Set("X-ExeDev-Email", "test@example.com")
+	return req
+}
+
+// createTestArticles creates a job with one article per title, owned by the test user.
+func createTestArticles(t *testing.T, server *Server, titles ...string) (*dbgen.User, []dbgen.Article) {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{
+		UserID:    user.ID,
+		Name:      "Test Job",
+		Prompt:    "test",
+		Frequency: "daily",
+	})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	var articles []dbgen.Article
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, title := range titles {
+		article, err := server.Queries.CreateArticle(ctx, dbgen.CreateArticleParams{
+			JobID:       job.ID,
+			UserID:      user.ID,
+			Title:       title,
+			Url:         fmt.Sprintf("https://example.com/%d/%d", job.ID, i+1),
+			RetrievedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("failed to create article: %v", err)
+		}
+		articles = append(articles, article)
+	}
+	return user, articles
+}
+
+func TestReadingListDuplicateAdd(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "Article 1")
+
+	body := fmt.Sprintf(`{"article_id": %d}`, articles[0].ID)
+	wants := []int{http.StatusOK, http.StatusConflict}
+	for i, want := range wants {
+		w := httptest.NewRecorder()
+		server.handleAddToReadingList(w, authedRequest(http.MethodPost, "/api/reading-list", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("add #%d status = %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
+
+func TestReadingListReorder(t *testing.T) {
+	server := newTestServer(t)
+	user, articles := createTestArticles(t, server, "Article 1", "Article 2", "Article 3")
+	ctx := context.Background()
+
+	for _, a := range articles {
+		_, err := server.Queries.AddToReadingList(ctx, dbgen.AddToReadingListParams{
+			UserID:    user.ID,
+			ArticleID: a.ID,
+			UserID_2:  user.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to add to reading list: %v", err)
+		}
+	}
+	a1, a2, a3 := articles[0].ID, articles[1].ID, articles[2].ID
+
+	tests := []struct {
+		name string
+		ids  []int64
+		want []int64
+	}{
+		{"initial order kept", nil, []int64{a1, a2, a3}},
+		{"partial list moves to front", []int64{a3, a1}, []int64{a3, a1, a2}},
+		{"repeat is stable", []int64{a3, a1}, []int64{a3, a1, a2}},
+		{"unknown and duplicate ids ignored", []int64{a2, 99999, a2}, []int64{a2, a3, a1}},
+		{"full explicit order", []int64{a1, a3, a2}, []int64{a1, a3, a2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := server.reorderReadingList(ctx, user.ID, tt.ids); err != nil {
+				t.Fatalf("reorderReadingList() error = %v", err)
+			}
+			list, err := server.Queries.GetReadingList(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetReadingList() error = %v", err)
+			}
+			var got []int64
+			for _, a := range list {
+				got = append(got, a.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarArticles(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server,
+		"Quantum computing breakthrough announced",
+		"Startup builds quantum sensor",
+		"Local bakery wins award",
+	)
+
+	req := authedRequest(http.MethodGet, "/api/articles/1/similar", nil)
+	req.SetPathValue("id", fmt.Sprint(articles[0].ID))
+	w := httptest.NewRecorder()
+	server.handleSimilarArticles(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var similar []dbgen.Article
+	if err := json.Unmarshal(w.Body.Bytes(), &similar); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(similar) != 1 || similar[0].ID != articles[1].ID {
+		t.Errorf("similar = %+v, want only article %d", similar, articles[1].ID)
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	t.Setenv("NEWS_APP_ADMIN_TOKEN", "admin-secret")
+	server := newTestServer(t)
+	handler := server.AdminMiddleware(server.handleAdminUsers)
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "nope", http.StatusForbidden},
+		{"valid token", "admin-secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			if tt.token != "" {
+				req.Header.Set("X-Admin-Token", tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	// Without a configured token the admin endpoints don't exist
+	server.adminToken = ""
+	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	req.Header.Set("X-Admin-Token", "admin-secret")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unconfigured status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminCrossUserData(t *testing.T) {
+	t.Setenv("NEWS_APP_ADMIN_TOKEN", "admin-secret")
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	// First user has one job with two articles
+	createTestArticles(t, server, "Article 1", "Article 2")
+
+	// Second user has a job and a content file on disk
+	other, err := server.Queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "other-user", Email: "other@example.com"})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: other.ID, Name: "Other Job", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	contentPath := filepath.Join(t.TempDir(), "article.txt")
+	if err := os.WriteFile(contentPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+	_, err = server.Queries.CreateArticle(ctx, dbgen.CreateArticleParams{JobID: job.ID, UserID: other.ID, Title: "Other", ContentPath: contentPath, RetrievedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to create article: %v", err)
+	}
+
+	adminRequest := func(method, target string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("X-Admin-Token", "admin-secret")
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	server.AdminMiddleware(server.handleAdminUsers)(w, adminRequest(http.MethodGet, "/admin/users"))
+	var users []dbgen.ListAllUsersWithCountsRow
+	if err := json.Unmarshal(w.Body.Bytes(), &users); err != nil {
+		t.Fatalf("failed to decode users: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users))
+	}
+	if users[0].JobCount != 1 || users[0].ArticleCount != 2 {
+		t.Errorf("first user counts = %d jobs, %d articles, want 1, 2", users[0].JobCount, users[0].ArticleCount)
+	}
+
+	w = httptest.NewRecorder()
+	server.AdminMiddleware(server.handleAdminJobs)(w, adminRequest(http.MethodGet, "/admin/jobs"))
+	var jobs []dbgen.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &jobs); err != nil {
+		t.Fatalf("failed to decode jobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Errorf("got %d jobs, want 2", len(jobs))
+	}
+
+	// Deleting the second user removes their data and files only
+	req := adminRequest(http.MethodDelete, fmt.Sprintf("/admin/users/%d", other.ID))
+	req.SetPathValue("id", fmt.Sprint(other.ID))
+	w = httptest.NewRecorder()
+	server.AdminMiddleware(server.handleAdminDeleteUser)(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if _, err := os.Stat(contentPath); !os.IsNotExist(err) {
+		t.Errorf("content file still exists after delete: %v", err)
+	}
+	if _, err := server.Queries.GetJobByID(ctx, job.ID); err == nil {
+		t.Error("job still exists after deleting its user")
+	}
+	remaining, err := server.Queries.ListAllActiveJobs(ctx)
+	if err != nil || len(remaining) != 1 {
+		t.Errorf("remaining jobs = %d (err %v), want 1", len(remaining), err)
+	}
+
+	w = httptest.NewRecorder()
+	server.AdminMiddleware(server.handleAdminDeleteUser)(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminSetQuota(t *testing.T) {
+	t.Setenv("NEWS_APP_ADMIN_TOKEN", "admin-secret")
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, err := server.Queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "quota-user", Email: "quota@example.com"})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	setQuota := func(userID int64, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/admin/users/%d/quota", userID), strings.NewReader(body))
+		req.Header.Set("X-Admin-Token", "admin-secret")
+		req.SetPathValue("id", fmt.Sprint(userID))
+		w := httptest.NewRecorder()
+		server.AdminMiddleware(server.handleAdminSetQuota)(w, req)
+		return w
+	}
+	quota := func() int64 {
+		t.Helper()
+		prefs, err := server.Queries.GetPreferences(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetPreferences() error = %v", err)
+		}
+		return prefs.ShelleyQuotaDaily
+	}
+
+	// The user has no preferences yet, so they're created
+	if w := setQuota(user.ID, `{"daily_quota": 10}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := quota(); got != 10 {
+		t.Errorf("quota = %d, want 10", got)
+	}
+	if w := setQuota(user.ID, `{"daily_quota": 0}`); w.Code != http.StatusOK {
+		t.Fatalf("unlimited: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := quota(); got != 0 {
+		t.Errorf("quota = %d, want 0", got)
+	}
+
+	if w := setQuota(user.ID, `{"daily_quota": -1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("negative quota: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := setQuota(user.ID+100, `{"daily_quota": 5}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing user: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	server := newTestServer(t)
+	origSystemdDir := systemdDir
+	systemdDir = t.TempDir()
+	t.Cleanup(func() { systemdDir = origSystemdDir })
+	ctx := context.Background()
+
+	user, articles := createTestArticles(t, server, "Article 1", "Article 2")
+	jobID := articles[0].JobID
+	contentPath := filepath.Join(t.TempDir(), "article.txt")
+	if err := os.WriteFile(contentPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+	if _, err := server.DB.Exec("UPDATE articles SET content_path = ? WHERE id = ?", contentPath, articles[0].ID); err != nil {
+		t.Fatalf("failed to set content path: %v", err)
+	}
+	timerPath := filepath.Join(systemdDir, jobServiceName(jobID)+".timer")
+	if err := os.WriteFile(timerPath, []byte("[Timer]"), 0644); err != nil {
+		t.Fatalf("failed to write timer: %v", err)
+	}
+	if _, err := server.Queries.CreateJobRun(ctx, jobID); err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	if _, err := server.Queries.AddToReadingList(ctx, dbgen.AddToReadingListParams{UserID: user.ID, ArticleID: articles[1].ID, UserID_2: user.ID}); err != nil {
+		t.Fatalf("failed to add to reading list: %v", err)
+	}
+
+	// Without the confirmation phrase nothing is deleted
+	w := httptest.NewRecorder()
+	server.handleDeleteAccount(w, authedRequest(http.MethodDelete, "/api/user", strings.NewReader(`{"confirm":"yes"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unconfirmed status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if _, err := server.Queries.GetJobByID(ctx, jobID); err != nil {
+		t.Fatalf("job deleted without confirmation: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleDeleteAccount(w, authedRequest(http.MethodDelete, "/api/user", strings.NewReader(`{"confirm":"DELETE MY ACCOUNT"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	for _, table := range []string{"users", "jobs", "job_runs", "articles", "preferences", "reading_list"} {
+		var n int
+		if err := server.DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if n != 0 {
+			t.Errorf("%s has %d rows after account deletion, want 0", table, n)
+		}
+	}
+	for _, path := range []string{contentPath, timerPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after account deletion: %v", path, err)
+		}
+	}
+}
+
+func TestGetPreferencesMasksWebhook(t *testing.T) {
+	server := newTestServer(t)
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	webhook := "https://discord.com/api/webhooks/123456/secret-token-x9Qz"
+	err = server.Queries.UpdatePreferences(context.Background(), dbgen.UpdatePreferencesParams{
+		SystemPrompt:   "Be brief",
+		DiscordWebhook: webhook,
+		NotifySuccess:  1,
+		DigestDay:      "MON",
+		UserID:         user.ID,
+	})
+	if err != nil {
+		t.Fatalf("failed to update preferences: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.handleGetPreferences(w, authedRequest(http.MethodGet, "/api/preferences", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "secret-token") {
+		t.Errorf("response leaks the webhook URL: %s", w.Body.String())
+	}
+	var prefs dbgen.Preference
+	if err := json.NewDecoder(w.Body).Decode(&prefs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if prefs.DiscordWebhook != "…x9Qz" {
+		t.Errorf("discord_webhook = %q, want %q", prefs.DiscordWebhook, "…x9Qz")
+	}
+	if prefs.SystemPrompt != "Be brief" || prefs.NotifySuccess != 1 || prefs.DigestDay != "MON" {
+		t.Errorf("preferences = %+v, want the stored values", prefs)
+	}
+}
+
+func TestCostSummary(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	cfg := jobrunner.Config{ModelCostMap: map[string]float64{"cheap": 3, "pricey": 15}}
+	var jobIDs []int64
+	for _, name := range []string{"Job A", "Job B"} {
+		job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		jobIDs = append(jobIDs, job.ID)
+	}
+	runs := []struct {
+		jobID  int64
+		tokens int64
+		model  string
+	}{
+		{jobIDs[0], 100_000, "cheap"}, // $0.30
+		{jobIDs[0], 200_000, "cheap"}, // $0.60
+		{jobIDs[1], 50_000, "pricey"}, // $0.75
+	}
+	for _, tt := range runs {
+		run, err := server.Queries.CreateJobRun(ctx, tt.jobID)
+		if err != nil {
+			t.Fatalf("failed to create run: %v", err)
+		}
+		err = server.Queries.UpdateJobRunComplete(ctx, dbgen.UpdateJobRunCompleteParams{
+			ID:               run.ID,
+			Status:           "completed",
+			EstimatedTokens:  tt.tokens,
+			EstimatedCostUsd: float64(tt.tokens) * cfg.CostPerToken(tt.model),
+		})
+		if err != nil {
+			t.Fatalf("failed to complete run: %v", err)
+		}
+	}
+
+	// Runs from before the period are left out
+	old, _ := server.Queries.CreateJobRun(ctx, jobIDs[1])
+	if _, err := server.DB.Exec("UPDATE job_runs SET started_at = ?, estimated_cost_usd = 5 WHERE id = ?", time.Now().AddDate(0, 0, -40).UTC(), old.ID); err != nil {
+		t.Fatalf("failed to backdate run: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.handleCostSummary(w, authedRequest(http.MethodGet, "/api/user/cost-summary", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp CostSummaryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if math.Abs(resp.TotalEstimatedUSD-1.65) > 1e-9 || resp.Period != "30d" {
+		t.Errorf("total = %v over %q, want 1.65 over 30d", resp.TotalEstimatedUSD, resp.Period)
+	}
+	if len(resp.ByJob) != 2 || resp.ByJob[0].JobID != jobIDs[0] || math.Abs(resp.ByJob[0].Cost-0.9) > 1e-9 {
+		t.Errorf("by_job = %+v, want job A at 0.90 first", resp.ByJob)
+	}
+}
+
+func TestBulkToggleJobs(t *testing.T) {
+	server := newTestServer(t)
+	origSystemdDir := systemdDir
+	systemdDir = t.TempDir()
+	t.Cleanup(func() { systemdDir = origSystemdDir })
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	other, err := server.Queries.CreateUser(ctx, dbgen.CreateUserParams{ExeUserID: "other-user", Email: "other@example.com"})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var ids []int64
+	for _, owner := range []int64{user.ID, user.ID, user.ID, other.ID} {
+		job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: owner, Name: "Job", Prompt: "test", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+	foreignID := ids[3]
+
+	body, _ := json.Marshal(map[string]any{"ids": ids, "active": false})
+	w := httptest.NewRecorder()
+	server.handleBulkToggleJobs(w, authedRequest(http.MethodPost, "/api/jobs/bulk-toggle", strings.NewReader(string(body))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var result bulkJobResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Updated != 3 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v, want 3 updated and no errors", result)
+	}
+
+	for _, id := range ids[:3] {
+		job, err := server.Queries.GetJob(ctx, dbgen.GetJobParams{ID: id, UserID: user.ID})
+		if err != nil || job.IsActive != 0 {
+			t.Errorf("job %d is_active = %d (err %v), want 0", id, job.IsActive, err)
+		}
+	}
+	foreign, err := server.Queries.GetJob(ctx, dbgen.GetJobParams{ID: foreignID, UserID: other.ID})
+	if err != nil || foreign.IsActive != 1 {
+		t.Errorf("foreign job is_active = %d (err %v), want 1", foreign.IsActive, err)
+	}
+
+	// Bulk delete also skips the foreign job
+	body, _ = json.Marshal(map[string]any{"ids": ids})
+	w = httptest.NewRecorder()
+	server.handleBulkDeleteJobs(w, authedRequest(http.MethodPost, "/api/jobs/bulk-delete", strings.NewReader(string(body))))
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Deleted != 3 {
+		t.Errorf("deleted = %d, want 3", result.Deleted)
+	}
+	if _, err := server.Queries.GetJob(ctx, dbgen.GetJobParams{ID: foreignID, UserID: other.ID}); err != nil {
+		t.Errorf("foreign job was deleted: %v", err)
+	}
+}
+
+func TestFindContextInFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "article.txt")
+	before := strings.Repeat("a", 150)
+	after := strings.Repeat("b", 150)
+	content := "Title: Test\n\n" + before + " Generics landed " + after + "\nlast line mentions generics again\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+
+	got, found, err := findContextInFile(path, "generics")
+	if err != nil || !found {
+		t.Fatalf("findContextInFile() = %q, %v, %v; want a match", got, found, err)
+	}
+	// The window is centred on the first match and capped at 200 characters
+	want := strings.Repeat("a", 95) + " Generics landed " + strings.Repeat("b", 88)
+	if got != want {
+		t.Errorf("findContextInFile() = %q, want %q", got, want)
+	}
+
+	if _, found, err := findContextInFile(path, "missing"); found || err != nil {
+		t.Errorf("findContextInFile(missing) found = %v, err = %v; want no match", found, err)
+	}
+	if _, _, err := findContextInFile(filepath.Join(dir, "nope.txt"), "x"); err == nil {
+		t.Error("findContextInFile() on a missing file returned no error")
+	}
+}
+
+func TestSearchArticlesFullText(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "Go release notes", "Go tooling update")
+
+	dir := t.TempDir()
+	contents := []string{
+		"The new release adds iterator support to the standard library.",
+		"Nothing relevant in this one.",
+	}
+	for i, a := range articles {
+		path := filepath.Join(dir, fmt.Sprintf("%d.txt", a.ID))
+		if err := os.WriteFile(path, []byte(contents[i]), 0644); err != nil {
+			t.Fatalf("failed to write content file: %v", err)
+		}
+		if _, err := server.DB.Exec("UPDATE articles SET content_path = ? WHERE id = ?", path, a.ID); err != nil {
+			t.Fatalf("failed to set content path: %v", err)
+		}
+	}
+
+	search := func(target string) []articleSearchResult {
+		t.Helper()
+		w := httptest.NewRecorder()
+		server.handleSearchArticles(w, authedRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var results []articleSearchResult
+		if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+			t.Fatalf("failed to decode results: %v", err)
+		}
+		return results
+	}
+
+	// Without full_text no content files are read
+	for _, r := range search("/api/articles/search?q=Go") {
+		if r.MatchContext != "" {
+			t.Errorf("article %d has match context without full_text", r.ID)
+		}
+	}
+
+	results := search("/api/articles/search?q=Go&full_text=1")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	contexts := map[int64]string{}
+	for _, r := range results {
+		contexts[r.ID] = r.MatchContext
+	}
+	if got := contexts[articles[0].ID]; got != "" {
+		t.Errorf("article without keyword in content has context %q", got)
+	}
+
+	results = search("/api/articles/search?q=release&full_text=1")
+	if len(results) != 1 || results[0].MatchContext != contents[0] {
+		t.Errorf("results = %+v, want one match with context %q", results, contents[0])
+	}
+
+	w := httptest.NewRecorder()
+	server.handleSearchArticles(w, authedRequest(http.MethodGet, "/api/articles/search", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty query status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTermsToFTS5Query(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"rocket", "rocket*"},
+		{`rocket "launch window" NASA`, `rocket* "launch window" NASA*`},
+		{"c++ AND café", `"c++"* "AND"* café*`},
+		{`'say "hi" now'`, `"say ""hi"" now"`},
+		{`climate -politics -"carbon tax"`, `climate* NOT politics* NOT "carbon tax"`},
+	}
+	for _, tt := range tests {
+		if got := termsToFTS5Query(parseSearchTerms(tt.query)); got != tt.want {
+			t.Errorf("termsToFTS5Query(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	// The index matches prefixes and phrases, and follows title edits
+	server := newTestServer(t)
+	user, articles := createTestArticles(t, server, "Rocket launches delayed", "Launch window opens", "Gardening tips")
+	if _, err := server.DB.Exec("UPDATE articles SET title = 'Tomato rocket salad' WHERE id = ?", articles[2].ID); err != nil {
+		t.Fatalf("failed to update title: %v", err)
+	}
+	for query, want := range map[string]int{"launch": 2, `"launch window"`: 1, "rocket": 2, "gardening": 0} {
+		f := articlesFilter{SearchQuery: query, ParsedTerms: parseSearchTerms(query), Limit: 10}
+		got, count := server.queryArticles(authedRequest(http.MethodGet, "/", nil), user.ID, f)
+		if len(got) != want || count != int64(want) {
+			t.Errorf("search %q = %d articles (count %d), want %d", query, len(got), count, want)
+		}
+	}
+}
+
+func TestParseSearchTerms(t *testing.T) {
+	got := parseSearchTerms(`climate -politics "sea level" -'carbon tax' - --x`)
+	want := SearchTerms{
+		Include: []string{"climate", "sea level", "-"},
+		Exclude: []string{"politics", "carbon tax", "-x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSearchTerms() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSearchExcludesTerms(t *testing.T) {
+	server := newTestServer(t)
+	user, _ := createTestArticles(t, server, "Climate summit opens", "Climate politics heat up", "Politics weekly", "Gardening 100% organic")
+
+	for query, want := range map[string][]string{
+		"climate -politics": {"Climate summit opens"},
+		"-politics":         {"Climate summit opens", "Gardening 100% organic"},
+		"-climate -100%":    {"Politics weekly"},
+	} {
+		f := articlesFilter{SearchQuery: query, ParsedTerms: parseSearchTerms(query), Limit: 10}
+		articles, count := server.queryArticles(authedRequest(http.MethodGet, "/", nil), user.ID, f)
+		var titles []string
+		for _, a := range articles {
+			titles = append(titles, a.Title)
+		}
+		slices.Sort(titles)
+		if !slices.Equal(titles, want) || count != int64(len(want)) {
+			t.Errorf("search %q = %q (count %d), want %q", query, titles, count, want)
+		}
+	}
+}
+
+func TestRunConversation(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Job", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	run, err := server.Queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	server.Queries.UpdateJobRunConversation(ctx, dbgen.UpdateJobRunConversationParams{ConversationID: "conv-1", ID: run.ID})
+
+	// Live conversation with three messages
+	available := true
+	shelley := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			http.Error(w, "archived", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"conversation": {"conversation_id": "conv-1"}, "messages": [
+			{"type": "user", "end_of_turn": true, "llm_data": {"Content": [{"Type": 2, "Text": "Find news"}]}},
+			{"type": "agent", "end_of_turn": false, "llm_data": {"Content": [{"Type": 2, "Text": "Searching"}]}},
+			{"type": "agent", "end_of_turn": true, "llm_data": "{\"Content\": [{\"Type\": 2, \"Text\": \"[]\"}]}"}
+		]}`)
+	}))
+	defer shelley.Close()
+	server.shelley = jobrunner.NewShelleyClient(shelley.URL)
+
+	get := func() (int, jobrunner.ConversationView) {
+		t.Helper()
+		target := fmt.Sprintf("/api/jobs/%d/runs/%d/conversation", job.ID, run.ID)
+		req := authedRequest(http.MethodGet, target, nil)
+		req.SetPathValue("id", fmt.Sprint(job.ID))
+		req.SetPathValue("run_id", fmt.Sprint(run.ID))
+		w := httptest.NewRecorder()
+		server.handleRunConversation(w, req)
+		var view jobrunner.ConversationView
+		json.Unmarshal(w.Body.Bytes(), &view)
+		return w.Code, view
+	}
+
+	code, view := get()
+	if code != http.StatusOK || len(view.Messages) != 3 {
+		t.Fatalf("live: status = %d, %d messages; want 200 with 3", code, len(view.Messages))
+	}
+	if view.Messages[2].Text != "[]" || !view.Messages[2].EndOfTurn {
+		t.Errorf("last message = %+v, want end-of-turn agent text", view.Messages[2])
+	}
+
+	// Archived conversation without a snapshot
+	available = false
+	if code, _ := get(); code != http.StatusNotFound {
+		t.Errorf("no snapshot: status = %d, want %d", code, http.StatusNotFound)
+	}
+
+	// Falls back to the stored snapshot
+	snapshot := `{"conversation_id": "conv-1", "messages": [{"type": "agent", "end_of_turn": true, "text": "saved"}]}`
+	server.Queries.UpdateJobRunSnapshot(ctx, dbgen.UpdateJobRunSnapshotParams{ConversationSnapshot: snapshot, ID: run.ID})
+	code, view = get()
+	if code != http.StatusOK || len(view.Messages) != 1 || view.Messages[0].Text != "saved" {
+		t.Errorf("snapshot: status = %d, view = %+v; want the snapshot", code, view)
+	}
+
+	// A malformed run ID gets a JSON error like the rest of the API
+	req := authedRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/runs/abc/conversation", job.ID), nil)
+	req.SetPathValue("id", fmt.Sprint(job.ID))
+	req.SetPathValue("run_id", "abc")
+	w := httptest.NewRecorder()
+	server.handleRunConversation(w, req)
+	if w.Code != http.StatusBadRequest || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("bad run ID: status = %d, Content-Type = %q, want a 400 JSON error", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestGetRun(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Space News", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	run, err := server.Queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	server.Queries.UpdateJobRunLogPath(ctx, dbgen.UpdateJobRunLogPathParams{LogPath: "/var/log/news-app/run_7.log", ID: run.ID})
+	server.Queries.UpdateJobRunConversation(ctx, dbgen.UpdateJobRunConversationParams{ConversationID: "conv-1", ID: run.ID})
+	saved, dups := int64(3), int64(1)
+	server.Queries.CompleteJobRun(ctx, dbgen.CompleteJobRunParams{Status: "completed", ArticlesSaved: &saved, DuplicatesSkipped: &dups, ID: run.ID})
+
+	req := authedRequest(http.MethodGet, fmt.Sprintf("/api/runs/%d", run.ID), nil)
+	req.SetPathValue("id", fmt.Sprint(run.ID))
+	w := httptest.NewRecorder()
+	server.handleGetRun(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, key := range []string{"id", "job_id", "job_name", "status", "started_at", "completed_at", "articles_saved", "duplicates_skipped", "error_message", "log_path", "conversation_id"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("response has no %q field", key)
+		}
+	}
+	if got["job_name"] != "Space News" || got["status"] != "completed" || got["articles_saved"] != float64(3) {
+		t.Errorf("job_name, status, articles_saved = %v, %v, %v", got["job_name"], got["status"], got["articles_saved"])
+	}
+	if got["log_path"] != "run_7.log" {
+		t.Errorf("log_path = %v, want only the file name", got["log_path"])
+	}
+
+	// Another user's run is not found
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/runs/%d", run.ID), nil)
+	req.Header.Set("X-ExeDev-UserID", "someone-else")
+	req.Header.Set("X-ExeDev-Email", "else@example.com")
+	req.SetPathValue("id", fmt.Sprint(run.ID))
+	w = httptest.NewRecorder()
+	server.handleGetRun(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other user: status = %d, want 404", w.Code)
+	}
+
+	// The job's run history is paginated
+	req = authedRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/runs", job.ID), nil)
+	req.SetPathValue("id", fmt.Sprint(job.ID))
+	w = httptest.NewRecorder()
+	server.handleJobRuns(w, req)
+	var runs JobRunsResponse
+	json.Unmarshal(w.Body.Bytes(), &runs)
+	if w.Code != http.StatusOK || runs.Total != 1 || len(runs.Runs) != 1 || runs.Runs[0].ID != run.ID {
+		t.Errorf("job runs: status = %d, response = %+v; want the one run", w.Code, runs)
+	}
+	if w.Header().Get("Link") == "" {
+		t.Error("job runs: missing Link pagination header")
+	}
+}
+
+func TestLatestRun(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Space News", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodGet, target, nil)
+		req.SetPathValue("id", fmt.Sprint(job.ID))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	latestURL := fmt.Sprintf("/api/jobs/%d/runs/latest", job.ID)
+	if w := get(server.handleLatestRun, latestURL); w.Code != http.StatusNotFound {
+		t.Errorf("no runs: status = %d, want 404", w.Code)
+	}
+
+	// The run created second started earlier, so started_at decides
+	latest, err := server.Queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	older, err := server.Queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	if _, err := server.DB.Exec("UPDATE job_runs SET started_at = '2020-01-01 00:00:00' WHERE id = ?", older.ID); err != nil {
+		t.Fatalf("failed to backdate run: %v", err)
+	}
+	logPath := filepath.Join(t.TempDir(), "run.log")
+	if err := os.WriteFile(logPath, []byte("fetched 3 articles\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server.Queries.UpdateJobRunLogPath(ctx, dbgen.UpdateJobRunLogPathParams{LogPath: logPath, ID: latest.ID})
+
+	run, err := server.Queries.GetLatestJobRun(ctx, dbgen.GetLatestJobRunParams{JobID: job.ID, UserID: user.ID})
+	if err != nil || run.ID != latest.ID {
+		t.Fatalf("GetLatestJobRun() = run %d, %v, want run %d", run.ID, err, latest.ID)
+	}
+
+	w := get(server.handleLatestRun, latestURL)
+	var detail RunDetail
+	json.Unmarshal(w.Body.Bytes(), &detail)
+	if w.Code != http.StatusOK || detail.ID != latest.ID || detail.JobName != "Space News" || detail.LogPath != "run.log" {
+		t.Errorf("latest run: status = %d, response = %+v; want run %d", w.Code, detail, latest.ID)
+	}
+
+	w = get(server.handleLatestRunLog, latestURL+"/log")
+	if w.Code != http.StatusOK || w.Body.String() != "fetched 3 articles\n" {
+		t.Errorf("latest run log: status = %d, body = %q", w.Code, w.Body.String())
+	}
+	if cd, want := w.Header().Get("Content-Disposition"), fmt.Sprintf(`attachment; filename="run_%d.log"`, latest.ID); cd != want {
+		t.Errorf("latest run log Content-Disposition = %q, want %q", cd, want)
+	}
+}
+
+// writeTestTemplates writes a minimal layout plus every page template, each
+// rendering body.
+func writeTestTemplates(t *testing.T, dir, body string) {
+	t.Helper()
+	files := map[string]string{"layout.html": `{{define "layout"}}{{template "content" .}}{{end}}`}
+	for _, name := range templateFiles {
+		files[name] = `{{define "content"}}` + body + `{{end}}`
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write template %s: %v", name, err)
+		}
+	}
+}
+
+func TestTemplateHotReload(t *testing.T) {
+	for _, hotReload := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hot_reload=%v", hotReload), func(t *testing.T) {
+			server := newTestServer(t)
+			server.hotReload = hotReload
+			server.TemplatesDir = t.TempDir()
+			writeTestTemplates(t, server.TemplatesDir, "v1")
+			if err := server.loadTemplates(); err != nil {
+				t.Fatalf("loadTemplates() error = %v", err)
+			}
+
+			writeTestTemplates(t, server.TemplatesDir, "v2")
+			rr := httptest.NewRecorder()
+			server.renderTemplate(rr, "dashboard.html", nil)
+
+			want := "v1"
+			if hotReload {
+				want = "v2"
+			}
+			if got := rr.Body.String(); got != want {
+				t.Errorf("rendered %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWatchTemplates(t *testing.T) {
+	old := templatePollInterval
+	templatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { templatePollInterval = old })
+
+	server := newTestServer(t)
+	server.TemplatesDir = t.TempDir()
+	writeTestTemplates(t, server.TemplatesDir, "v1")
+	if err := server.loadTemplates(); err != nil {
+		t.Fatalf("loadTemplates() error = %v", err)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	stop := watchTemplates(server.TemplatesDir, func() error {
+		err := server.loadTemplates()
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+		return err
+	})
+	defer stop()
+
+	// Push the modification time forward so the change is seen even on
+	// filesystems with coarse timestamps.
+	writeTestTemplates(t, server.TemplatesDir, "v2")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(server.TemplatesDir, "dashboard.html"), future, future)
+
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not reload templates after a change")
+	}
+
+	rr := httptest.NewRecorder()
+	server.renderTemplate(rr, "dashboard.html", nil)
+	if got := rr.Body.String(); got != "v2" {
+		t.Errorf("rendered %q after reload, want %q", got, "v2")
+	}
+}
+
+func TestCollectionDeleteMovesArticlesToParent(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, articles := createTestArticles(t, server, "First", "Second", "Third")
+
+	create := func(name string, parentID *int64) dbgen.Collection {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"name": name, "parent_id": parentID})
+		w := httptest.NewRecorder()
+		server.handleCreateCollection(w, authedRequest(http.MethodPost, "/api/collections", strings.NewReader(string(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("create %q: status = %d: %s", name, w.Code, w.Body.String())
+		}
+		var c dbgen.Collection
+		json.Unmarshal(w.Body.Bytes(), &c)
+		return c
+	}
+	parent := create("Science", nil)
+	childA := create("Physics", &parent.ID)
+	childB := create("Biology", &parent.ID)
+
+	// Nesting is limited to one level
+	body, _ := json.Marshal(map[string]any{"name": "Quantum", "parent_id": childA.ID})
+	w := httptest.NewRecorder()
+	server.handleCreateCollection(w, authedRequest(http.MethodPost, "/api/collections", strings.NewReader(string(body))))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("nested create: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	for _, add := range []struct {
+		collection int64
+		article    int64
+	}{
+		{parent.ID, articles[0].ID},
+		{childA.ID, articles[0].ID},
+		{childA.ID, articles[1].ID},
+		{childB.ID, articles[2].ID},
+	} {
+		if _, err := server.Queries.AddArticleToCollection(ctx, dbgen.AddArticleToCollectionParams{CollectionID: add.collection, ArticleID: add.article}); err != nil {
+			t.Fatalf("AddArticleToCollection() error = %v", err)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	server.handleListCollections(w, authedRequest(http.MethodGet, "/api/collections", nil))
+	var tree []collectionTree
+	if err := json.Unmarshal(w.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("decode tree: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ID != parent.ID || len(tree[0].Children) != 2 {
+		t.Fatalf("tree = %+v, want one parent with two children", tree)
+	}
+
+	req := authedRequest(http.MethodDelete, fmt.Sprintf("/api/collections/%d", childA.ID), nil)
+	req.SetPathValue("id", fmt.Sprint(childA.ID))
+	w = httptest.NewRecorder()
+	server.handleDeleteCollection(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	got, err := server.Queries.ListArticlesByCollection(ctx, dbgen.ListArticlesByCollectionParams{
+		UserID: user.ID, CollectionID: parent.ID, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("ListArticlesByCollection() error = %v", err)
+	}
+	ids := map[int64]bool{}
+	for _, a := range got {
+		ids[a.ID] = true
+	}
+	if len(got) != 2 || !ids[articles[0].ID] || !ids[articles[1].ID] {
+		t.Errorf("parent articles = %v, want the deleted child's articles merged in", ids)
+	}
+
+	// The other child is untouched
+	count, _ := server.Queries.CountArticlesByCollection(ctx, dbgen.CountArticlesByCollectionParams{UserID: user.ID, CollectionID: childB.ID})
+	if count != 1 {
+		t.Errorf("sibling article count = %d, want 1", count)
+	}
+
+	// The articles list page filters by collection
+	w = httptest.NewRecorder()
+	server.handleArticlesList(w, authedRequest(http.MethodGet, fmt.Sprintf("/articles?collection=%d", parent.ID), nil))
+	if w.Header().Get("X-Total-Count") != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", w.Header().Get("X-Total-Count"))
+	}
+
+	// A malformed article ID gets a JSON error like the rest of the API
+	req = authedRequest(http.MethodDelete, fmt.Sprintf("/api/collections/%d/articles/abc", parent.ID), nil)
+	req.SetPathValue("id", fmt.Sprint(parent.ID))
+	req.SetPathValue("article_id", "abc")
+	w = httptest.NewRecorder()
+	server.handleRemoveArticleFromCollection(w, req)
+	if w.Code != http.StatusBadRequest || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("remove with bad article ID: status = %d, Content-Type = %q, want a 400 JSON error", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestJSONErrorResponse(t *testing.T) {
+	server := newTestServer(t)
+	handler := requestIDMiddleware(http.HandlerFunc(server.handleJobStats))
+
+	req := authedRequest(http.MethodGet, "/api/jobs/99999/stats", nil)
+	req.SetPathValue("id", "99999")
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	var resp ErrResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != "JOB_NOT_FOUND" {
+		t.Errorf("code = %q, want %q", resp.Code, "JOB_NOT_FOUND")
+	}
+	if resp.Error != "Job not found" {
+		t.Errorf("error = %q, want %q", resp.Error, "Job not found")
+	}
+	if resp.RequestID != "req-123" || w.Header().Get("X-Request-ID") != "req-123" {
+		t.Errorf("request_id = %q, header = %q, want %q", resp.RequestID, w.Header().Get("X-Request-ID"), "req-123")
+	}
+
+	// Malformed IDs are replaced rather than echoed
+	req = authedRequest(http.MethodGet, "/api/jobs/99999/stats", nil)
+	req.SetPathValue("id", "99999")
+	req.Header.Set("X-Request-ID", "bad id\n")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if id := w.Header().Get("X-Request-ID"); id == "" || id == "bad id\n" {
+		t.Errorf("generated request ID = %q", id)
+	}
+}
+
+func TestJobArticlesAPI(t *testing.T) {
+	server := newTestServer(t)
+	_, first := createTestArticles(t, server, "Go release notes", "Rust news", "Go tooling")
+	_, second := createTestArticles(t, server, "Go conference")
+	jobID := first[0].JobID
+
+	req := authedRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/articles?q=Go", jobID), nil)
+	req.SetPathValue("id", strconv.FormatInt(jobID, 10))
+	w := httptest.NewRecorder()
+	server.handleJobArticles(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp JobArticlesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Articles) != 2 || resp.Page != 1 || resp.Pages != 1 {
+		t.Errorf("got total=%d articles=%d page=%d pages=%d, want 2, 2, 1, 1", resp.Total, len(resp.Articles), resp.Page, resp.Pages)
+	}
+	for _, a := range resp.Articles {
+		if a.JobID != jobID {
+			t.Errorf("article %q belongs to job %d, want %d", a.Title, a.JobID, jobID)
+		}
+	}
+	if w.Header().Get("Link") == "" {
+		t.Error("missing Link pagination header")
+	}
+
+	// Another user's job is not found
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/articles", jobID), nil)
+	req.Header.Set("X-ExeDev-UserID", "other-user")
+	req.Header.Set("X-ExeDev-Email", "other@example.com")
+	req.SetPathValue("id", strconv.FormatInt(jobID, 10))
+	w = httptest.NewRecorder()
+	server.handleJobArticles(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other user's job: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleListJobs(w, authedRequest(http.MethodGet, "/api/jobs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list jobs status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var jobs []dbgen.ListJobsWithArticleCountsRow
+	if err := json.NewDecoder(w.Body).Decode(&jobs); err != nil {
+		t.Fatalf("decode jobs: %v", err)
+	}
+	counts := map[int64]int64{}
+	for _, j := range jobs {
+		counts[j.ID] = j.ArticleCount
+	}
+	if len(jobs) != 2 || counts[jobID] != 3 || counts[second[0].JobID] != 1 {
+		t.Errorf("job article counts = %v, want %d:3 and %d:1", counts, jobID, second[0].JobID)
+	}
+}
+
+func TestListJobsSorted(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	now := time.Now().UTC()
+	soon, later := now.Add(time.Hour), now.Add(2*time.Hour)
+	jobs := []struct {
+		name    string
+		nextRun *time.Time
+	}{
+		{"banana", &later},
+		{"Apple", nil},
+		{"cherry", &soon},
+	}
+	for _, j := range jobs {
+		if _, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: j.name, Prompt: "p", Frequency: "daily", NextRunAt: j.nextRun}); err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"?sort=name&order=asc", "[Apple banana cherry]"},
+		{"?sort=name&order=desc", "[cherry banana Apple]"},
+		{"", "[cherry banana Apple]"}, // next_run ascending, unscheduled last
+		{"?sort=next_run&order=desc", "[banana cherry Apple]"},
+		{"?sort=bogus", "[cherry banana Apple]"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		server.handleListJobs(w, authedRequest(http.MethodGet, "/api/jobs"+tt.query, nil))
+		var rows []dbgen.ListJobsWithArticleCountsRow
+		if err := json.NewDecoder(w.Body).Decode(&rows); err != nil {
+			t.Fatalf("%s: decode: %v", tt.query, err)
+		}
+		var names []string
+		for _, row := range rows {
+			names = append(names, row.Name)
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			t.Errorf("GET /api/jobs%s = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestJobPromptPreview(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{
+		UserID:    user.ID,
+		Name:      "Space News",
+		Prompt:    "Find news about rocket launches",
+		Keywords:  "SpaceX, NASA",
+		Sources:   "spacenews.com",
+		Frequency: "daily",
+	})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	id := strconv.FormatInt(job.ID, 10)
+
+	req := authedRequest(http.MethodGet, "/api/jobs/"+id+"/prompt-preview", nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	server.handleJobPromptPreview(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var preview PromptPreviewResponse
+	if err := json.NewDecoder(w.Body).Decode(&preview); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, want := range []string{job.Prompt, job.Keywords, job.Sources} {
+		if !strings.Contains(preview.Prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, preview.Prompt)
+		}
+	}
+	if want := int(float64(len(strings.Fields(preview.Prompt))) * 1.3); preview.EstimatedTokens != want {
+		t.Errorf("estimated_tokens = %d, want %d", preview.EstimatedTokens, want)
+	}
+
+	req = authedRequest(http.MethodGet, "/jobs/"+id+"/preview", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	server.handleJobPreview(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("page status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "USER REQUEST: Find news about rocket launches") {
+		t.Error("preview page does not show the prompt")
+	}
+}
+
+func TestArticlesExactTimeRange(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "Early", "Morning", "Noon", "Evening")
+	jobID := articles[0].JobID
+	for i, ts := range []string{"2026-03-01 06:00:00", "2026-03-01 09:30:00", "2026-03-01 12:00:00", "2026-03-01 20:15:00"} {
+		if _, err := server.DB.Exec("UPDATE articles SET retrieved_at = ? WHERE id = ?", ts, articles[i].ID); err != nil {
+			t.Fatalf("set retrieved_at: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"from_time=2026-03-01T09:30:00Z&to_time=2026-03-01T12:00:00Z", []string{"Noon", "Morning"}},
+		{"from_time=2026-03-01T10:00:00%2B01:00", []string{"Evening", "Noon", "Morning"}},
+		{"to_time=2026-03-01T09:29:59Z", []string{"Early"}},
+		// from_time is more precise than from, so it wins
+		{"from=2026-03-01&from_time=2026-03-01T12:00:01Z", []string{"Evening"}},
+		{"from=2026-03-01&to=2026-03-01", []string{"Evening", "Noon", "Morning", "Early"}},
+	}
+	for _, tt := range tests {
+		req := authedRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/articles?%s", jobID, tt.query), nil)
+		req.SetPathValue("id", strconv.FormatInt(jobID, 10))
+		w := httptest.NewRecorder()
+		server.handleJobArticles(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", tt.query, w.Code, http.StatusOK)
+		}
+		var resp JobArticlesResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: decode: %v", tt.query, err)
+		}
+		var got []string
+		for _, a := range resp.Articles {
+			got = append(got, a.Title)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestArticleArchiveRestore(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "Kept", "Archived", "Purged")
+	jobID := articles[0].JobID
+	contentPath := filepath.Join(t.TempDir(), "article.txt")
+	if err := os.WriteFile(contentPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write content file: %v", err)
+	}
+	if _, err := server.DB.Exec("UPDATE articles SET content_path = ? WHERE id = ?", contentPath, articles[2].ID); err != nil {
+		t.Fatalf("failed to set content path: %v", err)
+	}
+
+	listTitles := func() []string {
+		t.Helper()
+		req := authedRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d/articles", jobID), nil)
+		req.SetPathValue("id", strconv.FormatInt(jobID, 10))
+		w := httptest.NewRecorder()
+		server.handleJobArticles(w, req)
+		var resp JobArticlesResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var titles []string
+		for _, a := range resp.Articles {
+			titles = append(titles, a.Title)
+		}
+		sort.Strings(titles)
+		return titles
+	}
+
+	body := fmt.Sprintf(`{"ids": [%d, %d]}`, articles[1].ID, articles[2].ID)
+	w := httptest.NewRecorder()
+	server.handleDeleteArticles(w, authedRequest(http.MethodPost, "/api/articles/delete", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := listTitles(); fmt.Sprint(got) != "[Kept]" {
+		t.Errorf("articles after delete = %v, want [Kept]", got)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleListArchivedArticles(w, authedRequest(http.MethodGet, "/api/articles/archive", nil))
+	var archived ArchivedArticlesResponse
+	if err := json.NewDecoder(w.Body).Decode(&archived); err != nil {
+		t.Fatalf("decode archive: %v", err)
+	}
+	if archived.Total != 2 || len(archived.Articles) != 2 || archived.Articles[0].ArchivedAt == nil {
+		t.Errorf("archive = %+v, want 2 archived articles", archived)
+	}
+
+	req := authedRequest(http.MethodPost, fmt.Sprintf("/api/articles/%d/restore", articles[1].ID), nil)
+	req.SetPathValue("id", strconv.FormatInt(articles[1].ID, 10))
+	w = httptest.NewRecorder()
+	server.handleRestoreArticle(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := listTitles(); fmt.Sprint(got) != "[Archived Kept]" {
+		t.Errorf("articles after restore = %v, want [Archived Kept]", got)
+	}
+
+	// Restoring an article that isn't archived is not found
+	w = httptest.NewRecorder()
+	server.handleRestoreArticle(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second restore status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// Purging only removes articles archived longer than ArchiveRetention
+	w = httptest.NewRecorder()
+	server.handlePurgeArticles(w, authedRequest(http.MethodPost, "/api/articles/purge", nil))
+	if !strings.Contains(w.Body.String(), `"purged":0`) {
+		t.Errorf("purge of recent archive = %s, want 0 purged", w.Body.String())
+	}
+	if _, err := server.DB.Exec("UPDATE articles SET archived_at = datetime('now', '-31 days') WHERE id = ?", articles[2].ID); err != nil {
+		t.Fatalf("failed to backdate archived_at: %v", err)
+	}
+	w = httptest.NewRecorder()
+	server.handlePurgeArticles(w, authedRequest(http.MethodPost, "/api/articles/purge", nil))
+	if !strings.Contains(w.Body.String(), `"purged":1`) {
+		t.Errorf("purge = %s, want 1 purged", w.Body.String())
+	}
+	var n int
+	server.DB.QueryRow("SELECT COUNT(*) FROM articles").Scan(&n)
+	if n != 2 {
+		t.Errorf("%d articles left after purge, want 2", n)
+	}
+	if _, err := os.Stat(contentPath); !os.IsNotExist(err) {
+		t.Errorf("content file still exists after purge: %v", err)
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	server := newTestServer(t)
+	handler := requestBodyLimitMiddleware(1024, http.HandlerFunc(server.handleCreateJob))
+
+	big := `{"name": "` + strings.Repeat("x", 2<<20) + `", "prompt": "test", "frequency": "daily"}`
+	tests := []struct {
+		name          string
+		contentLength int64
+	}{
+		{"declared length", int64(len(big))},
+		{"chunked", -1}, // caught while the handler decodes
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(big))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+			}
+			var resp ErrResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Code != ErrCodeRequestTooLarge {
+				t.Errorf("body = %+v (err %v), want code %s", resp, err, ErrCodeRequestTooLarge)
+			}
+		})
+	}
+
+	// Small bodies reach the handler, whose own errors are left alone
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"name": "Small"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("small invalid body status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	config := DefaultSecurityConfig()
+	handler := securityHeadersMiddleware(config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		path string
+		csp  string
+	}{
+		{"/api/jobs", config.APIContentSecurityPolicy},
+		{"/health", config.APIContentSecurityPolicy},
+		{"/jobs", config.HTMLContentSecurityPolicy},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		h := w.Header()
+		if got := h.Get("Content-Security-Policy"); got != tt.csp {
+			t.Errorf("%s: Content-Security-Policy = %q, want %q", tt.path, got, tt.csp)
+		}
+		if got := h.Get("Content-Security-Policy"); !strings.Contains(got, "object-src 'none'") || !strings.Contains(got, "frame-ancestors 'none'") {
+			t.Errorf("%s: Content-Security-Policy = %q allows plugins or framing", tt.path, got)
+		}
+		for key, want := range map[string]string{
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "DENY",
+			"Referrer-Policy":           "strict-origin-when-cross-origin",
+			"Strict-Transport-Security": "max-age=31536000",
+		} {
+			if got := h.Get(key); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.path, key, got, want)
+			}
+		}
+	}
+	if strings.Contains(config.APIContentSecurityPolicy, "script-src") {
+		t.Errorf("API policy %q allows scripts", config.APIContentSecurityPolicy)
+	}
+
+	// Empty fields leave their headers unset
+	handler = securityHeadersMiddleware(SecurityConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Header().Get("Strict-Transport-Security") != "" || w.Header().Get("Content-Security-Policy") != "" {
+		t.Errorf("empty config set headers %v", w.Header())
+	}
+}
+
+func TestCSRFTokenRotation(t *testing.T) {
+	server := newTestServer(t)
+	now := time.Now()
+	server.csrfTokens.now = func() time.Time { return now }
+	status := http.StatusOK
+	handler := server.csrfProtect(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			server.jsonError(w, r, "failed", status)
+			return
+		}
+		server.jsonStatus(w, "ok")
+	})
+	post := func(token string) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPost, "/api/preferences", nil)
+		req.Header.Set(csrfHeaderName, token)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	token := server.csrfTokens.GetOrCreateToken("test-user-123")
+
+	// A failed request leaves the token as it was
+	status = http.StatusInternalServerError
+	if w := post(token); w.Header().Get(csrfNewTokenHeaderName) != "" {
+		t.Errorf("failed request: rotated the token")
+	}
+	status = http.StatusOK
+
+	w := post(token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	newToken := w.Header().Get(csrfNewTokenHeaderName)
+	if newToken == "" || newToken == token {
+		t.Fatalf("X-New-CSRF-Token = %q, want a fresh token", newToken)
+	}
+
+	// A request sent with the old token, e.g. from another tab, still goes
+	// through for a while and catches up with the new token
+	w = post(token)
+	if w.Code != http.StatusOK {
+		t.Errorf("old token within the grace window: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get(csrfNewTokenHeaderName); got != newToken {
+		t.Errorf("old token within the grace window: X-New-CSRF-Token = %q, want the current token %q", got, newToken)
+	}
+
+	now = now.Add(csrfRotationGrace + time.Second)
+	if w := post(token); w.Code != http.StatusForbidden {
+		t.Errorf("old token after the grace window: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w := post(newToken); w.Code != http.StatusOK {
+		t.Errorf("rotated token: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestCSRFTokenTTL(t *testing.T) {
+	store := NewCSRFStore(time.Millisecond)
+	token := store.GetOrCreateToken("user")
+	time.Sleep(5 * time.Millisecond)
+	if store.ValidateToken("user", token) {
+		t.Error("expired token was accepted")
+	}
+
+	if got := NewCSRFStore(0).ttl; got != defaultCSRFTokenTTL {
+		t.Errorf("zero TTL: ttl = %v, want %v", got, defaultCSRFTokenTTL)
+	}
+}
+
+func TestSystemdVerifier(t *testing.T) {
+	server := newTestServer(t)
+	origSystemdDir := systemdDir
+	systemdDir = t.TempDir()
+	t.Cleanup(func() { systemdDir = origSystemdDir })
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	var jobs []dbgen.Job
+	for _, name := range []string{"Complete", "No service", "Disabled timer"} {
+		job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	write := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(systemdDir, name), []byte("[Unit]"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	for _, job := range jobs {
+		write(jobServiceName(job.ID) + ".timer")
+		if job.ID != jobs[1].ID {
+			write(jobServiceName(job.ID) + ".service")
+		}
+	}
+	write("news-job-99999.service")
+	write("unrelated.service")
+
+	verifier := NewSystemdVerifier(server.Queries)
+	verifier.isEnabled = func(unit string) bool { return unit != jobServiceName(jobs[2].ID)+".timer" }
+	report, err := verifier.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if report.OK() {
+		t.Fatal("report.OK() = true, want inconsistencies")
+	}
+	if fmt.Sprint(report.MissingServices) != fmt.Sprint([]int64{jobs[1].ID}) {
+		t.Errorf("MissingServices = %v, want [%d]", report.MissingServices, jobs[1].ID)
+	}
+	if len(report.MissingTimers) != 0 {
+		t.Errorf("MissingTimers = %v, want none", report.MissingTimers)
+	}
+	if fmt.Sprint(report.DisabledTimers) != fmt.Sprint([]int64{jobs[2].ID}) {
+		t.Errorf("DisabledTimers = %v, want [%d]", report.DisabledTimers, jobs[2].ID)
+	}
+	if fmt.Sprint(report.OrphanedFiles) != "[news-job-99999.service]" {
+		t.Errorf("OrphanedFiles = %v, want [news-job-99999.service]", report.OrphanedFiles)
+	}
+}
+
+func TestRunJobServerBusy(t *testing.T) {
+	server := newTestServer(t)
+	var started atomic.Int32
+	server.runJob = func(jobID int64, maxParallel int) { started.Add(1) }
+	ctx := context.Background()
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Job", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	// Fill every global run slot with runs of another job, as job processes
+	// started elsewhere would
+	server.runConfig.MaxConcurrentRuns = 2
+	other, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Other", Prompt: "test", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	var running []dbgen.JobRun
+	for range server.runConfig.MaxConcurrentRuns {
+		run, err := server.Queries.CreateJobRun(ctx, other.ID)
+		if err != nil {
+			t.Fatalf("failed to create run: %v", err)
+		}
+		running = append(running, run)
+	}
+
+	// max_parallel starts the run directly rather than through systemd
+	runJob := func() *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPost, fmt.Sprintf("/api/jobs/%d/run", job.ID), strings.NewReader(`{"max_parallel": 1}`))
+		req.SetPathValue("id", fmt.Sprint(job.ID))
+		w := httptest.NewRecorder()
+		server.handleRunJob(w, req)
+		return w
+	}
+
+	w := runJob()
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("full: status = %d, want 503", w.Code)
+	}
+	var resp ErrResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Code != ErrCodeServerBusy {
+		t.Errorf("full: code = %q, want %q", resp.Code, ErrCodeServerBusy)
+	}
+
+	if err := server.Queries.CancelJobRun(ctx, running[0].ID); err != nil {
+		t.Fatalf("failed to cancel run: %v", err)
+	}
+	if w := runJob(); w.Code != http.StatusOK {
+		t.Errorf("after release: status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	// runJob is started in a goroutine
+	for i := 0; i < 100 && started.Load() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := started.Load(); n != 1 {
+		t.Errorf("job processes started = %d, want 1", n)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Job", Prompt: "p", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	server.Queries.CreatePreferences(ctx, user.ID)
+	server.Queries.UpdateShelleyQuota(ctx, dbgen.UpdateShelleyQuotaParams{ShelleyQuotaDaily: 5, UserID: user.ID})
+
+	// Only runs that started a conversation today count
+	for _, convID := range []string{"conv-1", ""} {
+		run, _ := server.Queries.CreateJobRun(ctx, job.ID)
+		server.Queries.UpdateJobRunConversation(ctx, dbgen.UpdateJobRunConversationParams{ConversationID: convID, ID: run.ID})
+	}
+	old, _ := server.Queries.CreateJobRun(ctx, job.ID)
+	server.Queries.UpdateJobRunConversation(ctx, dbgen.UpdateJobRunConversationParams{ConversationID: "conv-0", ID: old.ID})
+	if _, err := server.DB.Exec("UPDATE job_runs SET started_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -2).UTC(), old.ID); err != nil {
+		t.Fatalf("failed to backdate run: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.handleUsage(w, authedRequest(http.MethodGet, "/api/user/usage", nil))
+	var resp UsageResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.ShelleyCallsToday != 1 || resp.DailyQuota != 5 {
+		t.Errorf("status = %d, response = %+v, want 1 call of a 5 quota", w.Code, resp)
+	}
+}
+
+func TestArticleCountByDate(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	var jobIDs []int64
+	for _, name := range []string{"Job A", "Job B"} {
+		job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	// Two articles from Job A three days ago, one from Job B yesterday, and
+	// one from Job A too long ago to count
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	articles := []struct {
+		jobID   int64
+		daysAgo int
+	}{{jobIDs[0], 3}, {jobIDs[0], 3}, {jobIDs[1], 1}, {jobIDs[0], 10}}
+	for i, a := range articles {
+		_, err := server.Queries.CreateArticle(ctx, dbgen.CreateArticleParams{
+			JobID: a.jobID, UserID: user.ID, Title: "t", Url: fmt.Sprintf("https://news.example/%d", i),
+			RetrievedAt: today.AddDate(0, 0, -a.daysAgo).Add(9 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to create article: %v", err)
+		}
+	}
+
+	get := func(query string) (int, []ArticleDateCount) {
+		w := httptest.NewRecorder()
+		server.handleArticleCountByDate(w, authedRequest(http.MethodGet, "/api/articles/count-by-date?"+query, nil))
+		var resp []ArticleDateCount
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	day := func(daysAgo int) string { return today.AddDate(0, 0, -daysAgo).Format("2006-01-02") }
+
+	code, resp := get("days=5")
+	want := fmt.Sprint([]ArticleDateCount{{day(4), 0}, {day(3), 2}, {day(2), 0}, {day(1), 1}, {day(0), 0}})
+	if code != http.StatusOK || fmt.Sprint(resp) != want {
+		t.Errorf("days=5: status = %d, response = %v, want %v", code, resp, want)
+	}
+
+	if _, resp := get(""); len(resp) != 30 || resp[29].Date != day(0) {
+		t.Errorf("default response has %d days, want 30 ending today", len(resp))
+	}
+
+	_, resp = get(fmt.Sprintf("days=5&job_id=%d", jobIDs[1]))
+	if resp[1].Count != 0 || resp[3].Count != 1 {
+		t.Errorf("job_id filter: response = %v, want only Job B's article", resp)
+	}
+
+	for _, query := range []string{"days=0", "days=366", "job_id=abc"} {
+		if code, _ := get(query); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, code)
+		}
+	}
+}
+
+func TestJobFetchHeadersRedacted(t *testing.T) {
+	server := newTestServer(t)
+
+	body := `{"name": "Paywalled", "prompt": "p", "frequency": "daily", "fetch_headers": "{\"Authorization\": \"Bearer secret\"}"}`
+	w := httptest.NewRecorder()
+	server.handleCreateJob(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: status = %d: %s", w.Code, w.Body.String())
+	}
+	var job dbgen.Job
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if job.FetchHeaders != `{"Authorization":"xxxxx"}` {
+		t.Errorf("fetch_headers = %q, want the value redacted", job.FetchHeaders)
+	}
+
+	// The stored headers keep the secret
+	stored, err := server.Queries.GetJobByID(context.Background(), job.ID)
+	if err != nil || !strings.Contains(stored.FetchHeaders, "Bearer secret") {
+		t.Errorf("stored fetch_headers = %q (err %v), want the secret kept", stored.FetchHeaders, err)
+	}
+}
+
+func TestJobPromptTemplate(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	body := `{"name": "Space", "prompt": "rockets", "frequency": "daily", "prompt_template": "Everything: {{.}}"}`
+	w := httptest.NewRecorder()
+	server.handleCreateJob(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("create with {{.}}: status = %d, want 400", w.Code)
+	}
+
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{
+		UserID:         user.ID,
+		Name:           "Space",
+		Prompt:         "rockets",
+		Keywords:       "SpaceX, NASA",
+		Frequency:      "daily",
+		PromptTemplate: "Search for {{.Prompt}} ({{.Keywords}})",
+	})
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	id := strconv.FormatInt(job.ID, 10)
+
+	req := authedRequest(http.MethodGet, "/api/jobs/"+id+"/prompt-preview", nil)
+	req.SetPathValue("id", id)
+	w = httptest.NewRecorder()
+	server.handleJobPromptPreview(w, req)
+	var preview PromptPreviewResponse
+	json.Unmarshal(w.Body.Bytes(), &preview)
+	if preview.Prompt != "Search for rockets (SpaceX, NASA)" || preview.TemplateError != "" {
+		t.Errorf("preview = %+v, want the rendered template", preview)
+	}
+}
+
+func TestJobScheduleJitter(t *testing.T) {
+	server := newTestServer(t)
+
+	create := func(body string) (int, dbgen.Job) {
+		w := httptest.NewRecorder()
+		server.handleCreateJob(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(body)))
+		var job dbgen.Job
+		json.Unmarshal(w.Body.Bytes(), &job)
+		return w.Code, job
+	}
+	if code, _ := create(`{"name": "Bad", "prompt": "p", "frequency": "daily", "schedule_jitter_secs": -1}`); code != http.StatusBadRequest {
+		t.Errorf("negative jitter: status = %d, want 400", code)
+	}
+	code, job := create(`{"name": "Urgent", "prompt": "p", "frequency": "hourly", "schedule_jitter_secs": 0}`)
+	if code != http.StatusOK || job.ScheduleJitterSecs == nil || *job.ScheduleJitterSecs != 0 {
+		t.Fatalf("create: status = %d, schedule_jitter_secs = %v, want 0", code, job.ScheduleJitterSecs)
+	}
+
+	if code, _ := create(`{"name": "Huge", "prompt": "p", "frequency": "hourly", "schedule_jitter_secs": 9223372036854775807}`); code != http.StatusBadRequest {
+		t.Errorf("jitter longer than the frequency: status = %d, want 400", code)
+	}
+
+	id := strconv.FormatInt(job.ID, 10)
+	update := func(body string) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPut, "/api/jobs/"+id, strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		server.handleUpdateJob(w, req)
+		return w
+	}
+	jitter := func() *int64 {
+		updated, err := server.Queries.GetJobByID(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		return updated.ScheduleJitterSecs
+	}
+
+	// Leaving it out of an update, as the edit page does, keeps it
+	if w := update(`{"name": "Urgent", "prompt": "p", "frequency": "hourly", "is_active": true}`); w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := jitter(); got == nil || *got != 0 {
+		t.Errorf("after omitting it schedule_jitter_secs = %v, want 0", got)
+	}
+	if w := update(`{"name": "Urgent", "prompt": "p", "frequency": "hourly", "is_active": true, "schedule_jitter_secs": 3601}`); w.Code != http.StatusBadRequest {
+		t.Errorf("jitter longer than the frequency: status = %d, want 400", w.Code)
+	}
+
+	// null goes back to the global delay
+	if w := update(`{"name": "Urgent", "prompt": "p", "frequency": "hourly", "is_active": true, "schedule_jitter_secs": null}`); w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := jitter(); got != nil {
+		t.Errorf("after null schedule_jitter_secs = %v, want nil", *got)
+	}
+}
+
+func TestUpdateJobFeedURL(t *testing.T) {
+	server := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	server.handleCreateJob(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"prompt": "p", "feed_url": "https://example.com/feed.xml"}`)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "name is required") {
+		t.Errorf("feed job without a name: status = %d, body = %s, want 400 asking for a name", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	server.handleCreateJob(w, authedRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"name": "Feed", "frequency": "daily", "feed_url": "https://example.com/feed.xml"}`)))
+	var job dbgen.Job
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	id := strconv.FormatInt(job.ID, 10)
+	update := func(body string) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPut, "/api/jobs/"+id, strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		server.handleUpdateJob(w, req)
+		return w
+	}
+	feedURL := func() string {
+		updated, err := server.Queries.GetJobByID(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		return updated.FeedUrl
+	}
+
+	// Omitting feed_url keeps the feed, so the job still needs no prompt
+	if w := update(`{"name": "Feed", "frequency": "daily", "is_active": true}`); w.Code != http.StatusOK {
+		t.Fatalf("update without feed_url: status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := feedURL(); got != "https://example.com/feed.xml" {
+		t.Errorf("feed_url after omitting it = %q, want it unchanged", got)
+	}
+	if w := update(`{"name": "Feed", "frequency": "daily", "feed_url": "ftp://example.com/feed"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid feed_url: status = %d, want 400", w.Code)
+	}
+	if w := update(`{"name": "Feed", "frequency": "daily", "feed_url": "https://example.org/atom.xml"}`); w.Code != http.StatusOK {
+		t.Fatalf("update feed_url: status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := feedURL(); got != "https://example.org/atom.xml" {
+		t.Errorf("feed_url = %q, want the new feed", got)
+	}
+
+	// Clearing the feed makes it a search job, which needs a prompt
+	if w := update(`{"name": "Feed", "frequency": "daily", "feed_url": ""}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "name and prompt are required") {
+		t.Errorf("clearing feed_url without a prompt: status = %d, body = %s, want 400", w.Code, w.Body.String())
+	}
+	if w := update(`{"name": "Search", "prompt": "p", "frequency": "daily", "feed_url": ""}`); w.Code != http.StatusOK {
+		t.Fatalf("clearing feed_url: status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := feedURL(); got != "" {
+		t.Errorf("feed_url after clearing it = %q, want empty", got)
+	}
+}
+
+func TestJobTags(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, err := server.getOrCreateUser(authedRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	ids := map[string]string{}
+	for _, name := range []string{"Work Job", "Home Job"} {
+		job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: name, Prompt: "p", Frequency: "daily"})
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		ids[name] = strconv.FormatInt(job.ID, 10)
+	}
+
+	setTags := func(id, body string) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPut, "/api/jobs/"+id+"/tags", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		server.handleSetJobTags(w, req)
+		return w
+	}
+	w := setTags(ids["Work Job"], `{"tags": [" Work ", "tech", "work", ""]}`)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"tags":["tech","work"]}` {
+		t.Errorf("set tags: status = %d, body = %s, want tech and work", w.Code, w.Body.String())
+	}
+	setTags(ids["Home Job"], `{"tags": ["personal"]}`)
+	if w := setTags("9999", `{"tags": ["x"]}`); w.Code != http.StatusNotFound {
+		t.Errorf("set tags on a missing job: status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleListJobs(w, authedRequest(http.MethodGet, "/api/jobs?tag=work", nil))
+	var jobs []dbgen.ListJobsWithArticleCountsRow
+	json.Unmarshal(w.Body.Bytes(), &jobs)
+	if len(jobs) != 1 || jobs[0].Name != "Work Job" {
+		t.Errorf("?tag=work returned %+v, want only Work Job", jobs)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleJobsList(w, authedRequest(http.MethodGet, "/jobs?tag=personal", nil))
+	if body := w.Body.String(); !strings.Contains(body, "Home Job") || strings.Contains(body, "Work Job") {
+		t.Errorf("jobs page ?tag=personal should list only Home Job")
+	}
+
+	w = httptest.NewRecorder()
+	server.handleListTags(w, authedRequest(http.MethodGet, "/api/jobs/tags", nil))
+	if got := strings.TrimSpace(w.Body.String()); got != `["personal","tech","work"]` {
+		t.Errorf("tags = %s, want personal, tech and work", got)
+	}
+
+	// Replacing the list removes tags that aren't in it
+	setTags(ids["Work Job"], `{"tags": ["tech"]}`)
+	if tags, _ := server.Queries.ListUserTags(ctx, user.ID); fmt.Sprint(tags) != "[personal tech]" {
+		t.Errorf("tags after replacing = %v, want [personal tech]", tags)
+	}
+}
+
+// MockQueries is a *dbgen.Queries that runs its queries against a real
+// database and records the sqlc name of each, so tests can check which
+// queries a handler made. Pass its embedded Queries to WithQueries.
+type MockQueries struct {
+	*dbgen.Queries
+	mu    sync.Mutex
+	calls []string
+}
+
+func newMockQueries(db dbgen.DBTX) *MockQueries {
+	m := &MockQueries{}
+	m.Queries = dbgen.New(recordingDBTX{db, m})
+	return m
+}
+
+// record notes the query named in query's "-- name: X :kind" header.
+func (m *MockQueries) record(query string) {
+	name, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return
+	}
+	name, _, _ = strings.Cut(name, " ")
+	m.mu.Lock()
+	m.calls = append(m.calls, name)
+	m.mu.Unlock()
+}
+
+// Called reports whether the query named name has run.
+func (m *MockQueries) Called(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Contains(m.calls, name)
+}
+
+// recordingDBTX passes queries on to db, recording them in m.
+type recordingDBTX struct {
+	db dbgen.DBTX
+	m  *MockQueries
+}
+
+func (r recordingDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.m.record(query)
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+func (r recordingDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	r.m.record(query)
+	return r.db.PrepareContext(ctx, query)
+}
+
+func (r recordingDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.m.record(query)
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+func (r recordingDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	r.m.record(query)
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+func TestWithQueries(t *testing.T) {
+	server := newTestServer(t)
+	if server.Queries == nil {
+		t.Fatal("New() left Queries nil without WithQueries")
+	}
+	mock := newMockQueries(server.DB)
+	WithQueries(mock.Queries)(server)
+
+	w := httptest.NewRecorder()
+	server.handleJobsList(w, authedRequest(http.MethodGet, "/jobs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	for _, name := range []string{"GetUserByExeID", "ListJobsByUserSortedByNextRun", "ListUserTags"} {
+		if !mock.Called(name) {
+			t.Errorf("%s was not called through the injected queries; calls = %q", name, mock.calls)
+		}
+	}
+}
+
+func TestArticleImages(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "With image")
+	image := "https://cdn.news.example/rocket.jpg"
+	if _, err := server.DB.Exec("UPDATE articles SET image_url = ? WHERE id = ?", image, articles[0].ID); err != nil {
+		t.Fatalf("set image_url: %v", err)
+	}
+
+	req := authedRequest(http.MethodGet, "/api/articles/"+strconv.FormatInt(articles[0].ID, 10), nil)
+	req.SetPathValue("id", strconv.FormatInt(articles[0].ID, 10))
+	w := httptest.NewRecorder()
+	server.handleGetArticle(w, req)
+	var got dbgen.Article
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil || got.ImageUrl != image {
+		t.Errorf("GET /api/articles/{id} image_url = %q (err %v), want %q", got.ImageUrl, err, image)
+	}
+
+	tests := []struct {
+		domains  string
+		imageURL string
+		want     bool
+	}{
+		{"", image, true},
+		{"", "http://cdn.news.example/rocket.jpg", false},
+		{"news.example", image, true},
+		{"other.example", image, false},
+		{"news.example", "", false},
+	}
+	for _, tt := range tests {
+		server.imageDomains = tt.domains
+		if got := server.imageAllowed(tt.imageURL); got != tt.want {
+			t.Errorf("imageAllowed(%q) with domains %q = %v, want %v", tt.imageURL, tt.domains, got, tt.want)
+		}
+	}
+
+	server.imageDomains = "other.example"
+	shown := []dbgen.Article{{ImageUrl: image}}
+	server.hideDisallowedImages(shown)
+	if shown[0].ImageUrl != "" {
+		t.Errorf("hideDisallowedImages() kept %q", shown[0].ImageUrl)
+	}
+}
+
+func TestIsJobStale(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	tests := []struct {
+		name string
+		job  dbgen.Job
+		want bool
+	}{
+		{"recent success", dbgen.Job{IsActive: 1, Frequency: "daily", LastSuccessAt: ago(30 * time.Hour), CreatedAt: now.AddDate(0, -1, 0)}, false},
+		{"old success", dbgen.Job{IsActive: 1, Frequency: "daily", LastSuccessAt: ago(49 * time.Hour), CreatedAt: now.AddDate(0, -1, 0)}, true},
+		{"hourly", dbgen.Job{IsActive: 1, Frequency: "hourly", LastSuccessAt: ago(3 * time.Hour), CreatedAt: now.AddDate(0, -1, 0)}, true},
+		{"never succeeded", dbgen.Job{IsActive: 1, Frequency: "weekly", CreatedAt: now.AddDate(0, -1, 0)}, true},
+		{"new job", dbgen.Job{IsActive: 1, Frequency: "daily", CreatedAt: now}, false},
+		{"paused", dbgen.Job{IsActive: 0, Frequency: "daily", CreatedAt: now.AddDate(0, -1, 0)}, false},
+		{"one-time", dbgen.Job{IsActive: 1, IsOneTime: 1, Frequency: "daily", CreatedAt: now.AddDate(0, -1, 0)}, false},
+	}
+	for _, tt := range tests {
+		if got := IsJobStale(tt.job); got != tt.want {
+			t.Errorf("%s: IsJobStale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileDownloadHeaders(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	user, _ := createTestArticles(t, server)
+	job, err := server.Queries.CreateJob(ctx, dbgen.CreateJobParams{UserID: user.ID, Name: "Downloads", Prompt: "p", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "article.txt")
+	os.WriteFile(contentPath, []byte("Article text"), 0644)
+	article, err := server.Queries.CreateArticle(ctx, dbgen.CreateArticleParams{JobID: job.ID, UserID: user.ID, Title: "Mars Rover: Finds Water!", ContentPath: contentPath, RetrievedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("create article: %v", err)
+	}
+	logPath := filepath.Join(dir, "run.log")
+	os.WriteFile(logPath, []byte("log"), 0644)
+	run, err := server.Queries.CreateJobRun(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	server.Queries.UpdateJobRunLogPath(ctx, dbgen.UpdateJobRunLogPathParams{ID: run.ID, LogPath: logPath})
+
+	get := func(handler http.HandlerFunc, target string, id int64) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodGet, target, nil)
+		req.SetPathValue("id", strconv.FormatInt(id, 10))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d", target, w.Code)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("GET %s Cache-Control = %q, want no-store", target, cc)
+		}
+		return w
+	}
+
+	contentURL := fmt.Sprintf("/api/articles/%d/content", article.ID)
+	w := get(server.handleArticleContent, contentURL, article.ID)
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="mars-rover-finds-water.txt"` {
+		t.Errorf("article Content-Disposition = %q", cd)
+	}
+	w = get(server.handleArticleContent, contentURL+"?inline=1", article.ID)
+	if cd := w.Header().Get("Content-Disposition"); cd != "" {
+		t.Errorf("inline article Content-Disposition = %q, want none", cd)
+	}
+	w = get(server.handleRunLog, fmt.Sprintf("/api/runs/%d/log", run.ID), run.ID)
+	if cd, want := w.Header().Get("Content-Disposition"), fmt.Sprintf(`attachment; filename="run_%d.log"`, run.ID); cd != want {
+		t.Errorf("run log Content-Disposition = %q, want %q", cd, want)
+	}
+}
+
+func TestRateLimiterAllowWithIP(t *testing.T) {
+	rl := NewRateLimiter(time.Minute, 2)
+	for i := 0; i < 2; i++ {
+		if !rl.AllowWithIP("run-job:1", "203.0.113.1") {
+			t.Fatalf("request %d from first IP denied", i+1)
+		}
+	}
+	if rl.AllowWithIP("run-job:1", "203.0.113.1") {
+		t.Error("third request from first IP allowed")
+	}
+	if !rl.AllowWithIP("run-job:1", "203.0.113.2") {
+		t.Error("request from second IP denied, want its own bucket")
+	}
+	if !rl.Allow("run-job:1") {
+		t.Error("user-only key denied, want a bucket separate from the IP ones")
+	}
+}
+
+func TestAllowRequestPerIPLimit(t *testing.T) {
+	server := newTestServer(t)
+	fromIP := func(ip string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/1/run", nil)
+		return req.WithContext(context.WithValue(req.Context(), clientIPKey{}, ip))
+	}
+	for i := 0; i < RateLimitRequestsPerIP; i++ {
+		if !server.allowRequest(fromIP("203.0.113.1"), "run-job:1") {
+			t.Fatalf("request %d from first IP denied", i+1)
+		}
+	}
+	if server.allowRequest(fromIP("203.0.113.1"), "run-job:1") {
+		t.Error("request over the per-IP limit allowed")
+	}
+
+	// Other IPs share what's left of the user's limit
+	allowed := 0
+	for i := 0; i < RateLimitRequests; i++ {
+		if server.allowRequest(fromIP(fmt.Sprintf("198.51.100.%d", i)), "run-job:1") {
+			allowed++
+		}
+	}
+	if want := RateLimitRequests - RateLimitRequestsPerIP; allowed != want {
+		t.Errorf("requests from other IPs allowed = %d, want %d", allowed, want)
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"remote address", nil, "192.0.2.1"},
+		{"real IP", map[string]string{"X-Real-IP": "203.0.113.5", "X-Forwarded-For": "198.51.100.1"}, "203.0.113.5"},
+		{"first forwarded", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.1"}, "198.51.100.1"},
+		{"invalid headers", map[string]string{"X-Real-IP": "nope", "X-Forwarded-For": "also nope"}, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		if got := getClientIP(req); got != tt.want {
+			t.Errorf("%s: getClientIP() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStreamArticlesToCSV(t *testing.T) {
+	server := newTestServer(t)
+	user, _ := createTestArticles(t, server)
+	job, err := server.Queries.CreateJob(context.Background(), dbgen.CreateJobParams{UserID: user.ID, Name: "Export", Prompt: "p", Frequency: "daily"})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	const n = 1000
+	tx, err := server.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range n {
+		// Inserted newest first, so the export has to sort them
+		_, err := tx.Exec("INSERT INTO articles (job_id, user_id, title, url, summary, content_path, retrieved_at) VALUES (?, ?, ?, ?, ?, '', ?)",
+			job.ID, user.ID, fmt.Sprintf("Article %d, \"quoted\"", n-i), fmt.Sprintf("https://example.com/%d", n-i), "Summary\nover two lines",
+			sqliteTimestamp(base.Add(time.Duration(n-i)*time.Minute)))
+		if err != nil {
+			t.Fatalf("insert article: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var buf bytes.Buffer
+	if err := StreamArticlesToCSV(context.Background(), server.DB, user.ID, &buf, articlesFilter{}); err != nil {
+		t.Fatalf("StreamArticlesToCSV() error = %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	// The output alone is a few hundred KB; holding every article as well
+	// isn't needed, and a per-row cost much above the row itself is a leak
+	if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > 8<<20 {
+		t.Errorf("heap grew %d bytes while streaming %d articles", grown, n)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != n+1 || strings.Join(records[0], ",") != strings.Join(csvExportColumns, ",") {
+		t.Fatalf("got %d records with header %q, want %d rows and a header", len(records), records[0], n)
+	}
+	if first, last := records[1], records[n]; first[2] != `Article 1, "quoted"` || last[2] != fmt.Sprintf(`Article %d, "quoted"`, n) || first[4] != "Summary\nover two lines" {
+		t.Errorf("first row %q, last row %q, want oldest first with fields intact", first, last)
+	}
+
+	// The handler streams with download headers and applies the filters
+	w := httptest.NewRecorder()
+	server.handleExportCSV(w, authedRequest(http.MethodGet, "/api/articles/export/csv?from_time=2024-01-01T16:00:00Z", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" || w.Header().Get("X-Accel-Buffering") != "no" {
+		t.Fatalf("handler status %d, headers %v", w.Code, w.Header())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment; filename=\"articles-") {
+		t.Errorf("Content-Disposition = %q", w.Header().Get("Content-Disposition"))
+	}
+	// Article i was retrieved i minutes after midnight, so 960 to 1000 match
+	if records, _ := csv.NewReader(w.Body).ReadAll(); len(records) != 1+41 {
+		t.Errorf("filtered export has %d records, want the header and 41 articles", len(records))
+	}
+}
+
+func TestServeListenerUnixSocket(t *testing.T) {
+	t.Setenv("NEWS_DB_MONITOR_INTERVAL", "0")
+	server := newTestServer(t)
+	sock := filepath.Join(t.TempDir(), "news-app.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- server.ServeListener(ln) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://news-app/health")
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	resp.Body.Close()
+	client.CloseIdleConnections()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /health status = %d, want 200", resp.StatusCode)
+	}
+
+	if err := server.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		t.Errorf("ServeListener() error = %v, want http.ErrServerClosed", err)
+	}
+}
+
+func TestHandleJobEditForm(t *testing.T) {
+	server := newTestServer(t)
+	user, articles := createTestArticles(t, server, "First")
+	jobID := articles[0].JobID
+
+	submit := func(form url.Values) *httptest.ResponseRecorder {
+		req := authedRequest(http.MethodPost, fmt.Sprintf("/jobs/%d/edit", jobID), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", fmt.Sprint(jobID))
+		w := httptest.NewRecorder()
+		server.handleJobEditForm(w, req)
+		return w
+	}
+	form := url.Values{
+		"csrf_token": {server.csrfTokens.GetOrCreateToken("test-user-123")},
+		"name":       {"Renamed"},
+		"prompt":     {"space news"},
+		"frequency":  {"weekly"},
+		"tags":       {"Space, science"},
+		"isActive":   {"on"},
+	}
+
+	w := submit(form)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusSeeOther, w.Body.String())
+	}
+	if loc, want := w.Header().Get("Location"), fmt.Sprintf("/jobs/%d", jobID); loc != want {
+		t.Errorf("Location = %q, want %q", loc, want)
+	}
+	job, err := server.Queries.GetJob(context.Background(), dbgen.GetJobParams{ID: jobID, UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Name != "Renamed" || job.Frequency != "weekly" {
+		t.Errorf("job = %q %q, want the submitted name and frequency", job.Name, job.Frequency)
+	}
+	if tags, _ := server.Queries.ListJobTags(context.Background(), jobID); !slices.Equal(tags, []string{"science", "space"}) {
+		t.Errorf("tags = %q, want [science space]", tags)
+	}
+
+	// The token was used up by the first submission
+	server.csrfTokens.now = func() time.Time { return time.Now().Add(csrfRotationGrace + time.Second) }
+	if w := submit(form); w.Code != http.StatusForbidden {
+		t.Errorf("reused token: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	server.csrfTokens.now = time.Now
+
+	form.Set("csrf_token", server.csrfTokens.GetOrCreateToken("test-user-123"))
+	form.Set("name", "")
+	form.Set("prompt", "kept prompt")
+	w = submit(form)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid form: status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if ct := w.Result().Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("invalid form: Content-Type = %q, want text/html", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Name is required") || !strings.Contains(body, "kept prompt") {
+		t.Errorf("invalid form: page doesn't show the error and submitted values:\n%s", body)
+	}
+}
+
+func TestArticleSourceNames(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "Storm warning", "Flood defences", "Rocket launch")
+	sources := []struct{ url, name string }{
+		{"https://www.theguardian.com/storm", "The Guardian"},
+		{"https://theguardian.com/flood", ""},
+		{"https://www.reuters.com/launch", "Reuters"},
+	}
+	for i, src := range sources {
+		if _, err := server.DB.Exec("UPDATE articles SET url = ?, source_name = ? WHERE id = ?", src.url, src.name, articles[i].ID); err != nil {
+			t.Fatalf("set source: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	server.handleArticlesList(w, authedRequest(http.MethodGet, "/articles?source_name=Reuters", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "Rocket launch") || strings.Contains(body, "Storm warning") {
+		t.Errorf("GET /articles?source_name=Reuters = %d, want only the Reuters article:\n%s", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleArticleSources(w, authedRequest(http.MethodGet, "/api/articles/sources", nil))
+	var got []ArticleSource
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode sources: %v", err)
+	}
+	want := []ArticleSource{
+		{Domain: "theguardian.com", SourceName: "The Guardian", Count: 2},
+		{Domain: "reuters.com", SourceName: "Reuters", Count: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GET /api/articles/sources = %+v, want %+v", got, want)
+	}
+}
+
+func TestArticlesListSorted(t *testing.T) {
+	server := newTestServer(t)
+	_, articles := createTestArticles(t, server, "banana", "Apple", "cherry")
+	sources := []string{"Reuters", "", "BBC"}
+	for i, a := range articles {
+		// Oldest first, so retrieved_at order differs from title order
+		if _, err := server.DB.Exec("UPDATE articles SET source_name = ?, retrieved_at = datetime('now', ?) WHERE id = ?",
+			sources[i], fmt.Sprintf("-%d hours", 3-i), a.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?sort=title&order=asc", []string{"Apple", "banana", "cherry"}},
+		{"?sort=title&order=desc", []string{"cherry", "banana", "Apple"}},
+		{"?sort=source_name", []string{"cherry", "banana", "Apple"}}, // no source name last
+		{"", []string{"cherry", "Apple", "banana"}},                  // newest first
+		{"?sort=retrieved_at&order=asc", []string{"banana", "Apple", "cherry"}},
+		{"?sort=title&order=asc&job=" + strconv.FormatInt(articles[0].JobID, 10), []string{"Apple", "banana", "cherry"}},
+		{"?sort=url;DROP TABLE articles", []string{"cherry", "Apple", "banana"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := authedRequest(http.MethodGet, "/articles", nil)
+		req.URL.RawQuery = strings.TrimPrefix(tt.query, "?")
+		server.handleArticlesList(w, req)
+		body := w.Body.String()
+
+		// Order the titles by where the page shows them
+		pos := func(title string) int { return strings.Index(body, ">"+title+"</a></h4>") }
+		got := slices.DeleteFunc(slices.Clone(tt.want), func(title string) bool { return pos(title) < 0 })
+		slices.SortFunc(got, func(a, b string) int { return pos(a) - pos(b) })
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GET /articles%s order = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := authedRequest(http.MethodGet, "/articles?sort=title&order=asc", nil)
+	server.handleArticlesList(w, req)
+	if link := w.Header().Get("Link"); !strings.Contains(link, "sort=title") || !strings.Contains(link, "order=asc") {
+		t.Errorf("Link header %q doesn't keep the sort", link)
+	}
+
+	// Listings that ignore the sort don't offer it
+	for query, sortable := range map[string]bool{"": true, "q=apple": false, "q=-apple": true, "source_name=BBC": false, "collection=1": false} {
+		w := httptest.NewRecorder()
+		server.handleArticlesList(w, authedRequest(http.MethodGet, "/articles?"+query, nil))
+		if got := strings.Contains(w.Body.String(), `class="filters article-sort"`); got != sortable {
+			t.Errorf("GET /articles?%s shows sort links = %v, want %v", query, got, sortable)
+		}
+	}
+}
@@ -74,6 +74,18 @@ function getCsrfHeaders() {
     return headers;
 }
 
+// The server rotates the CSRF token after every successful protected request
+// and sends the replacement in X-New-CSRF-Token, so keep our copy current.
+const originalFetch = window.fetch.bind(window);
+window.fetch = async function(...args) {
+    const res = await originalFetch(...args);
+    const token = res.headers.get('X-New-CSRF-Token');
+    if (token) {
+        window.CSRF_TOKEN = token;
+    }
+    return res;
+};
+
 // -----------------------------------------------------------------------------
 // Job Actions
 // -----------------------------------------------------------------------------
@@ -142,6 +154,110 @@ async function cancelRun(id) {
     }
 }
 
+// -----------------------------------------------------------------------------
+// Reading List
+// -----------------------------------------------------------------------------
+
+async function addToReadingList(articleId) {
+    try {
+        const res = await fetch('/api/reading-list', {
+            method: 'POST',
+            headers: getCsrfHeaders(),
+            body: JSON.stringify({ article_id: articleId })
+        });
+        if (res.ok) {
+            showSuccess('Added', 'The article was added to your reading list.');
+        } else {
+            const err = await res.json();
+            showError('Failed to Add Article', err.error);
+        }
+    } catch (err) {
+        showError('Network Error', err.message);
+    }
+}
+
+async function removeFromReadingList(articleId) {
+    try {
+        const res = await fetch(`/api/reading-list/${articleId}`, { method: 'DELETE', headers: getCsrfHeaders() });
+        if (res.ok) {
+            document.querySelector(`#reading-list [data-article-id="${articleId}"]`)?.remove();
+        } else {
+            const err = await res.json();
+            showError('Failed to Remove Article', err.error);
+        }
+    } catch (err) {
+        showError('Network Error', err.message);
+    }
+}
+
+async function moveReadingListItem(articleId, direction) {
+    const list = document.getElementById('reading-list');
+    const item = list?.querySelector(`[data-article-id="${articleId}"]`);
+    if (!item) return;
+
+    const sibling = direction < 0 ? item.previousElementSibling : item.nextElementSibling;
+    if (!sibling) return;
+    if (direction < 0) {
+        list.insertBefore(item, sibling);
+    } else {
+        list.insertBefore(sibling, item);
+    }
+
+    const ids = Array.from(list.querySelectorAll('[data-article-id]')).map(el => parseInt(el.dataset.articleId));
+    try {
+        const res = await fetch('/api/reading-list/reorder', {
+            method: 'PUT',
+            headers: getCsrfHeaders(),
+            body: JSON.stringify({ ids: ids })
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            showError('Failed to Reorder', err.error);
+        }
+    } catch (err) {
+        showError('Network Error', err.message);
+    }
+}
+
+// -----------------------------------------------------------------------------
+// Article Archive
+// -----------------------------------------------------------------------------
+
+async function restoreArticle(articleId) {
+    try {
+        const res = await fetch(`/api/articles/${articleId}/restore`, { method: 'POST', headers: getCsrfHeaders() });
+        if (res.ok) {
+            document.querySelector(`#archive-list [data-article-id="${articleId}"]`)?.remove();
+            showSuccess('Restored', 'The article is back in your article list.');
+        } else {
+            const err = await res.json();
+            showError('Failed to Restore Article', err.error);
+        }
+    } catch (err) {
+        showError('Network Error', err.message);
+    }
+}
+
+async function purgeArchive() {
+    if (!confirm('Permanently delete articles archived more than 30 days ago? This cannot be undone.')) {
+        return;
+    }
+    try {
+        const res = await fetch('/api/articles/purge', { method: 'POST', headers: getCsrfHeaders() });
+        const result = await res.json();
+        if (res.ok) {
+            showSuccess('Archive Purged', `${result.purged} article${result.purged === 1 ? '' : 's'} permanently deleted.`);
+            if (result.purged > 0) {
+                setTimeout(() => location.reload(), 1000);
+            }
+        } else {
+            showError('Failed to Purge Archive', result.error);
+        }
+    } catch (err) {
+        showError('Network Error', err.message);
+    }
+}
+
 // -----------------------------------------------------------------------------
 // Run Duration Timer
 // -----------------------------------------------------------------------------
@@ -188,6 +304,19 @@ async function submitJobForm(form, method, url, redirectUrl) {
     if (form.elements.isActive) {
         data.is_active = form.elements.isActive.checked;
     }
+    if (form.elements.feedUrl) {
+        data.feed_url = form.elements.feedUrl.value;
+    }
+    if (form.elements.fetchHeaders) {
+        data.fetch_headers = form.elements.fetchHeaders.value;
+        data.fetch_header_domains = form.elements.fetchHeaderDomains.value;
+    }
+    if (form.elements.promptTemplate) {
+        data.prompt_template = form.elements.promptTemplate.value;
+    }
+    if (form.elements.tags) {
+        data.tags = form.elements.tags.value.split(',').map(t => t.trim()).filter(t => t);
+    }
     
     try {
         const res = await fetch(url, {
@@ -219,7 +348,10 @@ async function submitPreferencesForm(form) {
         system_prompt: form.systemPrompt.value,
         discord_webhook: form.discordWebhook.value,
         notify_success: form.notifySuccess.checked,
-        notify_failure: form.notifyFailure.checked
+        notify_failure: form.notifyFailure.checked,
+        notify_weekly_digest: form.notifyWeeklyDigest.checked,
+        digest_day: form.digestDay.value,
+        use_discord_embeds: form.useDiscordEmbeds.checked
     };
     
     try {
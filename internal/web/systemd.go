@@ -1,10 +1,15 @@
 package web
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 
 	"github.com/exedev/news-app/internal/db/dbgen"
 	"github.com/exedev/news-app/internal/util"
@@ -24,6 +29,29 @@ var (
 )
 
 func createSystemdTimer(job dbgen.Job) error {
+	if err := writeJobUnitFiles(job); err != nil {
+		return err
+	}
+	serviceName := jobServiceName(job.ID)
+	
+	if job.IsOneTime == 0 {
+		// Enable and start timer
+		exec.Command("sudo", "systemctl", "daemon-reload").Run()
+		exec.Command("sudo", "systemctl", "enable", serviceName+".timer").Run()
+		exec.Command("sudo", "systemctl", "start", serviceName+".timer").Run()
+	} else {
+		// For one-time jobs, just reload and run immediately (in background)
+		exec.Command("sudo", "systemctl", "daemon-reload").Run()
+		// Use --no-block to avoid waiting for job completion
+		exec.Command("sudo", "systemctl", "start", "--no-block", serviceName+".service").Run()
+	}
+	
+	return nil
+}
+
+// writeJobUnitFiles writes a job's service file and, for recurring jobs, its
+// timer file, without enabling or starting anything.
+func writeJobUnitFiles(job dbgen.Job) error {
 	serviceName := jobServiceName(job.ID)
 	
 	// Create service file
@@ -64,16 +92,6 @@ WantedBy=timers.target
 		if err := writeFileWithSudo(timerPath, timerContent); err != nil {
 			return fmt.Errorf("write timer file: %w", err)
 		}
-		
-		// Enable and start timer
-		exec.Command("sudo", "systemctl", "daemon-reload").Run()
-		exec.Command("sudo", "systemctl", "enable", serviceName+".timer").Run()
-		exec.Command("sudo", "systemctl", "start", serviceName+".timer").Run()
-	} else {
-		// For one-time jobs, just reload and run immediately (in background)
-		exec.Command("sudo", "systemctl", "daemon-reload").Run()
-		// Use --no-block to avoid waiting for job completion
-		exec.Command("sudo", "systemctl", "start", "--no-block", serviceName+".service").Run()
 	}
 	
 	return nil
@@ -98,6 +116,13 @@ func updateSystemdTimer(job dbgen.Job) error {
 	return createSystemdTimer(job)
 }
 
+// UpdateJobTimer rewrites a job's systemd unit files for its current name
+// and frequency, and stops its timer if the job is inactive. It is for
+// commands that change jobs outside the web server.
+func UpdateJobTimer(job dbgen.Job) error {
+	return updateSystemdTimer(job)
+}
+
 func removeSystemdTimer(jobID int64) {
 	serviceName := jobServiceName(jobID)
 	
@@ -112,7 +137,134 @@ func removeSystemdTimer(jobID int64) {
 	exec.Command("sudo", "systemctl", "daemon-reload").Run()
 }
 
+// SystemdReport lists the differences between the jobs in the database and
+// the unit files in the systemd directory.
+type SystemdReport struct {
+	MissingServices []int64  // active jobs without a .service file
+	MissingTimers   []int64  // active recurring jobs without a .timer file
+	DisabledTimers  []int64  // active recurring jobs whose timer isn't enabled
+	OrphanedFiles   []string // unit files for jobs that no longer exist
+}
 
+// OK reports whether the database and unit files are consistent.
+func (r SystemdReport) OK() bool {
+	return len(r.MissingServices) == 0 && len(r.MissingTimers) == 0 &&
+		len(r.DisabledTimers) == 0 && len(r.OrphanedFiles) == 0
+}
+
+// jobUnitFileRE matches the unit files createSystemdTimer writes.
+var jobUnitFileRE = regexp.MustCompile(`^news-job-(\d+)\.(service|timer)$`)
+
+// SystemdVerifier checks that every active job has its systemd unit files
+// and that no unit files are left over from deleted jobs.
+type SystemdVerifier struct {
+	queries   *dbgen.Queries
+	isEnabled func(unit string) bool // systemctl is-enabled; stubbed in tests
+}
+
+// NewSystemdVerifier returns a verifier for the jobs in queries' database
+// and the unit files in NEWS_APP_SYSTEMD_DIR.
+func NewSystemdVerifier(queries *dbgen.Queries) *SystemdVerifier {
+	return &SystemdVerifier{queries: queries, isEnabled: systemdUnitEnabled}
+}
+
+// systemdUnitEnabled reports whether systemctl considers unit enabled.
+func systemdUnitEnabled(unit string) bool {
+	return exec.Command("systemctl", "is-enabled", "--quiet", unit).Run() == nil
+}
+
+// Verify compares the active jobs with the unit files on disk.
+func (v *SystemdVerifier) Verify(ctx context.Context) (SystemdReport, error) {
+	var report SystemdReport
+
+	jobs, err := v.queries.ListActiveJobs(ctx)
+	if err != nil {
+		return report, fmt.Errorf("list active jobs: %w", err)
+	}
+	for _, job := range jobs {
+		name := jobServiceName(job.ID)
+		if !fileExists(filepath.Join(systemdDir, name+".service")) {
+			report.MissingServices = append(report.MissingServices, job.ID)
+		}
+		if job.IsOneTime == 1 {
+			continue
+		}
+		if !fileExists(filepath.Join(systemdDir, name+".timer")) {
+			report.MissingTimers = append(report.MissingTimers, job.ID)
+		} else if !v.isEnabled(name + ".timer") {
+			report.DisabledTimers = append(report.DisabledTimers, job.ID)
+		}
+	}
+
+	entries, err := os.ReadDir(systemdDir)
+	if err != nil {
+		return report, fmt.Errorf("read systemd dir: %w", err)
+	}
+	for _, entry := range entries {
+		m := jobUnitFileRE.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.ParseInt(m[1], 10, 64)
+		_, err := v.queries.GetJobByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			report.OrphanedFiles = append(report.OrphanedFiles, entry.Name())
+		} else if err != nil {
+			return report, fmt.Errorf("get job %d: %w", id, err)
+		}
+	}
+	return report, nil
+}
+
+// Fix resolves the problems in report: missing unit files are written,
+// disabled timers are enabled and orphaned unit files are removed. One-time
+// jobs get their service file back without being started.
+func (v *SystemdVerifier) Fix(ctx context.Context, report SystemdReport) error {
+	missing := make(map[int64]bool)
+	for _, id := range append(report.MissingServices, report.MissingTimers...) {
+		missing[id] = true
+	}
+	for id := range missing {
+		job, err := v.queries.GetJobByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("get job %d: %w", id, err)
+		}
+		if job.IsOneTime == 1 {
+			err = writeJobUnitFiles(job)
+			exec.Command("sudo", "systemctl", "daemon-reload").Run()
+		} else {
+			err = createSystemdTimer(job)
+		}
+		if err != nil {
+			return fmt.Errorf("job %d: %w", id, err)
+		}
+	}
+
+	for _, id := range report.DisabledTimers {
+		if err := exec.Command("sudo", "systemctl", "enable", "--now", jobServiceName(id)+".timer").Run(); err != nil {
+			return fmt.Errorf("enable timer for job %d: %w", id, err)
+		}
+	}
+
+	removed := make(map[int64]bool)
+	for _, name := range report.OrphanedFiles {
+		m := jobUnitFileRE.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.ParseInt(m[1], 10, 64)
+		if !removed[id] {
+			removeSystemdTimer(id)
+			removed[id] = true
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
 func writeFileWithSudo(path, content string) error {
 	// Write to temp file then move with sudo
@@ -135,9 +287,13 @@ func writeFileWithSudo(path, content string) error {
 
 // runJobDirectly runs a job as a separate process (not as part of the web server).
 // This ensures jobs survive web server restarts.
-func runJobDirectly(jobID int64) {
+// A positive maxParallel overrides NEWS_JOB_MAX_PARALLEL for this run only.
+func runJobDirectly(jobID int64, maxParallel int) {
 	cmd := exec.Command(jobRunnerPath, jobRunnerArgs, fmt.Sprintf("%d", jobID))
 	cmd.Dir = workingDir
+	if maxParallel > 0 {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("NEWS_JOB_MAX_PARALLEL=%d", maxParallel))
+	}
 	
 	// Run in background - don't wait for completion
 	// The process will run independently of the web server